@@ -12,12 +12,15 @@ import (
 
 	"zpwoot/internal/api"
 	"zpwoot/internal/api/handlers"
+	"zpwoot/internal/api/middleware"
 	"zpwoot/internal/config"
 	"zpwoot/internal/db"
+	"zpwoot/internal/model"
 	natsclient "zpwoot/internal/nats"
 	"zpwoot/internal/repository"
 	"zpwoot/internal/service"
 	"zpwoot/pkg/logger"
+	"zpwoot/pkg/secrets"
 
 	_ "zpwoot/docs" // Importa a documentação gerada pelo swag
 )
@@ -55,6 +58,17 @@ func main() {
 	// Reconfigure logger with config level
 	logger.Init(config.AppConfig.LogLevel)
 
+	// Cifragem dos segredos gravados no banco (antes de qualquer leitura de sessão)
+	if config.AppConfig.SecretsMasterKey != "" {
+		keyring, err := secrets.NewKeyring(config.AppConfig.SecretsMasterKey, config.AppConfig.SecretsPreviousKeys...)
+		if err != nil {
+			logger.Log.Fatal().Err(err).Msg("Failed to load secrets master key")
+		}
+		secrets.SetDefault(keyring)
+	} else {
+		logger.Log.Warn().Msg("SECRETS_MASTER_KEY not set - proxy passwords, tokens and API keys are stored in plaintext")
+	}
+
 	// Initialize database
 	if err := db.InitDB(); err != nil {
 		logger.Log.Fatal().Err(err).Msg("Failed to initialize database")
@@ -84,15 +98,126 @@ func main() {
 
 	// Initialize repositories
 	sessionRepo := repository.NewSessionRepository(db.DB)
+	messageRepo := repository.NewMessageRepository(db.DB)
+	jobRepo := repository.NewJobRepository(db.DB)
+
+	// Initialize event stream (SSE/WebSocket)
+	eventStreamHub := service.NewEventStreamHub(natsClient, config.AppConfig.EventStreamBufferSize)
+	if err := eventStreamHub.Start(); err != nil {
+		logger.Log.Fatal().Err(err).Msg("Failed to start event stream")
+	}
 
 	// Initialize webhook services
 	webhookFormatter := service.NewWebhookFormatter()
-	webhookProcessor := service.NewWebhookProcessor(natsClient, webhookFormatter, sessionRepo)
+	webhookProcessor := service.NewWebhookProcessor(natsClient, webhookFormatter, sessionRepo, eventStreamHub)
 	webhookDelivery := service.NewWebhookDelivery(config.AppConfig.WebhookTimeout)
 
 	// Initialize services
-	sessionManager := service.NewSessionManager(whatsappSvc, sessionRepo, webhookProcessor, webhookFormatter)
+	historySyncService := service.NewHistorySyncService(messageRepo)
+	sessionManagerConfig := service.SessionManagerConfig{
+		MaxSessions:       config.AppConfig.MaxSessions,
+		ConnectionTimeout: time.Duration(config.AppConfig.ConnectionTimeout) * time.Second,
+		PairingTimeout:    time.Duration(config.AppConfig.PairingTimeout) * time.Second,
+		Reconnect: service.ReconnectConfig{
+			BaseDelay: config.AppConfig.ReconnectBaseDelay,
+			MaxDelay:  config.AppConfig.ReconnectMaxDelay,
+
+			KeepAliveMaxFailures: config.AppConfig.KeepAliveMaxFailures,
+		},
+	}
+	sessionManager := service.NewSessionManager(whatsappSvc, sessionRepo, webhookProcessor, webhookFormatter, historySyncService, sessionManagerConfig)
+	sessionManager.SetEventStream(eventStreamHub)
 	pairingService := service.NewPairingService(whatsappSvc, sessionRepo, sessionManager)
+	templateService := service.NewTemplateService(repository.NewTemplateRepository(db.DB))
+
+	// Segredos em texto puro ou cifrados com uma chave mestra anterior
+	tenantRepo := repository.NewTenantRepository(db.DB)
+	if keyring := secrets.Default(); keyring != nil {
+		if err := service.RotateSecrets(context.Background(), keyring, sessionRepo, tenantRepo); err != nil {
+			logger.Log.Fatal().Err(err).Msg("Failed to reencrypt secrets")
+		}
+	}
+
+	// Tenants: cota de sessões e webhook padrão na criação de sessões
+	tenantService := service.NewTenantService(tenantRepo)
+	sessionManager.SetTenants(tenantService)
+	apiKeyService := service.NewAPIKeyService(repository.NewAPIKeyRepository(db.DB), tenantService)
+
+	// Bearer tokens JWT do IdP (opcional)
+	var tokenVerifier middleware.TokenVerifier
+	if config.AppConfig.JWTJWKSURL != "" || config.AppConfig.JWTPublicKey != "" {
+		jwtVerifier, err := service.NewJWTVerifier(service.JWTConfig{
+			JWKSURL:       config.AppConfig.JWTJWKSURL,
+			PublicKey:     config.AppConfig.JWTPublicKey,
+			Issuer:        config.AppConfig.JWTIssuer,
+			Audience:      config.AppConfig.JWTAudience,
+			ScopesClaim:   config.AppConfig.JWTScopesClaim,
+			SessionsClaim: config.AppConfig.JWTSessionsClaim,
+			TenantClaim:   config.AppConfig.JWTTenantClaim,
+			JWKSRefresh:   config.AppConfig.JWTJWKSRefresh,
+			Leeway:        config.AppConfig.JWTLeeway,
+		}, tenantService)
+		if err != nil {
+			logger.Log.Fatal().Err(err).Msg("Failed to configure JWT authentication")
+		}
+		tokenVerifier = jwtVerifier
+	}
+
+	// Respostas automáticas (ligadas antes de restaurar as sessões)
+	autoResponder := service.NewAutoResponder(
+		repository.NewAutoReplyRepository(db.DB),
+		sessionManager,
+		templateService,
+		service.AutoResponderConfig{
+			CacheTTL: config.AppConfig.AutoReplyCacheTTL,
+		},
+	)
+	sessionManager.SetAutoResponder(autoResponder)
+
+	// Inbox do Chatwoot por sessão
+	chatwootService := service.NewChatwootService(
+		sessionRepo,
+		repository.NewChatwootRepository(db.DB),
+		sessionManager,
+		service.ChatwootServiceConfig{
+			Timeout: config.AppConfig.ChatwootTimeout,
+		},
+	)
+	sessionManager.SetChatwoot(chatwootService)
+
+	// Fluxo (Typebot) por sessão
+	flowBotService := service.NewFlowBotService(
+		sessionRepo,
+		repository.NewFlowBotRepository(db.DB),
+		sessionManager,
+		service.FlowBotServiceConfig{
+			Timeout: config.AppConfig.FlowBotTimeout,
+		},
+	)
+	sessionManager.SetFlowBot(flowBotService)
+
+	// Assistente (LLM) por sessão
+	assistantService := service.NewAssistantService(
+		sessionRepo,
+		repository.NewAssistantRepository(db.DB),
+		sessionManager,
+		service.AssistantServiceConfig{
+			Timeout: config.AppConfig.AssistantTimeout,
+		},
+	)
+	sessionManager.SetAssistant(assistantService)
+
+	// Transcrição de mensagens de voz
+	sessionManager.SetTranscription(service.NewTranscriptionService(
+		sessionRepo,
+		messageRepo,
+		sessionManager,
+		service.TranscriptionServiceConfig{
+			Timeout:      config.AppConfig.TranscriptionTimeout,
+			MaxAudioSize: config.AppConfig.TranscriptionMaxSizeMB << 20,
+			Concurrency:  config.AppConfig.TranscriptionConcurrency,
+		},
+	))
 
 	// Start webhook workers
 	webhookWorkers := make([]*service.WebhookWorker, config.AppConfig.WebhookWorkers)
@@ -116,6 +241,62 @@ func main() {
 		Int("workers", config.AppConfig.WebhookWorkers).
 		Msg("✅ Webhook workers started")
 
+	// Start event sinks (destinos externos além dos webhooks)
+	var eventSinks []service.EventSink
+	if config.AppConfig.AMQPURL != "" {
+		amqpSink := service.NewAMQPSink(service.AMQPSinkConfig{
+			URL:          config.AppConfig.AMQPURL,
+			Exchange:     config.AppConfig.AMQPExchange,
+			ExchangeType: config.AppConfig.AMQPExchangeType,
+			RoutingKey:   config.AppConfig.AMQPRoutingKey,
+		})
+		// Falha aqui não é fatal: a conexão é refeita na próxima publicação
+		if err := amqpSink.Connect(); err != nil {
+			logger.Log.Warn().Err(err).Msg("AMQP event sink unavailable, will retry on publish")
+		}
+		eventSinks = append(eventSinks, amqpSink)
+	}
+	if len(config.AppConfig.KafkaBrokers) > 0 {
+		kafkaSink, err := service.NewKafkaSink(service.KafkaSinkConfig{
+			Brokers:         config.AppConfig.KafkaBrokers,
+			Topic:           config.AppConfig.KafkaTopic,
+			ClientID:        config.AppConfig.KafkaClientID,
+			BatchMaxBytes:   int32(config.AppConfig.KafkaBatchMaxBytes),
+			Linger:          config.AppConfig.KafkaLinger,
+			Compression:     config.AppConfig.KafkaCompression,
+			Idempotent:      config.AppConfig.KafkaIdempotent,
+			DeliveryTimeout: config.AppConfig.KafkaDeliveryTimeout,
+		})
+		if err != nil {
+			logger.Log.Fatal().Err(err).Msg("Failed to create Kafka event sink")
+		}
+		pingCtx, pingCancel := context.WithTimeout(context.Background(), 10*time.Second)
+		if err := kafkaSink.Ping(pingCtx); err != nil {
+			logger.Log.Warn().Err(err).Msg("Kafka brokers unreachable, producer will keep retrying")
+		}
+		pingCancel()
+		eventSinks = append(eventSinks, kafkaSink)
+	}
+
+	eventSinkConfig := service.EventSinkConfig{
+		Partitions:     config.AppConfig.EventSinkPartitions,
+		PublishTimeout: config.AppConfig.EventSinkPublishTimeout,
+		MaxRetries:     config.AppConfig.EventSinkMaxRetries,
+		RetryBaseDelay: config.AppConfig.EventSinkRetryBaseDelay,
+	}
+	eventSinkWorkers := make([]*service.EventSinkWorker, 0, len(eventSinks))
+	for _, sink := range eventSinks {
+		worker := service.NewEventSinkWorker(natsClient, sink, eventSinkConfig)
+		if err := worker.Start(context.Background()); err != nil {
+			logger.Log.Fatal().
+				Err(err).
+				Str("sink", sink.Name()).
+				Msg("Failed to start event sink worker")
+		}
+		eventSinkWorkers = append(eventSinkWorkers, worker)
+		logger.Log.Info().Str("sink", sink.Name()).Msg("✅ Event sink started")
+	}
+
 	// Restore sessions if configured
 	if config.AppConfig.AutoRestoreSessions {
 		if err := sessionManager.RestoreAllSessions(context.Background()); err != nil {
@@ -133,8 +314,126 @@ func main() {
 	}
 
 	// Initialize handlers
-	sessionHandler := handlers.NewSessionHandler(sessionManager, pairingService)
+	sessionHandler := handlers.NewSessionHandler(sessionManager, pairingService, config.AppConfig.WSOriginPatterns)
 	messageHandler := handlers.NewMessageHandler(sessionManager)
+	newsletterHandler := handlers.NewNewsletterHandler(sessionManager)
+	eventStreamHandler := handlers.NewEventStreamHandler(sessionManager, eventStreamHub, config.AppConfig.EventStreamHeartbeat, config.AppConfig.WSOriginPatterns)
+	messageDispatcher := handlers.NewMessageDispatcher(sessionManager)
+	templateHandler := handlers.NewTemplateHandler(sessionManager, templateService)
+
+	// Start outbound queue (JetStream)
+	outboundQueue := service.NewOutboundQueue(
+		natsClient,
+		jobRepo,
+		sessionManager,
+		webhookProcessor,
+		webhookFormatter,
+		messageDispatcher,
+		service.OutboundQueueConfig{
+			RateLimit: model.RateLimitConfig{
+				MessagesPerMinute: config.AppConfig.OutboundRatePerMinute,
+				Burst:             config.AppConfig.OutboundBurst,
+				RecipientSpacing:  config.AppConfig.OutboundRecipientSpacing,
+			},
+			MaxAttempts: config.AppConfig.OutboundMaxAttempts,
+			RetryDelay:  config.AppConfig.OutboundRetryDelay,
+		},
+	)
+	if err := outboundQueue.Start(context.Background()); err != nil {
+		logger.Log.Fatal().Err(err).Msg("Failed to start outbound queue")
+	}
+	sessionManager.SetOutboundQueue(outboundQueue)
+	jobHandler := handlers.NewJobHandler(sessionManager, outboundQueue, templateService)
+
+	// Start message scheduler (agendamentos no Postgres, seguro com várias réplicas)
+	messageScheduler := service.NewMessageScheduler(
+		repository.NewScheduleRepository(db.DB),
+		outboundQueue,
+		service.MessageSchedulerConfig{
+			Interval:        config.AppConfig.SchedulerInterval,
+			BatchSize:       config.AppConfig.SchedulerBatchSize,
+			DefaultTimezone: config.AppConfig.SchedulerDefaultTimezone,
+		},
+	)
+	messageScheduler.Start()
+	scheduleHandler := handlers.NewScheduleHandler(sessionManager, messageScheduler, templateService)
+
+	// Start campaign runner (lease no Postgres, seguro com várias réplicas)
+	campaignService := service.NewCampaignService(
+		repository.NewCampaignRepository(db.DB),
+		sessionManager,
+		service.CampaignConfig{
+			PollInterval:  config.AppConfig.CampaignPollInterval,
+			LeaseDuration: config.AppConfig.CampaignLeaseDuration,
+		},
+	)
+	campaignService.Start()
+	campaignHandler := handlers.NewCampaignHandler(sessionManager, campaignService)
+	autoReplyHandler := handlers.NewAutoReplyHandler(sessionManager, autoResponder)
+	chatwootHandler := handlers.NewChatwootHandler(sessionManager, chatwootService)
+	flowBotHandler := handlers.NewFlowBotHandler(sessionManager, flowBotService)
+	assistantHandler := handlers.NewAssistantHandler(sessionManager, assistantService)
+	tenantHandler := handlers.NewTenantHandler(tenantService)
+	apiKeyHandler := handlers.NewAPIKeyHandler(apiKeyService)
+
+	// Start audit log (gravação assíncrona e retenção)
+	auditService := service.NewAuditService(
+		repository.NewAuditRepository(db.DB),
+		service.AuditConfig{
+			Retention:  config.AppConfig.AuditRetention,
+			BufferSize: config.AppConfig.AuditBufferSize,
+		},
+	)
+	auditService.Start()
+	auditHandler := handlers.NewAuditHandler(auditService)
+	var auditRecorder middleware.AuditRecorder
+	if config.AppConfig.AuditEnabled {
+		auditRecorder = auditService
+	}
+
+	// Rate limit da API (por API key e por IP)
+	var requestLimiter middleware.RequestLimiter
+	if config.AppConfig.RateLimitEnabled {
+		limiterConfig := service.RequestLimiterConfig{
+			Read: model.RequestRate{PerMinute: config.AppConfig.RateLimitReadPerMinute, Burst: config.AppConfig.RateLimitReadBurst},
+			Send: model.RequestRate{PerMinute: config.AppConfig.RateLimitSendPerMinute, Burst: config.AppConfig.RateLimitSendBurst},
+			IP:   model.RequestRate{PerMinute: config.AppConfig.RateLimitIPPerMinute, Burst: config.AppConfig.RateLimitIPBurst},
+		}
+
+		var limitStore service.RequestLimitStore
+		switch config.AppConfig.RateLimitStore {
+		case "memory":
+			limitStore = service.NewMemoryLimitStore()
+		case "nats":
+			natsStore, err := service.NewNATSLimitStore(context.Background(), natsClient, service.RequestLimitTTL(limiterConfig))
+			if err != nil {
+				logger.Log.Fatal().Err(err).Msg("Failed to create NATS rate limit store")
+			}
+			limitStore = natsStore
+		default:
+			logger.Log.Fatal().Str("store", config.AppConfig.RateLimitStore).Msg("Invalid RATE_LIMIT_STORE (use memory or nats)")
+		}
+
+		requestLimiter = service.NewRequestLimiter(limitStore, limiterConfig)
+		logger.Log.Info().Str("store", config.AppConfig.RateLimitStore).Msg("✅ API rate limit enabled")
+	}
+
+	// Start NATS command API (request/reply)
+	var commandHandler *handlers.CommandHandler
+	if config.AppConfig.NATSCommandsEnabled {
+		commandHandler = handlers.NewCommandHandler(
+			sessionManager,
+			messageDispatcher,
+			templateService,
+			natsClient,
+			config.AppConfig.NATSCommandPrefix,
+			config.AppConfig.NATSCommandQueue,
+			config.AppConfig.NATSCommandTimeout,
+		)
+		if err := commandHandler.Start(); err != nil {
+			logger.Log.Fatal().Err(err).Msg("Failed to start NATS command API")
+		}
+	}
 
 	// Setup Gin
 	gin.SetMode(gin.ReleaseMode)
@@ -142,8 +441,33 @@ func main() {
 	r := gin.New()
 	r.Use(gin.Recovery())
 
+	// Sem proxies confiáveis, c.ClientIP() é o IP da conexão e X-Forwarded-For/X-Real-IP são ignorados
+	if err := r.SetTrustedProxies(config.AppConfig.TrustedProxies); err != nil {
+		logger.Log.Fatal().Err(err).Msg("Invalid TRUSTED_PROXIES")
+	}
+
 	// Register routes
-	api.RegisterRoutes(r, sessionHandler, messageHandler)
+	api.RegisterRoutes(r, api.Deps{
+		SessionHandler:     sessionHandler,
+		MessageHandler:     messageHandler,
+		NewsletterHandler:  newsletterHandler,
+		EventStreamHandler: eventStreamHandler,
+		JobHandler:         jobHandler,
+		ScheduleHandler:    scheduleHandler,
+		CampaignHandler:    campaignHandler,
+		TemplateHandler:    templateHandler,
+		AutoReplyHandler:   autoReplyHandler,
+		ChatwootHandler:    chatwootHandler,
+		FlowBotHandler:     flowBotHandler,
+		AssistantHandler:   assistantHandler,
+		TenantHandler:      tenantHandler,
+		APIKeyHandler:      apiKeyHandler,
+		AuditHandler:       auditHandler,
+		KeyResolver:        apiKeyService,
+		TokenVerifier:      tokenVerifier,
+		AuditRecorder:      auditRecorder,
+		RequestLimiter:     requestLimiter,
+	})
 
 	// Server info
 	port := config.AppConfig.Port
@@ -170,11 +494,36 @@ func main() {
 	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
 	defer cancel()
 
+	if commandHandler != nil {
+		if err := commandHandler.Stop(); err != nil {
+			logger.Log.Error().Err(err).Msg("Error stopping NATS command API")
+		}
+	}
+
+	assistantService.Stop()
+	flowBotService.Stop()
+	chatwootService.Stop()
+	campaignService.Stop()
+	messageScheduler.Stop()
+	outboundQueue.Stop()
+
 	// Shutdown all sessions
 	logger.Log.Info().Msg("Disconnecting all sessions...")
 	if err := sessionManager.Shutdown(ctx); err != nil {
 		logger.Log.Error().Err(err).Msg("Error during session shutdown")
 	}
 
+	auditService.Stop()
+
+	if err := eventStreamHub.Stop(); err != nil {
+		logger.Log.Error().Err(err).Msg("Error stopping event stream")
+	}
+
+	for _, worker := range eventSinkWorkers {
+		if err := worker.Stop(); err != nil {
+			logger.Log.Error().Err(err).Msg("Error stopping event sink")
+		}
+	}
+
 	logger.Log.Info().Msg("✅ Server shutdown complete")
 }
@@ -0,0 +1,33 @@
+package dto
+
+import "time"
+
+type CreateAPIKeyRequest struct {
+	Name       string     `json:"name" binding:"required,min=3,max=100" example:"painel-relatorios"`
+	Scopes     []string   `json:"scopes" binding:"required,min=1" example:"sessions:read"` // sessions:read, sessions:write, messages:send, webhooks:manage, admin
+	AllowedIPs []string   `json:"allowed_ips,omitempty" example:"203.0.113.10,10.0.0.0/8"` // IPs ou CIDRs (vazio = qualquer origem)
+	ExpiresAt  *time.Time `json:"expires_at,omitempty" example:"2026-12-31T23:59:59Z"`     // Vazio = não expira
+	TenantID   string     `json:"tenant_id,omitempty"`                                     // Apenas chaves do servidor; chaves de tenant criam no próprio tenant
+}
+
+type APIKeyResponse struct {
+	ID         string     `json:"id" example:"7d4f1a52-2a7e-4c4b-9a0c-5a1b8e2f3c4d"`
+	TenantID   string     `json:"tenant_id,omitempty"`
+	Name       string     `json:"name" example:"painel-relatorios"`
+	KeyPrefix  string     `json:"key_prefix" example:"zpw_1a2b3c4d"`
+	Scopes     []string   `json:"scopes" example:"sessions:read"`
+	AllowedIPs []string   `json:"allowed_ips,omitempty"`
+	ExpiresAt  *time.Time `json:"expires_at,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
+
+// APIKeyCreatedResponse é retornado na criação: o segredo não é exibido novamente
+type APIKeyCreatedResponse struct {
+	Key    APIKeyResponse `json:"key"`
+	APIKey string         `json:"api_key" example:"zpw_1a2b3c4d5e6f..."`
+}
+
+type APIKeyListResponse struct {
+	Keys  []APIKeyResponse `json:"keys"`
+	Total int              `json:"total" example:"1"`
+}
@@ -0,0 +1,32 @@
+package dto
+
+import (
+	"encoding/json"
+	"time"
+)
+
+type AuditEntryResponse struct {
+	ID             int64           `json:"id" example:"1024"`
+	TenantID       string          `json:"tenant_id,omitempty"`
+	ActorKeyID     string          `json:"actor_key_id,omitempty"`
+	ActorKeyPrefix string          `json:"actor_key_prefix,omitempty" example:"zpw_1a2b3c4d"`
+	ActorName      string          `json:"actor_name,omitempty" example:"painel-relatorios"`
+	IP             string          `json:"ip" example:"203.0.113.10"`
+	Method         string          `json:"method" example:"POST"`
+	Route          string          `json:"route" example:"/sessions/:id/message/text"`
+	Path           string          `json:"path" example:"/sessions/5f2c.../message/text"`
+	RequestID      string          `json:"request_id"`
+	SessionID      string          `json:"session_id,omitempty"`
+	SessionJID     string          `json:"session_jid,omitempty" example:"5511999999999:12@s.whatsapp.net"`
+	Summary        json.RawMessage `json:"summary,omitempty" swaggertype:"object"` // Query e corpo com segredos mascarados
+	Status         int             `json:"status" example:"200"`
+	Result         string          `json:"result" example:"success"` // success, denied, failure
+	DurationMs     int64           `json:"duration_ms" example:"42"`
+	CreatedAt      time.Time       `json:"created_at"`
+}
+
+type AuditListResponse struct {
+	Entries []AuditEntryResponse `json:"entries"`
+	Limit   int                  `json:"limit" example:"100"`
+	Offset  int                  `json:"offset" example:"0"`
+}
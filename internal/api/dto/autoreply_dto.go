@@ -0,0 +1,57 @@
+package dto
+
+import "time"
+
+type BusinessHoursRange struct {
+	Weekdays []int  `json:"weekdays" binding:"required,min=1,dive,min=0,max=6" example:"1,2,3,4,5"` // 0 = domingo
+	Start    string `json:"start" binding:"required" example:"09:00"`
+	End      string `json:"end" binding:"required" example:"18:00"`
+}
+
+type BusinessHours struct {
+	Timezone string               `json:"timezone" binding:"required" example:"America/Sao_Paulo"`
+	Ranges   []BusinessHoursRange `json:"ranges" binding:"required,min=1,dive"`
+}
+
+// AutoReplyResponse é a mensagem enviada pela regra. Os textos aceitam {{name}} e {{phone}} do contato;
+// com templateId o conteúdo vem do template (renderizado com variables).
+type AutoReplyResponse struct {
+	Type       string            `json:"type" binding:"required,oneof=text image video audio document" example:"text"`
+	Text       string            `json:"text,omitempty" example:"Olá {{name}}! Digite 1 para vendas ou 2 para suporte"`
+	MediaURL   string            `json:"mediaUrl,omitempty" example:"https://example.com/menu.jpg"`
+	Caption    string            `json:"caption,omitempty"`
+	FileName   string            `json:"fileName,omitempty"`
+	TemplateID string            `json:"templateId,omitempty" example:"4f1e2d3c-5b6a-7980-a1b2-c3d4e5f60718"`
+	Variables  map[string]string `json:"variables,omitempty"`
+}
+
+type AutoReplyRuleRequest struct {
+	Name          string            `json:"name" binding:"required" example:"Menu"`
+	MatchType     string            `json:"matchType" binding:"required,oneof=keyword regex first_message outside_hours" example:"keyword"`
+	Pattern       string            `json:"pattern,omitempty" example:"oi"` // Palavra-chave (keyword) ou expressão regular (regex)
+	BusinessHours *BusinessHours    `json:"businessHours,omitempty"`        // Obrigatório para outside_hours
+	Response      AutoReplyResponse `json:"response" binding:"required"`
+	Cooldown      int               `json:"cooldown,omitempty" binding:"omitempty,min=0" example:"3600"` // Segundos entre respostas ao mesmo contato
+	Enabled       *bool             `json:"enabled,omitempty" example:"true"`                            // Padrão true
+	Priority      int               `json:"priority,omitempty" example:"10"`                             // Maior prioridade é avaliada primeiro
+}
+
+type AutoReplyRuleResponse struct {
+	RuleID        string            `json:"ruleId" example:"7a1b2c3d-4e5f-6071-8293-a4b5c6d7e8f9"`
+	SessionID     string            `json:"sessionId" example:"550e8400-e29b-41d4-a716-446655440000"`
+	Name          string            `json:"name" example:"Menu"`
+	MatchType     string            `json:"matchType" example:"keyword"`
+	Pattern       string            `json:"pattern,omitempty" example:"oi"`
+	BusinessHours *BusinessHours    `json:"businessHours,omitempty"`
+	Response      AutoReplyResponse `json:"response"`
+	Cooldown      int               `json:"cooldown" example:"3600"`
+	Enabled       bool              `json:"enabled" example:"true"`
+	Priority      int               `json:"priority" example:"10"`
+	CreatedAt     time.Time         `json:"createdAt" example:"2025-11-16T10:00:00Z"`
+	UpdatedAt     time.Time         `json:"updatedAt" example:"2025-11-16T10:00:00Z"`
+}
+
+type AutoReplyRuleListResponse struct {
+	Rules []AutoReplyRuleResponse `json:"rules"`
+	Total int                     `json:"total" example:"1"`
+}
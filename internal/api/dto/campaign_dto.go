@@ -0,0 +1,77 @@
+package dto
+
+import "time"
+
+type CampaignTemplate struct {
+	Type     string `json:"type" binding:"required,oneof=text image video audio document" example:"text"`
+	Text     string `json:"text,omitempty" example:"Olá {{name}}, sua consulta é amanhã às {{time}}"` // Aceita {{variaveis}}
+	MediaURL string `json:"mediaUrl,omitempty" example:"https://example.com/promo.jpg"`               // URL ou data URL (base64)
+	Caption  string `json:"caption,omitempty" example:"Oferta para {{name}}"`
+	FileName string `json:"fileName,omitempty" example:"catalogo.pdf"` // Apenas document
+}
+
+type CampaignRecipient struct {
+	Phone     string            `json:"phone" binding:"required" example:"5511999999999"`
+	Variables map[string]string `json:"variables,omitempty"` // Valores dos placeholders do template
+}
+
+type CreateCampaignRequest struct {
+	Name       string              `json:"name" binding:"required" example:"Black Friday"`
+	Template   CampaignTemplate    `json:"template" binding:"required"`
+	MinDelay   *int                `json:"minDelay,omitempty" binding:"omitempty,min=0" example:"3"`  // Segundos mínimos entre envios (padrão 3)
+	MaxDelay   *int                `json:"maxDelay,omitempty" binding:"omitempty,min=0" example:"10"` // Segundos máximos entre envios (padrão 10)
+	Recipients []CampaignRecipient `json:"recipients,omitempty" binding:"omitempty,dive"`
+}
+
+type AddRecipientsRequest struct {
+	Recipients []CampaignRecipient `json:"recipients" binding:"required,min=1,dive"`
+}
+
+type AddRecipientsResponse struct {
+	Added int `json:"added" example:"150"` // Telefones repetidos são ignorados
+	Total int `json:"total" example:"150"`
+}
+
+type CampaignProgress struct {
+	Total         int     `json:"total" example:"150"`
+	Pending       int     `json:"pending" example:"100"`
+	Sent          int     `json:"sent" example:"45"`
+	Failed        int     `json:"failed" example:"2"`
+	NotOnWhatsApp int     `json:"notOnWhatsapp" example:"3"`
+	Percent       float64 `json:"percent" example:"33.3"`
+}
+
+type CampaignResponse struct {
+	CampaignID  string           `json:"campaignId" example:"1d2c3b4a-5e6f-7a8b-9c0d-1e2f3a4b5c6d"`
+	SessionID   string           `json:"sessionId" example:"550e8400-e29b-41d4-a716-446655440000"`
+	Name        string           `json:"name" example:"Black Friday"`
+	Template    CampaignTemplate `json:"template"`
+	MinDelay    int              `json:"minDelay" example:"3"`
+	MaxDelay    int              `json:"maxDelay" example:"10"`
+	Status      string           `json:"status" example:"running" enums:"draft,running,paused,completed,canceled"`
+	Progress    CampaignProgress `json:"progress"`
+	CreatedAt   time.Time        `json:"createdAt" example:"2025-11-14T10:00:00Z"`
+	UpdatedAt   time.Time        `json:"updatedAt" example:"2025-11-14T10:05:00Z"`
+	StartedAt   *time.Time       `json:"startedAt,omitempty" example:"2025-11-14T10:01:00Z"`
+	CompletedAt *time.Time       `json:"completedAt,omitempty"`
+}
+
+type CampaignListResponse struct {
+	Campaigns []CampaignResponse `json:"campaigns"`
+	Total     int                `json:"total" example:"1"`
+}
+
+type CampaignRecipientResponse struct {
+	Phone     string            `json:"phone" example:"5511999999999"`
+	Variables map[string]string `json:"variables,omitempty"`
+	Status    string            `json:"status" example:"sent" enums:"pending,sending,sent,failed,not_on_whatsapp"`
+	MessageID string            `json:"messageId,omitempty" example:"3EB0XXXXX"`
+	Error     string            `json:"error,omitempty"`
+	SentAt    *time.Time        `json:"sentAt,omitempty" example:"2025-11-14T10:01:05Z"`
+}
+
+type CampaignRecipientListResponse struct {
+	Recipients []CampaignRecipientResponse `json:"recipients"`
+	Limit      int                         `json:"limit" example:"100"`
+	Offset     int                         `json:"offset" example:"0"`
+}
@@ -0,0 +1,33 @@
+package dto
+
+import (
+	"encoding/json"
+	"time"
+)
+
+type EnqueueMessageRequest struct {
+	Type    string          `json:"type" binding:"required,oneof=text image audio video document sticker location contact poll reaction" example:"text"`
+	Payload json.RawMessage `json:"payload" binding:"required" swaggertype:"object"` // Mesmo corpo do endpoint de envio do tipo
+}
+
+type JobResponse struct {
+	JobID     string     `json:"jobId" example:"7c9e6679-7425-40de-944b-e07fc1f90ae7"`
+	SessionID string     `json:"sessionId" example:"550e8400-e29b-41d4-a716-446655440000"`
+	Type      string     `json:"type" example:"text"`
+	Phone     string     `json:"phone" example:"5511999999999"`
+	Status    string     `json:"status" example:"queued" enums:"queued,sending,sent,failed"`
+	MessageID string     `json:"messageId,omitempty" example:"3EB0XXXXX"`
+	Error     string     `json:"error,omitempty"`
+	Attempts  int        `json:"attempts" example:"0"`
+	CreatedAt time.Time  `json:"createdAt" example:"2025-11-12T10:30:00Z"`
+	UpdatedAt time.Time  `json:"updatedAt" example:"2025-11-12T10:30:00Z"`
+	SentAt    *time.Time `json:"sentAt,omitempty" example:"2025-11-12T10:30:05Z"`
+}
+
+type RateLimitConfigResponse struct {
+	SessionID         string `json:"session_id" example:"550e8400-e29b-41d4-a716-446655440000"`
+	MessagesPerMinute int    `json:"messages_per_minute" example:"20"`
+	Burst             int    `json:"burst" example:"5"`
+	RecipientSpacing  int    `json:"recipient_spacing" example:"3"`
+	Custom            bool   `json:"custom" example:"true"` // false = padrão do servidor
+}
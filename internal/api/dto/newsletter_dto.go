@@ -0,0 +1,65 @@
+package dto
+
+import "time"
+
+type NewsletterJIDRequest struct {
+	JID string `json:"jid" binding:"required" example:"120363000000000000@newsletter"`
+}
+
+type MuteNewsletterRequest struct {
+	JID  string `json:"jid" binding:"required" example:"120363000000000000@newsletter"`
+	Mute bool   `json:"mute" example:"true"`
+}
+
+type CreateNewsletterRequest struct {
+	Name        string `json:"name" binding:"required,min=1,max=100" example:"Novidades do Produto"`
+	Description string `json:"description,omitempty" example:"Atualizações semanais do produto"`
+	Picture     string `json:"picture,omitempty" example:"https://example.com/logo.jpg"`
+}
+
+type SendNewsletterTextRequest struct {
+	JID     string `json:"jid" binding:"required" example:"120363000000000000@newsletter"`
+	Message string `json:"message" binding:"required" example:"Nova versão disponível!"`
+}
+
+type SendNewsletterMediaRequest struct {
+	JID      string `json:"jid" binding:"required" example:"120363000000000000@newsletter"`
+	Media    string `json:"media" binding:"required" example:"https://example.com/banner.jpg"`
+	Caption  string `json:"caption,omitempty" example:"Confira as novidades"`
+	FileName string `json:"fileName,omitempty" example:"release-notes.pdf"`
+}
+
+type NewsletterResponse struct {
+	JID             string     `json:"jid" example:"120363000000000000@newsletter"`
+	Name            string     `json:"name" example:"Novidades do Produto"`
+	Description     string     `json:"description,omitempty" example:"Atualizações semanais do produto"`
+	InviteCode      string     `json:"invite_code,omitempty" example:"0029VaXXXXXXXXXX"`
+	SubscriberCount int        `json:"subscriber_count" example:"1500"`
+	Verification    string     `json:"verification,omitempty" example:"verified"`
+	State           string     `json:"state,omitempty" example:"active"`
+	Role            string     `json:"role,omitempty" example:"subscriber"`
+	Mute            string     `json:"mute,omitempty" example:"off"`
+	PictureURL      string     `json:"picture_url,omitempty" example:"https://mmg.whatsapp.net/..."`
+	CreatedAt       *time.Time `json:"created_at,omitempty" example:"2025-11-05T10:00:00Z"`
+}
+
+type NewsletterListResponse struct {
+	Newsletters []NewsletterResponse `json:"newsletters"`
+	Total       int                  `json:"total" example:"2"`
+}
+
+type NewsletterMessageResponse struct {
+	ServerID       int            `json:"server_id" example:"120"`
+	MessageID      string         `json:"message_id" example:"3EB0XXXXX"`
+	Type           string         `json:"type" example:"text"`
+	Body           string         `json:"body,omitempty" example:"Nova versão disponível!"`
+	Timestamp      int64          `json:"timestamp" example:"1699999999"`
+	ViewsCount     int            `json:"views_count" example:"830"`
+	ReactionCounts map[string]int `json:"reaction_counts"`
+}
+
+type NewsletterMessagesResponse struct {
+	JID      string                      `json:"jid" example:"120363000000000000@newsletter"`
+	Messages []NewsletterMessageResponse `json:"messages"`
+	Total    int                         `json:"total" example:"20"`
+}
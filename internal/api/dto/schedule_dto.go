@@ -0,0 +1,38 @@
+package dto
+
+import (
+	"encoding/json"
+	"time"
+)
+
+type ScheduleMessageRequest struct {
+	Type     string          `json:"type" binding:"required,oneof=text image audio video document sticker location contact poll reaction" example:"text"`
+	Payload  json.RawMessage `json:"payload" binding:"required" swaggertype:"object"`         // Mesmo corpo do endpoint de envio do tipo
+	SendAt   string          `json:"sendAt" binding:"required" example:"2025-11-20T09:00:00"` // RFC3339 ou horário local no fuso informado
+	Timezone string          `json:"timezone,omitempty" example:"America/Sao_Paulo"`          // Fuso IANA (padrão do servidor se vazio)
+}
+
+type RescheduleMessageRequest struct {
+	SendAt   string `json:"sendAt" binding:"required" example:"2025-11-20T10:30:00"`
+	Timezone string `json:"timezone,omitempty" example:"America/Sao_Paulo"`
+}
+
+type ScheduleResponse struct {
+	ScheduleID string     `json:"scheduleId" example:"9b2f6c1e-7d3a-4b8e-a1f2-3c4d5e6f7a8b"`
+	SessionID  string     `json:"sessionId" example:"550e8400-e29b-41d4-a716-446655440000"`
+	Type       string     `json:"type" example:"text"`
+	Phone      string     `json:"phone" example:"5511999999999"`
+	SendAt     time.Time  `json:"sendAt" example:"2025-11-20T12:00:00Z"`
+	Timezone   string     `json:"timezone" example:"America/Sao_Paulo"`
+	Status     string     `json:"status" example:"scheduled" enums:"scheduled,processing,queued,failed,canceled"`
+	JobID      string     `json:"jobId,omitempty" example:"7c9e6679-7425-40de-944b-e07fc1f90ae7"` // Job da fila de envio criado na execução
+	Error      string     `json:"error,omitempty"`
+	CreatedAt  time.Time  `json:"createdAt" example:"2025-11-12T10:30:00Z"`
+	UpdatedAt  time.Time  `json:"updatedAt" example:"2025-11-12T10:30:00Z"`
+	ExecutedAt *time.Time `json:"executedAt,omitempty" example:"2025-11-20T12:00:01Z"`
+}
+
+type ScheduleListResponse struct {
+	Schedules []ScheduleResponse `json:"schedules"`
+	Total     int                `json:"total" example:"1"`
+}
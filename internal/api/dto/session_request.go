@@ -16,11 +16,74 @@ type WebhookConfig struct {
 	Token   string   `json:"token,omitempty" example:"secreto-opcional"`
 }
 
+type ChatwootConfig struct {
+	Enabled      bool   `json:"enabled" example:"true"`
+	URL          string `json:"url" binding:"required_if=Enabled true,omitempty,url" example:"https://chatwoot.exemplo.com"`
+	AccountID    int64  `json:"account_id" binding:"required_if=Enabled true,omitempty,min=1" example:"1"`
+	InboxID      int64  `json:"inbox_id" binding:"required_if=Enabled true,omitempty,min=1" example:"3"` // Inbox do tipo API
+	Token        string `json:"token" binding:"required_if=Enabled true" example:"api-access-token-do-agente"`
+	WebhookToken string `json:"webhook_token,omitempty" example:""` // Vazio = mantém o atual (gerado na primeira configuração)
+}
+
+type FlowBotConfig struct {
+	Enabled       bool     `json:"enabled" example:"true"`
+	URL           string   `json:"url" binding:"required_if=Enabled true,omitempty,url" example:"https://typebot.exemplo.com"`
+	TypebotID     string   `json:"typebot_id" binding:"required_if=Enabled true" example:"atendimento-inicial"` // ID público do fluxo
+	Token         string   `json:"token,omitempty" example:""`                                                  // Bearer token (fluxos privados)
+	Triggers      []string `json:"triggers,omitempty" binding:"omitempty,max=50,dive,min=1,max=100" example:"oi,menu"`
+	Expire        int      `json:"expire,omitempty" binding:"omitempty,min=0,max=43200" example:"60"` // Minutos sem interação até expirar (0 = não expira)
+	FinishKeyword string   `json:"finish_keyword,omitempty" binding:"omitempty,max=100" example:"#sair"`
+	StopOnFromMe  bool     `json:"stop_on_from_me,omitempty" example:"true"`                                   // Mensagem enviada pelo celular pausa o fluxo
+	MessageDelay  int      `json:"message_delay,omitempty" binding:"omitempty,min=0,max=10000" example:"1000"` // Milissegundos entre os blocos
+}
+
+type AssistantConfig struct {
+	Enabled       bool     `json:"enabled" example:"true"`
+	BaseURL       string   `json:"base_url" binding:"required_if=Enabled true,omitempty,url" example:"https://api.openai.com/v1"`
+	Model         string   `json:"model" binding:"required_if=Enabled true,max=200" example:"gpt-4o-mini"`
+	APIKey        string   `json:"api_key,omitempty" example:"sk-..."` // Opcional em servidores locais (Ollama, vLLM)
+	SystemPrompt  string   `json:"system_prompt,omitempty" binding:"max=20000" example:"Você é o atendente da Loja Exemplo. Responda em português, de forma curta."`
+	Triggers      []string `json:"triggers,omitempty" binding:"omitempty,max=50,dive,min=1,max=100" example:"assistente"`
+	HistorySize   int      `json:"history_size,omitempty" binding:"omitempty,min=0,max=200" example:"20"` // Mensagens lembradas por contato (padrão 20)
+	Expire        int      `json:"expire,omitempty" binding:"omitempty,min=0,max=43200" example:"60"`     // Minutos sem interação até expirar (0 = não expira)
+	FinishKeyword string   `json:"finish_keyword,omitempty" binding:"omitempty,max=100" example:"#sair"`
+	StopOnFromMe  bool     `json:"stop_on_from_me,omitempty" example:"true"` // Mensagem enviada pelo celular pausa o assistente
+	Temperature   *float64 `json:"temperature,omitempty" binding:"omitempty,min=0,max=2" example:"0.7"`
+	MaxTokens     int      `json:"max_tokens,omitempty" binding:"omitempty,min=1,max=32000" example:"500"`
+}
+
+type TranscriptionConfig struct {
+	Enabled  bool   `json:"enabled" example:"true"`
+	BaseURL  string `json:"base_url" binding:"required_if=Enabled true,omitempty,url" example:"https://api.openai.com/v1"`
+	Model    string `json:"model" binding:"required_if=Enabled true,max=200" example:"whisper-1"`
+	APIKey   string `json:"api_key,omitempty" example:"sk-..."`                        // Opcional em servidores locais
+	Language string `json:"language,omitempty" binding:"omitempty,len=2" example:"pt"` // ISO-639-1 (vazio = detecção automática)
+	FromMe   bool   `json:"from_me,omitempty" example:"false"`                         // Transcrever também as mensagens de voz enviadas
+}
+
+type HistorySyncConfig struct {
+	Mode string `json:"mode" binding:"omitempty,oneof=full recent none" example:"recent"`
+	Days int    `json:"days,omitempty" binding:"omitempty,min=1,max=3650" example:"30"` // Usado apenas no modo recent
+}
+
+type CallConfig struct {
+	Policy  string `json:"policy" binding:"required,oneof=accept reject reject_message" example:"reject_message"`
+	Message string `json:"message,omitempty" binding:"required_if=Policy reject_message,max=4096" example:"Este número não recebe chamadas. Envie uma mensagem."`
+}
+
+type RateLimitConfig struct {
+	MessagesPerMinute int `json:"messages_per_minute" binding:"required,min=1,max=600" example:"20"`
+	Burst             int `json:"burst" binding:"omitempty,min=1,max=100" example:"5"`
+	RecipientSpacing  int `json:"recipient_spacing" binding:"omitempty,min=0,max=3600" example:"3"` // Segundos entre envios ao mesmo destinatário
+}
+
 type CreateSessionRequest struct {
-	Name    string         `json:"name" binding:"required,min=3,max=100" example:"sessao-atendimento-1"`
-	APIKey  *string        `json:"apikey" example:"null"`
-	Proxy   *ProxyConfig   `json:"proxy,omitempty"`
-	Webhook *WebhookConfig `json:"webhook,omitempty"`
+	Name        string             `json:"name" binding:"required,min=3,max=100" example:"sessao-atendimento-1"`
+	APIKey      *string            `json:"apikey" example:"null"`
+	Proxy       *ProxyConfig       `json:"proxy,omitempty"`
+	Webhook     *WebhookConfig     `json:"webhook,omitempty"`
+	HistorySync *HistorySyncConfig `json:"history_sync,omitempty"`
+	Call        *CallConfig        `json:"call,omitempty"`
 }
 
 type PairPhoneRequest struct {
@@ -5,6 +5,7 @@ import "time"
 type SessionResponse struct {
 	ID               string     `json:"id" example:"550e8400-e29b-41d4-a716-446655440000"`
 	Name             string     `json:"name" example:"Minha Sessão WhatsApp"`
+	TenantID         string     `json:"tenant_id,omitempty" example:"0b8c7f7e-4f5e-4a43-9b1d-2a6f1f1e9c11"`
 	JID              string     `json:"jid,omitempty" example:"5511999999999@s.whatsapp.net"`
 	Status           string     `json:"status" example:"connected"`
 	PushName         string     `json:"push_name,omitempty" example:"João Silva"`
@@ -37,6 +38,120 @@ type SessionStatusResponse struct {
 	CanConnect     bool       `json:"can_connect" example:"true"`
 }
 
+// ChatwootConfigResponse não retorna o token da API; webhook_path deve ser configurado como
+// URL de webhook da inbox (prefixado com o endereço público do zpwoot)
+type ChatwootConfigResponse struct {
+	SessionID   string    `json:"session_id" example:"550e8400-e29b-41d4-a716-446655440000"`
+	Enabled     bool      `json:"enabled" example:"true"`
+	URL         string    `json:"url,omitempty" example:"https://chatwoot.exemplo.com"`
+	AccountID   int64     `json:"account_id,omitempty" example:"1"`
+	InboxID     int64     `json:"inbox_id,omitempty" example:"3"`
+	WebhookPath string    `json:"webhook_path,omitempty" example:"/chatwoot/webhook/550e8400-e29b-41d4-a716-446655440000?token=3f2a..."`
+	UpdatedAt   time.Time `json:"updated_at" example:"2025-11-17T10:30:00Z"`
+}
+
+// FlowBotConfigResponse não retorna o token do fluxo
+type FlowBotConfigResponse struct {
+	SessionID     string    `json:"session_id" example:"550e8400-e29b-41d4-a716-446655440000"`
+	Enabled       bool      `json:"enabled" example:"true"`
+	URL           string    `json:"url,omitempty" example:"https://typebot.exemplo.com"`
+	TypebotID     string    `json:"typebot_id,omitempty" example:"atendimento-inicial"`
+	Triggers      []string  `json:"triggers,omitempty" example:"oi,menu"`
+	Expire        int       `json:"expire,omitempty" example:"60"`
+	FinishKeyword string    `json:"finish_keyword,omitempty" example:"#sair"`
+	StopOnFromMe  bool      `json:"stop_on_from_me,omitempty" example:"true"`
+	MessageDelay  int       `json:"message_delay,omitempty" example:"1000"`
+	UpdatedAt     time.Time `json:"updated_at" example:"2025-11-18T10:30:00Z"`
+}
+
+// FlowBotSessionResponse é a conversa de um contato com o fluxo
+type FlowBotSessionResponse struct {
+	Phone        string    `json:"phone" example:"5511999999999"`
+	BotSessionID string    `json:"bot_session_id,omitempty" example:"cm3x9k2l0000108l45h2c7d1a"`
+	Status       string    `json:"status" example:"active"` // active ou paused (atendimento humano)
+	Choices      []string  `json:"choices,omitempty" example:"Vendas,Suporte"`
+	CreatedAt    time.Time `json:"created_at" example:"2025-11-18T10:30:00Z"`
+	UpdatedAt    time.Time `json:"updated_at" example:"2025-11-18T10:35:00Z"`
+}
+
+type FlowBotSessionListResponse struct {
+	Sessions []FlowBotSessionResponse `json:"sessions"`
+	Limit    int                      `json:"limit" example:"100"`
+	Offset   int                      `json:"offset" example:"0"`
+}
+
+// AssistantConfigResponse não retorna a API key do modelo
+type AssistantConfigResponse struct {
+	SessionID     string    `json:"session_id" example:"550e8400-e29b-41d4-a716-446655440000"`
+	Enabled       bool      `json:"enabled" example:"true"`
+	BaseURL       string    `json:"base_url,omitempty" example:"https://api.openai.com/v1"`
+	Model         string    `json:"model,omitempty" example:"gpt-4o-mini"`
+	SystemPrompt  string    `json:"system_prompt,omitempty" example:"Você é o atendente da Loja Exemplo. Responda em português, de forma curta."`
+	Triggers      []string  `json:"triggers,omitempty" example:"assistente"`
+	HistorySize   int       `json:"history_size,omitempty" example:"20"`
+	Expire        int       `json:"expire,omitempty" example:"60"`
+	FinishKeyword string    `json:"finish_keyword,omitempty" example:"#sair"`
+	StopOnFromMe  bool      `json:"stop_on_from_me,omitempty" example:"true"`
+	Temperature   *float64  `json:"temperature,omitempty" example:"0.7"`
+	MaxTokens     int       `json:"max_tokens,omitempty" example:"500"`
+	UpdatedAt     time.Time `json:"updated_at" example:"2025-11-19T10:30:00Z"`
+}
+
+// AssistantConversationResponse é a conversa de um contato com o assistente
+type AssistantConversationResponse struct {
+	Phone     string    `json:"phone" example:"5511999999999"`
+	Status    string    `json:"status" example:"active"` // active ou paused (atendimento humano)
+	CreatedAt time.Time `json:"created_at" example:"2025-11-19T10:30:00Z"`
+	UpdatedAt time.Time `json:"updated_at" example:"2025-11-19T10:35:00Z"`
+}
+
+type AssistantConversationListResponse struct {
+	Conversations []AssistantConversationResponse `json:"conversations"`
+	Limit         int                             `json:"limit" example:"100"`
+	Offset        int                             `json:"offset" example:"0"`
+}
+
+// TranscriptionConfigResponse não retorna a API key
+type TranscriptionConfigResponse struct {
+	SessionID string    `json:"session_id" example:"550e8400-e29b-41d4-a716-446655440000"`
+	Enabled   bool      `json:"enabled" example:"true"`
+	BaseURL   string    `json:"base_url,omitempty" example:"https://api.openai.com/v1"`
+	Model     string    `json:"model,omitempty" example:"whisper-1"`
+	Language  string    `json:"language,omitempty" example:"pt"`
+	FromMe    bool      `json:"from_me,omitempty" example:"false"`
+	UpdatedAt time.Time `json:"updated_at" example:"2025-11-20T10:30:00Z"`
+}
+
+type CallConfigResponse struct {
+	SessionID string    `json:"session_id" example:"550e8400-e29b-41d4-a716-446655440000"`
+	Policy    string    `json:"policy" example:"reject_message"`
+	Message   string    `json:"message,omitempty" example:"Este número não recebe chamadas. Envie uma mensagem."`
+	UpdatedAt time.Time `json:"updated_at" example:"2025-11-06T10:30:00Z"`
+}
+
+type HistorySyncProgressResponse struct {
+	SessionID       string    `json:"session_id" example:"550e8400-e29b-41d4-a716-446655440000"`
+	Mode            string    `json:"mode" example:"recent"`
+	SyncType        string    `json:"sync_type,omitempty" example:"INITIAL_BOOTSTRAP"`
+	ChunkOrder      int       `json:"chunk_order" example:"3"`
+	Progress        int       `json:"progress" example:"75"` // Percentual informado pelo WhatsApp
+	ChunksProcessed int       `json:"chunks_processed" example:"4"`
+	Conversations   int       `json:"conversations" example:"120"`
+	Messages        int       `json:"messages" example:"5340"`
+	StartedAt       time.Time `json:"started_at,omitempty" example:"2025-11-05T18:30:00Z"`
+	UpdatedAt       time.Time `json:"updated_at,omitempty" example:"2025-11-05T18:32:00Z"`
+}
+
+type QRCountdownEvent struct {
+	Event     string `json:"event" example:"countdown"`
+	ExpiresIn int    `json:"expires_in" example:"17"` // Segundos até o QR code atual expirar
+}
+
+type StreamHeartbeat struct {
+	Event     string    `json:"event" example:"heartbeat"`
+	Timestamp time.Time `json:"timestamp" example:"2025-11-05T18:30:00Z"`
+}
+
 type PairQRResponse struct {
 	SessionID string    `json:"session_id" example:"550e8400-e29b-41d4-a716-446655440000"`
 	QRCode    string    `json:"qr_code" example:"data:image/png;base64,iVBORw0KGgoAAAANSUhEUgAA..."` // Base64 data URL
@@ -63,11 +178,12 @@ type SuccessResponse struct {
 	Data    interface{} `json:"data,omitempty" swaggertype:"object"`
 }
 
+// WebhookConfigResponse não retorna o token do webhook, apenas se ele está configurado
 type WebhookConfigResponse struct {
 	SessionID string    `json:"session_id" example:"550e8400-e29b-41d4-a716-446655440000"`
 	Enabled   bool      `json:"enabled" example:"true"`
 	URL       string    `json:"url" example:"https://hooks.exemplo.com/whatsapp"`
 	Events    []string  `json:"events" example:"message,status,qr,connected,disconnected"`
-	Token     string    `json:"token,omitempty" example:"Bearer secret-token-123"`
+	HasToken  bool      `json:"has_token" example:"true"`
 	UpdatedAt time.Time `json:"updated_at" example:"2025-11-06T10:30:00Z"`
 }
@@ -0,0 +1,80 @@
+package dto
+
+import "time"
+
+type TemplateButton struct {
+	ID   string `json:"id,omitempty" example:"yes"`
+	Text string `json:"text" binding:"required" example:"Confirmar"`
+}
+
+type CreateTemplateRequest struct {
+	Name      string           `json:"name" binding:"required" example:"lembrete_consulta"`
+	SessionID string           `json:"sessionId,omitempty" example:"550e8400-e29b-41d4-a716-446655440000"` // Vazio = template global
+	Text      string           `json:"text,omitempty" example:"Olá {{name}}, sua consulta é {{date}} às {{time}}"`
+	MediaURL  string           `json:"mediaUrl,omitempty" example:"https://example.com/banner.jpg"`
+	Caption   string           `json:"caption,omitempty" example:"Oferta para {{name}}"`
+	FileName  string           `json:"fileName,omitempty" example:"boleto_{{id}}.pdf"`
+	Buttons   []TemplateButton `json:"buttons,omitempty" binding:"omitempty,dive"`
+}
+
+// UpdateTemplateRequest cria uma nova versão com o conteúdo informado (nome vazio = mantém o atual)
+type UpdateTemplateRequest struct {
+	Name     string           `json:"name,omitempty" example:"lembrete_consulta"`
+	Text     string           `json:"text,omitempty" example:"Oi {{name}}! Sua consulta é {{date}} às {{time}}"`
+	MediaURL string           `json:"mediaUrl,omitempty"`
+	Caption  string           `json:"caption,omitempty"`
+	FileName string           `json:"fileName,omitempty"`
+	Buttons  []TemplateButton `json:"buttons,omitempty" binding:"omitempty,dive"`
+}
+
+type RenderTemplateRequest struct {
+	Version   int               `json:"version,omitempty" example:"0"` // 0 = versão atual
+	Variables map[string]string `json:"variables,omitempty"`
+}
+
+type TemplateResponse struct {
+	TemplateID string           `json:"templateId" example:"4f1e2d3c-5b6a-7980-a1b2-c3d4e5f60718"`
+	SessionID  string           `json:"sessionId,omitempty" example:"550e8400-e29b-41d4-a716-446655440000"`
+	Name       string           `json:"name" example:"lembrete_consulta"`
+	Version    int              `json:"version" example:"2"`
+	Text       string           `json:"text,omitempty" example:"Olá {{name}}, sua consulta é {{date}} às {{time}}"`
+	MediaURL   string           `json:"mediaUrl,omitempty"`
+	Caption    string           `json:"caption,omitempty"`
+	FileName   string           `json:"fileName,omitempty"`
+	Buttons    []TemplateButton `json:"buttons,omitempty"`
+	Variables  []string         `json:"variables" example:"name,date,time"` // Placeholders usados
+	CreatedAt  time.Time        `json:"createdAt" example:"2025-11-15T10:00:00Z"`
+	UpdatedAt  time.Time        `json:"updatedAt" example:"2025-11-15T10:00:00Z"`
+}
+
+type TemplateListResponse struct {
+	Templates []TemplateResponse `json:"templates"`
+	Total     int                `json:"total" example:"1"`
+}
+
+type TemplateVersionResponse struct {
+	Version   int              `json:"version" example:"1"`
+	Text      string           `json:"text,omitempty"`
+	MediaURL  string           `json:"mediaUrl,omitempty"`
+	Caption   string           `json:"caption,omitempty"`
+	FileName  string           `json:"fileName,omitempty"`
+	Buttons   []TemplateButton `json:"buttons,omitempty"`
+	CreatedAt time.Time        `json:"createdAt" example:"2025-11-15T10:00:00Z"`
+}
+
+type TemplateVersionListResponse struct {
+	TemplateID string                    `json:"templateId" example:"4f1e2d3c-5b6a-7980-a1b2-c3d4e5f60718"`
+	Versions   []TemplateVersionResponse `json:"versions"`
+	Total      int                       `json:"total" example:"2"`
+}
+
+type RenderTemplateResponse struct {
+	TemplateID string           `json:"templateId" example:"4f1e2d3c-5b6a-7980-a1b2-c3d4e5f60718"`
+	Version    int              `json:"version" example:"2"`
+	Text       string           `json:"text,omitempty" example:"Olá Ana, sua consulta é 20/11 às 14h"`
+	MediaURL   string           `json:"mediaUrl,omitempty"`
+	Caption    string           `json:"caption,omitempty"`
+	FileName   string           `json:"fileName,omitempty"`
+	Buttons    []TemplateButton `json:"buttons,omitempty"`
+	Missing    []string         `json:"missing,omitempty"` // Variáveis sem valor (o envio seria recusado)
+}
@@ -0,0 +1,48 @@
+package dto
+
+import "time"
+
+type CreateTenantRequest struct {
+	Name            string         `json:"name" binding:"required,min=3,max=100" example:"Agência Exemplo"`
+	MaxSessions     int            `json:"max_sessions" binding:"min=0" example:"10"` // 0 = sem limite
+	WebhookDefaults *WebhookConfig `json:"webhook_defaults,omitempty"`                // Aplicado às sessões criadas sem webhook
+	Enabled         *bool          `json:"enabled,omitempty" example:"true"`          // Padrão: true
+}
+
+// UpdateTenantRequest substitui nome, cota e webhook padrão (enabled omitido = mantém o atual)
+type UpdateTenantRequest struct {
+	Name            string         `json:"name" binding:"required,min=3,max=100" example:"Agência Exemplo"`
+	MaxSessions     int            `json:"max_sessions" binding:"min=0" example:"20"`
+	WebhookDefaults *WebhookConfig `json:"webhook_defaults,omitempty"`
+	Enabled         *bool          `json:"enabled,omitempty" example:"true"`
+}
+
+// TenantWebhookDefaults é o webhook padrão do tenant sem o token
+type TenantWebhookDefaults struct {
+	Enabled  bool     `json:"enabled" example:"true"`
+	URL      string   `json:"url" example:"https://hooks.agencia.com/whatsapp"`
+	Events   []string `json:"events,omitempty" example:"message,connected"`
+	HasToken bool     `json:"has_token" example:"true"`
+}
+
+type TenantResponse struct {
+	ID              string                 `json:"id" example:"0b8c7f7e-4f5e-4a43-9b1d-2a6f1f1e9c11"`
+	Name            string                 `json:"name" example:"Agência Exemplo"`
+	APIKeyPrefix    string                 `json:"api_key_prefix" example:"zpw_1a2b3c4d"`
+	MaxSessions     int                    `json:"max_sessions" example:"10"`
+	WebhookDefaults *TenantWebhookDefaults `json:"webhook_defaults,omitempty"`
+	Enabled         bool                   `json:"enabled" example:"true"`
+	CreatedAt       time.Time              `json:"created_at"`
+	UpdatedAt       time.Time              `json:"updated_at"`
+}
+
+// TenantKeyResponse é retornado na criação e na rotação: a API key não é exibida novamente
+type TenantKeyResponse struct {
+	Tenant TenantResponse `json:"tenant"`
+	APIKey string         `json:"api_key" example:"zpw_1a2b3c4d5e6f..."`
+}
+
+type TenantListResponse struct {
+	Tenants []TenantResponse `json:"tenants"`
+	Total   int              `json:"total" example:"1"`
+}
@@ -0,0 +1,162 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"zpwoot/internal/api/dto"
+	"zpwoot/internal/model"
+	"zpwoot/internal/service"
+	"zpwoot/pkg/logger"
+)
+
+type APIKeyHandler struct {
+	apiKeyService *service.APIKeyService
+}
+
+func NewAPIKeyHandler(apiKeyService *service.APIKeyService) *APIKeyHandler {
+	return &APIKeyHandler{apiKeyService: apiKeyService}
+}
+
+func apiKeyResponse(key *model.APIKey) dto.APIKeyResponse {
+	scopes := make([]string, 0, len(key.Scopes))
+	for _, scope := range key.Scopes {
+		scopes = append(scopes, string(scope))
+	}
+
+	return dto.APIKeyResponse{
+		ID:         key.ID,
+		TenantID:   key.TenantID,
+		Name:       key.Name,
+		KeyPrefix:  key.KeyPrefix,
+		Scopes:     scopes,
+		AllowedIPs: key.AllowedIPs,
+		ExpiresAt:  key.ExpiresAt,
+		CreatedAt:  key.CreatedAt,
+	}
+}
+
+// respondAPIKeyError traduz erros do serviço para a resposta HTTP
+func respondAPIKeyError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, service.ErrInvalidAPIKey):
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{Error: "invalid_api_key", Message: err.Error()})
+	case errors.Is(err, service.ErrAPIKeyNotFound):
+		c.JSON(http.StatusNotFound, dto.ErrorResponse{Error: "api_key_not_found", Message: err.Error()})
+	case errors.Is(err, service.ErrTenantNotFound):
+		c.JSON(http.StatusNotFound, dto.ErrorResponse{Error: "tenant_not_found", Message: err.Error()})
+	default:
+		logger.Log.Error().Err(err).Msg("API key operation failed")
+		c.JSON(http.StatusInternalServerError, dto.ErrorResponse{Error: "api_key_failed", Message: err.Error()})
+	}
+}
+
+// @Summary Criar API key
+// @Description Cria uma API key com escopos (sessions:read, sessions:write, messages:send, webhooks:manage, admin), validade e IPs permitidos opcionais. O segredo é exibido apenas nesta resposta
+// @Tags API Keys
+// @Accept json
+// @Produce json
+// @Param request body dto.CreateAPIKeyRequest true "API key"
+// @Success 201 {object} dto.APIKeyCreatedResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 403 {object} dto.ErrorResponse
+// @Security ApiKeyAuth
+// @Router /keys [post]
+func (h *APIKeyHandler) CreateKey(c *gin.Context) {
+	var req dto.CreateAPIKeyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+			Error:   "invalid_request",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	key := &model.APIKey{
+		TenantID:   req.TenantID,
+		Name:       req.Name,
+		AllowedIPs: req.AllowedIPs,
+		ExpiresAt:  req.ExpiresAt,
+	}
+	for _, scope := range req.Scopes {
+		key.Scopes = append(key.Scopes, model.Scope(scope))
+	}
+
+	secret, err := h.apiKeyService.Create(c.Request.Context(), key)
+	if err != nil {
+		respondAPIKeyError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, dto.APIKeyCreatedResponse{
+		Key:    apiKeyResponse(key),
+		APIKey: secret,
+	})
+}
+
+// @Summary Listar API keys
+// @Description Lista as API keys (sem os segredos). Chaves de tenant veem apenas as do próprio tenant
+// @Tags API Keys
+// @Produce json
+// @Success 200 {object} dto.APIKeyListResponse
+// @Failure 403 {object} dto.ErrorResponse
+// @Security ApiKeyAuth
+// @Router /keys [get]
+func (h *APIKeyHandler) ListKeys(c *gin.Context) {
+	keys, err := h.apiKeyService.List(c.Request.Context())
+	if err != nil {
+		respondAPIKeyError(c, err)
+		return
+	}
+
+	response := dto.APIKeyListResponse{
+		Keys:  make([]dto.APIKeyResponse, 0, len(keys)),
+		Total: len(keys),
+	}
+	for _, key := range keys {
+		response.Keys = append(response.Keys, apiKeyResponse(key))
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// @Summary Consultar API key
+// @Tags API Keys
+// @Produce json
+// @Param keyId path string true "API key ID"
+// @Success 200 {object} dto.APIKeyResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Security ApiKeyAuth
+// @Router /keys/{keyId} [get]
+func (h *APIKeyHandler) GetKey(c *gin.Context) {
+	key, err := h.apiKeyService.Get(c.Request.Context(), c.Param("keyId"))
+	if err != nil {
+		respondAPIKeyError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, apiKeyResponse(key))
+}
+
+// @Summary Revogar API key
+// @Description Remove a API key; ela deixa de autenticar imediatamente
+// @Tags API Keys
+// @Produce json
+// @Param keyId path string true "API key ID"
+// @Success 200 {object} dto.SuccessResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Security ApiKeyAuth
+// @Router /keys/{keyId} [delete]
+func (h *APIKeyHandler) RevokeKey(c *gin.Context) {
+	if err := h.apiKeyService.Revoke(c.Request.Context(), c.Param("keyId")); err != nil {
+		respondAPIKeyError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.SuccessResponse{
+		Success: true,
+		Message: "API key revoked successfully",
+	})
+}
@@ -0,0 +1,244 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"zpwoot/internal/api/dto"
+	"zpwoot/internal/model"
+	"zpwoot/internal/service"
+	"zpwoot/pkg/logger"
+)
+
+type AssistantHandler struct {
+	sessionManager   *service.SessionManager
+	assistantService *service.AssistantService
+}
+
+func NewAssistantHandler(sessionManager *service.SessionManager, assistantService *service.AssistantService) *AssistantHandler {
+	return &AssistantHandler{
+		sessionManager:   sessionManager,
+		assistantService: assistantService,
+	}
+}
+
+func assistantConfigResponse(session *model.Session) dto.AssistantConfigResponse {
+	response := dto.AssistantConfigResponse{
+		SessionID: session.ID,
+		UpdatedAt: session.UpdatedAt,
+	}
+
+	if config := session.AssistantConfig; config != nil {
+		response.Enabled = config.Enabled
+		response.BaseURL = config.BaseURL
+		response.Model = config.Model
+		response.SystemPrompt = config.SystemPrompt
+		response.Triggers = config.Triggers
+		response.HistorySize = config.HistorySize
+		response.Expire = config.Expire
+		response.FinishKeyword = config.FinishKeyword
+		response.StopOnFromMe = config.StopOnFromMe
+		response.Temperature = config.Temperature
+		response.MaxTokens = config.MaxTokens
+	}
+
+	return response
+}
+
+// @Summary Configurar assistente (LLM)
+// @Description Liga a sessão a um modelo compatível com /chat/completions da OpenAI. Mensagens de texto de conversas individuais que contenham um gatilho iniciam a conversa; o assistente lembra as últimas mensagens de cada contato e responde com presença "digitando". Não pode ser ativado junto com o fluxo (409)
+// @Tags Assistant
+// @Accept json
+// @Produce json
+// @Param id path string true "Session ID"
+// @Param request body dto.AssistantConfig true "Assistente"
+// @Success 200 {object} dto.AssistantConfigResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 409 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Security ApiKeyAuth
+// @Router /sessions/{id}/assistant/set [post]
+func (h *AssistantHandler) SetAssistantConfig(c *gin.Context) {
+	sessionID := c.Param("id")
+
+	var req dto.AssistantConfig
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+			Error:   "invalid_request",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	assistantConfig := &model.AssistantConfig{
+		Enabled:       req.Enabled,
+		BaseURL:       req.BaseURL,
+		Model:         req.Model,
+		APIKey:        req.APIKey,
+		SystemPrompt:  req.SystemPrompt,
+		Triggers:      req.Triggers,
+		HistorySize:   req.HistorySize,
+		Expire:        req.Expire,
+		FinishKeyword: req.FinishKeyword,
+		StopOnFromMe:  req.StopOnFromMe,
+		Temperature:   req.Temperature,
+		MaxTokens:     req.MaxTokens,
+	}
+
+	if err := h.sessionManager.UpdateAssistantConfig(c.Request.Context(), sessionID, assistantConfig); err != nil {
+		if errors.Is(err, service.ErrResponderConflict) {
+			c.JSON(http.StatusConflict, dto.ErrorResponse{
+				Error:   "responder_conflict",
+				Message: err.Error(),
+			})
+			return
+		}
+		logger.Log.Error().Err(err).Str("session_id", sessionID).Msg("Failed to set assistant config")
+		c.JSON(http.StatusInternalServerError, dto.ErrorResponse{
+			Error:   "update_failed",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	session, err := h.sessionManager.GetSession(c.Request.Context(), sessionID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, dto.ErrorResponse{
+			Error:   "fetch_failed",
+			Message: "Assistant config saved but failed to fetch updated session",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, assistantConfigResponse(session))
+}
+
+// @Summary Obter configuração do assistente
+// @Description Retorna o assistente da sessão (sem a API key)
+// @Tags Assistant
+// @Produce json
+// @Param id path string true "Session ID"
+// @Success 200 {object} dto.AssistantConfigResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Security ApiKeyAuth
+// @Router /sessions/{id}/assistant/find [get]
+func (h *AssistantHandler) FindAssistantConfig(c *gin.Context) {
+	sessionID := c.Param("id")
+
+	session, err := h.sessionManager.GetSession(c.Request.Context(), sessionID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, dto.ErrorResponse{
+			Error:   "session_not_found",
+			Message: fmt.Sprintf("Session not found: %s", sessionID),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, assistantConfigResponse(session))
+}
+
+// @Summary Listar conversas do assistente
+// @Description Lista os contatos atendidos pelo assistente (active) ou entregues a um humano (paused)
+// @Tags Assistant
+// @Produce json
+// @Param id path string true "Session ID"
+// @Param status query string false "Filtrar por status" Enums(active, paused)
+// @Param limit query int false "Quantidade (padrão 100, máximo 1000)"
+// @Param offset query int false "Deslocamento"
+// @Success 200 {object} dto.AssistantConversationListResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Security ApiKeyAuth
+// @Router /sessions/{id}/assistant/conversations [get]
+func (h *AssistantHandler) ListConversations(c *gin.Context) {
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "100"))
+	offset, _ := strconv.Atoi(c.DefaultQuery("offset", "0"))
+	if limit <= 0 || limit > 1000 {
+		limit = 100
+	}
+	if offset < 0 {
+		offset = 0
+	}
+
+	conversations, err := h.assistantService.List(c.Request.Context(), c.Param("id"), model.AssistantStatus(c.Query("status")), limit, offset)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, dto.ErrorResponse{
+			Error:   "list_failed",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	response := dto.AssistantConversationListResponse{
+		Conversations: make([]dto.AssistantConversationResponse, 0, len(conversations)),
+		Limit:         limit,
+		Offset:        offset,
+	}
+	for _, conversation := range conversations {
+		response.Conversations = append(response.Conversations, dto.AssistantConversationResponse{
+			Phone:     conversation.Phone,
+			Status:    string(conversation.Status),
+			CreatedAt: conversation.CreatedAt,
+			UpdatedAt: conversation.UpdatedAt,
+		})
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// @Summary Passar conversa para um humano
+// @Description Pausa o assistente para o contato: as mensagens deixam de ser respondidas até a conversa ser encerrada (ou expirar)
+// @Tags Assistant
+// @Produce json
+// @Param id path string true "Session ID"
+// @Param phone path string true "Telefone do contato"
+// @Success 200 {object} dto.SuccessResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Security ApiKeyAuth
+// @Router /sessions/{id}/assistant/conversations/{phone}/stop [post]
+func (h *AssistantHandler) StopConversation(c *gin.Context) {
+	if err := h.assistantService.Pause(c.Request.Context(), c.Param("id"), c.Param("phone")); err != nil {
+		c.JSON(http.StatusInternalServerError, dto.ErrorResponse{
+			Error:   "stop_failed",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.SuccessResponse{
+		Success: true,
+		Message: "Conversation handed to a human",
+	})
+}
+
+// @Summary Encerrar conversa do assistente
+// @Description Remove o estado e a memória do contato; a próxima mensagem com um gatilho inicia uma nova conversa
+// @Tags Assistant
+// @Produce json
+// @Param id path string true "Session ID"
+// @Param phone path string true "Telefone do contato"
+// @Success 200 {object} dto.SuccessResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Security ApiKeyAuth
+// @Router /sessions/{id}/assistant/conversations/{phone} [delete]
+func (h *AssistantHandler) DeleteConversation(c *gin.Context) {
+	if err := h.assistantService.Close(c.Request.Context(), c.Param("id"), c.Param("phone")); err != nil {
+		status := http.StatusInternalServerError
+		if errors.Is(err, service.ErrAssistantConversationNotFound) {
+			status = http.StatusNotFound
+		}
+		c.JSON(status, dto.ErrorResponse{
+			Error:   "delete_failed",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.SuccessResponse{
+		Success: true,
+		Message: "Assistant conversation closed",
+	})
+}
@@ -0,0 +1,128 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"zpwoot/internal/api/dto"
+	"zpwoot/internal/model"
+	"zpwoot/internal/service"
+	"zpwoot/pkg/logger"
+)
+
+type AuditHandler struct {
+	auditService *service.AuditService
+}
+
+func NewAuditHandler(auditService *service.AuditService) *AuditHandler {
+	return &AuditHandler{auditService: auditService}
+}
+
+// parseAuditTime aceita RFC3339 ou apenas a data (YYYY-MM-DD, em UTC)
+func parseAuditTime(value string) (*time.Time, error) {
+	if value == "" {
+		return nil, nil
+	}
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		if t, err = time.Parse("2006-01-02", value); err != nil {
+			return nil, err
+		}
+	}
+	return &t, nil
+}
+
+// @Summary Consultar audit log
+// @Description Lista as chamadas que alteraram estado (criação, remoção e conexão de sessões, webhooks, envios...), da mais recente para a mais antiga, com autor, IP, sessão, resumo sem segredos e resultado. Chaves de tenant veem apenas o próprio tenant
+// @Tags Audit
+// @Produce json
+// @Param sessionId query string false "Sessão"
+// @Param keyId query string false "ID da API key autora"
+// @Param method query string false "Método HTTP"
+// @Param route query string false "Rota declarada (ex.: /sessions/:id/message/text)"
+// @Param result query string false "success, denied ou failure"
+// @Param requestId query string false "Request ID (header X-Request-ID da resposta)"
+// @Param from query string false "Início (RFC3339 ou YYYY-MM-DD)"
+// @Param to query string false "Fim, exclusivo (RFC3339 ou YYYY-MM-DD)"
+// @Param limit query int false "Máximo de registros (padrão 100, máximo 1000)"
+// @Param offset query int false "Deslocamento"
+// @Success 200 {object} dto.AuditListResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 403 {object} dto.ErrorResponse
+// @Security ApiKeyAuth
+// @Router /audit [get]
+func (h *AuditHandler) ListAudit(c *gin.Context) {
+	from, err := parseAuditTime(c.Query("from"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{Error: "invalid_request", Message: "invalid from: use RFC3339 or YYYY-MM-DD"})
+		return
+	}
+	to, err := parseAuditTime(c.Query("to"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{Error: "invalid_request", Message: "invalid to: use RFC3339 or YYYY-MM-DD"})
+		return
+	}
+
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "100"))
+	offset, _ := strconv.Atoi(c.DefaultQuery("offset", "0"))
+	if limit <= 0 || limit > 1000 {
+		limit = 100
+	}
+	if offset < 0 {
+		offset = 0
+	}
+
+	entries, err := h.auditService.List(c.Request.Context(), model.AuditFilter{
+		SessionID:  c.Query("sessionId"),
+		ActorKeyID: c.Query("keyId"),
+		Method:     strings.ToUpper(c.Query("method")),
+		Route:      c.Query("route"),
+		Result:     model.AuditResult(c.Query("result")),
+		RequestID:  c.Query("requestId"),
+		From:       from,
+		To:         to,
+		Limit:      limit,
+		Offset:     offset,
+	})
+	if err != nil {
+		logger.Log.Error().Err(err).Msg("Failed to list audit entries")
+		c.JSON(http.StatusInternalServerError, dto.ErrorResponse{
+			Error:   "list_failed",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	response := dto.AuditListResponse{
+		Entries: make([]dto.AuditEntryResponse, 0, len(entries)),
+		Limit:   limit,
+		Offset:  offset,
+	}
+	for _, entry := range entries {
+		response.Entries = append(response.Entries, dto.AuditEntryResponse{
+			ID:             entry.ID,
+			TenantID:       entry.TenantID,
+			ActorKeyID:     entry.ActorKeyID,
+			ActorKeyPrefix: entry.ActorKeyPrefix,
+			ActorName:      entry.ActorName,
+			IP:             entry.IP,
+			Method:         entry.Method,
+			Route:          entry.Route,
+			Path:           entry.Path,
+			RequestID:      entry.RequestID,
+			SessionID:      entry.SessionID,
+			SessionJID:     entry.SessionJID,
+			Summary:        entry.Summary,
+			Status:         entry.Status,
+			Result:         string(entry.Result),
+			DurationMs:     entry.DurationMs,
+			CreatedAt:      entry.CreatedAt,
+		})
+	}
+
+	c.JSON(http.StatusOK, response)
+}
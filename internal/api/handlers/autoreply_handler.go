@@ -0,0 +1,265 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"zpwoot/internal/api/dto"
+	"zpwoot/internal/model"
+	"zpwoot/internal/service"
+	"zpwoot/pkg/logger"
+)
+
+type AutoReplyHandler struct {
+	sessionManager *service.SessionManager
+	autoResponder  *service.AutoResponder
+}
+
+func NewAutoReplyHandler(sessionManager *service.SessionManager, autoResponder *service.AutoResponder) *AutoReplyHandler {
+	return &AutoReplyHandler{
+		sessionManager: sessionManager,
+		autoResponder:  autoResponder,
+	}
+}
+
+func toAutoReplyRule(sessionID string, req *dto.AutoReplyRuleRequest) *model.AutoReplyRule {
+	rule := &model.AutoReplyRule{
+		SessionID: sessionID,
+		Name:      req.Name,
+		MatchType: model.AutoReplyMatchType(req.MatchType),
+		Pattern:   req.Pattern,
+		Response: &model.AutoReplyResponse{
+			Type:       req.Response.Type,
+			Text:       req.Response.Text,
+			MediaURL:   req.Response.MediaURL,
+			Caption:    req.Response.Caption,
+			FileName:   req.Response.FileName,
+			TemplateID: req.Response.TemplateID,
+			Variables:  req.Response.Variables,
+		},
+		Cooldown: req.Cooldown,
+		Enabled:  true,
+		Priority: req.Priority,
+	}
+	if req.Enabled != nil {
+		rule.Enabled = *req.Enabled
+	}
+
+	if req.BusinessHours != nil {
+		rule.BusinessHours = &model.BusinessHours{Timezone: req.BusinessHours.Timezone}
+		for _, r := range req.BusinessHours.Ranges {
+			rule.BusinessHours.Ranges = append(rule.BusinessHours.Ranges, model.BusinessHoursRange{
+				Weekdays: r.Weekdays,
+				Start:    r.Start,
+				End:      r.End,
+			})
+		}
+	}
+
+	return rule
+}
+
+func autoReplyRuleResponse(rule *model.AutoReplyRule) dto.AutoReplyRuleResponse {
+	response := dto.AutoReplyRuleResponse{
+		RuleID:    rule.ID,
+		SessionID: rule.SessionID,
+		Name:      rule.Name,
+		MatchType: string(rule.MatchType),
+		Pattern:   rule.Pattern,
+		Response: dto.AutoReplyResponse{
+			Type:       rule.Response.Type,
+			Text:       rule.Response.Text,
+			MediaURL:   rule.Response.MediaURL,
+			Caption:    rule.Response.Caption,
+			FileName:   rule.Response.FileName,
+			TemplateID: rule.Response.TemplateID,
+			Variables:  rule.Response.Variables,
+		},
+		Cooldown:  rule.Cooldown,
+		Enabled:   rule.Enabled,
+		Priority:  rule.Priority,
+		CreatedAt: rule.CreatedAt,
+		UpdatedAt: rule.UpdatedAt,
+	}
+
+	if rule.BusinessHours != nil {
+		response.BusinessHours = &dto.BusinessHours{Timezone: rule.BusinessHours.Timezone}
+		for _, r := range rule.BusinessHours.Ranges {
+			response.BusinessHours.Ranges = append(response.BusinessHours.Ranges, dto.BusinessHoursRange{
+				Weekdays: r.Weekdays,
+				Start:    r.Start,
+				End:      r.End,
+			})
+		}
+	}
+
+	return response
+}
+
+// respondAutoReplyError traduz erros de validação e do serviço para a resposta HTTP
+func respondAutoReplyError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, service.ErrInvalidAutoReplyRule):
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{Error: "invalid_rule", Message: err.Error()})
+	case errors.Is(err, service.ErrTemplateNotFound):
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{Error: "template_not_found", Message: err.Error()})
+	default:
+		logger.Log.Error().Err(err).Msg("Auto-reply rule operation failed")
+		c.JSON(http.StatusInternalServerError, dto.ErrorResponse{Error: "autoreply_failed", Message: err.Error()})
+	}
+}
+
+// @Summary Criar regra de resposta automática
+// @Description Cria uma regra avaliada a cada mensagem recebida em conversas individuais. A condição pode ser palavra-chave exata (sem diferenciar maiúsculas), regex, primeira mensagem do contato ou fora do horário comercial. A primeira regra ativa que casar (maior prioridade) responde, respeitando o cooldown por contato
+// @Tags AutoReply
+// @Accept json
+// @Produce json
+// @Param id path string true "Session ID"
+// @Param request body dto.AutoReplyRuleRequest true "Regra"
+// @Success 201 {object} dto.AutoReplyRuleResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Security ApiKeyAuth
+// @Router /sessions/{id}/autoreply [post]
+func (h *AutoReplyHandler) CreateRule(c *gin.Context) {
+	sessionID := c.Param("id")
+
+	var req dto.AutoReplyRuleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+			Error:   "invalid_request",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	if _, err := h.sessionManager.GetSession(c.Request.Context(), sessionID); err != nil {
+		c.JSON(http.StatusNotFound, dto.ErrorResponse{
+			Error:   "session_not_found",
+			Message: fmt.Sprintf("Session not found: %s", sessionID),
+		})
+		return
+	}
+
+	rule := toAutoReplyRule(sessionID, &req)
+	if err := h.autoResponder.Create(c.Request.Context(), rule); err != nil {
+		respondAutoReplyError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, autoReplyRuleResponse(rule))
+}
+
+// @Summary Listar regras de resposta automática
+// @Description Lista as regras da sessão na ordem de avaliação
+// @Tags AutoReply
+// @Produce json
+// @Param id path string true "Session ID"
+// @Success 200 {object} dto.AutoReplyRuleListResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Security ApiKeyAuth
+// @Router /sessions/{id}/autoreply [get]
+func (h *AutoReplyHandler) ListRules(c *gin.Context) {
+	rules, err := h.autoResponder.List(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		respondAutoReplyError(c, err)
+		return
+	}
+
+	response := dto.AutoReplyRuleListResponse{
+		Rules: make([]dto.AutoReplyRuleResponse, 0, len(rules)),
+		Total: len(rules),
+	}
+	for _, rule := range rules {
+		response.Rules = append(response.Rules, autoReplyRuleResponse(rule))
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// @Summary Consultar regra de resposta automática
+// @Description Retorna uma regra da sessão
+// @Tags AutoReply
+// @Produce json
+// @Param id path string true "Session ID"
+// @Param ruleId path string true "Rule ID"
+// @Success 200 {object} dto.AutoReplyRuleResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Security ApiKeyAuth
+// @Router /sessions/{id}/autoreply/{ruleId} [get]
+func (h *AutoReplyHandler) GetRule(c *gin.Context) {
+	rule, err := h.autoResponder.Get(c.Request.Context(), c.Param("id"), c.Param("ruleId"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, dto.ErrorResponse{Error: "rule_not_found", Message: err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, autoReplyRuleResponse(rule))
+}
+
+// @Summary Atualizar regra de resposta automática
+// @Description Substitui a regra (use enabled=false para desativá-la sem remover)
+// @Tags AutoReply
+// @Accept json
+// @Produce json
+// @Param id path string true "Session ID"
+// @Param ruleId path string true "Rule ID"
+// @Param request body dto.AutoReplyRuleRequest true "Regra"
+// @Success 200 {object} dto.AutoReplyRuleResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Security ApiKeyAuth
+// @Router /sessions/{id}/autoreply/{ruleId} [put]
+func (h *AutoReplyHandler) UpdateRule(c *gin.Context) {
+	sessionID := c.Param("id")
+	ruleID := c.Param("ruleId")
+
+	if _, err := h.autoResponder.Get(c.Request.Context(), sessionID, ruleID); err != nil {
+		c.JSON(http.StatusNotFound, dto.ErrorResponse{Error: "rule_not_found", Message: err.Error()})
+		return
+	}
+
+	var req dto.AutoReplyRuleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+			Error:   "invalid_request",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	rule := toAutoReplyRule(sessionID, &req)
+	rule.ID = ruleID
+	if err := h.autoResponder.Update(c.Request.Context(), rule); err != nil {
+		respondAutoReplyError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, autoReplyRuleResponse(rule))
+}
+
+// @Summary Deletar regra de resposta automática
+// @Description Remove a regra e o histórico de cooldown dela
+// @Tags AutoReply
+// @Produce json
+// @Param id path string true "Session ID"
+// @Param ruleId path string true "Rule ID"
+// @Success 200 {object} dto.SuccessResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Security ApiKeyAuth
+// @Router /sessions/{id}/autoreply/{ruleId} [delete]
+func (h *AutoReplyHandler) DeleteRule(c *gin.Context) {
+	if err := h.autoResponder.Delete(c.Request.Context(), c.Param("id"), c.Param("ruleId")); err != nil {
+		c.JSON(http.StatusNotFound, dto.ErrorResponse{Error: "rule_not_found", Message: err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.SuccessResponse{
+		Success: true,
+		Message: "Auto-reply rule deleted successfully",
+	})
+}
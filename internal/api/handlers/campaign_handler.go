@@ -0,0 +1,488 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"zpwoot/internal/api/dto"
+	"zpwoot/internal/model"
+	"zpwoot/internal/service"
+	"zpwoot/pkg/logger"
+)
+
+const (
+	defaultCampaignMinDelay = 3
+	defaultCampaignMaxDelay = 10
+)
+
+type CampaignHandler struct {
+	sessionManager  *service.SessionManager
+	campaignService *service.CampaignService
+}
+
+func NewCampaignHandler(sessionManager *service.SessionManager, campaignService *service.CampaignService) *CampaignHandler {
+	return &CampaignHandler{
+		sessionManager:  sessionManager,
+		campaignService: campaignService,
+	}
+}
+
+func toRecipientModels(recipients []dto.CampaignRecipient) []*model.CampaignRecipient {
+	models := make([]*model.CampaignRecipient, 0, len(recipients))
+	for _, recipient := range recipients {
+		models = append(models, &model.CampaignRecipient{
+			Phone:     recipient.Phone,
+			Variables: model.RecipientVariables(recipient.Variables),
+		})
+	}
+	return models
+}
+
+func campaignProgress(progress *model.CampaignProgress) dto.CampaignProgress {
+	response := dto.CampaignProgress{
+		Total:         progress.Total,
+		Pending:       progress.Pending,
+		Sent:          progress.Sent,
+		Failed:        progress.Failed,
+		NotOnWhatsApp: progress.NotOnWhatsApp,
+	}
+	if progress.Total > 0 {
+		done := progress.Total - progress.Pending
+		response.Percent = float64(done*1000/progress.Total) / 10
+	}
+	return response
+}
+
+// campaignResponse monta a resposta com o progresso atual da campanha
+func (h *CampaignHandler) campaignResponse(c *gin.Context, campaign *model.Campaign) (dto.CampaignResponse, error) {
+	progress, err := h.campaignService.Progress(c.Request.Context(), campaign.ID)
+	if err != nil {
+		return dto.CampaignResponse{}, err
+	}
+
+	return dto.CampaignResponse{
+		CampaignID: campaign.ID,
+		SessionID:  campaign.SessionID,
+		Name:       campaign.Name,
+		Template: dto.CampaignTemplate{
+			Type:     campaign.Template.Type,
+			Text:     campaign.Template.Text,
+			MediaURL: campaign.Template.MediaURL,
+			Caption:  campaign.Template.Caption,
+			FileName: campaign.Template.FileName,
+		},
+		MinDelay:    campaign.MinDelay,
+		MaxDelay:    campaign.MaxDelay,
+		Status:      string(campaign.Status),
+		Progress:    campaignProgress(progress),
+		CreatedAt:   campaign.CreatedAt,
+		UpdatedAt:   campaign.UpdatedAt,
+		StartedAt:   campaign.StartedAt,
+		CompletedAt: campaign.CompletedAt,
+	}, nil
+}
+
+func (h *CampaignHandler) respondCampaign(c *gin.Context, status int, campaign *model.Campaign) {
+	response, err := h.campaignResponse(c, campaign)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, dto.ErrorResponse{
+			Error:   "progress_failed",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(status, response)
+}
+
+// respondCampaignError traduz erros de validação para 400 e os demais para 404
+func respondCampaignError(c *gin.Context, err error) {
+	if errors.Is(err, service.ErrInvalidCampaign) {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+			Error:   "invalid_campaign",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusNotFound, dto.ErrorResponse{
+		Error:   "campaign_not_found",
+		Message: err.Error(),
+	})
+}
+
+// readRecipients lê a lista de destinatários do corpo: CSV (text/csv ou arquivo multipart "file")
+// ou JSON ({"recipients": [...]})
+func readRecipients(c *gin.Context) ([]*model.CampaignRecipient, error) {
+	contentType := c.ContentType()
+
+	switch {
+	case contentType == "multipart/form-data":
+		fileHeader, err := c.FormFile("file")
+		if err != nil {
+			return nil, fmt.Errorf("%w: file is required", service.ErrInvalidCampaign)
+		}
+
+		file, err := fileHeader.Open()
+		if err != nil {
+			return nil, fmt.Errorf("failed to open file: %w", err)
+		}
+		defer file.Close()
+
+		if strings.EqualFold(filepath.Ext(fileHeader.Filename), ".json") {
+			return decodeRecipientsJSON(file)
+		}
+		return service.ParseRecipientsCSV(file)
+
+	case contentType == "text/csv":
+		return service.ParseRecipientsCSV(c.Request.Body)
+
+	default:
+		return decodeRecipientsJSON(c.Request.Body)
+	}
+}
+
+func decodeRecipientsJSON(r io.Reader) ([]*model.CampaignRecipient, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read recipients: %w", err)
+	}
+
+	var req dto.AddRecipientsRequest
+	if err := decodeRequest(data, &req); err != nil {
+		return nil, fmt.Errorf("%w: %v", service.ErrInvalidCampaign, err)
+	}
+
+	return toRecipientModels(req.Recipients), nil
+}
+
+// @Summary Criar campanha
+// @Description Cria uma campanha em draft com o template (texto ou mídia, com {{variaveis}}) e, opcionalmente, os destinatários. Destinatários também podem ser enviados depois em /recipients (CSV ou JSON)
+// @Tags Campaigns
+// @Accept json
+// @Produce json
+// @Param id path string true "Session ID"
+// @Param request body dto.CreateCampaignRequest true "Campanha"
+// @Success 201 {object} dto.CampaignResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Security ApiKeyAuth
+// @Router /sessions/{id}/campaigns [post]
+func (h *CampaignHandler) CreateCampaign(c *gin.Context) {
+	sessionID := c.Param("id")
+
+	var req dto.CreateCampaignRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+			Error:   "invalid_request",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	if _, err := h.sessionManager.GetSession(c.Request.Context(), sessionID); err != nil {
+		c.JSON(http.StatusNotFound, dto.ErrorResponse{
+			Error:   "session_not_found",
+			Message: fmt.Sprintf("Session not found: %s", sessionID),
+		})
+		return
+	}
+
+	campaign := &model.Campaign{
+		SessionID: sessionID,
+		Name:      req.Name,
+		Template: &model.CampaignTemplate{
+			Type:     req.Template.Type,
+			Text:     req.Template.Text,
+			MediaURL: req.Template.MediaURL,
+			Caption:  req.Template.Caption,
+			FileName: req.Template.FileName,
+		},
+		MinDelay: defaultCampaignMinDelay,
+		MaxDelay: defaultCampaignMaxDelay,
+	}
+	if req.MinDelay != nil {
+		campaign.MinDelay = *req.MinDelay
+	}
+	if req.MaxDelay != nil {
+		campaign.MaxDelay = *req.MaxDelay
+	}
+
+	if err := h.campaignService.Create(c.Request.Context(), campaign, toRecipientModels(req.Recipients)); err != nil {
+		if errors.Is(err, service.ErrInvalidCampaign) {
+			respondCampaignError(c, err)
+			return
+		}
+
+		logger.Log.Error().Err(err).Str("session_id", sessionID).Msg("Failed to create campaign")
+		c.JSON(http.StatusInternalServerError, dto.ErrorResponse{
+			Error:   "create_failed",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	h.respondCampaign(c, http.StatusCreated, campaign)
+}
+
+// @Summary Listar campanhas
+// @Description Lista as campanhas da sessão com o progresso de cada uma
+// @Tags Campaigns
+// @Produce json
+// @Param id path string true "Session ID"
+// @Success 200 {object} dto.CampaignListResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Security ApiKeyAuth
+// @Router /sessions/{id}/campaigns [get]
+func (h *CampaignHandler) ListCampaigns(c *gin.Context) {
+	sessionID := c.Param("id")
+
+	campaigns, err := h.campaignService.List(c.Request.Context(), sessionID)
+	if err != nil {
+		logger.Log.Error().Err(err).Str("session_id", sessionID).Msg("Failed to list campaigns")
+		c.JSON(http.StatusInternalServerError, dto.ErrorResponse{
+			Error:   "list_failed",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	response := dto.CampaignListResponse{
+		Campaigns: make([]dto.CampaignResponse, 0, len(campaigns)),
+		Total:     len(campaigns),
+	}
+	for _, campaign := range campaigns {
+		item, err := h.campaignResponse(c, campaign)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, dto.ErrorResponse{
+				Error:   "progress_failed",
+				Message: err.Error(),
+			})
+			return
+		}
+		response.Campaigns = append(response.Campaigns, item)
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// @Summary Consultar campanha
+// @Description Retorna a campanha com o progresso (enviados, falhas, sem WhatsApp e pendentes)
+// @Tags Campaigns
+// @Produce json
+// @Param id path string true "Session ID"
+// @Param campaignId path string true "Campaign ID"
+// @Success 200 {object} dto.CampaignResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Security ApiKeyAuth
+// @Router /sessions/{id}/campaigns/{campaignId} [get]
+func (h *CampaignHandler) GetCampaign(c *gin.Context) {
+	campaign, err := h.campaignService.Get(c.Request.Context(), c.Param("id"), c.Param("campaignId"))
+	if err != nil {
+		respondCampaignError(c, err)
+		return
+	}
+
+	h.respondCampaign(c, http.StatusOK, campaign)
+}
+
+// @Summary Adicionar destinatários
+// @Description Adiciona destinatários à campanha. Aceita CSV (Content-Type text/csv ou arquivo multipart "file") com cabeçalho, onde a coluna "phone" é o telefone e as demais colunas viram variáveis, ou JSON. Telefones repetidos são ignorados
+// @Tags Campaigns
+// @Accept json,text/csv,mpfd
+// @Produce json
+// @Param id path string true "Session ID"
+// @Param campaignId path string true "Campaign ID"
+// @Param request body dto.AddRecipientsRequest false "Destinatários (JSON)"
+// @Param file formData file false "Arquivo CSV ou JSON"
+// @Success 200 {object} dto.AddRecipientsResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Security ApiKeyAuth
+// @Router /sessions/{id}/campaigns/{campaignId}/recipients [post]
+func (h *CampaignHandler) AddRecipients(c *gin.Context) {
+	sessionID := c.Param("id")
+	campaignID := c.Param("campaignId")
+
+	recipients, err := readRecipients(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+			Error:   "invalid_request",
+			Message: err.Error(),
+		})
+		return
+	}
+	if len(recipients) == 0 {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+			Error:   "invalid_request",
+			Message: "no recipients found",
+		})
+		return
+	}
+
+	added, err := h.campaignService.AddRecipients(c.Request.Context(), sessionID, campaignID, recipients)
+	if err != nil {
+		respondCampaignError(c, err)
+		return
+	}
+
+	progress, err := h.campaignService.Progress(c.Request.Context(), campaignID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, dto.ErrorResponse{
+			Error:   "progress_failed",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.AddRecipientsResponse{
+		Added: added,
+		Total: progress.Total,
+	})
+}
+
+// @Summary Listar destinatários
+// @Description Lista os destinatários da campanha com o resultado de cada envio
+// @Tags Campaigns
+// @Produce json
+// @Param id path string true "Session ID"
+// @Param campaignId path string true "Campaign ID"
+// @Param status query string false "Filtrar por status" Enums(pending, sending, sent, failed, not_on_whatsapp)
+// @Param limit query int false "Quantidade (padrão 100, máximo 1000)"
+// @Param offset query int false "Deslocamento"
+// @Success 200 {object} dto.CampaignRecipientListResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Security ApiKeyAuth
+// @Router /sessions/{id}/campaigns/{campaignId}/recipients [get]
+func (h *CampaignHandler) ListRecipients(c *gin.Context) {
+	campaign, err := h.campaignService.Get(c.Request.Context(), c.Param("id"), c.Param("campaignId"))
+	if err != nil {
+		respondCampaignError(c, err)
+		return
+	}
+
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "100"))
+	offset, _ := strconv.Atoi(c.DefaultQuery("offset", "0"))
+	if limit <= 0 || limit > 1000 {
+		limit = 100
+	}
+	if offset < 0 {
+		offset = 0
+	}
+
+	recipients, err := h.campaignService.ListRecipients(c.Request.Context(), campaign.ID, model.RecipientStatus(c.Query("status")), limit, offset)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, dto.ErrorResponse{
+			Error:   "list_failed",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	response := dto.CampaignRecipientListResponse{
+		Recipients: make([]dto.CampaignRecipientResponse, 0, len(recipients)),
+		Limit:      limit,
+		Offset:     offset,
+	}
+	for _, recipient := range recipients {
+		response.Recipients = append(response.Recipients, dto.CampaignRecipientResponse{
+			Phone:     recipient.Phone,
+			Variables: recipient.Variables,
+			Status:    string(recipient.Status),
+			MessageID: recipient.MessageID,
+			Error:     recipient.Error,
+			SentAt:    recipient.SentAt,
+		})
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// @Summary Iniciar campanha
+// @Description Inicia uma campanha em draft. Os envios usam intervalo aleatório entre minDelay e maxDelay e aguardam a sessão estar conectada
+// @Tags Campaigns
+// @Produce json
+// @Param id path string true "Session ID"
+// @Param campaignId path string true "Campaign ID"
+// @Success 200 {object} dto.CampaignResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Security ApiKeyAuth
+// @Router /sessions/{id}/campaigns/{campaignId}/start [post]
+func (h *CampaignHandler) StartCampaign(c *gin.Context) {
+	campaign, err := h.campaignService.StartCampaign(c.Request.Context(), c.Param("id"), c.Param("campaignId"))
+	if err != nil {
+		respondCampaignError(c, err)
+		return
+	}
+
+	h.respondCampaign(c, http.StatusOK, campaign)
+}
+
+// @Summary Pausar campanha
+// @Description Pausa uma campanha em execução; o envio em andamento é concluído antes de parar
+// @Tags Campaigns
+// @Produce json
+// @Param id path string true "Session ID"
+// @Param campaignId path string true "Campaign ID"
+// @Success 200 {object} dto.CampaignResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Security ApiKeyAuth
+// @Router /sessions/{id}/campaigns/{campaignId}/pause [post]
+func (h *CampaignHandler) PauseCampaign(c *gin.Context) {
+	campaign, err := h.campaignService.PauseCampaign(c.Request.Context(), c.Param("id"), c.Param("campaignId"))
+	if err != nil {
+		respondCampaignError(c, err)
+		return
+	}
+
+	h.respondCampaign(c, http.StatusOK, campaign)
+}
+
+// @Summary Retomar campanha
+// @Description Retoma uma campanha pausada a partir do próximo destinatário pendente
+// @Tags Campaigns
+// @Produce json
+// @Param id path string true "Session ID"
+// @Param campaignId path string true "Campaign ID"
+// @Success 200 {object} dto.CampaignResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Security ApiKeyAuth
+// @Router /sessions/{id}/campaigns/{campaignId}/resume [post]
+func (h *CampaignHandler) ResumeCampaign(c *gin.Context) {
+	campaign, err := h.campaignService.ResumeCampaign(c.Request.Context(), c.Param("id"), c.Param("campaignId"))
+	if err != nil {
+		respondCampaignError(c, err)
+		return
+	}
+
+	h.respondCampaign(c, http.StatusOK, campaign)
+}
+
+// @Summary Cancelar campanha
+// @Description Cancela a campanha; destinatários ainda pendentes não recebem a mensagem
+// @Tags Campaigns
+// @Produce json
+// @Param id path string true "Session ID"
+// @Param campaignId path string true "Campaign ID"
+// @Success 200 {object} dto.CampaignResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Security ApiKeyAuth
+// @Router /sessions/{id}/campaigns/{campaignId}/cancel [post]
+func (h *CampaignHandler) CancelCampaign(c *gin.Context) {
+	campaign, err := h.campaignService.CancelCampaign(c.Request.Context(), c.Param("id"), c.Param("campaignId"))
+	if err != nil {
+		respondCampaignError(c, err)
+		return
+	}
+
+	h.respondCampaign(c, http.StatusOK, campaign)
+}
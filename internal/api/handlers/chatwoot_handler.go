@@ -0,0 +1,161 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+
+	"github.com/gin-gonic/gin"
+
+	"zpwoot/internal/api/dto"
+	"zpwoot/internal/model"
+	"zpwoot/internal/service"
+	"zpwoot/pkg/logger"
+)
+
+type ChatwootHandler struct {
+	sessionManager  *service.SessionManager
+	chatwootService *service.ChatwootService
+}
+
+func NewChatwootHandler(sessionManager *service.SessionManager, chatwootService *service.ChatwootService) *ChatwootHandler {
+	return &ChatwootHandler{
+		sessionManager:  sessionManager,
+		chatwootService: chatwootService,
+	}
+}
+
+func chatwootConfigResponse(session *model.Session) dto.ChatwootConfigResponse {
+	response := dto.ChatwootConfigResponse{
+		SessionID: session.ID,
+		UpdatedAt: session.UpdatedAt,
+	}
+
+	if config := session.ChatwootConfig; config != nil {
+		response.Enabled = config.Enabled
+		response.URL = config.URL
+		response.AccountID = config.AccountID
+		response.InboxID = config.InboxID
+		response.WebhookPath = fmt.Sprintf("/chatwoot/webhook/%s?token=%s", session.ID, url.QueryEscape(config.WebhookToken))
+	}
+
+	return response
+}
+
+// @Summary Configurar Chatwoot
+// @Description Liga a sessão a uma inbox do tipo API do Chatwoot. Mensagens recebidas (e enviadas pelo celular) são espelhadas em contatos/conversas da inbox; respostas dos agentes são enviadas pela sessão. Configure webhook_path (com o endereço público do zpwoot) como URL de webhook da inbox
+// @Tags Chatwoot
+// @Accept json
+// @Produce json
+// @Param id path string true "Session ID"
+// @Param request body dto.ChatwootConfig true "Inbox do Chatwoot"
+// @Success 200 {object} dto.ChatwootConfigResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Security ApiKeyAuth
+// @Router /sessions/{id}/chatwoot/set [post]
+func (h *ChatwootHandler) SetChatwootConfig(c *gin.Context) {
+	sessionID := c.Param("id")
+
+	var req dto.ChatwootConfig
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+			Error:   "invalid_request",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	chatwootConfig := &model.ChatwootConfig{
+		Enabled:      req.Enabled,
+		URL:          req.URL,
+		AccountID:    req.AccountID,
+		InboxID:      req.InboxID,
+		Token:        req.Token,
+		WebhookToken: req.WebhookToken,
+	}
+
+	if err := h.sessionManager.UpdateChatwootConfig(c.Request.Context(), sessionID, chatwootConfig); err != nil {
+		logger.Log.Error().Err(err).Str("session_id", sessionID).Msg("Failed to set chatwoot config")
+		c.JSON(http.StatusInternalServerError, dto.ErrorResponse{
+			Error:   "update_failed",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	session, err := h.sessionManager.GetSession(c.Request.Context(), sessionID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, dto.ErrorResponse{
+			Error:   "fetch_failed",
+			Message: "Chatwoot config saved but failed to fetch updated session",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, chatwootConfigResponse(session))
+}
+
+// @Summary Obter configuração do Chatwoot
+// @Description Retorna a inbox do Chatwoot da sessão (sem o token da API)
+// @Tags Chatwoot
+// @Produce json
+// @Param id path string true "Session ID"
+// @Success 200 {object} dto.ChatwootConfigResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Security ApiKeyAuth
+// @Router /sessions/{id}/chatwoot/find [get]
+func (h *ChatwootHandler) FindChatwootConfig(c *gin.Context) {
+	sessionID := c.Param("id")
+
+	session, err := h.sessionManager.GetSession(c.Request.Context(), sessionID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, dto.ErrorResponse{
+			Error:   "session_not_found",
+			Message: fmt.Sprintf("Session not found: %s", sessionID),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, chatwootConfigResponse(session))
+}
+
+// @Summary Webhook do Chatwoot
+// @Description Recebe os eventos da inbox (message_created, message_updated). Autenticado pelo token da URL gerado na configuração, não pela API key
+// @Tags Chatwoot
+// @Accept json
+// @Produce json
+// @Param id path string true "Session ID"
+// @Param token query string true "Token do webhook"
+// @Success 200 {object} dto.SuccessResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Router /chatwoot/webhook/{id} [post]
+func (h *ChatwootHandler) ReceiveWebhook(c *gin.Context) {
+	sessionID := c.Param("id")
+
+	var evt service.ChatwootWebhookEvent
+	if err := c.ShouldBindJSON(&evt); err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+			Error:   "invalid_request",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	if err := h.chatwootService.HandleWebhook(c.Request.Context(), sessionID, c.Query("token"), &evt); err != nil {
+		switch {
+		case errors.Is(err, service.ErrChatwootUnauthorized):
+			c.JSON(http.StatusUnauthorized, dto.ErrorResponse{Error: "unauthorized", Message: err.Error()})
+		case errors.Is(err, service.ErrChatwootDisabled):
+			c.JSON(http.StatusNotFound, dto.ErrorResponse{Error: "chatwoot_disabled", Message: err.Error()})
+		default:
+			logger.Log.Error().Err(err).Str("session_id", sessionID).Msg("Failed to process chatwoot webhook")
+			c.JSON(http.StatusInternalServerError, dto.ErrorResponse{Error: "webhook_failed", Message: err.Error()})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.SuccessResponse{Success: true})
+}
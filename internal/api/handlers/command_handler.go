@@ -0,0 +1,221 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/nats-io/nats.go"
+	"go.mau.fi/whatsmeow"
+
+	"zpwoot/internal/api/dto"
+	natsclient "zpwoot/internal/nats"
+	"zpwoot/internal/service"
+	"zpwoot/pkg/logger"
+)
+
+// Header com o status HTTP equivalente da resposta (200, 400, 404, 500, 503)
+const CommandStatusHeader = "Zpwoot-Status"
+
+// commandSessions é a parte do SessionManager usada pela API de comandos
+type commandSessions interface {
+	AddHostListener(listener service.SessionHostListener)
+	GetClient(sessionID string) (*whatsmeow.Client, error)
+}
+
+// CommandHandler atende comandos via NATS request/reply em <prefix>.<session>.send.<tipo>,
+// aceitando os mesmos DTOs do MessageHandler (inclusive templateId + variables). Cada réplica assina
+// apenas os subjects das sessões que hospeda: comandos de sessões sem réplica recebem "no responders"
+// do NATS. O queue group evita envios duplicados se duas réplicas hospedarem a sessão ao mesmo tempo.
+type CommandHandler struct {
+	sessionManager commandSessions
+	dispatcher     *MessageDispatcher
+	templates      *service.TemplateService
+	natsClient     *natsclient.Client
+	prefix         string
+	queue          string
+	timeout        time.Duration
+
+	mu            sync.Mutex
+	subscriptions map[string]*nats.Subscription
+	stopped       bool
+}
+
+func NewCommandHandler(sessionManager commandSessions, dispatcher *MessageDispatcher, templates *service.TemplateService, natsClient *natsclient.Client, prefix, queue string, timeout time.Duration) *CommandHandler {
+	if timeout <= 0 {
+		timeout = time.Minute
+	}
+
+	return &CommandHandler{
+		sessionManager: sessionManager,
+		dispatcher:     dispatcher,
+		templates:      templates,
+		natsClient:     natsClient,
+		prefix:         strings.TrimSuffix(prefix, "."),
+		queue:          queue,
+		timeout:        timeout,
+		subscriptions:  make(map[string]*nats.Subscription),
+	}
+}
+
+// Start assina os comandos das sessões já hospedadas e acompanha as próximas
+func (h *CommandHandler) Start() error {
+	h.sessionManager.AddHostListener(h)
+
+	logger.Log.Info().
+		Str(logger.FieldSubject, h.prefix+".<session>.send.*").
+		Str(logger.FieldQueue, h.queue).
+		Msg("✅ NATS command API started")
+
+	return nil
+}
+
+func (h *CommandHandler) Stop() error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.stopped = true
+
+	var errs []error
+	for sessionID, sub := range h.subscriptions {
+		if err := sub.Unsubscribe(); err != nil {
+			errs = append(errs, err)
+		}
+		delete(h.subscriptions, sessionID)
+	}
+	return errors.Join(errs...)
+}
+
+// SessionHosted assina os comandos da sessão que passou a rodar nesta réplica
+func (h *CommandHandler) SessionHosted(sessionID string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.stopped || h.subscriptions[sessionID] != nil {
+		return
+	}
+
+	subject := h.prefix + "." + sessionID + ".send.*"
+	sub, err := h.natsClient.QueueSubscribe(subject, h.queue, h.handleMessage)
+	if err != nil {
+		logger.Log.Error().
+			Err(err).
+			Str("session_id", sessionID).
+			Str(logger.FieldSubject, subject).
+			Msg("Failed to subscribe to session commands")
+		return
+	}
+
+	h.subscriptions[sessionID] = sub
+}
+
+// SessionReleased cancela a assinatura da sessão que deixou esta réplica
+func (h *CommandHandler) SessionReleased(sessionID string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	sub := h.subscriptions[sessionID]
+	if sub == nil {
+		return
+	}
+	delete(h.subscriptions, sessionID)
+
+	if err := sub.Unsubscribe(); err != nil {
+		logger.Log.Warn().Err(err).Str("session_id", sessionID).Msg("Failed to unsubscribe from session commands")
+	}
+}
+
+// handleMessage processa cada comando em sua própria goroutine para não serializar envios de mídia
+func (h *CommandHandler) handleMessage(msg *nats.Msg) {
+	go h.processCommand(msg)
+}
+
+func (h *CommandHandler) processCommand(msg *nats.Msg) {
+	// <prefix>.<session>.send.<tipo>
+	tokens := strings.Split(strings.TrimPrefix(msg.Subject, h.prefix+"."), ".")
+	if len(tokens) != 3 {
+		h.respondError(msg, &commandError{status: http.StatusBadRequest, code: "invalid_subject", err: fmt.Errorf("invalid command subject: %s", msg.Subject)})
+		return
+	}
+	sessionID, kind := tokens[0], tokens[2]
+
+	data, err := expandTemplate(context.Background(), h.templates, sessionID, kind, msg.Data)
+	if err != nil {
+		h.respondError(msg, err)
+		return
+	}
+
+	if _, err := h.dispatcher.Validate(kind, data); err != nil {
+		h.respondError(msg, err)
+		return
+	}
+
+	// A sessão é desta réplica, mas está reconectando: o cliente pode tentar de novo
+	client, err := h.sessionManager.GetClient(sessionID)
+	if err != nil {
+		h.respondError(msg, &commandError{status: http.StatusServiceUnavailable, code: "session_not_connected", err: errors.New("Session not connected, retry later")})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), h.timeout)
+	defer cancel()
+
+	resp, err := h.dispatcher.Dispatch(ctx, client, kind, data)
+	if err != nil {
+		logger.Log.Error().
+			Err(err).
+			Str("session_id", sessionID).
+			Str("command", kind).
+			Msg("Failed to execute NATS command")
+
+		h.respondError(msg, err)
+		return
+	}
+
+	logger.Log.Info().
+		Str("session_id", sessionID).
+		Str("command", kind).
+		Str("message_id", resp.MessageID).
+		Msg("NATS command executed successfully")
+
+	h.respond(msg, http.StatusOK, resp)
+}
+
+func (h *CommandHandler) respondError(msg *nats.Msg, err error) {
+	var cmdErr *commandError
+	if !errors.As(err, &cmdErr) {
+		cmdErr = &commandError{status: http.StatusInternalServerError, code: "send_failed", err: err}
+	}
+
+	h.respond(msg, cmdErr.status, dto.ErrorResponse{
+		Error:   cmdErr.code,
+		Message: cmdErr.Error(),
+	})
+}
+
+func (h *CommandHandler) respond(msg *nats.Msg, status int, v interface{}) {
+	// Comandos publicados sem reply (fire-and-forget) não recebem resposta
+	if msg.Reply == "" {
+		return
+	}
+
+	data, err := json.Marshal(v)
+	if err != nil {
+		logger.Log.Error().Err(err).Str(logger.FieldSubject, msg.Subject).Msg("Failed to marshal command reply")
+		return
+	}
+
+	reply := nats.NewMsg(msg.Reply)
+	reply.Header.Set(CommandStatusHeader, strconv.Itoa(status))
+	reply.Data = data
+
+	if err := msg.RespondMsg(reply); err != nil {
+		logger.Log.Error().Err(err).Str(logger.FieldSubject, msg.Subject).Msg("Failed to reply to command")
+	}
+}
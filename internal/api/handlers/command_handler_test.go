@@ -0,0 +1,87 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/nats-io/nats.go"
+	"go.mau.fi/whatsmeow"
+
+	"zpwoot/internal/api/dto"
+	"zpwoot/internal/nats/natstest"
+	"zpwoot/internal/service"
+)
+
+// fakeCommandSessions hospeda sessões sem client conectado
+type fakeCommandSessions struct {
+	listener service.SessionHostListener
+}
+
+func (s *fakeCommandSessions) AddHostListener(listener service.SessionHostListener) {
+	s.listener = listener
+}
+
+func (s *fakeCommandSessions) GetClient(sessionID string) (*whatsmeow.Client, error) {
+	return nil, errors.New("session not connected")
+}
+
+func TestCommandHandlerDispatch(t *testing.T) {
+	srv := natstest.NewServer(t)
+	sessions := &fakeCommandSessions{}
+	handler := NewCommandHandler(sessions, NewMessageDispatcher(nil), service.NewTemplateService(nil), natstest.Connect(t, srv.ClientURL()), "zpwoot.cmd.", "zpwoot", time.Second)
+	if err := handler.Start(); err != nil {
+		t.Fatal(err)
+	}
+	defer handler.Stop()
+
+	conn, err := nats.Connect(srv.ClientURL())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	request := func(sessionID, kind, body string) (*nats.Msg, error) {
+		t.Helper()
+		return conn.Request("zpwoot.cmd."+sessionID+".send."+kind, []byte(body), 2*time.Second)
+	}
+	expectError := func(reply *nats.Msg, status, code string) {
+		t.Helper()
+		var resp dto.ErrorResponse
+		if err := json.Unmarshal(reply.Data, &resp); err != nil {
+			t.Fatal(err)
+		}
+		if got := reply.Header.Get(CommandStatusHeader); got != status || resp.Error != code {
+			t.Errorf("reply = %s %s, want %s %s", got, resp.Error, status, code)
+		}
+	}
+
+	// Sessão não hospedada nesta réplica: ninguém assina o subject
+	if _, err := request("s1", "text", `{"phone":"5511999999999","message":"oi"}`); !errors.Is(err, nats.ErrNoResponders) {
+		t.Fatalf("request before hosted error = %v, want no responders", err)
+	}
+
+	sessions.listener.SessionHosted("s1")
+	if err := conn.Flush(); err != nil {
+		t.Fatal(err)
+	}
+
+	reply, err := request("s1", "text", `{"phone":"5511999999999"}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expectError(reply, "400", "invalid_request")
+
+	// Hospedada, mas reconectando
+	reply, err = request("s1", "text", `{"phone":"5511999999999","message":"oi"}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expectError(reply, "503", "session_not_connected")
+
+	sessions.listener.SessionReleased("s1")
+	if _, err := request("s1", "text", `{"phone":"5511999999999","message":"oi"}`); !errors.Is(err, nats.ErrNoResponders) {
+		t.Errorf("request after released error = %v, want no responders", err)
+	}
+}
@@ -0,0 +1,223 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/coder/websocket"
+	"github.com/gin-contrib/sse"
+	"github.com/gin-gonic/gin"
+
+	"zpwoot/internal/api/dto"
+	"zpwoot/internal/constants"
+	"zpwoot/internal/service"
+	"zpwoot/pkg/logger"
+)
+
+type EventStreamHandler struct {
+	sessionManager   *service.SessionManager
+	hub              *service.EventStreamHub
+	heartbeat        time.Duration
+	wsOriginPatterns []string // Origens aceitas no WebSocket (além da própria)
+}
+
+func NewEventStreamHandler(sessionManager *service.SessionManager, hub *service.EventStreamHub, heartbeat time.Duration, wsOriginPatterns []string) *EventStreamHandler {
+	if heartbeat <= 0 {
+		heartbeat = 15 * time.Second
+	}
+
+	return &EventStreamHandler{
+		sessionManager:   sessionManager,
+		hub:              hub,
+		heartbeat:        heartbeat,
+		wsOriginPatterns: wsOriginPatterns,
+	}
+}
+
+// parseStreamRequest valida a sessão, o filtro de eventos (?events=a,b) e o Last-Event-ID
+func (h *EventStreamHandler) parseStreamRequest(c *gin.Context) ([]string, int64, bool) {
+	sessionID := c.Param("id")
+
+	if _, err := h.sessionManager.GetSession(c.Request.Context(), sessionID); err != nil {
+		c.JSON(http.StatusNotFound, dto.ErrorResponse{
+			Error:   "session_not_found",
+			Message: err.Error(),
+		})
+		return nil, 0, false
+	}
+
+	var events []string
+	if raw := c.Query("events"); raw != "" {
+		for _, event := range strings.Split(raw, ",") {
+			event = strings.TrimSpace(event)
+			if event == "" {
+				continue
+			}
+			if !constants.IsValidEventType(event) {
+				c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+					Error:   "invalid_event",
+					Message: fmt.Sprintf("Invalid event type: %s. Use /webhook/events to see supported events", event),
+				})
+				return nil, 0, false
+			}
+			if event == string(constants.EventAll) {
+				events = nil
+				break
+			}
+			events = append(events, event)
+		}
+	}
+
+	// Header padrão do EventSource, com fallback para query (WebSocket)
+	lastEventID := c.GetHeader("Last-Event-ID")
+	if lastEventID == "" {
+		lastEventID = c.Query("last_event_id")
+	}
+
+	var lastID int64
+	if lastEventID != "" {
+		id, err := strconv.ParseInt(lastEventID, 10, 64)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+				Error:   "invalid_request",
+				Message: "Last-Event-ID must be a numeric event id",
+			})
+			return nil, 0, false
+		}
+		lastID = id
+	}
+
+	return events, lastID, true
+}
+
+// @Summary Stream de eventos (SSE)
+// @Description Entrega via Server-Sent Events os mesmos payloads enviados aos webhooks, sem exigir URL pública. Filtre com ?events=message,receipt e retome com o header Last-Event-ID (ou ?last_event_id=). Um evento "heartbeat" é enviado periodicamente. Um evento "reset" indica que eventos foram perdidos (cliente lento ou Last-Event-ID fora do buffer) e que o estado deve ser recarregado
+// @Tags Events
+// @Produce text/event-stream
+// @Param id path string true "Session ID"
+// @Param events query string false "Eventos separados por vírgula"
+// @Param last_event_id query string false "Último ID recebido"
+// @Success 200 {object} service.StreamEvent
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Security ApiKeyAuth
+// @Router /sessions/{id}/events/stream [get]
+func (h *EventStreamHandler) StreamEvents(c *gin.Context) {
+	sessionID := c.Param("id")
+
+	events, lastID, ok := h.parseStreamRequest(c)
+	if !ok {
+		return
+	}
+
+	replay, sub, unsubscribe := h.hub.Subscribe(sessionID, events, lastID)
+	defer unsubscribe()
+
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Header("X-Accel-Buffering", "no")
+
+	for _, evt := range replay {
+		renderSSE(c, evt)
+	}
+	c.Writer.Flush()
+
+	ticker := time.NewTicker(h.heartbeat)
+	defer ticker.Stop()
+
+	c.Stream(func(w io.Writer) bool {
+		select {
+		case <-c.Request.Context().Done():
+			return false
+		case evt := <-sub.Events():
+			renderSSE(c, evt)
+			return true
+		case <-ticker.C:
+			c.SSEvent("heartbeat", dto.StreamHeartbeat{Event: "heartbeat", Timestamp: time.Now()})
+			return true
+		}
+	})
+}
+
+func renderSSE(c *gin.Context, evt service.StreamEvent) {
+	c.Render(-1, sse.Event{
+		Id:    strconv.FormatInt(evt.ID, 10),
+		Event: evt.Event,
+		Data:  evt,
+	})
+}
+
+// @Summary Stream de eventos (WebSocket)
+// @Description Mesmo conteúdo do stream SSE, enviado como mensagens JSON em uma conexão WebSocket. Use ?events= e ?last_event_id= para filtrar e retomar
+// @Tags Events
+// @Param id path string true "Session ID"
+// @Param events query string false "Eventos separados por vírgula"
+// @Param last_event_id query string false "Último ID recebido"
+// @Success 101 {object} service.StreamEvent
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Security ApiKeyAuth
+// @Router /sessions/{id}/events/ws [get]
+func (h *EventStreamHandler) StreamEventsWS(c *gin.Context) {
+	sessionID := c.Param("id")
+
+	events, lastID, ok := h.parseStreamRequest(c)
+	if !ok {
+		return
+	}
+
+	conn, err := websocket.Accept(c.Writer, c.Request, &websocket.AcceptOptions{
+		OriginPatterns: h.wsOriginPatterns, // Navegadores de outras origens só com WS_ORIGIN_PATTERNS
+	})
+	if err != nil {
+		logger.Log.Warn().Err(err).Str("session_id", sessionID).Msg("Failed to accept events websocket")
+		return
+	}
+	defer conn.CloseNow()
+
+	replay, sub, unsubscribe := h.hub.Subscribe(sessionID, events, lastID)
+	defer unsubscribe()
+
+	// Mensagens do cliente são ignoradas; o contexto é cancelado quando ele fecha a conexão
+	ctx := conn.CloseRead(c.Request.Context())
+
+	for _, evt := range replay {
+		if err := writeWSJSON(ctx, conn, evt); err != nil {
+			return
+		}
+	}
+
+	ticker := time.NewTicker(h.heartbeat)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case evt := <-sub.Events():
+			if err := writeWSJSON(ctx, conn, evt); err != nil {
+				return
+			}
+		case <-ticker.C:
+			if err := writeWSJSON(ctx, conn, dto.StreamHeartbeat{Event: "heartbeat", Timestamp: time.Now()}); err != nil {
+				return
+			}
+			if err := pingWS(ctx, conn); err != nil {
+				return
+			}
+		}
+	}
+}
+
+// pingWS verifica se o cliente ainda responde
+func pingWS(ctx context.Context, conn *websocket.Conn) error {
+	pingCtx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+	return conn.Ping(pingCtx)
+}
@@ -0,0 +1,240 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"zpwoot/internal/api/dto"
+	"zpwoot/internal/model"
+	"zpwoot/internal/service"
+	"zpwoot/pkg/logger"
+)
+
+type FlowBotHandler struct {
+	sessionManager *service.SessionManager
+	flowBotService *service.FlowBotService
+}
+
+func NewFlowBotHandler(sessionManager *service.SessionManager, flowBotService *service.FlowBotService) *FlowBotHandler {
+	return &FlowBotHandler{
+		sessionManager: sessionManager,
+		flowBotService: flowBotService,
+	}
+}
+
+func flowBotConfigResponse(session *model.Session) dto.FlowBotConfigResponse {
+	response := dto.FlowBotConfigResponse{
+		SessionID: session.ID,
+		UpdatedAt: session.UpdatedAt,
+	}
+
+	if config := session.FlowBotConfig; config != nil {
+		response.Enabled = config.Enabled
+		response.URL = config.URL
+		response.TypebotID = config.TypebotID
+		response.Triggers = config.Triggers
+		response.Expire = config.Expire
+		response.FinishKeyword = config.FinishKeyword
+		response.StopOnFromMe = config.StopOnFromMe
+		response.MessageDelay = config.MessageDelay
+	}
+
+	return response
+}
+
+// @Summary Configurar fluxo (Typebot)
+// @Description Liga a sessão a um fluxo compatível com a API de chat do Typebot. Mensagens de conversas individuais que contenham um gatilho iniciam o fluxo; os blocos retornados (texto, imagem, vídeo, áudio e opções numeradas) são enviados ao contato. Não pode ser ativado junto com o assistente (409)
+// @Tags FlowBot
+// @Accept json
+// @Produce json
+// @Param id path string true "Session ID"
+// @Param request body dto.FlowBotConfig true "Fluxo"
+// @Success 200 {object} dto.FlowBotConfigResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 409 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Security ApiKeyAuth
+// @Router /sessions/{id}/flowbot/set [post]
+func (h *FlowBotHandler) SetFlowBotConfig(c *gin.Context) {
+	sessionID := c.Param("id")
+
+	var req dto.FlowBotConfig
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+			Error:   "invalid_request",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	flowBotConfig := &model.FlowBotConfig{
+		Enabled:       req.Enabled,
+		URL:           req.URL,
+		TypebotID:     req.TypebotID,
+		Token:         req.Token,
+		Triggers:      req.Triggers,
+		Expire:        req.Expire,
+		FinishKeyword: req.FinishKeyword,
+		StopOnFromMe:  req.StopOnFromMe,
+		MessageDelay:  req.MessageDelay,
+	}
+
+	if err := h.sessionManager.UpdateFlowBotConfig(c.Request.Context(), sessionID, flowBotConfig); err != nil {
+		if errors.Is(err, service.ErrResponderConflict) {
+			c.JSON(http.StatusConflict, dto.ErrorResponse{
+				Error:   "responder_conflict",
+				Message: err.Error(),
+			})
+			return
+		}
+		logger.Log.Error().Err(err).Str("session_id", sessionID).Msg("Failed to set flow bot config")
+		c.JSON(http.StatusInternalServerError, dto.ErrorResponse{
+			Error:   "update_failed",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	session, err := h.sessionManager.GetSession(c.Request.Context(), sessionID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, dto.ErrorResponse{
+			Error:   "fetch_failed",
+			Message: "Flow bot config saved but failed to fetch updated session",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, flowBotConfigResponse(session))
+}
+
+// @Summary Obter configuração do fluxo
+// @Description Retorna o fluxo da sessão (sem o token)
+// @Tags FlowBot
+// @Produce json
+// @Param id path string true "Session ID"
+// @Success 200 {object} dto.FlowBotConfigResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Security ApiKeyAuth
+// @Router /sessions/{id}/flowbot/find [get]
+func (h *FlowBotHandler) FindFlowBotConfig(c *gin.Context) {
+	sessionID := c.Param("id")
+
+	session, err := h.sessionManager.GetSession(c.Request.Context(), sessionID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, dto.ErrorResponse{
+			Error:   "session_not_found",
+			Message: fmt.Sprintf("Session not found: %s", sessionID),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, flowBotConfigResponse(session))
+}
+
+// @Summary Listar conversas do fluxo
+// @Description Lista os contatos em atendimento pelo fluxo (active) ou entregues a um humano (paused)
+// @Tags FlowBot
+// @Produce json
+// @Param id path string true "Session ID"
+// @Param status query string false "Filtrar por status" Enums(active, paused)
+// @Param limit query int false "Quantidade (padrão 100, máximo 1000)"
+// @Param offset query int false "Deslocamento"
+// @Success 200 {object} dto.FlowBotSessionListResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Security ApiKeyAuth
+// @Router /sessions/{id}/flowbot/sessions [get]
+func (h *FlowBotHandler) ListSessions(c *gin.Context) {
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "100"))
+	offset, _ := strconv.Atoi(c.DefaultQuery("offset", "0"))
+	if limit <= 0 || limit > 1000 {
+		limit = 100
+	}
+	if offset < 0 {
+		offset = 0
+	}
+
+	sessions, err := h.flowBotService.List(c.Request.Context(), c.Param("id"), model.FlowBotStatus(c.Query("status")), limit, offset)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, dto.ErrorResponse{
+			Error:   "list_failed",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	response := dto.FlowBotSessionListResponse{
+		Sessions: make([]dto.FlowBotSessionResponse, 0, len(sessions)),
+		Limit:    limit,
+		Offset:   offset,
+	}
+	for _, session := range sessions {
+		response.Sessions = append(response.Sessions, dto.FlowBotSessionResponse{
+			Phone:        session.Phone,
+			BotSessionID: session.BotSessionID,
+			Status:       string(session.Status),
+			Choices:      session.Choices,
+			CreatedAt:    session.CreatedAt,
+			UpdatedAt:    session.UpdatedAt,
+		})
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// @Summary Passar conversa para um humano
+// @Description Pausa o fluxo para o contato: as mensagens deixam de ser respondidas pelo fluxo até a conversa ser encerrada (ou expirar)
+// @Tags FlowBot
+// @Produce json
+// @Param id path string true "Session ID"
+// @Param phone path string true "Telefone do contato"
+// @Success 200 {object} dto.SuccessResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Security ApiKeyAuth
+// @Router /sessions/{id}/flowbot/sessions/{phone}/stop [post]
+func (h *FlowBotHandler) StopSession(c *gin.Context) {
+	if err := h.flowBotService.Pause(c.Request.Context(), c.Param("id"), c.Param("phone")); err != nil {
+		c.JSON(http.StatusInternalServerError, dto.ErrorResponse{
+			Error:   "stop_failed",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.SuccessResponse{
+		Success: true,
+		Message: "Conversation handed to a human",
+	})
+}
+
+// @Summary Encerrar conversa do fluxo
+// @Description Remove o estado do contato (ativo ou pausado); a próxima mensagem com um gatilho inicia o fluxo novamente
+// @Tags FlowBot
+// @Produce json
+// @Param id path string true "Session ID"
+// @Param phone path string true "Telefone do contato"
+// @Success 200 {object} dto.SuccessResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Security ApiKeyAuth
+// @Router /sessions/{id}/flowbot/sessions/{phone} [delete]
+func (h *FlowBotHandler) DeleteSession(c *gin.Context) {
+	if err := h.flowBotService.Close(c.Request.Context(), c.Param("id"), c.Param("phone")); err != nil {
+		status := http.StatusInternalServerError
+		if errors.Is(err, service.ErrFlowBotSessionNotFound) {
+			status = http.StatusNotFound
+		}
+		c.JSON(status, dto.ErrorResponse{
+			Error:   "delete_failed",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.SuccessResponse{
+		Success: true,
+		Message: "Flow bot session closed",
+	})
+}
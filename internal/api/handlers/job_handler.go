@@ -0,0 +1,206 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"zpwoot/internal/api/dto"
+	"zpwoot/internal/model"
+	"zpwoot/internal/service"
+	"zpwoot/pkg/logger"
+)
+
+type JobHandler struct {
+	sessionManager  *service.SessionManager
+	queue           *service.OutboundQueue
+	templateService *service.TemplateService
+}
+
+func NewJobHandler(sessionManager *service.SessionManager, queue *service.OutboundQueue, templateService *service.TemplateService) *JobHandler {
+	return &JobHandler{
+		sessionManager:  sessionManager,
+		queue:           queue,
+		templateService: templateService,
+	}
+}
+
+func jobResponse(job *model.MessageJob) dto.JobResponse {
+	return dto.JobResponse{
+		JobID:     job.ID,
+		SessionID: job.SessionID,
+		Type:      job.Type,
+		Phone:     job.Phone,
+		Status:    string(job.Status),
+		MessageID: job.MessageID,
+		Error:     job.Error,
+		Attempts:  job.Attempts,
+		CreatedAt: job.CreatedAt,
+		UpdatedAt: job.UpdatedAt,
+		SentAt:    job.SentAt,
+	}
+}
+
+// @Summary Enfileirar mensagem
+// @Description Enfileira o envio respeitando o rate limit da sessão (mensagens/minuto, burst e intervalo por destinatário). O payload é o mesmo corpo do endpoint de envio do tipo informado (aceita templateId + variables). O resultado é enviado no webhook message_job e pode ser consultado em /jobs/{jobId}
+// @Tags Jobs
+// @Accept json
+// @Produce json
+// @Param id path string true "Session ID"
+// @Param request body dto.EnqueueMessageRequest true "Tipo e corpo da mensagem"
+// @Success 202 {object} dto.JobResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Security ApiKeyAuth
+// @Router /sessions/{id}/jobs [post]
+func (h *JobHandler) EnqueueMessage(c *gin.Context) {
+	sessionID := c.Param("id")
+
+	var req dto.EnqueueMessageRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+			Error:   "invalid_request",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	if _, err := h.sessionManager.GetSession(c.Request.Context(), sessionID); err != nil {
+		c.JSON(http.StatusNotFound, dto.ErrorResponse{
+			Error:   "session_not_found",
+			Message: fmt.Sprintf("Session not found: %s", sessionID),
+		})
+		return
+	}
+
+	// O template é aplicado na hora de enfileirar, fixando a versão usada
+	payload, err := expandTemplate(c.Request.Context(), h.templateService, sessionID, req.Type, req.Payload)
+	var job *model.MessageJob
+	if err == nil {
+		job, err = h.queue.Enqueue(c.Request.Context(), sessionID, req.Type, payload)
+	}
+	if err != nil {
+		var cmdErr *commandError
+		if errors.As(err, &cmdErr) {
+			c.JSON(cmdErr.status, dto.ErrorResponse{
+				Error:   cmdErr.code,
+				Message: cmdErr.Error(),
+			})
+			return
+		}
+
+		logger.Log.Error().Err(err).Str("session_id", sessionID).Msg("Failed to enqueue message")
+		c.JSON(http.StatusInternalServerError, dto.ErrorResponse{
+			Error:   "enqueue_failed",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusAccepted, jobResponse(job))
+}
+
+// @Summary Consultar job
+// @Description Retorna o status de um envio enfileirado (queued, sending, sent ou failed)
+// @Tags Jobs
+// @Produce json
+// @Param id path string true "Session ID"
+// @Param jobId path string true "Job ID"
+// @Success 200 {object} dto.JobResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Security ApiKeyAuth
+// @Router /sessions/{id}/jobs/{jobId} [get]
+func (h *JobHandler) GetJob(c *gin.Context) {
+	sessionID := c.Param("id")
+	jobID := c.Param("jobId")
+
+	job, err := h.queue.GetJob(c.Request.Context(), sessionID, jobID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, dto.ErrorResponse{
+			Error:   "job_not_found",
+			Message: fmt.Sprintf("Job not found: %s", jobID),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, jobResponse(job))
+}
+
+// @Summary Configurar rate limit
+// @Description Define o ritmo de envio da fila da sessão: mensagens por minuto, burst e intervalo mínimo (segundos) entre envios ao mesmo destinatário
+// @Tags Jobs
+// @Accept json
+// @Produce json
+// @Param id path string true "Session ID"
+// @Param request body dto.RateLimitConfig true "Ritmo de envio"
+// @Success 200 {object} dto.RateLimitConfigResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Security ApiKeyAuth
+// @Router /sessions/{id}/ratelimit/set [post]
+func (h *JobHandler) SetRateLimit(c *gin.Context) {
+	sessionID := c.Param("id")
+
+	var req dto.RateLimitConfig
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+			Error:   "invalid_request",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	rateLimitConfig := &model.RateLimitConfig{
+		MessagesPerMinute: req.MessagesPerMinute,
+		Burst:             req.Burst,
+		RecipientSpacing:  req.RecipientSpacing,
+	}
+
+	if err := h.sessionManager.UpdateRateLimitConfig(c.Request.Context(), sessionID, rateLimitConfig); err != nil {
+		logger.Log.Error().Err(err).Str("session_id", sessionID).Msg("Failed to set rate limit config")
+		c.JSON(http.StatusInternalServerError, dto.ErrorResponse{
+			Error:   "update_failed",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	h.respondRateLimit(c, sessionID)
+}
+
+// @Summary Obter rate limit
+// @Description Retorna o ritmo de envio efetivo da fila da sessão (configuração própria ou padrão do servidor)
+// @Tags Jobs
+// @Produce json
+// @Param id path string true "Session ID"
+// @Success 200 {object} dto.RateLimitConfigResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Security ApiKeyAuth
+// @Router /sessions/{id}/ratelimit/find [get]
+func (h *JobHandler) FindRateLimit(c *gin.Context) {
+	h.respondRateLimit(c, c.Param("id"))
+}
+
+func (h *JobHandler) respondRateLimit(c *gin.Context, sessionID string) {
+	session, err := h.sessionManager.GetSession(c.Request.Context(), sessionID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, dto.ErrorResponse{
+			Error:   "session_not_found",
+			Message: fmt.Sprintf("Session not found: %s", sessionID),
+		})
+		return
+	}
+
+	config := h.queue.RateLimitConfig(session)
+
+	c.JSON(http.StatusOK, dto.RateLimitConfigResponse{
+		SessionID:         sessionID,
+		MessagesPerMinute: config.MessagesPerMinute,
+		Burst:             config.Burst,
+		RecipientSpacing:  config.RecipientSpacing,
+		Custom:            session.RateLimitConfig != nil,
+	})
+}
@@ -0,0 +1,249 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin/binding"
+	"go.mau.fi/whatsmeow"
+
+	"zpwoot/internal/api/dto"
+	"zpwoot/internal/service"
+)
+
+// commandError carrega o status e o código de erro devolvidos ao solicitante
+type commandError struct {
+	status int
+	code   string
+	err    error
+}
+
+func (e *commandError) Error() string {
+	return e.err.Error()
+}
+
+func invalidCommand(err error) *commandError {
+	return &commandError{status: http.StatusBadRequest, code: "invalid_request", err: err}
+}
+
+// sendFunc executa o envio já validado
+type sendFunc func(ctx context.Context, client *whatsmeow.Client) (string, time.Time, error)
+
+// decodeFunc decodifica e valida o DTO, retornando o destinatário e o envio correspondente
+type decodeFunc func(data []byte) (string, sendFunc, error)
+
+// MessageDispatcher envia mensagens a partir do tipo (text, image, ...) e do corpo JSON do
+// endpoint correspondente. Usado pela API NATS e pela fila de saída (service.OutboundSender).
+type MessageDispatcher struct {
+	sessionManager *service.SessionManager
+	decoders       map[string]decodeFunc
+}
+
+func NewMessageDispatcher(sessionManager *service.SessionManager) *MessageDispatcher {
+	d := &MessageDispatcher{sessionManager: sessionManager}
+
+	d.decoders = map[string]decodeFunc{
+		"text":     d.decodeText,
+		"image":    d.decodeImage,
+		"audio":    d.decodeAudio,
+		"video":    d.decodeVideo,
+		"document": d.decodeDocument,
+		"sticker":  d.decodeSticker,
+		"location": d.decodeLocation,
+		"contact":  d.decodeContact,
+		"poll":     d.decodePoll,
+		"reaction": d.decodeReaction,
+	}
+
+	return d
+}
+
+// Types retorna os tipos de mensagem suportados
+func (d *MessageDispatcher) Types() []string {
+	return []string{"text", "image", "audio", "video", "document", "sticker", "location", "contact", "poll", "reaction"}
+}
+
+func (d *MessageDispatcher) decode(kind string, data []byte) (string, sendFunc, error) {
+	decoder, ok := d.decoders[kind]
+	if !ok {
+		return "", nil, &commandError{status: http.StatusBadRequest, code: "invalid_command", err: fmt.Errorf("unsupported message type: %s", kind)}
+	}
+	return decoder(data)
+}
+
+// Validate valida o corpo e retorna o destinatário
+func (d *MessageDispatcher) Validate(kind string, data []byte) (string, error) {
+	phone, _, err := d.decode(kind, data)
+	return phone, err
+}
+
+// Send valida e envia a mensagem
+func (d *MessageDispatcher) Send(ctx context.Context, client *whatsmeow.Client, kind string, data []byte) (string, time.Time, error) {
+	_, send, err := d.decode(kind, data)
+	if err != nil {
+		return "", time.Time{}, err
+	}
+	return send(ctx, client)
+}
+
+// Dispatch envia a mensagem e monta a mesma resposta dos endpoints HTTP
+func (d *MessageDispatcher) Dispatch(ctx context.Context, client *whatsmeow.Client, kind string, data []byte) (*dto.MessageResponse, error) {
+	phone, send, err := d.decode(kind, data)
+	if err != nil {
+		return nil, err
+	}
+
+	messageID, timestamp, err := send(ctx, client)
+	if err != nil {
+		return nil, err
+	}
+
+	return &dto.MessageResponse{
+		Success:   true,
+		MessageID: messageID,
+		Timestamp: timestamp.Unix(),
+		Phone:     phone,
+	}, nil
+}
+
+// decodeRequest decodifica o DTO e aplica as mesmas validações (binding) da API HTTP
+func decodeRequest(data []byte, req interface{}) error {
+	if err := json.Unmarshal(data, req); err != nil {
+		return invalidCommand(err)
+	}
+	if err := binding.Validator.ValidateStruct(req); err != nil {
+		return invalidCommand(err)
+	}
+	return nil
+}
+
+func (d *MessageDispatcher) decodeText(data []byte) (string, sendFunc, error) {
+	var req dto.SendTextRequest
+	if err := decodeRequest(data, &req); err != nil {
+		return "", nil, err
+	}
+
+	return req.Phone, func(ctx context.Context, client *whatsmeow.Client) (string, time.Time, error) {
+		return d.sessionManager.SendTextMessage(ctx, client, req.Phone, req.Message)
+	}, nil
+}
+
+func (d *MessageDispatcher) decodeImage(data []byte) (string, sendFunc, error) {
+	var req dto.SendImageRequest
+	if err := decodeRequest(data, &req); err != nil {
+		return "", nil, err
+	}
+
+	return req.Phone, func(ctx context.Context, client *whatsmeow.Client) (string, time.Time, error) {
+		return d.sessionManager.SendImageFromURL(ctx, client, req.Phone, req.Image, req.Caption)
+	}, nil
+}
+
+func (d *MessageDispatcher) decodeAudio(data []byte) (string, sendFunc, error) {
+	var req dto.SendAudioRequest
+	if err := decodeRequest(data, &req); err != nil {
+		return "", nil, err
+	}
+
+	return req.Phone, func(ctx context.Context, client *whatsmeow.Client) (string, time.Time, error) {
+		return d.sessionManager.SendAudioFromURL(ctx, client, req.Phone, req.Audio)
+	}, nil
+}
+
+func (d *MessageDispatcher) decodeVideo(data []byte) (string, sendFunc, error) {
+	var req dto.SendVideoRequest
+	if err := decodeRequest(data, &req); err != nil {
+		return "", nil, err
+	}
+
+	return req.Phone, func(ctx context.Context, client *whatsmeow.Client) (string, time.Time, error) {
+		return d.sessionManager.SendVideoFromURL(ctx, client, req.Phone, req.Video, req.Caption)
+	}, nil
+}
+
+func (d *MessageDispatcher) decodeDocument(data []byte) (string, sendFunc, error) {
+	var req dto.SendDocumentRequest
+	if err := decodeRequest(data, &req); err != nil {
+		return "", nil, err
+	}
+
+	return req.Phone, func(ctx context.Context, client *whatsmeow.Client) (string, time.Time, error) {
+		return d.sessionManager.SendDocumentFromURL(ctx, client, req.Phone, req.Document, req.FileName, req.Caption)
+	}, nil
+}
+
+func (d *MessageDispatcher) decodeSticker(data []byte) (string, sendFunc, error) {
+	var req dto.SendStickerRequest
+	if err := decodeRequest(data, &req); err != nil {
+		return "", nil, err
+	}
+	if req.Sticker == "" && req.StickerBase64 == "" {
+		return "", nil, invalidCommand(errors.New("sticker (URL) or stickerBase64 is required"))
+	}
+
+	return req.Phone, func(ctx context.Context, client *whatsmeow.Client) (string, time.Time, error) {
+		return d.sessionManager.SendSticker(ctx, client, req.Phone, req.Sticker, req.StickerBase64)
+	}, nil
+}
+
+func (d *MessageDispatcher) decodeLocation(data []byte) (string, sendFunc, error) {
+	var req dto.SendLocationRequest
+	if err := decodeRequest(data, &req); err != nil {
+		return "", nil, err
+	}
+
+	return req.Phone, func(ctx context.Context, client *whatsmeow.Client) (string, time.Time, error) {
+		return d.sessionManager.SendLocation(ctx, client, req.Phone, req.Latitude, req.Longitude, req.Name)
+	}, nil
+}
+
+func (d *MessageDispatcher) decodeContact(data []byte) (string, sendFunc, error) {
+	var req dto.SendContactRequest
+	if err := decodeRequest(data, &req); err != nil {
+		return "", nil, err
+	}
+
+	return req.Phone, func(ctx context.Context, client *whatsmeow.Client) (string, time.Time, error) {
+		if len(req.Contacts) == 1 {
+			contact := req.Contacts[0]
+			return d.sessionManager.SendContact(ctx, client, req.Phone, contact.Name, contact.Phone, contact.Vcard)
+		}
+
+		contacts := make([]service.ContactData, len(req.Contacts))
+		for i, c := range req.Contacts {
+			contacts[i] = service.ContactData{Name: c.Name, Phone: c.Phone, Vcard: c.Vcard}
+		}
+		return d.sessionManager.SendContactsList(ctx, client, req.Phone, contacts)
+	}, nil
+}
+
+func (d *MessageDispatcher) decodePoll(data []byte) (string, sendFunc, error) {
+	var req dto.SendPollRequest
+	if err := decodeRequest(data, &req); err != nil {
+		return "", nil, err
+	}
+
+	selectableCount := uint32(req.SelectableCount)
+	if selectableCount == 0 {
+		selectableCount = 1
+	}
+
+	return req.Phone, func(ctx context.Context, client *whatsmeow.Client) (string, time.Time, error) {
+		return d.sessionManager.SendPoll(ctx, client, req.Phone, req.Question, req.Options, selectableCount)
+	}, nil
+}
+
+func (d *MessageDispatcher) decodeReaction(data []byte) (string, sendFunc, error) {
+	var req dto.SendReactionRequest
+	if err := decodeRequest(data, &req); err != nil {
+		return "", nil, err
+	}
+
+	return req.Phone, func(ctx context.Context, client *whatsmeow.Client) (string, time.Time, error) {
+		return d.sessionManager.SendReaction(ctx, client, req.Phone, req.MessageID, req.Emoji)
+	}, nil
+}
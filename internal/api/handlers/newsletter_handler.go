@@ -0,0 +1,410 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"go.mau.fi/whatsmeow/types"
+
+	"zpwoot/internal/api/dto"
+	"zpwoot/internal/service"
+	"zpwoot/pkg/logger"
+)
+
+type NewsletterHandler struct {
+	sessionManager *service.SessionManager
+}
+
+func NewNewsletterHandler(sessionManager *service.SessionManager) *NewsletterHandler {
+	return &NewsletterHandler{
+		sessionManager: sessionManager,
+	}
+}
+
+// @Summary Listar canais
+// @Description Retorna os canais (newsletters) que a sessão segue
+// @Tags Newsletter
+// @Produce json
+// @Param id path string true "Session ID"
+// @Success 200 {object} dto.NewsletterListResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Security ApiKeyAuth
+// @Router /sessions/{id}/newsletter/list [get]
+func (h *NewsletterHandler) ListNewsletters(c *gin.Context) {
+	sessionID := c.Param("id")
+
+	client, err := h.sessionManager.GetClient(sessionID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, dto.ErrorResponse{Error: "session_not_found", Message: err.Error()})
+		return
+	}
+
+	ctx := context.Background()
+	newsletters, err := h.sessionManager.ListNewsletters(ctx, client)
+	if err != nil {
+		logger.Log.Error().Err(err).Str("session_id", sessionID).Msg("Failed to list newsletters")
+		c.JSON(http.StatusInternalServerError, dto.ErrorResponse{Error: "list_failed", Message: err.Error()})
+		return
+	}
+
+	responses := make([]dto.NewsletterResponse, 0, len(newsletters))
+	for _, newsletter := range newsletters {
+		if newsletter == nil {
+			continue
+		}
+		responses = append(responses, toNewsletterResponse(newsletter))
+	}
+
+	c.JSON(http.StatusOK, dto.NewsletterListResponse{
+		Newsletters: responses,
+		Total:       len(responses),
+	})
+}
+
+// @Summary Obter informações do canal
+// @Description Retorna informações de um canal pelo JID ou pelo código/link de convite
+// @Tags Newsletter
+// @Produce json
+// @Param id path string true "Session ID"
+// @Param jid query string false "JID do canal (xxx@newsletter)"
+// @Param invite query string false "Código ou link de convite (https://whatsapp.com/channel/...)"
+// @Success 200 {object} dto.NewsletterResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Security ApiKeyAuth
+// @Router /sessions/{id}/newsletter/info [get]
+func (h *NewsletterHandler) GetNewsletterInfo(c *gin.Context) {
+	sessionID := c.Param("id")
+	jid := c.Query("jid")
+	invite := c.Query("invite")
+
+	if jid == "" && invite == "" {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{Error: "invalid_request", Message: "jid or invite is required"})
+		return
+	}
+
+	client, err := h.sessionManager.GetClient(sessionID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, dto.ErrorResponse{Error: "session_not_found", Message: err.Error()})
+		return
+	}
+
+	ctx := context.Background()
+	var info *types.NewsletterMetadata
+	if jid != "" {
+		info, err = h.sessionManager.GetNewsletterInfo(ctx, client, jid)
+	} else {
+		info, err = h.sessionManager.GetNewsletterInfoWithInvite(ctx, client, invite)
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, dto.ErrorResponse{Error: "info_failed", Message: err.Error()})
+		return
+	}
+
+	if info == nil {
+		c.JSON(http.StatusNotFound, dto.ErrorResponse{Error: "newsletter_not_found", Message: "Newsletter not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, toNewsletterResponse(info))
+}
+
+// @Summary Seguir canal
+// @Tags Newsletter
+// @Accept json
+// @Produce json
+// @Param id path string true "Session ID"
+// @Param request body dto.NewsletterJIDRequest true "Canal"
+// @Success 200 {object} dto.SuccessResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Security ApiKeyAuth
+// @Router /sessions/{id}/newsletter/follow [post]
+func (h *NewsletterHandler) FollowNewsletter(c *gin.Context) {
+	sessionID := c.Param("id")
+	var req dto.NewsletterJIDRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{Error: "invalid_request", Message: err.Error()})
+		return
+	}
+
+	client, err := h.sessionManager.GetClient(sessionID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, dto.ErrorResponse{Error: "session_not_found", Message: err.Error()})
+		return
+	}
+
+	ctx := context.Background()
+	if err := h.sessionManager.FollowNewsletter(ctx, client, req.JID); err != nil {
+		c.JSON(http.StatusInternalServerError, dto.ErrorResponse{Error: "follow_failed", Message: err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.SuccessResponse{Success: true, Message: "Newsletter followed"})
+}
+
+// @Summary Deixar de seguir canal
+// @Tags Newsletter
+// @Accept json
+// @Produce json
+// @Param id path string true "Session ID"
+// @Param request body dto.NewsletterJIDRequest true "Canal"
+// @Success 200 {object} dto.SuccessResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Security ApiKeyAuth
+// @Router /sessions/{id}/newsletter/unfollow [post]
+func (h *NewsletterHandler) UnfollowNewsletter(c *gin.Context) {
+	sessionID := c.Param("id")
+	var req dto.NewsletterJIDRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{Error: "invalid_request", Message: err.Error()})
+		return
+	}
+
+	client, err := h.sessionManager.GetClient(sessionID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, dto.ErrorResponse{Error: "session_not_found", Message: err.Error()})
+		return
+	}
+
+	ctx := context.Background()
+	if err := h.sessionManager.UnfollowNewsletter(ctx, client, req.JID); err != nil {
+		c.JSON(http.StatusInternalServerError, dto.ErrorResponse{Error: "unfollow_failed", Message: err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.SuccessResponse{Success: true, Message: "Newsletter unfollowed"})
+}
+
+// @Summary Silenciar canal
+// @Description Silencia (mute=true) ou reativa (mute=false) notificações de um canal
+// @Tags Newsletter
+// @Accept json
+// @Produce json
+// @Param id path string true "Session ID"
+// @Param request body dto.MuteNewsletterRequest true "Canal e estado"
+// @Success 200 {object} dto.SuccessResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Security ApiKeyAuth
+// @Router /sessions/{id}/newsletter/mute [post]
+func (h *NewsletterHandler) MuteNewsletter(c *gin.Context) {
+	sessionID := c.Param("id")
+	var req dto.MuteNewsletterRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{Error: "invalid_request", Message: err.Error()})
+		return
+	}
+
+	client, err := h.sessionManager.GetClient(sessionID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, dto.ErrorResponse{Error: "session_not_found", Message: err.Error()})
+		return
+	}
+
+	ctx := context.Background()
+	if err := h.sessionManager.MuteNewsletter(ctx, client, req.JID, req.Mute); err != nil {
+		c.JSON(http.StatusInternalServerError, dto.ErrorResponse{Error: "mute_failed", Message: err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.SuccessResponse{Success: true, Message: "Newsletter mute updated"})
+}
+
+// @Summary Criar canal
+// @Tags Newsletter
+// @Accept json
+// @Produce json
+// @Param id path string true "Session ID"
+// @Param request body dto.CreateNewsletterRequest true "Dados do canal"
+// @Success 201 {object} dto.NewsletterResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Security ApiKeyAuth
+// @Router /sessions/{id}/newsletter/create [post]
+func (h *NewsletterHandler) CreateNewsletter(c *gin.Context) {
+	sessionID := c.Param("id")
+	var req dto.CreateNewsletterRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{Error: "invalid_request", Message: err.Error()})
+		return
+	}
+
+	client, err := h.sessionManager.GetClient(sessionID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, dto.ErrorResponse{Error: "session_not_found", Message: err.Error()})
+		return
+	}
+
+	ctx := context.Background()
+	info, err := h.sessionManager.CreateNewsletter(ctx, client, req.Name, req.Description, req.Picture)
+	if err != nil {
+		logger.Log.Error().Err(err).Str("session_id", sessionID).Msg("Failed to create newsletter")
+		c.JSON(http.StatusInternalServerError, dto.ErrorResponse{Error: "create_failed", Message: err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, toNewsletterResponse(info))
+}
+
+// @Summary Publicar texto no canal
+// @Tags Newsletter
+// @Accept json
+// @Produce json
+// @Param id path string true "Session ID"
+// @Param request body dto.SendNewsletterTextRequest true "Mensagem"
+// @Success 200 {object} dto.MessageResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Security ApiKeyAuth
+// @Router /sessions/{id}/newsletter/send/text [post]
+func (h *NewsletterHandler) SendText(c *gin.Context) {
+	sessionID := c.Param("id")
+	var req dto.SendNewsletterTextRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{Error: "invalid_request", Message: err.Error()})
+		return
+	}
+
+	client, err := h.sessionManager.GetClient(sessionID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, dto.ErrorResponse{Error: "session_not_found", Message: err.Error()})
+		return
+	}
+
+	ctx := context.Background()
+	messageID, timestamp, err := h.sessionManager.SendNewsletterText(ctx, client, req.JID, req.Message)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, dto.ErrorResponse{Error: "send_failed", Message: err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.MessageResponse{Success: true, MessageID: messageID, Timestamp: timestamp.Unix(), Phone: req.JID})
+}
+
+// @Summary Publicar mídia no canal
+// @Description Publica imagem, vídeo ou documento (detectado pelo MIME type) em um canal
+// @Tags Newsletter
+// @Accept json
+// @Produce json
+// @Param id path string true "Session ID"
+// @Param request body dto.SendNewsletterMediaRequest true "Mídia"
+// @Success 200 {object} dto.MessageResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Security ApiKeyAuth
+// @Router /sessions/{id}/newsletter/send/media [post]
+func (h *NewsletterHandler) SendMedia(c *gin.Context) {
+	sessionID := c.Param("id")
+	var req dto.SendNewsletterMediaRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{Error: "invalid_request", Message: err.Error()})
+		return
+	}
+
+	client, err := h.sessionManager.GetClient(sessionID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, dto.ErrorResponse{Error: "session_not_found", Message: err.Error()})
+		return
+	}
+
+	ctx := context.Background()
+	messageID, timestamp, err := h.sessionManager.SendNewsletterMedia(ctx, client, req.JID, req.Media, req.Caption, req.FileName)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, dto.ErrorResponse{Error: "send_failed", Message: err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.MessageResponse{Success: true, MessageID: messageID, Timestamp: timestamp.Unix(), Phone: req.JID})
+}
+
+// @Summary Mensagens recentes do canal
+// @Description Retorna as mensagens mais recentes de um canal com contagem de visualizações e reações
+// @Tags Newsletter
+// @Produce json
+// @Param id path string true "Session ID"
+// @Param jid query string true "JID do canal (xxx@newsletter)"
+// @Param count query int false "Quantidade de mensagens (padrão 20)"
+// @Param before query int false "Server ID para paginação"
+// @Success 200 {object} dto.NewsletterMessagesResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Security ApiKeyAuth
+// @Router /sessions/{id}/newsletter/messages [get]
+func (h *NewsletterHandler) GetMessages(c *gin.Context) {
+	sessionID := c.Param("id")
+	jid := c.Query("jid")
+	if jid == "" {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{Error: "invalid_request", Message: "jid is required"})
+		return
+	}
+
+	count, _ := strconv.Atoi(c.DefaultQuery("count", "20"))
+	before, _ := strconv.Atoi(c.DefaultQuery("before", "0"))
+
+	client, err := h.sessionManager.GetClient(sessionID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, dto.ErrorResponse{Error: "session_not_found", Message: err.Error()})
+		return
+	}
+
+	ctx := context.Background()
+	messages, err := h.sessionManager.GetNewsletterMessages(ctx, client, jid, count, before)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, dto.ErrorResponse{Error: "fetch_failed", Message: err.Error()})
+		return
+	}
+
+	responses := make([]dto.NewsletterMessageResponse, 0, len(messages))
+	for _, msg := range messages {
+		if msg == nil {
+			continue
+		}
+		reactions := msg.ReactionCounts
+		if reactions == nil {
+			reactions = map[string]int{}
+		}
+		responses = append(responses, dto.NewsletterMessageResponse{
+			ServerID:       msg.MessageServerID,
+			MessageID:      msg.MessageID,
+			Type:           msg.Type,
+			Body:           service.ExtractMessageText(msg.Message),
+			Timestamp:      msg.Timestamp.Unix(),
+			ViewsCount:     msg.ViewsCount,
+			ReactionCounts: reactions,
+		})
+	}
+
+	c.JSON(http.StatusOK, dto.NewsletterMessagesResponse{
+		JID:      jid,
+		Messages: responses,
+		Total:    len(responses),
+	})
+}
+
+func toNewsletterResponse(info *types.NewsletterMetadata) dto.NewsletterResponse {
+	response := dto.NewsletterResponse{
+		JID:             info.ID.String(),
+		Name:            info.ThreadMeta.Name.Text,
+		Description:     info.ThreadMeta.Description.Text,
+		InviteCode:      info.ThreadMeta.InviteCode,
+		SubscriberCount: info.ThreadMeta.SubscriberCount,
+		Verification:    string(info.ThreadMeta.VerificationState),
+		State:           string(info.State.Type),
+	}
+
+	if info.ViewerMeta != nil {
+		response.Role = string(info.ViewerMeta.Role)
+		response.Mute = string(info.ViewerMeta.Mute)
+	}
+
+	if info.ThreadMeta.Picture != nil {
+		response.PictureURL = info.ThreadMeta.Picture.URL
+	}
+
+	if !info.ThreadMeta.CreationTime.IsZero() {
+		createdAt := info.ThreadMeta.CreationTime.Time
+		response.CreatedAt = &createdAt
+	}
+
+	return response
+}
@@ -0,0 +1,175 @@
+package handlers
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/coder/websocket"
+	"github.com/coder/websocket/wsjson"
+	"github.com/gin-gonic/gin"
+
+	"zpwoot/internal/api/dto"
+	"zpwoot/internal/service"
+	"zpwoot/pkg/logger"
+)
+
+// qrCountdown calcula o countdown do código atual a partir do último evento recebido
+type qrCountdown struct {
+	expiresAt *time.Time
+}
+
+func (q *qrCountdown) update(evt service.QREvent) {
+	if evt.ExpiresAt != nil {
+		q.expiresAt = evt.ExpiresAt
+	}
+}
+
+func (q *qrCountdown) event() (dto.QRCountdownEvent, bool) {
+	if q.expiresAt == nil {
+		return dto.QRCountdownEvent{}, false
+	}
+
+	remaining := int(time.Until(*q.expiresAt).Seconds())
+	if remaining < 0 {
+		remaining = 0
+	}
+
+	return dto.QRCountdownEvent{Event: "countdown", ExpiresIn: remaining}, true
+}
+
+// checkPairableSession valida se a sessão existe e ainda precisa de pareamento
+func (h *SessionHandler) checkPairableSession(c *gin.Context, sessionID string) bool {
+	session, err := h.sessionManager.GetSession(c.Request.Context(), sessionID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, dto.ErrorResponse{
+			Error:   "session_not_found",
+			Message: err.Error(),
+		})
+		return false
+	}
+
+	if !session.NeedsPairing() {
+		c.JSON(http.StatusConflict, dto.ErrorResponse{
+			Error:   "already_paired",
+			Message: "Session is already paired",
+		})
+		return false
+	}
+
+	return true
+}
+
+// @Summary Stream de QR Code (SSE)
+// @Description Envia via Server-Sent Events cada novo QR code gerado (evento "code"), um "countdown" por segundo e o resultado final ("success", "timeout" ou "error"). Conecte a sessão (/connect) para iniciar o pareamento. Navegadores podem enviar a API key via query ?apikey=
+// @Tags Sessions
+// @Produce text/event-stream
+// @Param id path string true "Session ID"
+// @Success 200 {object} service.QREvent
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 409 {object} dto.ErrorResponse
+// @Security ApiKeyAuth
+// @Router /sessions/{id}/qr/stream [get]
+func (h *SessionHandler) StreamQRCode(c *gin.Context) {
+	sessionID := c.Param("id")
+
+	if !h.checkPairableSession(c, sessionID) {
+		return
+	}
+
+	events, unsubscribe := h.sessionManager.SubscribeQR(sessionID)
+	defer unsubscribe()
+
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Header("X-Accel-Buffering", "no")
+
+	ticker := time.NewTicker(time.Second)
+	defer ticker.Stop()
+
+	countdown := &qrCountdown{}
+
+	c.Stream(func(w io.Writer) bool {
+		select {
+		case <-c.Request.Context().Done():
+			return false
+		case evt := <-events:
+			countdown.update(evt)
+			c.SSEvent(evt.Event, evt)
+			return !evt.IsFinal()
+		case <-ticker.C:
+			if tick, ok := countdown.event(); ok {
+				c.SSEvent(tick.Event, tick)
+			}
+			return true
+		}
+	})
+}
+
+// @Summary Stream de QR Code (WebSocket)
+// @Description Mesmo conteúdo do stream SSE, enviado como mensagens JSON em uma conexão WebSocket. A conexão é fechada após o evento final
+// @Tags Sessions
+// @Param id path string true "Session ID"
+// @Success 101 {object} service.QREvent
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 409 {object} dto.ErrorResponse
+// @Security ApiKeyAuth
+// @Router /sessions/{id}/qr/ws [get]
+func (h *SessionHandler) StreamQRCodeWS(c *gin.Context) {
+	sessionID := c.Param("id")
+
+	if !h.checkPairableSession(c, sessionID) {
+		return
+	}
+
+	conn, err := websocket.Accept(c.Writer, c.Request, &websocket.AcceptOptions{
+		OriginPatterns: h.wsOriginPatterns, // Navegadores de outras origens só com WS_ORIGIN_PATTERNS
+	})
+	if err != nil {
+		logger.Log.Warn().Err(err).Str("session_id", sessionID).Msg("Failed to accept QR websocket")
+		return
+	}
+	defer conn.CloseNow()
+
+	events, unsubscribe := h.sessionManager.SubscribeQR(sessionID)
+	defer unsubscribe()
+
+	// Mensagens do cliente são ignoradas; o contexto é cancelado quando ele fecha a conexão
+	ctx := conn.CloseRead(c.Request.Context())
+
+	ticker := time.NewTicker(time.Second)
+	defer ticker.Stop()
+
+	countdown := &qrCountdown{}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case evt := <-events:
+			countdown.update(evt)
+			if err := writeWSJSON(ctx, conn, evt); err != nil {
+				return
+			}
+			if evt.IsFinal() {
+				conn.Close(websocket.StatusNormalClosure, evt.Event)
+				return
+			}
+		case <-ticker.C:
+			if tick, ok := countdown.event(); ok {
+				if err := writeWSJSON(ctx, conn, tick); err != nil {
+					return
+				}
+			}
+		}
+	}
+}
+
+// writeWSJSON escreve uma mensagem JSON com timeout para não travar em clientes lentos
+func writeWSJSON(ctx context.Context, conn *websocket.Conn, v interface{}) error {
+	writeCtx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+	return wsjson.Write(writeCtx, conn, v)
+}
@@ -0,0 +1,240 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"zpwoot/internal/api/dto"
+	"zpwoot/internal/model"
+	"zpwoot/internal/service"
+	"zpwoot/pkg/logger"
+)
+
+type ScheduleHandler struct {
+	sessionManager  *service.SessionManager
+	scheduler       *service.MessageScheduler
+	templateService *service.TemplateService
+}
+
+func NewScheduleHandler(sessionManager *service.SessionManager, scheduler *service.MessageScheduler, templateService *service.TemplateService) *ScheduleHandler {
+	return &ScheduleHandler{
+		sessionManager:  sessionManager,
+		scheduler:       scheduler,
+		templateService: templateService,
+	}
+}
+
+func scheduleResponse(schedule *model.ScheduledMessage) dto.ScheduleResponse {
+	return dto.ScheduleResponse{
+		ScheduleID: schedule.ID,
+		SessionID:  schedule.SessionID,
+		Type:       schedule.Type,
+		Phone:      schedule.Phone,
+		SendAt:     schedule.SendAt,
+		Timezone:   schedule.Timezone,
+		Status:     string(schedule.Status),
+		JobID:      schedule.JobID,
+		Error:      schedule.Error,
+		CreatedAt:  schedule.CreatedAt,
+		UpdatedAt:  schedule.UpdatedAt,
+		ExecutedAt: schedule.ExecutedAt,
+	}
+}
+
+// @Summary Agendar mensagem
+// @Description Agenda o envio para sendAt. O payload é o mesmo corpo do endpoint de envio do tipo informado (aceita templateId + variables). sendAt aceita RFC3339 ou horário local (YYYY-MM-DDTHH:MM:SS) interpretado em timezone. No horário a mensagem entra na fila de envio da sessão (jobId)
+// @Tags Schedules
+// @Accept json
+// @Produce json
+// @Param id path string true "Session ID"
+// @Param request body dto.ScheduleMessageRequest true "Tipo, corpo e horário da mensagem"
+// @Success 201 {object} dto.ScheduleResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Security ApiKeyAuth
+// @Router /sessions/{id}/message/schedule [post]
+func (h *ScheduleHandler) ScheduleMessage(c *gin.Context) {
+	sessionID := c.Param("id")
+
+	var req dto.ScheduleMessageRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+			Error:   "invalid_request",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	if _, err := h.sessionManager.GetSession(c.Request.Context(), sessionID); err != nil {
+		c.JSON(http.StatusNotFound, dto.ErrorResponse{
+			Error:   "session_not_found",
+			Message: fmt.Sprintf("Session not found: %s", sessionID),
+		})
+		return
+	}
+
+	// O template é aplicado no agendamento: variáveis faltando são reportadas agora
+	payload, err := expandTemplate(c.Request.Context(), h.templateService, sessionID, req.Type, req.Payload)
+	var schedule *model.ScheduledMessage
+	if err == nil {
+		schedule, err = h.scheduler.Schedule(c.Request.Context(), sessionID, req.Type, payload, req.SendAt, req.Timezone)
+	}
+	if err != nil {
+		var cmdErr *commandError
+		if errors.As(err, &cmdErr) {
+			c.JSON(cmdErr.status, dto.ErrorResponse{
+				Error:   cmdErr.code,
+				Message: cmdErr.Error(),
+			})
+			return
+		}
+		if errors.Is(err, service.ErrInvalidSendAt) {
+			c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+				Error:   "invalid_send_at",
+				Message: err.Error(),
+			})
+			return
+		}
+
+		logger.Log.Error().Err(err).Str("session_id", sessionID).Msg("Failed to schedule message")
+		c.JSON(http.StatusInternalServerError, dto.ErrorResponse{
+			Error:   "schedule_failed",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, scheduleResponse(schedule))
+}
+
+// @Summary Listar agendamentos
+// @Description Lista os agendamentos da sessão ordenados pelo horário de envio
+// @Tags Schedules
+// @Produce json
+// @Param id path string true "Session ID"
+// @Param status query string false "Filtrar por status" Enums(scheduled, processing, queued, failed, canceled)
+// @Success 200 {object} dto.ScheduleListResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Security ApiKeyAuth
+// @Router /sessions/{id}/message/schedule [get]
+func (h *ScheduleHandler) ListSchedules(c *gin.Context) {
+	sessionID := c.Param("id")
+	status := model.ScheduleStatus(c.Query("status"))
+
+	schedules, err := h.scheduler.List(c.Request.Context(), sessionID, status)
+	if err != nil {
+		logger.Log.Error().Err(err).Str("session_id", sessionID).Msg("Failed to list schedules")
+		c.JSON(http.StatusInternalServerError, dto.ErrorResponse{
+			Error:   "list_failed",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	response := dto.ScheduleListResponse{
+		Schedules: make([]dto.ScheduleResponse, 0, len(schedules)),
+		Total:     len(schedules),
+	}
+	for _, schedule := range schedules {
+		response.Schedules = append(response.Schedules, scheduleResponse(schedule))
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// @Summary Consultar agendamento
+// @Description Retorna um agendamento da sessão
+// @Tags Schedules
+// @Produce json
+// @Param id path string true "Session ID"
+// @Param scheduleId path string true "Schedule ID"
+// @Success 200 {object} dto.ScheduleResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Security ApiKeyAuth
+// @Router /sessions/{id}/message/schedule/{scheduleId} [get]
+func (h *ScheduleHandler) GetSchedule(c *gin.Context) {
+	scheduleID := c.Param("scheduleId")
+
+	schedule, err := h.scheduler.Get(c.Request.Context(), c.Param("id"), scheduleID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, dto.ErrorResponse{
+			Error:   "schedule_not_found",
+			Message: fmt.Sprintf("Schedule not found: %s", scheduleID),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, scheduleResponse(schedule))
+}
+
+// @Summary Reagendar mensagem
+// @Description Altera o horário de um agendamento que ainda não foi executado (status scheduled)
+// @Tags Schedules
+// @Accept json
+// @Produce json
+// @Param id path string true "Session ID"
+// @Param scheduleId path string true "Schedule ID"
+// @Param request body dto.RescheduleMessageRequest true "Novo horário"
+// @Success 200 {object} dto.ScheduleResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Security ApiKeyAuth
+// @Router /sessions/{id}/message/schedule/{scheduleId} [put]
+func (h *ScheduleHandler) RescheduleMessage(c *gin.Context) {
+	scheduleID := c.Param("scheduleId")
+
+	var req dto.RescheduleMessageRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+			Error:   "invalid_request",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	schedule, err := h.scheduler.Reschedule(c.Request.Context(), c.Param("id"), scheduleID, req.SendAt, req.Timezone)
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidSendAt) {
+			c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+				Error:   "invalid_send_at",
+				Message: err.Error(),
+			})
+			return
+		}
+
+		c.JSON(http.StatusNotFound, dto.ErrorResponse{
+			Error:   "schedule_not_found",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, scheduleResponse(schedule))
+}
+
+// @Summary Cancelar agendamento
+// @Description Cancela um agendamento que ainda não foi executado (status scheduled)
+// @Tags Schedules
+// @Produce json
+// @Param id path string true "Session ID"
+// @Param scheduleId path string true "Schedule ID"
+// @Success 200 {object} dto.ScheduleResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Security ApiKeyAuth
+// @Router /sessions/{id}/message/schedule/{scheduleId} [delete]
+func (h *ScheduleHandler) CancelSchedule(c *gin.Context) {
+	schedule, err := h.scheduler.Cancel(c.Request.Context(), c.Param("id"), c.Param("scheduleId"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, dto.ErrorResponse{
+			Error:   "schedule_not_found",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, scheduleResponse(schedule))
+}
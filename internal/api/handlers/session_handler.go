@@ -2,6 +2,7 @@ package handlers
 
 import (
 	"encoding/base64"
+	"errors"
 	"fmt"
 	"net/http"
 
@@ -9,21 +10,62 @@ import (
 	"github.com/skip2/go-qrcode"
 
 	"zpwoot/internal/api/dto"
+	"zpwoot/internal/api/middleware"
 	"zpwoot/internal/constants"
 	"zpwoot/internal/model"
+	"zpwoot/internal/repository"
 	"zpwoot/internal/service"
 	"zpwoot/pkg/logger"
 )
 
 type SessionHandler struct {
-	sessionManager *service.SessionManager
-	pairingService *service.PairingService
+	sessionManager   *service.SessionManager
+	pairingService   *service.PairingService
+	wsOriginPatterns []string // Origens aceitas no WebSocket de QR code (além da própria)
 }
 
-func NewSessionHandler(sessionManager *service.SessionManager, pairingService *service.PairingService) *SessionHandler {
+func NewSessionHandler(sessionManager *service.SessionManager, pairingService *service.PairingService, wsOriginPatterns []string) *SessionHandler {
 	return &SessionHandler{
-		sessionManager: sessionManager,
-		pairingService: pairingService,
+		sessionManager:   sessionManager,
+		pairingService:   pairingService,
+		wsOriginPatterns: wsOriginPatterns,
+	}
+}
+
+// AuthorizeSession garante que a sessão da rota (/:id) pertence ao tenant autenticado e está
+// entre as sessões permitidas pelo token antes do handler; com a API key global não há
+// restrição. Sessões fora do escopo respondem 404, e tokens restritos a sessões não criam sessões.
+func (h *SessionHandler) AuthorizeSession() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx := c.Request.Context()
+		restricted := repository.SessionIDsFromContext(ctx) != nil
+		if !restricted && repository.TenantFromContext(ctx) == "" {
+			c.Next()
+			return
+		}
+
+		sessionID := c.Param("id")
+		if sessionID == "" {
+			if restricted && c.Request.Method != http.MethodGet {
+				c.AbortWithStatusJSON(http.StatusForbidden, dto.ErrorResponse{
+					Error:   "forbidden",
+					Message: "Token is restricted to specific sessions",
+				})
+				return
+			}
+			c.Next()
+			return
+		}
+
+		if _, err := h.sessionManager.GetSession(ctx, sessionID); err != nil {
+			c.AbortWithStatusJSON(http.StatusNotFound, dto.ErrorResponse{
+				Error:   "session_not_found",
+				Message: fmt.Sprintf("Session not found: %s", sessionID),
+			})
+			return
+		}
+
+		c.Next()
 	}
 }
 
@@ -35,6 +77,7 @@ func NewSessionHandler(sessionManager *service.SessionManager, pairingService *s
 // @Param request body dto.CreateSessionRequest true "Dados da sessão"
 // @Success 201 {object} dto.SessionResponse
 // @Failure 400 {object} dto.ErrorResponse
+// @Failure 429 {object} dto.ErrorResponse
 // @Failure 500 {object} dto.ErrorResponse
 // @Security ApiKeyAuth
 // @Router /sessions/create [post]
@@ -71,17 +114,43 @@ func (h *SessionHandler) CreateSession(c *gin.Context) {
 		}
 	}
 
+	var historySyncConfig *model.HistorySyncConfig
+	if req.HistorySync != nil {
+		historySyncConfig = &model.HistorySyncConfig{
+			Mode: model.HistorySyncMode(req.HistorySync.Mode),
+			Days: req.HistorySync.Days,
+		}
+	}
+
+	var callConfig *model.CallConfig
+	if req.Call != nil {
+		callConfig = &model.CallConfig{
+			Policy:  model.CallPolicy(req.Call.Policy),
+			Message: req.Call.Message,
+		}
+	}
+
 	// Criar sessão
 	session := &model.Session{
-		Name:          req.Name,
-		Status:        string(model.SessionStatusDisconnected),
-		Connected:     false,
-		ProxyConfig:   proxyConfig,
-		WebhookConfig: webhookConfig,
-		APIKey:        req.APIKey,
+		Name:              req.Name,
+		Status:            string(model.SessionStatusDisconnected),
+		Connected:         false,
+		ProxyConfig:       proxyConfig,
+		WebhookConfig:     webhookConfig,
+		HistorySyncConfig: historySyncConfig,
+		CallConfig:        callConfig,
+		APIKey:            (*model.SecretString)(req.APIKey),
 	}
 
 	if err := h.sessionManager.CreateSessionWithConfig(c.Request.Context(), session); err != nil {
+		if errors.Is(err, service.ErrMaxSessionsReached) {
+			c.JSON(http.StatusTooManyRequests, dto.ErrorResponse{
+				Error:   "max_sessions_reached",
+				Message: err.Error(),
+			})
+			return
+		}
+
 		logger.Log.Error().Err(err).Msg("Failed to create session")
 		c.JSON(http.StatusInternalServerError, dto.ErrorResponse{
 			Error:   "create_failed",
@@ -90,6 +159,9 @@ func (h *SessionHandler) CreateSession(c *gin.Context) {
 		return
 	}
 
+	// Sessão criada no audit log (a rota não tem /:id)
+	c.Set(middleware.ContextSessionID, session.ID)
+
 	c.JSON(http.StatusCreated, toSessionResponse(session))
 }
 
@@ -183,6 +255,7 @@ func (h *SessionHandler) DeleteSession(c *gin.Context) {
 // @Param id path string true "Session ID"
 // @Success 200 {object} dto.SuccessResponse
 // @Failure 400 {object} dto.ErrorResponse
+// @Failure 429 {object} dto.ErrorResponse
 // @Failure 500 {object} dto.ErrorResponse
 // @Security ApiKeyAuth
 // @Router /sessions/{id}/connect [post]
@@ -190,6 +263,14 @@ func (h *SessionHandler) ConnectSession(c *gin.Context) {
 	sessionID := c.Param("id")
 
 	if err := h.sessionManager.ConnectSession(c.Request.Context(), sessionID); err != nil {
+		if errors.Is(err, service.ErrMaxSessionsReached) {
+			c.JSON(http.StatusTooManyRequests, dto.ErrorResponse{
+				Error:   "max_sessions_reached",
+				Message: err.Error(),
+			})
+			return
+		}
+
 		logger.Log.Error().Err(err).Str("session_id", sessionID).Msg("Failed to connect session")
 		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
 			Error:   "connect_failed",
@@ -299,6 +380,230 @@ func (h *SessionHandler) GetSessionStatus(c *gin.Context) {
 	c.JSON(http.StatusOK, status)
 }
 
+// @Summary Configurar política de chamadas
+// @Description Define como a sessão trata chamadas recebidas: accept (deixa tocar), reject (rejeita) ou reject_message (rejeita e responde com texto)
+// @Tags Calls
+// @Accept json
+// @Produce json
+// @Param id path string true "Session ID"
+// @Param request body dto.CallConfig true "Política de chamadas"
+// @Success 200 {object} dto.CallConfigResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Security ApiKeyAuth
+// @Router /sessions/{id}/call/set [post]
+func (h *SessionHandler) SetCallConfig(c *gin.Context) {
+	sessionID := c.Param("id")
+
+	var req dto.CallConfig
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+			Error:   "invalid_request",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	callConfig := &model.CallConfig{
+		Policy:  model.CallPolicy(req.Policy),
+		Message: req.Message,
+	}
+
+	if err := h.sessionManager.UpdateCallConfig(c.Request.Context(), sessionID, callConfig); err != nil {
+		logger.Log.Error().Err(err).Str("session_id", sessionID).Msg("Failed to set call config")
+		c.JSON(http.StatusInternalServerError, dto.ErrorResponse{
+			Error:   "update_failed",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	session, err := h.sessionManager.GetSession(c.Request.Context(), sessionID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, dto.ErrorResponse{
+			Error:   "fetch_failed",
+			Message: "Call config saved but failed to fetch updated session",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.CallConfigResponse{
+		SessionID: sessionID,
+		Policy:    string(callConfig.Policy),
+		Message:   callConfig.Message,
+		UpdatedAt: session.UpdatedAt,
+	})
+}
+
+// @Summary Obter política de chamadas
+// @Description Retorna a política atual de chamadas recebidas da sessão
+// @Tags Calls
+// @Produce json
+// @Param id path string true "Session ID"
+// @Success 200 {object} dto.CallConfigResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Security ApiKeyAuth
+// @Router /sessions/{id}/call/find [get]
+func (h *SessionHandler) FindCallConfig(c *gin.Context) {
+	sessionID := c.Param("id")
+
+	session, err := h.sessionManager.GetSession(c.Request.Context(), sessionID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, dto.ErrorResponse{
+			Error:   "session_not_found",
+			Message: fmt.Sprintf("Session not found: %s", sessionID),
+		})
+		return
+	}
+
+	response := dto.CallConfigResponse{
+		SessionID: sessionID,
+		Policy:    string(session.CallPolicy()),
+		UpdatedAt: session.UpdatedAt,
+	}
+	if session.CallConfig != nil {
+		response.Message = session.CallConfig.Message
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+func transcriptionConfigResponse(session *model.Session) dto.TranscriptionConfigResponse {
+	response := dto.TranscriptionConfigResponse{
+		SessionID: session.ID,
+		UpdatedAt: session.UpdatedAt,
+	}
+
+	if config := session.TranscriptionConfig; config != nil {
+		response.Enabled = config.Enabled
+		response.BaseURL = config.BaseURL
+		response.Model = config.Model
+		response.Language = config.Language
+		response.FromMe = config.FromMe
+	}
+
+	return response
+}
+
+// @Summary Configurar transcrição de mensagens de voz
+// @Description Transcreve as mensagens de voz (PTT) recebidas com um endpoint compatível com /audio/transcriptions (Whisper). O texto é enviado no webhook da mensagem como data.transcription e gravado com a mensagem
+// @Tags Transcription
+// @Accept json
+// @Produce json
+// @Param id path string true "Session ID"
+// @Param request body dto.TranscriptionConfig true "Endpoint de transcrição"
+// @Success 200 {object} dto.TranscriptionConfigResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Security ApiKeyAuth
+// @Router /sessions/{id}/transcription/set [post]
+func (h *SessionHandler) SetTranscriptionConfig(c *gin.Context) {
+	sessionID := c.Param("id")
+
+	var req dto.TranscriptionConfig
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+			Error:   "invalid_request",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	transcriptionConfig := &model.TranscriptionConfig{
+		Enabled:  req.Enabled,
+		BaseURL:  req.BaseURL,
+		Model:    req.Model,
+		APIKey:   req.APIKey,
+		Language: req.Language,
+		FromMe:   req.FromMe,
+	}
+
+	if err := h.sessionManager.UpdateTranscriptionConfig(c.Request.Context(), sessionID, transcriptionConfig); err != nil {
+		logger.Log.Error().Err(err).Str("session_id", sessionID).Msg("Failed to set transcription config")
+		c.JSON(http.StatusInternalServerError, dto.ErrorResponse{
+			Error:   "update_failed",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	session, err := h.sessionManager.GetSession(c.Request.Context(), sessionID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, dto.ErrorResponse{
+			Error:   "fetch_failed",
+			Message: "Transcription config saved but failed to fetch updated session",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, transcriptionConfigResponse(session))
+}
+
+// @Summary Obter configuração de transcrição
+// @Description Retorna o endpoint de transcrição de mensagens de voz da sessão (sem a API key)
+// @Tags Transcription
+// @Produce json
+// @Param id path string true "Session ID"
+// @Success 200 {object} dto.TranscriptionConfigResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Security ApiKeyAuth
+// @Router /sessions/{id}/transcription/find [get]
+func (h *SessionHandler) FindTranscriptionConfig(c *gin.Context) {
+	sessionID := c.Param("id")
+
+	session, err := h.sessionManager.GetSession(c.Request.Context(), sessionID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, dto.ErrorResponse{
+			Error:   "session_not_found",
+			Message: fmt.Sprintf("Session not found: %s", sessionID),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, transcriptionConfigResponse(session))
+}
+
+// @Summary Progresso da sincronização de histórico
+// @Description Retorna o progresso acumulado da importação do histórico de mensagens da sessão
+// @Tags Sessions
+// @Produce json
+// @Param id path string true "Session ID"
+// @Success 200 {object} dto.HistorySyncProgressResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Security ApiKeyAuth
+// @Router /sessions/{id}/history [get]
+func (h *SessionHandler) GetHistorySyncProgress(c *gin.Context) {
+	sessionID := c.Param("id")
+
+	session, err := h.sessionManager.GetSession(c.Request.Context(), sessionID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, dto.ErrorResponse{
+			Error:   "session_not_found",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	response := dto.HistorySyncProgressResponse{
+		SessionID: sessionID,
+		Mode:      string(session.HistoryMode()),
+	}
+
+	progress, err := h.sessionManager.GetHistorySyncProgress(c.Request.Context(), sessionID)
+	if err == nil {
+		response.SyncType = progress.SyncType
+		response.ChunkOrder = progress.ChunkOrder
+		response.Progress = progress.Progress
+		response.ChunksProcessed = progress.ChunksProcessed
+		response.Conversations = progress.Conversations
+		response.Messages = progress.Messages
+		response.StartedAt = progress.StartedAt
+		response.UpdatedAt = progress.UpdatedAt
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
 // @Summary Atualizar webhook (DEPRECATED)
 // @Description Atualiza a URL e eventos do webhook de uma sessão (use /webhook/set)
 // @Tags Sessions
@@ -440,7 +745,7 @@ func (h *SessionHandler) SetWebhook(c *gin.Context) {
 		Enabled:   webhookConfig.Enabled,
 		URL:       webhookConfig.URL,
 		Events:    webhookConfig.Events,
-		Token:     webhookConfig.Token,
+		HasToken:  webhookConfig.Token != "",
 		UpdatedAt: session.UpdatedAt,
 	}
 
@@ -476,7 +781,6 @@ func (h *SessionHandler) FindWebhook(c *gin.Context) {
 		Enabled:   false,
 		URL:       "",
 		Events:    []string{},
-		Token:     "",
 		UpdatedAt: session.UpdatedAt,
 	}
 
@@ -485,7 +789,7 @@ func (h *SessionHandler) FindWebhook(c *gin.Context) {
 		response.Enabled = session.WebhookConfig.Enabled
 		response.URL = session.WebhookConfig.URL
 		response.Events = session.WebhookConfig.Events
-		response.Token = session.WebhookConfig.Token
+		response.HasToken = session.WebhookConfig.Token != ""
 	}
 
 	logger.Log.Info().
@@ -536,6 +840,7 @@ func toSessionResponse(session *model.Session) dto.SessionResponse {
 	return dto.SessionResponse{
 		ID:            session.ID,
 		Name:          session.Name,
+		TenantID:      session.TenantID,
 		JID:           session.DeviceJID,
 		Status:        session.Status,
 		WebhookURL:    webhookURL,
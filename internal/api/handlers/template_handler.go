@@ -0,0 +1,432 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"path"
+
+	"github.com/gin-gonic/gin"
+
+	"zpwoot/internal/api/dto"
+	"zpwoot/internal/model"
+	"zpwoot/internal/repository"
+	"zpwoot/internal/service"
+	"zpwoot/pkg/logger"
+)
+
+type TemplateHandler struct {
+	sessionManager  *service.SessionManager
+	templateService *service.TemplateService
+}
+
+func NewTemplateHandler(sessionManager *service.SessionManager, templateService *service.TemplateService) *TemplateHandler {
+	return &TemplateHandler{
+		sessionManager:  sessionManager,
+		templateService: templateService,
+	}
+}
+
+func toTemplateButtons(buttons []dto.TemplateButton) []model.TemplateButton {
+	var models []model.TemplateButton
+	for _, button := range buttons {
+		models = append(models, model.TemplateButton{ID: button.ID, Text: button.Text})
+	}
+	return models
+}
+
+func fromTemplateButtons(buttons []model.TemplateButton) []dto.TemplateButton {
+	var dtos []dto.TemplateButton
+	for _, button := range buttons {
+		dtos = append(dtos, dto.TemplateButton{ID: button.ID, Text: button.Text})
+	}
+	return dtos
+}
+
+func templateResponse(template *model.MessageTemplate) dto.TemplateResponse {
+	content := template.Content
+
+	texts := []string{content.Text, content.MediaURL, content.Caption, content.FileName}
+	for _, button := range content.Buttons {
+		texts = append(texts, button.Text)
+	}
+
+	variables := service.PlaceholderNames(texts...)
+	if variables == nil {
+		variables = []string{}
+	}
+
+	return dto.TemplateResponse{
+		TemplateID: template.ID,
+		SessionID:  template.SessionID,
+		Name:       template.Name,
+		Version:    template.Version,
+		Text:       content.Text,
+		MediaURL:   content.MediaURL,
+		Caption:    content.Caption,
+		FileName:   content.FileName,
+		Buttons:    fromTemplateButtons(content.Buttons),
+		Variables:  variables,
+		CreatedAt:  template.CreatedAt,
+		UpdatedAt:  template.UpdatedAt,
+	}
+}
+
+// templateCommandError converte erros de template no status e código devolvidos ao solicitante
+func templateCommandError(err error) error {
+	switch {
+	case errors.Is(err, service.ErrTemplateNotFound):
+		return &commandError{status: http.StatusNotFound, code: "template_not_found", err: err}
+	case errors.Is(err, service.ErrMissingVariables):
+		return &commandError{status: http.StatusBadRequest, code: "missing_variables", err: err}
+	case errors.Is(err, service.ErrInvalidTemplate):
+		return &commandError{status: http.StatusBadRequest, code: "invalid_template", err: err}
+	default:
+		return &commandError{status: http.StatusInternalServerError, code: "template_failed", err: err}
+	}
+}
+
+// expandTemplate aplica o template referenciado no corpo (se houver) antes da validação do envio
+func expandTemplate(ctx context.Context, templateService *service.TemplateService, sessionID, kind string, payload []byte) ([]byte, error) {
+	expanded, err := templateService.ExpandPayload(ctx, sessionID, kind, payload)
+	if err != nil {
+		return nil, templateCommandError(err)
+	}
+	return expanded, nil
+}
+
+// templateBodyLimit é o maior corpo lido à procura de templateId; corpos maiores (mídia em base64)
+// seguem direto para o handler, sem ficar inteiros na memória
+const templateBodyLimit = 1 << 20
+
+// ExpandTemplates permite que os endpoints de envio recebam {"phone", "templateId", "variables"}
+// no lugar do conteúdo: o corpo é reescrito com o template renderizado antes do handler.
+// O tipo da mensagem é o último segmento da rota (/message/text, /message/image, ...).
+func (h *TemplateHandler) ExpandTemplates() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.Request.Method != http.MethodPost || c.ContentType() != "application/json" {
+			c.Next()
+			return
+		}
+
+		body, err := io.ReadAll(io.LimitReader(c.Request.Body, templateBodyLimit+1))
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusBadRequest, dto.ErrorResponse{
+				Error:   "invalid_request",
+				Message: err.Error(),
+			})
+			return
+		}
+
+		if len(body) > templateBodyLimit {
+			c.Request.Body = readCloser{io.MultiReader(bytes.NewReader(body), c.Request.Body), c.Request.Body}
+			c.Next()
+			return
+		}
+
+		if bytes.Contains(body, []byte(`"templateId"`)) {
+			body, err = expandTemplate(c.Request.Context(), h.templateService, c.Param("id"), path.Base(c.FullPath()), body)
+			if err != nil {
+				var cmdErr *commandError
+				errors.As(err, &cmdErr)
+				c.AbortWithStatusJSON(cmdErr.status, dto.ErrorResponse{
+					Error:   cmdErr.code,
+					Message: cmdErr.Error(),
+				})
+				return
+			}
+		}
+
+		c.Request.Body = io.NopCloser(bytes.NewReader(body))
+		c.Request.ContentLength = int64(len(body))
+		c.Next()
+	}
+}
+
+// readCloser devolve ao handler o início já lido do corpo seguido do restante
+type readCloser struct {
+	io.Reader
+	io.Closer
+}
+
+// respondTemplateError traduz erros do repositório/serviço para a resposta HTTP
+func respondTemplateError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, service.ErrInvalidTemplate):
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{Error: "invalid_template", Message: err.Error()})
+	case errors.Is(err, repository.ErrTemplateNameTaken):
+		c.JSON(http.StatusConflict, dto.ErrorResponse{Error: "template_exists", Message: err.Error()})
+	default:
+		logger.Log.Error().Err(err).Msg("Template operation failed")
+		c.JSON(http.StatusInternalServerError, dto.ErrorResponse{Error: "template_failed", Message: err.Error()})
+	}
+}
+
+// @Summary Criar template
+// @Description Cria um template de mensagem (texto, mídia e botões com placeholders {{variavel}}), global ou restrito a uma sessão. Os endpoints de envio aceitam templateId + variables no lugar do conteúdo
+// @Tags Templates
+// @Accept json
+// @Produce json
+// @Param request body dto.CreateTemplateRequest true "Template"
+// @Success 201 {object} dto.TemplateResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 409 {object} dto.ErrorResponse
+// @Security ApiKeyAuth
+// @Router /templates [post]
+func (h *TemplateHandler) CreateTemplate(c *gin.Context) {
+	var req dto.CreateTemplateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+			Error:   "invalid_request",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	if req.SessionID != "" {
+		if _, err := h.sessionManager.GetSession(c.Request.Context(), req.SessionID); err != nil {
+			c.JSON(http.StatusNotFound, dto.ErrorResponse{
+				Error:   "session_not_found",
+				Message: fmt.Sprintf("Session not found: %s", req.SessionID),
+			})
+			return
+		}
+	}
+
+	template := &model.MessageTemplate{
+		SessionID: req.SessionID,
+		Name:      req.Name,
+		Content: &model.TemplateContent{
+			Text:     req.Text,
+			MediaURL: req.MediaURL,
+			Caption:  req.Caption,
+			FileName: req.FileName,
+			Buttons:  toTemplateButtons(req.Buttons),
+		},
+	}
+
+	if err := h.templateService.Create(c.Request.Context(), template); err != nil {
+		respondTemplateError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, templateResponse(template))
+}
+
+// @Summary Listar templates
+// @Description Lista os templates na versão atual. Com sessionId, retorna os globais e os da sessão
+// @Tags Templates
+// @Produce json
+// @Param sessionId query string false "Session ID"
+// @Success 200 {object} dto.TemplateListResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Security ApiKeyAuth
+// @Router /templates [get]
+func (h *TemplateHandler) ListTemplates(c *gin.Context) {
+	templates, err := h.templateService.List(c.Request.Context(), c.Query("sessionId"))
+	if err != nil {
+		respondTemplateError(c, err)
+		return
+	}
+
+	response := dto.TemplateListResponse{
+		Templates: make([]dto.TemplateResponse, 0, len(templates)),
+		Total:     len(templates),
+	}
+	for _, template := range templates {
+		response.Templates = append(response.Templates, templateResponse(template))
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// @Summary Consultar template
+// @Description Retorna o template na versão atual ou na versão informada
+// @Tags Templates
+// @Produce json
+// @Param templateId path string true "Template ID"
+// @Param version query int false "Versão (padrão: atual)"
+// @Success 200 {object} dto.TemplateResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Security ApiKeyAuth
+// @Router /templates/{templateId} [get]
+func (h *TemplateHandler) GetTemplate(c *gin.Context) {
+	var query struct {
+		Version int `form:"version"`
+	}
+	if err := c.ShouldBindQuery(&query); err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{Error: "invalid_request", Message: err.Error()})
+		return
+	}
+
+	template, err := h.templateService.Get(c.Request.Context(), c.Param("templateId"), query.Version)
+	if err != nil {
+		c.JSON(http.StatusNotFound, dto.ErrorResponse{Error: "template_not_found", Message: err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, templateResponse(template))
+}
+
+// @Summary Atualizar template
+// @Description Cria uma nova versão do template com o conteúdo informado. Envios que informam templateVersion continuam usando a versão anterior
+// @Tags Templates
+// @Accept json
+// @Produce json
+// @Param templateId path string true "Template ID"
+// @Param request body dto.UpdateTemplateRequest true "Novo conteúdo"
+// @Success 200 {object} dto.TemplateResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 409 {object} dto.ErrorResponse
+// @Security ApiKeyAuth
+// @Router /templates/{templateId} [put]
+func (h *TemplateHandler) UpdateTemplate(c *gin.Context) {
+	var req dto.UpdateTemplateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+			Error:   "invalid_request",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	current, err := h.templateService.Get(c.Request.Context(), c.Param("templateId"), 0)
+	if err != nil {
+		c.JSON(http.StatusNotFound, dto.ErrorResponse{Error: "template_not_found", Message: err.Error()})
+		return
+	}
+
+	template := &model.MessageTemplate{
+		ID:   current.ID,
+		Name: current.Name,
+		Content: &model.TemplateContent{
+			Text:     req.Text,
+			MediaURL: req.MediaURL,
+			Caption:  req.Caption,
+			FileName: req.FileName,
+			Buttons:  toTemplateButtons(req.Buttons),
+		},
+	}
+	if req.Name != "" {
+		template.Name = req.Name
+	}
+
+	if err := h.templateService.Update(c.Request.Context(), template); err != nil {
+		respondTemplateError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, templateResponse(template))
+}
+
+// @Summary Deletar template
+// @Description Remove o template e todas as suas versões
+// @Tags Templates
+// @Produce json
+// @Param templateId path string true "Template ID"
+// @Success 200 {object} dto.SuccessResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Security ApiKeyAuth
+// @Router /templates/{templateId} [delete]
+func (h *TemplateHandler) DeleteTemplate(c *gin.Context) {
+	if err := h.templateService.Delete(c.Request.Context(), c.Param("templateId")); err != nil {
+		c.JSON(http.StatusNotFound, dto.ErrorResponse{Error: "template_not_found", Message: err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.SuccessResponse{
+		Success: true,
+		Message: "Template deleted successfully",
+	})
+}
+
+// @Summary Listar versões do template
+// @Description Lista todas as versões do template, da mais recente para a mais antiga
+// @Tags Templates
+// @Produce json
+// @Param templateId path string true "Template ID"
+// @Success 200 {object} dto.TemplateVersionListResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Security ApiKeyAuth
+// @Router /templates/{templateId}/versions [get]
+func (h *TemplateHandler) ListVersions(c *gin.Context) {
+	templateID := c.Param("templateId")
+
+	versions, err := h.templateService.Versions(c.Request.Context(), templateID)
+	if err != nil {
+		respondTemplateError(c, err)
+		return
+	}
+	if len(versions) == 0 {
+		c.JSON(http.StatusNotFound, dto.ErrorResponse{
+			Error:   "template_not_found",
+			Message: fmt.Sprintf("Template not found: %s", templateID),
+		})
+		return
+	}
+
+	response := dto.TemplateVersionListResponse{
+		TemplateID: templateID,
+		Versions:   make([]dto.TemplateVersionResponse, 0, len(versions)),
+		Total:      len(versions),
+	}
+	for _, version := range versions {
+		response.Versions = append(response.Versions, dto.TemplateVersionResponse{
+			Version:   version.Version,
+			Text:      version.Content.Text,
+			MediaURL:  version.Content.MediaURL,
+			Caption:   version.Content.Caption,
+			FileName:  version.Content.FileName,
+			Buttons:   fromTemplateButtons(version.Content.Buttons),
+			CreatedAt: version.CreatedAt,
+		})
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// @Summary Pré-visualizar template
+// @Description Renderiza o template com as variáveis informadas, sem enviar, e lista as variáveis faltando
+// @Tags Templates
+// @Accept json
+// @Produce json
+// @Param templateId path string true "Template ID"
+// @Param request body dto.RenderTemplateRequest true "Variáveis"
+// @Success 200 {object} dto.RenderTemplateResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Security ApiKeyAuth
+// @Router /templates/{templateId}/render [post]
+func (h *TemplateHandler) RenderTemplate(c *gin.Context) {
+	var req dto.RenderTemplateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+			Error:   "invalid_request",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	template, err := h.templateService.Get(c.Request.Context(), c.Param("templateId"), req.Version)
+	if err != nil {
+		c.JSON(http.StatusNotFound, dto.ErrorResponse{Error: "template_not_found", Message: err.Error()})
+		return
+	}
+
+	content, missing := service.RenderContent(template.Content, req.Variables)
+
+	c.JSON(http.StatusOK, dto.RenderTemplateResponse{
+		TemplateID: template.ID,
+		Version:    template.Version,
+		Text:       content.Text,
+		MediaURL:   content.MediaURL,
+		Caption:    content.Caption,
+		FileName:   content.FileName,
+		Buttons:    fromTemplateButtons(content.Buttons),
+		Missing:    missing,
+	})
+}
@@ -0,0 +1,249 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"zpwoot/internal/api/dto"
+	"zpwoot/internal/model"
+	"zpwoot/internal/repository"
+	"zpwoot/internal/service"
+	"zpwoot/pkg/logger"
+)
+
+type TenantHandler struct {
+	tenantService *service.TenantService
+}
+
+func NewTenantHandler(tenantService *service.TenantService) *TenantHandler {
+	return &TenantHandler{tenantService: tenantService}
+}
+
+func toTenantWebhook(webhook *dto.WebhookConfig) *model.WebhookConfig {
+	if webhook == nil {
+		return nil
+	}
+	return &model.WebhookConfig{
+		Enabled: webhook.Enabled,
+		URL:     webhook.URL,
+		Events:  webhook.Events,
+		Token:   webhook.Token,
+	}
+}
+
+func tenantResponse(tenant *model.Tenant) dto.TenantResponse {
+	response := dto.TenantResponse{
+		ID:           tenant.ID,
+		Name:         tenant.Name,
+		APIKeyPrefix: tenant.APIKeyPrefix,
+		MaxSessions:  tenant.MaxSessions,
+		Enabled:      tenant.Enabled,
+		CreatedAt:    tenant.CreatedAt,
+		UpdatedAt:    tenant.UpdatedAt,
+	}
+
+	if webhook := tenant.WebhookDefaults; webhook != nil {
+		response.WebhookDefaults = &dto.TenantWebhookDefaults{
+			Enabled:  webhook.Enabled,
+			URL:      webhook.URL,
+			Events:   webhook.Events,
+			HasToken: webhook.Token != "",
+		}
+	}
+
+	return response
+}
+
+// respondTenantError traduz erros do repositório/serviço para a resposta HTTP
+func respondTenantError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, service.ErrTenantNotFound):
+		c.JSON(http.StatusNotFound, dto.ErrorResponse{Error: "tenant_not_found", Message: err.Error()})
+	case errors.Is(err, repository.ErrTenantHasSessions):
+		c.JSON(http.StatusConflict, dto.ErrorResponse{Error: "tenant_has_sessions", Message: "Delete the tenant sessions first"})
+	default:
+		logger.Log.Error().Err(err).Msg("Tenant operation failed")
+		c.JSON(http.StatusInternalServerError, dto.ErrorResponse{Error: "tenant_failed", Message: err.Error()})
+	}
+}
+
+// @Summary Criar tenant
+// @Description Cria uma organização com API key própria, cota de sessões e webhook padrão. A API key é exibida apenas nesta resposta
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Param request body dto.CreateTenantRequest true "Tenant"
+// @Success 201 {object} dto.TenantKeyResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 403 {object} dto.ErrorResponse
+// @Security ApiKeyAuth
+// @Router /admin/tenants [post]
+func (h *TenantHandler) CreateTenant(c *gin.Context) {
+	var req dto.CreateTenantRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+			Error:   "invalid_request",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	tenant := &model.Tenant{
+		Name:            req.Name,
+		MaxSessions:     req.MaxSessions,
+		WebhookDefaults: toTenantWebhook(req.WebhookDefaults),
+		Enabled:         req.Enabled == nil || *req.Enabled,
+	}
+
+	apiKey, err := h.tenantService.Create(c.Request.Context(), tenant)
+	if err != nil {
+		respondTenantError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, dto.TenantKeyResponse{
+		Tenant: tenantResponse(tenant),
+		APIKey: apiKey,
+	})
+}
+
+// @Summary Listar tenants
+// @Description Lista as organizações cadastradas
+// @Tags Admin
+// @Produce json
+// @Success 200 {object} dto.TenantListResponse
+// @Failure 403 {object} dto.ErrorResponse
+// @Security ApiKeyAuth
+// @Router /admin/tenants [get]
+func (h *TenantHandler) ListTenants(c *gin.Context) {
+	tenants, err := h.tenantService.List(c.Request.Context())
+	if err != nil {
+		respondTenantError(c, err)
+		return
+	}
+
+	response := dto.TenantListResponse{
+		Tenants: make([]dto.TenantResponse, 0, len(tenants)),
+		Total:   len(tenants),
+	}
+	for _, tenant := range tenants {
+		response.Tenants = append(response.Tenants, tenantResponse(tenant))
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// @Summary Consultar tenant
+// @Tags Admin
+// @Produce json
+// @Param tenantId path string true "Tenant ID"
+// @Success 200 {object} dto.TenantResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Security ApiKeyAuth
+// @Router /admin/tenants/{tenantId} [get]
+func (h *TenantHandler) GetTenant(c *gin.Context) {
+	tenant, err := h.tenantService.Get(c.Request.Context(), c.Param("tenantId"))
+	if err != nil {
+		respondTenantError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, tenantResponse(tenant))
+}
+
+// @Summary Atualizar tenant
+// @Description Atualiza nome, cota de sessões, webhook padrão e status. Tenants desativados não autenticam
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Param tenantId path string true "Tenant ID"
+// @Param request body dto.UpdateTenantRequest true "Tenant"
+// @Success 200 {object} dto.TenantResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Security ApiKeyAuth
+// @Router /admin/tenants/{tenantId} [put]
+func (h *TenantHandler) UpdateTenant(c *gin.Context) {
+	var req dto.UpdateTenantRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+			Error:   "invalid_request",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	tenant, err := h.tenantService.Get(c.Request.Context(), c.Param("tenantId"))
+	if err != nil {
+		respondTenantError(c, err)
+		return
+	}
+
+	tenant.Name = req.Name
+	tenant.MaxSessions = req.MaxSessions
+	tenant.WebhookDefaults = toTenantWebhook(req.WebhookDefaults)
+	if req.Enabled != nil {
+		tenant.Enabled = *req.Enabled
+	}
+
+	if err := h.tenantService.Update(c.Request.Context(), tenant); err != nil {
+		respondTenantError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, tenantResponse(tenant))
+}
+
+// @Summary Deletar tenant
+// @Description Remove o tenant e seus templates. Falha se o tenant ainda tiver sessões
+// @Tags Admin
+// @Produce json
+// @Param tenantId path string true "Tenant ID"
+// @Success 200 {object} dto.SuccessResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 409 {object} dto.ErrorResponse
+// @Security ApiKeyAuth
+// @Router /admin/tenants/{tenantId} [delete]
+func (h *TenantHandler) DeleteTenant(c *gin.Context) {
+	if err := h.tenantService.Delete(c.Request.Context(), c.Param("tenantId")); err != nil {
+		respondTenantError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.SuccessResponse{
+		Success: true,
+		Message: "Tenant deleted successfully",
+	})
+}
+
+// @Summary Rotacionar API key do tenant
+// @Description Gera uma nova API key; a anterior deixa de valer imediatamente. A nova chave é exibida apenas nesta resposta
+// @Tags Admin
+// @Produce json
+// @Param tenantId path string true "Tenant ID"
+// @Success 200 {object} dto.TenantKeyResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Security ApiKeyAuth
+// @Router /admin/tenants/{tenantId}/rotate-key [post]
+func (h *TenantHandler) RotateKey(c *gin.Context) {
+	tenantID := c.Param("tenantId")
+
+	apiKey, err := h.tenantService.RotateKey(c.Request.Context(), tenantID)
+	if err != nil {
+		respondTenantError(c, err)
+		return
+	}
+
+	tenant, err := h.tenantService.Get(c.Request.Context(), tenantID)
+	if err != nil {
+		respondTenantError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.TenantKeyResponse{
+		Tenant: tenantResponse(tenant),
+		APIKey: apiKey,
+	})
+}
@@ -0,0 +1,95 @@
+package middleware
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"zpwoot/internal/model"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ContextSessionID é a chave do gin.Context com a sessão criada pelo handler (rotas sem /:id)
+const ContextSessionID = "session_id"
+
+// auditBodyLimit é o maior corpo JSON lido para o resumo do audit log; corpos maiores (mídia em
+// base64) seguem para o handler sem ficar inteiros na memória e registram apenas o tipo
+const auditBodyLimit = 64 << 10
+
+// AuditRecorder grava as chamadas auditadas; o resumo sem segredos é montado a partir da query e do
+// corpo ainda no caminho da requisição (body é nil quando passa de auditBodyLimit)
+type AuditRecorder interface {
+	Record(entry *model.AuditEntry, query url.Values, contentType string, body []byte)
+}
+
+// Audit registra toda chamada que altera estado (métodos diferentes de GET/HEAD/OPTIONS), inclusive as
+// recusadas pela autenticação, com o autor, a sessão, o resultado e o request_id do RequestLogger.
+// Deve ser registrado antes dos grupos autenticados; skipRoutes recebe rotas declaradas (c.FullPath()).
+func Audit(recorder AuditRecorder, skipRoutes ...string) gin.HandlerFunc {
+	skipMap := make(map[string]bool)
+	for _, route := range skipRoutes {
+		skipMap[route] = true
+	}
+
+	return func(c *gin.Context) {
+		// Rotas inexistentes (404) não são auditadas
+		method := c.Request.Method
+		if method == http.MethodGet || method == http.MethodHead || method == http.MethodOptions ||
+			c.FullPath() == "" || skipMap[c.FullPath()] {
+			c.Next()
+			return
+		}
+
+		// O início do corpo JSON é lido aqui e devolvido intacto para o handler (multipart não é lido)
+		contentType := c.ContentType()
+		var body []byte
+		if c.Request.Body != nil && strings.Contains(contentType, "json") {
+			original := c.Request.Body
+			body, _ = io.ReadAll(io.LimitReader(original, auditBodyLimit+1))
+			c.Request.Body = struct {
+				io.Reader
+				io.Closer
+			}{io.MultiReader(bytes.NewReader(body), original), original}
+
+			if len(body) > auditBodyLimit {
+				body = nil
+			}
+		}
+
+		startTime := time.Now()
+		c.Next()
+
+		// Chamadas recusadas pelo rate limit não chegaram ao handler; um cliente em loop encheria o log
+		status := c.Writer.Status()
+		if status == http.StatusTooManyRequests {
+			return
+		}
+
+		entry := &model.AuditEntry{
+			IP:         c.ClientIP(),
+			Method:     method,
+			Route:      c.FullPath(),
+			Path:       c.Request.URL.Path,
+			RequestID:  c.GetString("request_id"),
+			SessionID:  c.Param("id"),
+			Status:     status,
+			Result:     model.AuditResultFromStatus(status),
+			DurationMs: time.Since(startTime).Milliseconds(),
+		}
+		if entry.SessionID == "" {
+			entry.SessionID = c.GetString(ContextSessionID)
+		}
+		if key := APIKeyFromContext(c); key != nil {
+			entry.TenantID = key.TenantID
+			entry.ActorKeyID = key.ID
+			entry.ActorKeyPrefix = key.KeyPrefix
+			entry.ActorName = key.Name
+		}
+
+		recorder.Record(entry, c.Request.URL.Query(), contentType, body)
+	}
+}
@@ -0,0 +1,62 @@
+package middleware
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+
+	"zpwoot/internal/model"
+)
+
+type recordedAudit struct {
+	entry *model.AuditEntry
+	body  []byte
+}
+
+type fakeAuditRecorder struct {
+	records []recordedAudit
+}
+
+func (r *fakeAuditRecorder) Record(entry *model.AuditEntry, query url.Values, contentType string, body []byte) {
+	r.records = append(r.records, recordedAudit{entry: entry, body: body})
+}
+
+func TestAuditBodyLimit(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	recorder := &fakeAuditRecorder{}
+
+	var received []string
+	r := gin.New()
+	r.Use(Audit(recorder))
+	r.POST("/message/image", func(c *gin.Context) {
+		data, _ := io.ReadAll(c.Request.Body)
+		received = append(received, string(data))
+		c.Status(http.StatusOK)
+	})
+
+	small := `{"phone":"5511999999999","image":"abc"}`
+	large := `{"phone":"5511999999999","image":"` + strings.Repeat("A", auditBodyLimit) + `"}`
+	for _, body := range []string{small, large} {
+		req := httptest.NewRequest(http.MethodPost, "/message/image", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		r.ServeHTTP(httptest.NewRecorder(), req)
+	}
+
+	// O handler sempre recebe o corpo completo
+	if len(received) != 2 || received[0] != small || received[1] != large {
+		t.Fatalf("handler bodies differ from the request (%d bodies)", len(received))
+	}
+
+	// Apenas corpos dentro do limite chegam ao audit log
+	if len(recorder.records) != 2 {
+		t.Fatalf("audit records = %d, want 2", len(recorder.records))
+	}
+	if string(recorder.records[0].body) != small || recorder.records[1].body != nil {
+		t.Errorf("audited bodies = %q, %d bytes; want small body only", recorder.records[0].body, len(recorder.records[1].body))
+	}
+}
@@ -1,32 +1,204 @@
 package middleware
 
 import (
+	"context"
 	"net/http"
+	"net/url"
 	"strings"
 	"time"
 
 	"zpwoot/internal/config"
+	"zpwoot/internal/model"
+	"zpwoot/internal/repository"
 	"zpwoot/pkg/logger"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
 )
 
-func AuthenticateGlobal() gin.HandlerFunc {
+const (
+	// ContextAPIKey é a chave do gin.Context com a *model.APIKey autenticada
+	ContextAPIKey = "api_key"
+	// ContextTenantID é a chave do gin.Context com o tenant autenticado (vazio = servidor)
+	ContextTenantID = "tenant_id"
+	// ContextScope é a chave do gin.Context com o escopo exigido pela rota (definido por RequireScope)
+	ContextScope = "scope"
+)
+
+// KeyResolver identifica as API keys geradas pelo servidor (nil quando o segredo não é uma delas)
+type KeyResolver interface {
+	ResolveAPIKey(ctx context.Context, apiKey string) (*model.APIKey, error)
+}
+
+// TokenVerifier valida bearer tokens (JWT do IdP) e retorna a chave equivalente às claims
+type TokenVerifier interface {
+	VerifyToken(ctx context.Context, token string) (*model.APIKey, error)
+}
+
+// globalKey representa a API key global (e o acesso sem API_KEY configurada)
+var globalKey = &model.APIKey{Name: "global", Scopes: []model.Scope{model.ScopeAdmin}}
+
+// setAPIKey registra a chave autenticada; chaves de tenant (e tokens com lista de sessões)
+// restringem os repositórios via contexto da requisição
+func setAPIKey(c *gin.Context, key *model.APIKey) {
+	c.Set(ContextAPIKey, key)
+	if key.TenantID != "" {
+		c.Set(ContextTenantID, key.TenantID)
+		c.Request = c.Request.WithContext(repository.WithTenant(c.Request.Context(), key.TenantID))
+	}
+	if key.SessionIDs != nil {
+		c.Request = c.Request.WithContext(repository.WithSessionIDs(c.Request.Context(), key.SessionIDs))
+	}
+}
+
+// bearerToken retorna o token do header "Authorization: Bearer" ou, com allowQuery, do parâmetro
+// access_token (EventSource e WebSocket do navegador não enviam headers)
+func bearerToken(c *gin.Context, allowQuery bool) string {
+	header := c.GetHeader("Authorization")
+	if len(header) > 7 && strings.EqualFold(header[:7], "bearer ") {
+		return strings.TrimSpace(header[7:])
+	}
+	if allowQuery {
+		return strings.TrimSpace(c.Query("access_token"))
+	}
+	return ""
+}
+
+// APIKeyFromContext retorna a chave autenticada na requisição (nil fora das rotas autenticadas)
+func APIKeyFromContext(c *gin.Context) *model.APIKey {
+	key, _ := c.Get(ContextAPIKey)
+	apiKey, _ := key.(*model.APIKey)
+	return apiKey
+}
+
+// AuthenticateGlobal aceita a API key global (todas as permissões), uma API key gerada pelo
+// servidor (chaves com escopos e a API key principal dos tenants) ou, com tokens != nil, um
+// bearer token JWT do IdP. As permissões são verificadas por RequireScope em cada grupo de rotas.
+// Credenciais na URL (?apikey= e ?access_token=) vazam em logs de proxy e no histórico do navegador:
+// são aceitas apenas nas rotas declaradas em queryRoutes (c.FullPath()), os streams SSE/WebSocket.
+func AuthenticateGlobal(keys KeyResolver, tokens TokenVerifier, queryRoutes ...string) gin.HandlerFunc {
+	queryMap := make(map[string]bool)
+	for _, route := range queryRoutes {
+		queryMap[route] = true
+	}
+
 	return func(c *gin.Context) {
+		allowQuery := queryMap[c.FullPath()]
+
+		// Bearer token (JWT) no lugar da API key
+		if token := bearerToken(c, allowQuery); token != "" {
+			if tokens == nil {
+				c.JSON(http.StatusUnauthorized, gin.H{
+					"error":   "unauthorized",
+					"message": "Bearer tokens are not enabled. Use header: apikey: <your_key>",
+				})
+				c.Abort()
+				return
+			}
+
+			key, err := tokens.VerifyToken(c.Request.Context(), token)
+			if err != nil {
+				logger.Log.Warn().
+					Err(err).
+					Str(logger.FieldIP, c.ClientIP()).
+					Str(logger.FieldPath, c.Request.URL.Path).
+					Msg("Invalid bearer token")
+
+				c.JSON(http.StatusUnauthorized, gin.H{
+					"error":   "unauthorized",
+					"message": "Invalid bearer token",
+				})
+				c.Abort()
+				return
+			}
+
+			setAPIKey(c, key)
+
+			logger.Log.Debug().
+				Str(logger.FieldIP, c.ClientIP()).
+				Str(logger.FieldPath, c.Request.URL.Path).
+				Str("subject", key.Name).
+				Msg("Request authenticated with bearer token")
+
+			c.Next()
+			return
+		}
+
 		// Obter API Key configurada
 		expectedAPIKey := config.AppConfig.APIKey
 
+		// Obter API Key do header "apikey"
+		apiKey := strings.TrimSpace(c.GetHeader("apikey"))
+
+		// Fallback para query string nos streams: EventSource e WebSocket do navegador não enviam headers
+		if apiKey == "" && allowQuery {
+			apiKey = strings.TrimSpace(c.Query("apikey"))
+		}
+
+		// API Key gerada pelo servidor
+		if apiKey != "" && keys != nil {
+			key, err := keys.ResolveAPIKey(c.Request.Context(), apiKey)
+			if err != nil {
+				logger.Log.Error().Err(err).Str(logger.FieldPath, c.Request.URL.Path).Msg("Failed to resolve API key")
+				c.JSON(http.StatusInternalServerError, gin.H{
+					"error":   "auth_failed",
+					"message": "Failed to validate API key",
+				})
+				c.Abort()
+				return
+			}
+
+			if key != nil {
+				if key.IsExpired(time.Now()) {
+					logger.Log.Warn().
+						Str(logger.FieldIP, c.ClientIP()).
+						Str("key_prefix", key.KeyPrefix).
+						Msg("Expired API key")
+
+					c.JSON(http.StatusUnauthorized, gin.H{
+						"error":   "unauthorized",
+						"message": "API key expired",
+					})
+					c.Abort()
+					return
+				}
+
+				if !key.AllowsIP(c.ClientIP()) {
+					logger.Log.Warn().
+						Str(logger.FieldIP, c.ClientIP()).
+						Str("key_prefix", key.KeyPrefix).
+						Msg("API key used from a disallowed IP")
+
+					c.JSON(http.StatusForbidden, gin.H{
+						"error":   "forbidden",
+						"message": "API key is not allowed from this IP",
+					})
+					c.Abort()
+					return
+				}
+
+				setAPIKey(c, key)
+
+				logger.Log.Debug().
+					Str(logger.FieldIP, c.ClientIP()).
+					Str(logger.FieldPath, c.Request.URL.Path).
+					Str("key_prefix", key.KeyPrefix).
+					Str("tenant_id", key.TenantID).
+					Msg("Request authenticated with API key")
+
+				c.Next()
+				return
+			}
+		}
+
 		// Se não houver API Key configurada, permitir acesso
 		if expectedAPIKey == "" {
 			logger.Log.Warn().Msg("No API key configured - authentication disabled")
+			setAPIKey(c, globalKey)
 			c.Next()
 			return
 		}
 
-		// Obter API Key do header "apikey"
-		apiKey := strings.TrimSpace(c.GetHeader("apikey"))
-
 		// Validar API Key
 		if apiKey == "" {
 			logger.Log.Warn().
@@ -62,6 +234,65 @@ func AuthenticateGlobal() gin.HandlerFunc {
 			Str(logger.FieldPath, c.Request.URL.Path).
 			Msg("Request authenticated")
 
+		setAPIKey(c, globalKey)
+		c.Next()
+	}
+}
+
+// RequireScope declara o escopo exigido por um grupo de rotas: read para GET/HEAD e write para
+// os demais métodos (admin concede todos)
+func RequireScope(read, write model.Scope) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		scope := write
+		if c.Request.Method == http.MethodGet || c.Request.Method == http.MethodHead {
+			scope = read
+		}
+
+		if key := APIKeyFromContext(c); key == nil || !key.HasScope(scope) {
+			c.JSON(http.StatusForbidden, gin.H{
+				"error":   "forbidden",
+				"message": "API key requires the " + string(scope) + " scope",
+			})
+			c.Abort()
+			return
+		}
+
+		c.Set(ContextScope, scope)
+		c.Next()
+	}
+}
+
+// RequireAllSessions recusa tokens restritos a algumas sessões (claim de sessões do JWT) nas rotas
+// que não são filtradas por sessão: em /keys uma chave criada não herdaria a restrição, e em
+// /templates o token leria e alteraria templates globais e de outras sessões
+func RequireAllSessions() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if key := APIKeyFromContext(c); key == nil || key.SessionIDs != nil {
+			c.JSON(http.StatusForbidden, gin.H{
+				"error":   "forbidden",
+				"message": "This endpoint is not available to tokens restricted to specific sessions",
+			})
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// RequireAdmin restringe a rota às chaves do servidor com escopo admin (a API key global, por
+// exemplo); chaves de tenant e tokens restritos a algumas sessões recebem 403
+func RequireAdmin() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if key := APIKeyFromContext(c); key == nil || key.TenantID != "" || key.SessionIDs != nil || !key.HasScope(model.ScopeAdmin) {
+			c.JSON(http.StatusForbidden, gin.H{
+				"error":   "forbidden",
+				"message": "This endpoint requires a server API key with the admin scope",
+			})
+			c.Abort()
+			return
+		}
+
 		c.Next()
 	}
 }
@@ -70,7 +301,7 @@ func CORS() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		c.Writer.Header().Set("Access-Control-Allow-Origin", "*")
 		c.Writer.Header().Set("Access-Control-Allow-Credentials", "true")
-		c.Writer.Header().Set("Access-Control-Allow-Headers", "Content-Type, Content-Length, Accept-Encoding, apikey, accept, origin, Cache-Control, X-Requested-With")
+		c.Writer.Header().Set("Access-Control-Allow-Headers", "Content-Type, Content-Length, Accept-Encoding, Authorization, apikey, accept, origin, Cache-Control, X-Requested-With")
 		c.Writer.Header().Set("Access-Control-Allow-Methods", "POST, OPTIONS, GET, PUT, DELETE, PATCH")
 
 		if c.Request.Method == "OPTIONS" {
@@ -87,6 +318,7 @@ func RequestLogger() gin.HandlerFunc {
 		// Generate unique request ID
 		requestID := uuid.New().String()
 		c.Set("request_id", requestID)
+		c.Header("X-Request-ID", requestID)
 
 		// Record start time
 		startTime := time.Now()
@@ -98,7 +330,7 @@ func RequestLogger() gin.HandlerFunc {
 			Str(logger.FieldPath, c.Request.URL.Path).
 			Str(logger.FieldIP, c.ClientIP()).
 			Str(logger.FieldUserAgent, c.Request.UserAgent()).
-			Str("query", c.Request.URL.RawQuery).
+			Str("query", redactQuery(c.Request.URL.Query())).
 			Msg("→ Incoming request")
 
 		// Process request
@@ -145,3 +377,13 @@ func RequestLoggerWithSkip(skipPaths ...string) gin.HandlerFunc {
 		RequestLogger()(c)
 	}
 }
+
+// redactQuery remove a API key, o bearer token e tokens de webhook da query string antes de registrá-la no log
+func redactQuery(query url.Values) string {
+	for _, key := range []string{"apikey", "token", "access_token"} {
+		if query.Has(key) {
+			query.Set(key, "***")
+		}
+	}
+	return query.Encode()
+}
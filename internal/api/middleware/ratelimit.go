@@ -0,0 +1,116 @@
+package middleware
+
+import (
+	"context"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"zpwoot/internal/model"
+	"zpwoot/pkg/logger"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RequestLimiter consome requisições dos token buckets por IP e por API key
+type RequestLimiter interface {
+	AllowIP(ctx context.Context, ip string) (model.RequestLimitResult, error)
+	AllowKey(ctx context.Context, key string, send bool) (model.RequestLimitResult, error)
+}
+
+// RateLimitIP limita as requisições por IP de origem, antes da autenticação (protege também contra
+// tentativas de adivinhar API keys). skipRoutes recebe rotas declaradas (c.FullPath()), como /health.
+func RateLimitIP(limiter RequestLimiter, skipRoutes ...string) gin.HandlerFunc {
+	skipMap := make(map[string]bool)
+	for _, route := range skipRoutes {
+		skipMap[route] = true
+	}
+
+	return func(c *gin.Context) {
+		if skipMap[c.FullPath()] {
+			c.Next()
+			return
+		}
+
+		result, err := limiter.AllowIP(c.Request.Context(), c.ClientIP())
+		applyRateLimit(c, result, err)
+	}
+}
+
+// RateLimitKey limita as requisições por API key, com buckets separados para envios de mensagem
+// (rotas com escopo messages:send) e demais chamadas. Deve vir depois de RequireScope/RequireAdmin;
+// limiter nil desativa o limite.
+func RateLimitKey(limiter RequestLimiter) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		key := APIKeyFromContext(c)
+		if limiter == nil || key == nil {
+			c.Next()
+			return
+		}
+
+		send := c.GetString(ContextScope) == string(model.ScopeMessagesSend)
+		result, err := limiter.AllowKey(c.Request.Context(), rateLimitIdentity(key), send)
+		applyRateLimit(c, result, err)
+	}
+}
+
+// rateLimitIdentity identifica o bucket da chave: chaves geradas pelo ID, as demais (global,
+// tenant, tokens JWT) pelo tenant e nome
+func rateLimitIdentity(key *model.APIKey) string {
+	if key.ID != "" {
+		return key.ID
+	}
+	return key.TenantID + "/" + key.Name
+}
+
+// applyRateLimit define os headers RateLimit-* e recusa a requisição com 429 quando o bucket está vazio.
+// Erros indicam armazenamento indisponível (NATS fora do ar, por exemplo) e não bloqueiam a API;
+// buckets disputados entre réplicas voltam negados pelo store, sem erro.
+func applyRateLimit(c *gin.Context, result model.RequestLimitResult, err error) {
+	if err != nil {
+		logger.Log.Warn().
+			Err(err).
+			Str(logger.FieldIP, c.ClientIP()).
+			Str(logger.FieldPath, c.Request.URL.Path).
+			Msg("Rate limit check failed, allowing request")
+		c.Next()
+		return
+	}
+
+	// Limite desativado para a classe da requisição
+	if result.Limit == 0 {
+		c.Next()
+		return
+	}
+
+	header := c.Writer.Header()
+	header.Set("RateLimit-Limit", strconv.Itoa(result.Limit))
+	header.Set("RateLimit-Remaining", strconv.Itoa(result.Remaining))
+	header.Set("RateLimit-Reset", strconv.Itoa(ceilSeconds(result.Reset)))
+
+	if !result.Allowed {
+		retryAfter := ceilSeconds(result.RetryAfter)
+		header.Set("Retry-After", strconv.Itoa(retryAfter))
+
+		logger.Log.Warn().
+			Str(logger.FieldIP, c.ClientIP()).
+			Str(logger.FieldMethod, c.Request.Method).
+			Str(logger.FieldPath, c.Request.URL.Path).
+			Int("retry_after", retryAfter).
+			Msg("Rate limit exceeded")
+
+		c.JSON(http.StatusTooManyRequests, gin.H{
+			"error":   "rate_limited",
+			"message": "Too many requests, retry after " + strconv.Itoa(retryAfter) + " seconds",
+		})
+		c.Abort()
+		return
+	}
+
+	c.Next()
+}
+
+func ceilSeconds(d time.Duration) int {
+	return int(math.Ceil(d.Seconds()))
+}
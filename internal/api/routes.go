@@ -7,13 +7,55 @@ import (
 
 	"zpwoot/internal/api/handlers"
 	"zpwoot/internal/api/middleware"
+	"zpwoot/internal/model"
 )
 
-func RegisterRoutes(r *gin.Engine, sessionHandler *handlers.SessionHandler, messageHandler *handlers.MessageHandler) {
+// Deps reúne os handlers e as dependências dos middlewares registrados por RegisterRoutes.
+// KeyResolver, TokenVerifier, AuditRecorder e RequestLimiter são opcionais (nil desativa o recurso).
+type Deps struct {
+	SessionHandler     *handlers.SessionHandler
+	MessageHandler     *handlers.MessageHandler
+	NewsletterHandler  *handlers.NewsletterHandler
+	EventStreamHandler *handlers.EventStreamHandler
+	JobHandler         *handlers.JobHandler
+	ScheduleHandler    *handlers.ScheduleHandler
+	CampaignHandler    *handlers.CampaignHandler
+	TemplateHandler    *handlers.TemplateHandler
+	AutoReplyHandler   *handlers.AutoReplyHandler
+	ChatwootHandler    *handlers.ChatwootHandler
+	FlowBotHandler     *handlers.FlowBotHandler
+	AssistantHandler   *handlers.AssistantHandler
+	TenantHandler      *handlers.TenantHandler
+	APIKeyHandler      *handlers.APIKeyHandler
+	AuditHandler       *handlers.AuditHandler
+	KeyResolver        middleware.KeyResolver
+	TokenVerifier      middleware.TokenVerifier
+	AuditRecorder      middleware.AuditRecorder
+	RequestLimiter     middleware.RequestLimiter
+}
+
+func RegisterRoutes(r *gin.Engine, deps Deps) {
 	// Middlewares globais
 	r.Use(middleware.CORS())
 	r.Use(middleware.RequestLogger())
 
+	// Rate limit por IP, antes da autenticação (health check e Swagger ficam de fora)
+	if deps.RequestLimiter != nil {
+		r.Use(middleware.RateLimitIP(deps.RequestLimiter, "/health", "/swagger/*any"))
+	}
+
+	// Audit log das chamadas que alteram estado (o webhook do Chatwoot não é uma chamada da API)
+	if deps.AuditRecorder != nil {
+		r.Use(middleware.Audit(deps.AuditRecorder, "/chatwoot/webhook/:id"))
+	}
+
+	// API key (global, com escopos ou de tenant) ou bearer token JWT; na query string apenas nos streams
+	authenticate := middleware.AuthenticateGlobal(deps.KeyResolver, deps.TokenVerifier,
+		"/sessions/:id/qr/stream", "/sessions/:id/qr/ws", "/sessions/:id/events/stream", "/sessions/:id/events/ws")
+
+	// Rate limit por API key, depois da verificação de escopo (envios têm bucket próprio)
+	limit := middleware.RateLimitKey(deps.RequestLimiter)
+
 	// Swagger documentation (sem autenticação)
 	r.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerFiles.Handler))
 
@@ -25,98 +67,382 @@ func RegisterRoutes(r *gin.Engine, sessionHandler *handlers.SessionHandler, mess
 		})
 	})
 
-	// Grupo de rotas de sessões com autenticação
+	// Webhook das inboxes do Chatwoot (autenticado pelo token da URL, não pela API key)
+	r.POST("/chatwoot/webhook/:id", deps.ChatwootHandler.ReceiveWebhook)
+
+	// Templates de mensagem (globais ou por sessão; tokens restritos a algumas sessões não têm acesso)
+	templates := r.Group("/templates")
+	templates.Use(authenticate, middleware.RequireScope(model.ScopeSessionsRead, model.ScopeSessionsWrite), middleware.RequireAllSessions(), limit)
+	{
+		// POST /templates - Criar template
+		templates.POST("", deps.TemplateHandler.CreateTemplate)
+
+		// GET /templates - Listar templates (?sessionId= filtra globais + sessão)
+		templates.GET("", deps.TemplateHandler.ListTemplates)
+
+		// GET /templates/:templateId - Consultar template (?version= para versão anterior)
+		templates.GET("/:templateId", deps.TemplateHandler.GetTemplate)
+
+		// PUT /templates/:templateId - Atualizar template (cria nova versão)
+		templates.PUT("/:templateId", deps.TemplateHandler.UpdateTemplate)
+
+		// DELETE /templates/:templateId - Deletar template
+		templates.DELETE("/:templateId", deps.TemplateHandler.DeleteTemplate)
+
+		// GET /templates/:templateId/versions - Listar versões
+		templates.GET("/:templateId/versions", deps.TemplateHandler.ListVersions)
+
+		// POST /templates/:templateId/render - Pré-visualizar com variáveis
+		templates.POST("/:templateId/render", deps.TemplateHandler.RenderTemplate)
+	}
+
+	// API keys com escopos (chaves de tenant gerenciam apenas as do próprio tenant)
+	keys := r.Group("/keys")
+	keys.Use(authenticate, middleware.RequireScope(model.ScopeAdmin, model.ScopeAdmin), middleware.RequireAllSessions(), limit)
+	{
+		// POST /keys - Criar API key (retorna o segredo)
+		keys.POST("", deps.APIKeyHandler.CreateKey)
+
+		// GET /keys - Listar API keys
+		keys.GET("", deps.APIKeyHandler.ListKeys)
+
+		// GET /keys/:keyId - Consultar API key
+		keys.GET("/:keyId", deps.APIKeyHandler.GetKey)
+
+		// DELETE /keys/:keyId - Revogar API key
+		keys.DELETE("/:keyId", deps.APIKeyHandler.RevokeKey)
+	}
+
+	// Audit log (chaves de tenant consultam apenas o próprio tenant)
+	audit := r.Group("/audit")
+	audit.Use(authenticate, middleware.RequireScope(model.ScopeAdmin, model.ScopeAdmin), limit)
+	{
+		// GET /audit - Consultar audit log (filtros por sessão, chave, rota, resultado e período)
+		audit.GET("", deps.AuditHandler.ListAudit)
+	}
+
+	// Administração de tenants (apenas chaves do servidor com escopo admin)
+	admin := r.Group("/admin")
+	admin.Use(authenticate, middleware.RequireAdmin(), limit)
+	{
+		// POST /admin/tenants - Criar tenant (retorna a API key)
+		admin.POST("/tenants", deps.TenantHandler.CreateTenant)
+
+		// GET /admin/tenants - Listar tenants
+		admin.GET("/tenants", deps.TenantHandler.ListTenants)
+
+		// GET /admin/tenants/:tenantId - Consultar tenant
+		admin.GET("/tenants/:tenantId", deps.TenantHandler.GetTenant)
+
+		// PUT /admin/tenants/:tenantId - Atualizar nome, cota, webhook padrão e status
+		admin.PUT("/tenants/:tenantId", deps.TenantHandler.UpdateTenant)
+
+		// DELETE /admin/tenants/:tenantId - Deletar tenant (sem sessões)
+		admin.DELETE("/tenants/:tenantId", deps.TenantHandler.DeleteTenant)
+
+		// POST /admin/tenants/:tenantId/rotate-key - Gerar nova API key
+		admin.POST("/tenants/:tenantId/rotate-key", deps.TenantHandler.RotateKey)
+	}
+
+	// Grupo de rotas de sessões com autenticação (API keys de tenant acessam apenas as próprias sessões)
 	sessions := r.Group("/sessions")
-	sessions.Use(middleware.AuthenticateGlobal())
+	sessions.Use(authenticate, deps.SessionHandler.AuthorizeSession())
 	{
+		// Cada grupo declara o escopo exigido (leitura, escrita)
+		core := sessions.Group("", middleware.RequireScope(model.ScopeSessionsRead, model.ScopeSessionsWrite), limit)
+
 		// === ROTAS DE EVENTOS DE WEBHOOK (GLOBAIS) ===
 		// GET /sessions/webhook/events - Listar todos os eventos suportados
-		sessions.GET("/webhook/events", sessionHandler.ListWebhookEvents)
+		core.GET("/webhook/events", deps.SessionHandler.ListWebhookEvents)
 
 		// POST /sessions/create - Criar nova sessão
-		sessions.POST("/create", sessionHandler.CreateSession)
+		core.POST("/create", deps.SessionHandler.CreateSession)
 
 		// GET /sessions/list - Listar todas as sessões
-		sessions.GET("/list", sessionHandler.GetSessions)
+		core.GET("/list", deps.SessionHandler.GetSessions)
 
 		// GET /sessions/:id/info - Obter detalhes da sessão
-		sessions.GET("/:id/info", sessionHandler.GetSession)
+		core.GET("/:id/info", deps.SessionHandler.GetSession)
 
 		// DELETE /sessions/:id/delete - Deletar sessão
-		sessions.DELETE("/:id/delete", sessionHandler.DeleteSession)
+		core.DELETE("/:id/delete", deps.SessionHandler.DeleteSession)
 
 		// POST /sessions/:id/connect - Conectar sessão
-		sessions.POST("/:id/connect", sessionHandler.ConnectSession)
+		core.POST("/:id/connect", deps.SessionHandler.ConnectSession)
 
 		// POST /sessions/:id/disconnect - Desconectar sessão
-		sessions.POST("/:id/disconnect", sessionHandler.DisconnectSession)
+		core.POST("/:id/disconnect", deps.SessionHandler.DisconnectSession)
 
 		// GET /sessions/:id/qr - Obter QR Code atual
-		sessions.GET("/:id/qr", sessionHandler.GetQRCode)
+		core.GET("/:id/qr", deps.SessionHandler.GetQRCode)
+
+		// GET /sessions/:id/qr/stream - Stream de QR codes (SSE)
+		core.GET("/:id/qr/stream", deps.SessionHandler.StreamQRCode)
+
+		// GET /sessions/:id/qr/ws - Stream de QR codes (WebSocket)
+		core.GET("/:id/qr/ws", deps.SessionHandler.StreamQRCodeWS)
 
 		// POST /sessions/:id/pair - Parear com telefone
-		sessions.POST("/:id/pair", sessionHandler.PairPhone)
+		core.POST("/:id/pair", deps.SessionHandler.PairPhone)
 
 		// GET /sessions/:id/status - Obter status da sessão
-		sessions.GET("/:id/status", sessionHandler.GetSessionStatus)
+		core.GET("/:id/status", deps.SessionHandler.GetSessionStatus)
+
+		// GET /sessions/:id/history - Progresso da sincronização de histórico
+		core.GET("/:id/history", deps.SessionHandler.GetHistorySyncProgress)
 
 		// === ROTAS DE WEBHOOK ===
-		webhook := sessions.Group("/:id/webhook")
+		webhook := sessions.Group("/:id/webhook", middleware.RequireScope(model.ScopeWebhooksManage, model.ScopeWebhooksManage), limit)
 		{
 			// POST /sessions/:id/webhook/set - Configurar/Atualizar webhook
-			webhook.POST("/set", sessionHandler.SetWebhook)
+			webhook.POST("/set", deps.SessionHandler.SetWebhook)
 
 			// GET /sessions/:id/webhook/find - Obter configuração de webhook
-			webhook.GET("/find", sessionHandler.FindWebhook)
+			webhook.GET("/find", deps.SessionHandler.FindWebhook)
+		}
+
+		// === ROTAS DO CHATWOOT ===
+		chatwoot := sessions.Group("/:id/chatwoot", middleware.RequireScope(model.ScopeWebhooksManage, model.ScopeWebhooksManage), limit)
+		{
+			// POST /sessions/:id/chatwoot/set - Configurar inbox do Chatwoot
+			chatwoot.POST("/set", deps.ChatwootHandler.SetChatwootConfig)
+
+			// GET /sessions/:id/chatwoot/find - Obter configuração do Chatwoot
+			chatwoot.GET("/find", deps.ChatwootHandler.FindChatwootConfig)
+		}
+
+		// === ROTAS DO FLUXO (TYPEBOT) ===
+		flowbot := sessions.Group("/:id/flowbot", middleware.RequireScope(model.ScopeSessionsRead, model.ScopeSessionsWrite), limit)
+		{
+			// POST /sessions/:id/flowbot/set - Configurar fluxo
+			flowbot.POST("/set", deps.FlowBotHandler.SetFlowBotConfig)
+
+			// GET /sessions/:id/flowbot/find - Obter configuração do fluxo
+			flowbot.GET("/find", deps.FlowBotHandler.FindFlowBotConfig)
+
+			// GET /sessions/:id/flowbot/sessions - Listar conversas do fluxo
+			flowbot.GET("/sessions", deps.FlowBotHandler.ListSessions)
+
+			// POST /sessions/:id/flowbot/sessions/:phone/stop - Passar conversa para um humano
+			flowbot.POST("/sessions/:phone/stop", deps.FlowBotHandler.StopSession)
+
+			// DELETE /sessions/:id/flowbot/sessions/:phone - Encerrar conversa do fluxo
+			flowbot.DELETE("/sessions/:phone", deps.FlowBotHandler.DeleteSession)
+		}
+
+		// === ROTAS DO ASSISTENTE (LLM) ===
+		assistant := sessions.Group("/:id/assistant", middleware.RequireScope(model.ScopeSessionsRead, model.ScopeSessionsWrite), limit)
+		{
+			// POST /sessions/:id/assistant/set - Configurar assistente
+			assistant.POST("/set", deps.AssistantHandler.SetAssistantConfig)
+
+			// GET /sessions/:id/assistant/find - Obter configuração do assistente
+			assistant.GET("/find", deps.AssistantHandler.FindAssistantConfig)
+
+			// GET /sessions/:id/assistant/conversations - Listar conversas do assistente
+			assistant.GET("/conversations", deps.AssistantHandler.ListConversations)
+
+			// POST /sessions/:id/assistant/conversations/:phone/stop - Passar conversa para um humano
+			assistant.POST("/conversations/:phone/stop", deps.AssistantHandler.StopConversation)
+
+			// DELETE /sessions/:id/assistant/conversations/:phone - Encerrar conversa e apagar a memória
+			assistant.DELETE("/conversations/:phone", deps.AssistantHandler.DeleteConversation)
+		}
+
+		// === ROTAS DE STREAM DE EVENTOS ===
+		eventsGroup := sessions.Group("/:id/events", middleware.RequireScope(model.ScopeSessionsRead, model.ScopeSessionsRead), limit)
+		{
+			// GET /sessions/:id/events/stream - Stream de eventos (SSE)
+			eventsGroup.GET("/stream", deps.EventStreamHandler.StreamEvents)
+
+			// GET /sessions/:id/events/ws - Stream de eventos (WebSocket)
+			eventsGroup.GET("/ws", deps.EventStreamHandler.StreamEventsWS)
+		}
+
+		// === ROTAS DE CHAMADAS ===
+		call := sessions.Group("/:id/call", middleware.RequireScope(model.ScopeSessionsRead, model.ScopeSessionsWrite), limit)
+		{
+			// POST /sessions/:id/call/set - Configurar política de chamadas
+			call.POST("/set", deps.SessionHandler.SetCallConfig)
+
+			// GET /sessions/:id/call/find - Obter política de chamadas
+			call.GET("/find", deps.SessionHandler.FindCallConfig)
+		}
+
+		// === ROTAS DE TRANSCRIÇÃO ===
+		transcription := sessions.Group("/:id/transcription", middleware.RequireScope(model.ScopeSessionsRead, model.ScopeSessionsWrite), limit)
+		{
+			// POST /sessions/:id/transcription/set - Configurar transcrição de mensagens de voz
+			transcription.POST("/set", deps.SessionHandler.SetTranscriptionConfig)
+
+			// GET /sessions/:id/transcription/find - Obter configuração de transcrição
+			transcription.GET("/find", deps.SessionHandler.FindTranscriptionConfig)
+		}
+
+		// === ROTAS DA FILA DE ENVIO ===
+		jobs := sessions.Group("/:id/jobs", middleware.RequireScope(model.ScopeSessionsRead, model.ScopeMessagesSend), limit)
+		{
+			// POST /sessions/:id/jobs - Enfileirar mensagem (rate limit da sessão)
+			jobs.POST("", deps.JobHandler.EnqueueMessage)
+
+			// GET /sessions/:id/jobs/:jobId - Consultar status do job
+			jobs.GET("/:jobId", deps.JobHandler.GetJob)
+		}
+
+		rateLimit := sessions.Group("/:id/ratelimit", middleware.RequireScope(model.ScopeSessionsRead, model.ScopeSessionsWrite), limit)
+		{
+			// POST /sessions/:id/ratelimit/set - Configurar ritmo de envio
+			rateLimit.POST("/set", deps.JobHandler.SetRateLimit)
+
+			// GET /sessions/:id/ratelimit/find - Obter ritmo de envio
+			rateLimit.GET("/find", deps.JobHandler.FindRateLimit)
+		}
+
+		// === ROTAS DE CAMPANHAS ===
+		campaigns := sessions.Group("/:id/campaigns", middleware.RequireScope(model.ScopeSessionsRead, model.ScopeMessagesSend), limit)
+		{
+			// POST /sessions/:id/campaigns - Criar campanha
+			campaigns.POST("", deps.CampaignHandler.CreateCampaign)
+
+			// GET /sessions/:id/campaigns - Listar campanhas
+			campaigns.GET("", deps.CampaignHandler.ListCampaigns)
+
+			// GET /sessions/:id/campaigns/:campaignId - Consultar campanha e progresso
+			campaigns.GET("/:campaignId", deps.CampaignHandler.GetCampaign)
+
+			// POST /sessions/:id/campaigns/:campaignId/recipients - Adicionar destinatários (CSV ou JSON)
+			campaigns.POST("/:campaignId/recipients", deps.CampaignHandler.AddRecipients)
+
+			// GET /sessions/:id/campaigns/:campaignId/recipients - Listar destinatários
+			campaigns.GET("/:campaignId/recipients", deps.CampaignHandler.ListRecipients)
+
+			// POST /sessions/:id/campaigns/:campaignId/start - Iniciar campanha
+			campaigns.POST("/:campaignId/start", deps.CampaignHandler.StartCampaign)
+
+			// POST /sessions/:id/campaigns/:campaignId/pause - Pausar campanha
+			campaigns.POST("/:campaignId/pause", deps.CampaignHandler.PauseCampaign)
+
+			// POST /sessions/:id/campaigns/:campaignId/resume - Retomar campanha
+			campaigns.POST("/:campaignId/resume", deps.CampaignHandler.ResumeCampaign)
+
+			// POST /sessions/:id/campaigns/:campaignId/cancel - Cancelar campanha
+			campaigns.POST("/:campaignId/cancel", deps.CampaignHandler.CancelCampaign)
+		}
+
+		// === ROTAS DE RESPOSTA AUTOMÁTICA ===
+		autoReply := sessions.Group("/:id/autoreply", middleware.RequireScope(model.ScopeSessionsRead, model.ScopeSessionsWrite), limit)
+		{
+			// POST /sessions/:id/autoreply - Criar regra
+			autoReply.POST("", deps.AutoReplyHandler.CreateRule)
+
+			// GET /sessions/:id/autoreply - Listar regras
+			autoReply.GET("", deps.AutoReplyHandler.ListRules)
+
+			// GET /sessions/:id/autoreply/:ruleId - Consultar regra
+			autoReply.GET("/:ruleId", deps.AutoReplyHandler.GetRule)
+
+			// PUT /sessions/:id/autoreply/:ruleId - Atualizar regra (inclusive ativar/desativar)
+			autoReply.PUT("/:ruleId", deps.AutoReplyHandler.UpdateRule)
+
+			// DELETE /sessions/:id/autoreply/:ruleId - Remover regra
+			autoReply.DELETE("/:ruleId", deps.AutoReplyHandler.DeleteRule)
 		}
 
 		// === ROTAS DE MENSAGENS ===
+		// Corpos com templateId + variables são expandidos antes do envio
 		messages := sessions.Group("/:id/message")
+		messages.Use(middleware.RequireScope(model.ScopeSessionsRead, model.ScopeMessagesSend), limit, deps.TemplateHandler.ExpandTemplates())
 		{
 			// POST /sessions/:id/message/text - Enviar mensagem de texto
-			messages.POST("/text", messageHandler.SendText)
+			messages.POST("/text", deps.MessageHandler.SendText)
 
 			// POST /sessions/:id/message/image - Enviar imagem
-			messages.POST("/image", messageHandler.SendImage)
+			messages.POST("/image", deps.MessageHandler.SendImage)
 
 			// POST /sessions/:id/message/audio - Enviar áudio
-			messages.POST("/audio", messageHandler.SendAudio)
+			messages.POST("/audio", deps.MessageHandler.SendAudio)
 
 			// POST /sessions/:id/message/video - Enviar vídeo
-			messages.POST("/video", messageHandler.SendVideo)
+			messages.POST("/video", deps.MessageHandler.SendVideo)
 
 			// POST /sessions/:id/message/document - Enviar documento
-			messages.POST("/document", messageHandler.SendDocument)
+			messages.POST("/document", deps.MessageHandler.SendDocument)
 
 			// POST /sessions/:id/message/sticker - Enviar sticker
-			messages.POST("/sticker", messageHandler.SendSticker)
+			messages.POST("/sticker", deps.MessageHandler.SendSticker)
 
 			// POST /sessions/:id/message/media - Enviar mídia genérica (auto-detect)
-			messages.POST("/media", messageHandler.SendMedia)
+			messages.POST("/media", deps.MessageHandler.SendMedia)
 
 			// POST /sessions/:id/message/contact - Enviar contato
-			messages.POST("/contact", messageHandler.SendContact)
+			messages.POST("/contact", deps.MessageHandler.SendContact)
 
 			// POST /sessions/:id/message/location - Enviar localização
-			messages.POST("/location", messageHandler.SendLocation)
+			messages.POST("/location", deps.MessageHandler.SendLocation)
 
 			// POST /sessions/:id/message/poll - Enviar enquete
-			messages.POST("/poll", messageHandler.SendPoll)
+			messages.POST("/poll", deps.MessageHandler.SendPoll)
 
 			// POST /sessions/:id/message/reaction - Enviar reação
-			messages.POST("/reaction", messageHandler.SendReaction)
+			messages.POST("/reaction", deps.MessageHandler.SendReaction)
 
 			// POST /sessions/:id/message/presence - Enviar presença (digitando, gravando, etc)
-			messages.POST("/presence", messageHandler.SendPresence)
+			messages.POST("/presence", deps.MessageHandler.SendPresence)
 
 			// POST /sessions/:id/message/read - Marcar como lida
-			messages.POST("/read", messageHandler.MarkAsRead)
+			messages.POST("/read", deps.MessageHandler.MarkAsRead)
 
 			// DELETE /sessions/:id/message/revoke - Revogar mensagem
-			messages.DELETE("/revoke", messageHandler.RevokeMessage)
+			messages.DELETE("/revoke", deps.MessageHandler.RevokeMessage)
 
 			// PUT /sessions/:id/message/edit - Editar mensagem
-			messages.PUT("/edit", messageHandler.EditMessage)
+			messages.PUT("/edit", deps.MessageHandler.EditMessage)
+
+			// POST /sessions/:id/message/schedule - Agendar mensagem
+			messages.POST("/schedule", deps.ScheduleHandler.ScheduleMessage)
+
+			// GET /sessions/:id/message/schedule - Listar agendamentos
+			messages.GET("/schedule", deps.ScheduleHandler.ListSchedules)
+
+			// GET /sessions/:id/message/schedule/:scheduleId - Consultar agendamento
+			messages.GET("/schedule/:scheduleId", deps.ScheduleHandler.GetSchedule)
+
+			// PUT /sessions/:id/message/schedule/:scheduleId - Reagendar mensagem
+			messages.PUT("/schedule/:scheduleId", deps.ScheduleHandler.RescheduleMessage)
+
+			// DELETE /sessions/:id/message/schedule/:scheduleId - Cancelar agendamento
+			messages.DELETE("/schedule/:scheduleId", deps.ScheduleHandler.CancelSchedule)
+		}
+
+		// === ROTAS DE CANAIS (NEWSLETTER) ===
+		newsletter := sessions.Group("/:id/newsletter", middleware.RequireScope(model.ScopeSessionsRead, model.ScopeMessagesSend), limit)
+		{
+			// GET /sessions/:id/newsletter/list - Listar canais seguidos
+			newsletter.GET("/list", deps.NewsletterHandler.ListNewsletters)
+
+			// GET /sessions/:id/newsletter/info - Obter canal por JID ou convite
+			newsletter.GET("/info", deps.NewsletterHandler.GetNewsletterInfo)
+
+			// POST /sessions/:id/newsletter/follow - Seguir canal
+			newsletter.POST("/follow", deps.NewsletterHandler.FollowNewsletter)
+
+			// POST /sessions/:id/newsletter/unfollow - Deixar de seguir canal
+			newsletter.POST("/unfollow", deps.NewsletterHandler.UnfollowNewsletter)
+
+			// POST /sessions/:id/newsletter/mute - Silenciar/reativar canal
+			newsletter.POST("/mute", deps.NewsletterHandler.MuteNewsletter)
+
+			// POST /sessions/:id/newsletter/create - Criar canal
+			newsletter.POST("/create", deps.NewsletterHandler.CreateNewsletter)
+
+			// POST /sessions/:id/newsletter/send/text - Publicar texto
+			newsletter.POST("/send/text", deps.NewsletterHandler.SendText)
+
+			// POST /sessions/:id/newsletter/send/media - Publicar mídia
+			newsletter.POST("/send/media", deps.NewsletterHandler.SendMedia)
+
+			// GET /sessions/:id/newsletter/messages - Mensagens recentes com reações
+			newsletter.GET("/messages", deps.NewsletterHandler.GetMessages)
 		}
 	}
 }
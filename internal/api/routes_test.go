@@ -0,0 +1,56 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+
+	"zpwoot/internal/config"
+	"zpwoot/internal/model"
+)
+
+// restrictedKeys autentica qualquer chave como um token restrito à sessão s1
+type restrictedKeys struct{}
+
+func (restrictedKeys) ResolveAPIKey(ctx context.Context, apiKey string) (*model.APIKey, error) {
+	return &model.APIKey{
+		Name:       "restricted",
+		Scopes:     []model.Scope{model.ScopeSessionsRead, model.ScopeSessionsWrite, model.ScopeAdmin},
+		SessionIDs: []string{"s1"},
+	}, nil
+}
+
+func TestRestrictedTokenRoutes(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	config.AppConfig = &config.Config{}
+	r := gin.New()
+	RegisterRoutes(r, Deps{KeyResolver: restrictedKeys{}})
+
+	// Templates não são filtrados por sessão: um token de s1 alcançaria os de outras sessões e os globais
+	tests := []struct {
+		method string
+		path   string
+	}{
+		{http.MethodGet, "/templates"},
+		{http.MethodGet, "/templates/tpl-of-s2"},
+		{http.MethodPut, "/templates/tpl-of-s2"},
+		{http.MethodDelete, "/templates/tpl-of-s2"},
+		{http.MethodGet, "/templates/tpl-of-s2/versions"},
+		{http.MethodGet, "/keys"},
+		{http.MethodPost, "/admin/tenants"},
+	}
+
+	for _, tt := range tests {
+		req := httptest.NewRequest(tt.method, tt.path, nil)
+		req.Header.Set("apikey", "zpw_restricted")
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+
+		if w.Code != http.StatusForbidden {
+			t.Errorf("%s %s = %d, want 403", tt.method, tt.path, w.Code)
+		}
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"os"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/joho/godotenv"
@@ -14,6 +15,7 @@ type Config struct {
 	DatabaseURL     string
 	DatabaseDriver  string
 	APIKey          string
+	TrustedProxies  []string // IPs/CIDRs de proxies reversos cujos X-Forwarded-For/X-Real-IP são aceitos (vazio = nenhum)
 	Environment     string
 	LogLevel        string
 	WhatsAppDataDir string
@@ -24,16 +26,119 @@ type Config struct {
 	PairingTimeout      int
 	AutoRestoreSessions bool
 
+	// Reconnect Configuration
+	ReconnectBaseDelay time.Duration
+	ReconnectMaxDelay  time.Duration
+
+	// Keepalives seguidos sem resposta antes de forçar a reconexão (0 = desativado)
+	KeepAliveMaxFailures int
+
 	// NATS Configuration
 	NATSURL           string
 	NATSMaxReconnect  int
 	NATSReconnectWait time.Duration
 
+	// NATS Command API (request/reply)
+	NATSCommandsEnabled bool
+	NATSCommandPrefix   string
+	NATSCommandQueue    string
+	NATSCommandTimeout  time.Duration
+
 	// Webhook Configuration
 	WebhookWorkers        int
 	WebhookTimeout        time.Duration
 	WebhookMaxRetries     int
 	WebhookRetryBaseDelay time.Duration
+
+	// Outbound Queue Configuration (fila de envio com rate limit)
+	OutboundRatePerMinute    int
+	OutboundBurst            int
+	OutboundRecipientSpacing int // Segundos
+	OutboundMaxAttempts      int
+	OutboundRetryDelay       time.Duration
+
+	// Scheduler Configuration (mensagens agendadas)
+	SchedulerInterval        time.Duration
+	SchedulerBatchSize       int
+	SchedulerDefaultTimezone string
+
+	// Campaign Configuration (envios em massa)
+	CampaignPollInterval  time.Duration
+	CampaignLeaseDuration time.Duration
+
+	// Auto-reply Configuration (regras de resposta automática)
+	AutoReplyCacheTTL time.Duration
+
+	// Chatwoot Configuration (inbox por sessão)
+	ChatwootTimeout time.Duration
+
+	// Flow Bot Configuration (Typebot por sessão)
+	FlowBotTimeout time.Duration
+
+	// Assistant Configuration (LLM por sessão)
+	AssistantTimeout time.Duration
+
+	// Transcription Configuration (mensagens de voz)
+	TranscriptionTimeout     time.Duration
+	TranscriptionMaxSizeMB   int64
+	TranscriptionConcurrency int
+
+	// JWT Configuration (bearer tokens do IdP) - desabilitado se JWTJWKSURL e JWTPublicKey vazios
+	JWTJWKSURL       string
+	JWTPublicKey     string // PEM ou caminho do arquivo
+	JWTIssuer        string
+	JWTAudience      string
+	JWTScopesClaim   string
+	JWTSessionsClaim string
+	JWTTenantClaim   string
+	JWTJWKSRefresh   time.Duration
+	JWTLeeway        time.Duration
+
+	// Secrets Configuration (cifragem de senhas, tokens e API keys no banco) - texto puro se SecretsMasterKey vazio
+	SecretsMasterKey    string   // Base64 de 32 bytes
+	SecretsPreviousKeys []string // Chaves anteriores, apenas para decifrar durante a rotação
+
+	// Audit Log Configuration (chamadas que alteram estado)
+	AuditEnabled    bool
+	AuditRetention  time.Duration // 0 = manter para sempre
+	AuditBufferSize int
+
+	// Rate Limit Configuration (token buckets por API key e por IP; PER_MINUTE=0 desativa o limite)
+	RateLimitEnabled       bool
+	RateLimitStore         string // memory (por réplica) ou nats (KV compartilhado entre réplicas)
+	RateLimitReadPerMinute int    // Leituras e demais chamadas, por API key
+	RateLimitReadBurst     int
+	RateLimitSendPerMinute int // Envios de mensagem, por API key
+	RateLimitSendBurst     int
+	RateLimitIPPerMinute   int // Todas as chamadas, por IP
+	RateLimitIPBurst       int
+
+	// Event Stream Configuration (SSE/WebSocket)
+	EventStreamBufferSize int
+	EventStreamHeartbeat  time.Duration
+	WSOriginPatterns      []string // Hosts de outras origens aceitos nos WebSockets (ex.: *.example.com)
+
+	// Event Sink Configuration (destinos externos de eventos)
+	EventSinkPartitions     int
+	EventSinkPublishTimeout time.Duration
+	EventSinkMaxRetries     int
+	EventSinkRetryBaseDelay time.Duration
+
+	// AMQP Sink Configuration (RabbitMQ) - desabilitado se AMQPURL vazio
+	AMQPURL          string
+	AMQPExchange     string
+	AMQPExchangeType string
+	AMQPRoutingKey   string
+
+	// Kafka Sink Configuration - desabilitado se KafkaBrokers vazio
+	KafkaBrokers         []string
+	KafkaTopic           string
+	KafkaClientID        string
+	KafkaBatchMaxBytes   int
+	KafkaLinger          time.Duration
+	KafkaCompression     string
+	KafkaIdempotent      bool
+	KafkaDeliveryTimeout time.Duration
 }
 
 var AppConfig *Config
@@ -50,21 +155,124 @@ func Load() error {
 		LogLevel:            getEnv("LOG_LEVEL", "info"),
 		WhatsAppDataDir:     getEnv("WHATSAPP_DATA_DIR", "./data"),
 		APIKey:              os.Getenv("API_KEY"),
+		TrustedProxies:      getEnvList("TRUSTED_PROXIES"),
 		MaxSessions:         getEnvInt("MAX_SESSIONS", 10),
 		ConnectionTimeout:   getEnvInt("CONNECTION_TIMEOUT", 30),
 		PairingTimeout:      getEnvInt("PAIRING_TIMEOUT", 120),
 		AutoRestoreSessions: getEnvBool("AUTO_RESTORE_SESSIONS", true),
 
+		// Reconnect
+		ReconnectBaseDelay: getEnvDuration("RECONNECT_BASE_DELAY", 2*time.Second),
+		ReconnectMaxDelay:  getEnvDuration("RECONNECT_MAX_DELAY", 5*time.Minute),
+
+		KeepAliveMaxFailures: getEnvInt("KEEPALIVE_MAX_FAILURES", 3),
+
 		// NATS
 		NATSURL:           getEnv("NATS_URL", "nats://localhost:4222"),
 		NATSMaxReconnect:  getEnvInt("NATS_MAX_RECONNECT", 10),
 		NATSReconnectWait: getEnvDuration("NATS_RECONNECT_WAIT", 2*time.Second),
 
+		// NATS command API
+		NATSCommandsEnabled: getEnvBool("NATS_COMMANDS_ENABLED", true),
+		NATSCommandPrefix:   getEnv("NATS_COMMAND_PREFIX", "zpwoot.cmd"),
+		NATSCommandQueue:    getEnv("NATS_COMMAND_QUEUE", "zpwoot-commands"),
+		NATSCommandTimeout:  getEnvDuration("NATS_COMMAND_TIMEOUT", time.Minute),
+
 		// Webhooks
 		WebhookWorkers:        getEnvInt("WEBHOOK_WORKERS", 10),
 		WebhookTimeout:        getEnvDuration("WEBHOOK_TIMEOUT", 30*time.Second),
 		WebhookMaxRetries:     getEnvInt("WEBHOOK_MAX_RETRIES", 3),
 		WebhookRetryBaseDelay: getEnvDuration("WEBHOOK_RETRY_BASE_DELAY", 5*time.Second),
+
+		// Outbound queue
+		OutboundRatePerMinute:    getEnvInt("OUTBOUND_RATE_PER_MINUTE", 20),
+		OutboundBurst:            getEnvInt("OUTBOUND_BURST", 5),
+		OutboundRecipientSpacing: getEnvInt("OUTBOUND_RECIPIENT_SPACING", 3),
+		OutboundMaxAttempts:      getEnvInt("OUTBOUND_MAX_ATTEMPTS", 3),
+		OutboundRetryDelay:       getEnvDuration("OUTBOUND_RETRY_DELAY", 30*time.Second),
+
+		// Scheduler
+		SchedulerInterval:        getEnvDuration("SCHEDULER_INTERVAL", 5*time.Second),
+		SchedulerBatchSize:       getEnvInt("SCHEDULER_BATCH_SIZE", 100),
+		SchedulerDefaultTimezone: getEnv("SCHEDULER_DEFAULT_TIMEZONE", "UTC"),
+
+		// Campaigns
+		CampaignPollInterval:  getEnvDuration("CAMPAIGN_POLL_INTERVAL", 5*time.Second),
+		CampaignLeaseDuration: getEnvDuration("CAMPAIGN_LEASE_DURATION", 2*time.Minute),
+
+		// Auto-reply
+		AutoReplyCacheTTL: getEnvDuration("AUTOREPLY_CACHE_TTL", 30*time.Second),
+
+		// Chatwoot
+		ChatwootTimeout: getEnvDuration("CHATWOOT_TIMEOUT", 30*time.Second),
+
+		// Flow bot
+		FlowBotTimeout: getEnvDuration("FLOWBOT_TIMEOUT", 30*time.Second),
+
+		// Assistant
+		AssistantTimeout: getEnvDuration("ASSISTANT_TIMEOUT", 60*time.Second),
+
+		// Transcription
+		TranscriptionTimeout:     getEnvDuration("TRANSCRIPTION_TIMEOUT", 60*time.Second),
+		TranscriptionMaxSizeMB:   int64(getEnvInt("TRANSCRIPTION_MAX_SIZE_MB", 25)),
+		TranscriptionConcurrency: getEnvInt("TRANSCRIPTION_CONCURRENCY", 4),
+
+		// JWT
+		JWTJWKSURL:       os.Getenv("JWT_JWKS_URL"),
+		JWTPublicKey:     os.Getenv("JWT_PUBLIC_KEY"),
+		JWTIssuer:        os.Getenv("JWT_ISSUER"),
+		JWTAudience:      os.Getenv("JWT_AUDIENCE"),
+		JWTScopesClaim:   getEnv("JWT_SCOPES_CLAIM", "scope"),
+		JWTSessionsClaim: getEnv("JWT_SESSIONS_CLAIM", "sessions"),
+		JWTTenantClaim:   getEnv("JWT_TENANT_CLAIM", "tenant_id"),
+		JWTJWKSRefresh:   getEnvDuration("JWT_JWKS_REFRESH", time.Hour),
+		JWTLeeway:        getEnvDuration("JWT_LEEWAY", time.Minute),
+
+		// Secrets
+		SecretsMasterKey:    os.Getenv("SECRETS_MASTER_KEY"),
+		SecretsPreviousKeys: getEnvList("SECRETS_PREVIOUS_KEYS"),
+
+		// Audit log
+		AuditEnabled:    getEnvBool("AUDIT_ENABLED", true),
+		AuditRetention:  getEnvDuration("AUDIT_RETENTION", 90*24*time.Hour),
+		AuditBufferSize: getEnvInt("AUDIT_BUFFER_SIZE", 1000),
+
+		// Rate limit
+		RateLimitEnabled:       getEnvBool("RATE_LIMIT_ENABLED", true),
+		RateLimitStore:         getEnv("RATE_LIMIT_STORE", "memory"),
+		RateLimitReadPerMinute: getEnvInt("RATE_LIMIT_READ_PER_MINUTE", 600),
+		RateLimitReadBurst:     getEnvInt("RATE_LIMIT_READ_BURST", 100),
+		RateLimitSendPerMinute: getEnvInt("RATE_LIMIT_SEND_PER_MINUTE", 60),
+		RateLimitSendBurst:     getEnvInt("RATE_LIMIT_SEND_BURST", 10),
+		RateLimitIPPerMinute:   getEnvInt("RATE_LIMIT_IP_PER_MINUTE", 1200),
+		RateLimitIPBurst:       getEnvInt("RATE_LIMIT_IP_BURST", 200),
+
+		// Event stream
+		EventStreamBufferSize: getEnvInt("EVENT_STREAM_BUFFER_SIZE", 500),
+		EventStreamHeartbeat:  getEnvDuration("EVENT_STREAM_HEARTBEAT", 15*time.Second),
+		WSOriginPatterns:      getEnvList("WS_ORIGIN_PATTERNS"),
+
+		// Event sinks
+		EventSinkPartitions:     getEnvInt("EVENT_SINK_PARTITIONS", 8),
+		EventSinkPublishTimeout: getEnvDuration("EVENT_SINK_PUBLISH_TIMEOUT", 10*time.Second),
+		EventSinkMaxRetries:     getEnvInt("EVENT_SINK_MAX_RETRIES", 5),
+		EventSinkRetryBaseDelay: getEnvDuration("EVENT_SINK_RETRY_BASE_DELAY", time.Second),
+
+		// AMQP
+		AMQPURL:          os.Getenv("AMQP_URL"),
+		AMQPExchange:     getEnv("AMQP_EXCHANGE", "zpwoot.events"),
+		AMQPExchangeType: getEnv("AMQP_EXCHANGE_TYPE", "topic"),
+		AMQPRoutingKey:   getEnv("AMQP_ROUTING_KEY", "zpwoot.{session}.{event}"),
+
+		// Kafka
+		KafkaBrokers:         getEnvList("KAFKA_BROKERS"),
+		KafkaTopic:           getEnv("KAFKA_TOPIC", "zpwoot.events"),
+		KafkaClientID:        getEnv("KAFKA_CLIENT_ID", "zpwoot"),
+		KafkaBatchMaxBytes:   getEnvInt("KAFKA_BATCH_MAX_BYTES", 1048576),
+		KafkaLinger:          getEnvDuration("KAFKA_LINGER", 10*time.Millisecond),
+		KafkaCompression:     getEnv("KAFKA_COMPRESSION", "snappy"),
+		KafkaIdempotent:      getEnvBool("KAFKA_IDEMPOTENT", true),
+		KafkaDeliveryTimeout: getEnvDuration("KAFKA_DELIVERY_TIMEOUT", 2*time.Minute),
 	}
 
 	if cfg.DatabaseURL == "" {
@@ -125,3 +333,14 @@ func getEnvDuration(key string, defaultVal time.Duration) time.Duration {
 	}
 	return d
 }
+
+// getEnvList lê uma lista separada por vírgulas, ignorando itens vazios
+func getEnvList(key string) []string {
+	var list []string
+	for _, item := range strings.Split(os.Getenv(key), ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			list = append(list, item)
+		}
+	}
+	return list
+}
@@ -345,6 +345,17 @@ const (
 	EventFBMessage WebhookEventType = "fb_message"
 )
 
+// ============================================================================
+// FILA DE ENVIO
+// Eventos gerados pelo zpwoot para envios enfileirados
+// ============================================================================
+
+const (
+	// EventMessageJob - Job da fila de envio finalizado (sent ou failed)
+	// Tipo: *model.MessageJob
+	EventMessageJob WebhookEventType = "message_job"
+)
+
 // ============================================================================
 // ESPECIAIS
 // Eventos especiais e meta-eventos
@@ -450,6 +461,9 @@ var AllWebhookEvents = map[string][]WebhookEventType{
 	"facebook": {
 		EventFBMessage,
 	},
+	"jobs": {
+		EventMessageJob,
+	},
 	"special": {
 		EventAll,
 	},
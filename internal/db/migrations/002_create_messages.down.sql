@@ -0,0 +1,18 @@
+-- Migration Rollback: Drop message store
+-- Description: Removes chats, messages and history_sync_progress tables
+-- Author: zpwoot
+-- Date: 2025-11-10
+
+DROP TABLE IF EXISTS history_sync_progress;
+
+DROP TRIGGER IF EXISTS update_messages_updated_at ON messages;
+DROP INDEX IF EXISTS idx_messages_timestamp;
+DROP INDEX IF EXISTS idx_messages_chat_timestamp;
+DROP TABLE IF EXISTS messages;
+
+DROP TRIGGER IF EXISTS update_chats_updated_at ON chats;
+DROP INDEX IF EXISTS idx_chats_last_message_at;
+DROP TABLE IF EXISTS chats;
+
+ALTER TABLE sessions
+DROP COLUMN IF EXISTS history_sync_config;
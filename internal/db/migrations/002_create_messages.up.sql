@@ -0,0 +1,83 @@
+-- Migration: Create message store
+-- Description: Creates chats, messages and history_sync_progress tables used by history sync ingestion
+-- Author: zpwoot
+-- Date: 2025-11-10
+
+-- History sync preferences per session: {mode, days}
+ALTER TABLE sessions
+ADD COLUMN IF NOT EXISTS history_sync_config JSONB DEFAULT NULL;
+
+COMMENT ON COLUMN sessions.history_sync_config IS 'JSON configuration for history sync: {mode: full|recent|none, days}';
+
+-- Chats (conversations) known by each session
+CREATE TABLE IF NOT EXISTS chats (
+    session_id TEXT NOT NULL REFERENCES sessions(id) ON DELETE CASCADE,
+    jid TEXT NOT NULL,
+
+    name TEXT,
+    unread_count INTEGER NOT NULL DEFAULT 0,
+    archived BOOLEAN NOT NULL DEFAULT FALSE,
+    last_message_at TIMESTAMPTZ,
+
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+
+    PRIMARY KEY (session_id, jid)
+);
+
+CREATE INDEX IF NOT EXISTS idx_chats_last_message_at ON chats(session_id, last_message_at DESC);
+
+CREATE TRIGGER update_chats_updated_at
+    BEFORE UPDATE ON chats
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
+
+-- Messages received live or imported from history sync
+CREATE TABLE IF NOT EXISTS messages (
+    session_id TEXT NOT NULL REFERENCES sessions(id) ON DELETE CASCADE,
+    chat_jid TEXT NOT NULL,
+    id TEXT NOT NULL,
+
+    sender_jid TEXT,
+    from_me BOOLEAN NOT NULL DEFAULT FALSE,
+    push_name TEXT,
+    type TEXT NOT NULL DEFAULT 'unknown',
+    body TEXT,
+    source TEXT NOT NULL DEFAULT 'live',
+    raw JSONB DEFAULT NULL,
+    timestamp TIMESTAMPTZ NOT NULL,
+
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+
+    PRIMARY KEY (session_id, chat_jid, id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_messages_chat_timestamp ON messages(session_id, chat_jid, timestamp DESC);
+CREATE INDEX IF NOT EXISTS idx_messages_timestamp ON messages(session_id, timestamp DESC);
+
+CREATE TRIGGER update_messages_updated_at
+    BEFORE UPDATE ON messages
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
+
+-- History sync progress per session
+CREATE TABLE IF NOT EXISTS history_sync_progress (
+    session_id TEXT PRIMARY KEY REFERENCES sessions(id) ON DELETE CASCADE,
+
+    sync_type TEXT NOT NULL,
+    chunk_order INTEGER NOT NULL DEFAULT 0,
+    progress INTEGER NOT NULL DEFAULT 0,
+    chunks_processed INTEGER NOT NULL DEFAULT 0,
+    conversations INTEGER NOT NULL DEFAULT 0,
+    messages INTEGER NOT NULL DEFAULT 0,
+
+    started_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+COMMENT ON TABLE chats IS 'Chats known by each session (from history sync and live messages)';
+COMMENT ON TABLE messages IS 'Message store: messages imported from history sync or received live';
+COMMENT ON COLUMN messages.source IS 'Origin of the message: history or live';
+COMMENT ON COLUMN messages.raw IS 'Full message content encoded with protojson';
+COMMENT ON TABLE history_sync_progress IS 'History sync progress per session (last chunk and cumulative counts)';
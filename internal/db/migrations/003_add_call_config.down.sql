@@ -0,0 +1,7 @@
+-- Migration Rollback: Remove call config from sessions
+-- Description: Removes call_config column from sessions
+-- Author: zpwoot
+-- Date: 2025-11-11
+
+ALTER TABLE sessions
+DROP COLUMN IF EXISTS call_config;
@@ -0,0 +1,9 @@
+-- Migration: Add call config to sessions
+-- Description: Adds per-session policy for incoming calls (accept, reject, reject_message)
+-- Author: zpwoot
+-- Date: 2025-11-11
+
+ALTER TABLE sessions
+ADD COLUMN IF NOT EXISTS call_config JSONB DEFAULT NULL;
+
+COMMENT ON COLUMN sessions.call_config IS 'JSON configuration for incoming calls: {policy: accept|reject|reject_message, message}';
@@ -0,0 +1,12 @@
+-- Migration Rollback: Drop outbound message jobs
+-- Description: Removes message_jobs table and sessions.rate_limit_config column
+-- Author: zpwoot
+-- Date: 2025-11-12
+
+DROP TRIGGER IF EXISTS update_message_jobs_updated_at ON message_jobs;
+DROP INDEX IF EXISTS idx_message_jobs_created_at;
+DROP INDEX IF EXISTS idx_message_jobs_session_status;
+DROP TABLE IF EXISTS message_jobs;
+
+ALTER TABLE sessions
+DROP COLUMN IF EXISTS rate_limit_config;
@@ -0,0 +1,43 @@
+-- Migration: Create outbound message jobs
+-- Description: Creates message_jobs table for the outbound queue and adds per-session rate limit config
+-- Author: zpwoot
+-- Date: 2025-11-12
+
+-- Per-session outbound pacing: {messages_per_minute, burst, recipient_spacing}
+ALTER TABLE sessions
+ADD COLUMN IF NOT EXISTS rate_limit_config JSONB DEFAULT NULL;
+
+COMMENT ON COLUMN sessions.rate_limit_config IS 'JSON configuration for outbound pacing: {messages_per_minute, burst, recipient_spacing}';
+
+-- Messages queued for paced delivery
+CREATE TABLE IF NOT EXISTS message_jobs (
+    id TEXT PRIMARY KEY,
+    session_id TEXT NOT NULL REFERENCES sessions(id) ON DELETE CASCADE,
+
+    type TEXT NOT NULL,
+    phone TEXT NOT NULL,
+    payload JSONB NOT NULL,
+
+    status TEXT NOT NULL DEFAULT 'queued',
+    message_id TEXT,
+    error TEXT,
+    attempts INTEGER NOT NULL DEFAULT 0,
+
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    sent_at TIMESTAMPTZ,
+
+    CONSTRAINT message_jobs_status_check CHECK (status IN ('queued', 'sending', 'sent', 'failed'))
+);
+
+CREATE INDEX IF NOT EXISTS idx_message_jobs_session_status ON message_jobs(session_id, status);
+CREATE INDEX IF NOT EXISTS idx_message_jobs_created_at ON message_jobs(session_id, created_at DESC);
+
+CREATE TRIGGER update_message_jobs_updated_at
+    BEFORE UPDATE ON message_jobs
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
+
+COMMENT ON TABLE message_jobs IS 'Outbound queue jobs: messages sent asynchronously with per-session rate limiting';
+COMMENT ON COLUMN message_jobs.type IS 'Message type (text, image, audio, ...), same as the send endpoints';
+COMMENT ON COLUMN message_jobs.payload IS 'Request body of the matching send endpoint';
@@ -0,0 +1,9 @@
+-- Migration Rollback: Drop scheduled messages
+-- Description: Removes scheduled_messages table
+-- Author: zpwoot
+-- Date: 2025-11-13
+
+DROP TRIGGER IF EXISTS update_scheduled_messages_updated_at ON scheduled_messages;
+DROP INDEX IF EXISTS idx_scheduled_messages_session;
+DROP INDEX IF EXISTS idx_scheduled_messages_due;
+DROP TABLE IF EXISTS scheduled_messages;
@@ -0,0 +1,39 @@
+-- Migration: Create scheduled messages
+-- Description: Creates scheduled_messages table executed by the scheduler (row locking for multiple replicas)
+-- Author: zpwoot
+-- Date: 2025-11-13
+
+CREATE TABLE IF NOT EXISTS scheduled_messages (
+    id TEXT PRIMARY KEY,
+    session_id TEXT NOT NULL REFERENCES sessions(id) ON DELETE CASCADE,
+
+    type TEXT NOT NULL,
+    phone TEXT NOT NULL,
+    payload JSONB NOT NULL,
+
+    send_at TIMESTAMPTZ NOT NULL,
+    timezone TEXT NOT NULL DEFAULT 'UTC',
+
+    status TEXT NOT NULL DEFAULT 'scheduled',
+    job_id TEXT,
+    error TEXT,
+
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    executed_at TIMESTAMPTZ,
+
+    CONSTRAINT scheduled_messages_status_check CHECK (status IN ('scheduled', 'processing', 'queued', 'failed', 'canceled'))
+);
+
+-- Índice parcial usado pelo scheduler para buscar os agendamentos vencidos
+CREATE INDEX IF NOT EXISTS idx_scheduled_messages_due ON scheduled_messages(send_at) WHERE status IN ('scheduled', 'processing');
+CREATE INDEX IF NOT EXISTS idx_scheduled_messages_session ON scheduled_messages(session_id, send_at);
+
+CREATE TRIGGER update_scheduled_messages_updated_at
+    BEFORE UPDATE ON scheduled_messages
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
+
+COMMENT ON TABLE scheduled_messages IS 'Messages scheduled for a future time; handed to the outbound queue when due';
+COMMENT ON COLUMN scheduled_messages.timezone IS 'IANA timezone used to interpret send_at when created (informational)';
+COMMENT ON COLUMN scheduled_messages.job_id IS 'Outbound queue job created when the schedule was executed';
@@ -0,0 +1,12 @@
+-- Migration Rollback: Drop campaigns
+-- Description: Removes campaigns and campaign_recipients tables
+-- Author: zpwoot
+-- Date: 2025-11-14
+
+DROP INDEX IF EXISTS idx_campaign_recipients_status;
+DROP TABLE IF EXISTS campaign_recipients;
+
+DROP TRIGGER IF EXISTS update_campaigns_updated_at ON campaigns;
+DROP INDEX IF EXISTS idx_campaigns_running;
+DROP INDEX IF EXISTS idx_campaigns_session;
+DROP TABLE IF EXISTS campaigns;
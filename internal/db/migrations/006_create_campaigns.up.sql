@@ -0,0 +1,61 @@
+-- Migration: Create campaigns
+-- Description: Creates campaigns and campaign_recipients tables for bulk sends with progress tracking
+-- Author: zpwoot
+-- Date: 2025-11-14
+
+CREATE TABLE IF NOT EXISTS campaigns (
+    id TEXT PRIMARY KEY,
+    session_id TEXT NOT NULL REFERENCES sessions(id) ON DELETE CASCADE,
+    name TEXT NOT NULL,
+
+    -- Mensagem enviada a cada destinatário (tipo, texto/legenda com {{variaveis}}, mídia)
+    template JSONB NOT NULL,
+
+    -- Intervalo aleatório entre envios (segundos)
+    min_delay INTEGER NOT NULL DEFAULT 3,
+    max_delay INTEGER NOT NULL DEFAULT 10,
+
+    status TEXT NOT NULL DEFAULT 'draft',
+
+    -- Lease da réplica que está executando a campanha
+    lease_owner TEXT,
+    lease_until TIMESTAMPTZ,
+
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    started_at TIMESTAMPTZ,
+    completed_at TIMESTAMPTZ,
+
+    CONSTRAINT campaigns_status_check CHECK (status IN ('draft', 'running', 'paused', 'completed', 'canceled')),
+    CONSTRAINT campaigns_delay_check CHECK (min_delay >= 0 AND max_delay >= min_delay)
+);
+
+CREATE INDEX IF NOT EXISTS idx_campaigns_session ON campaigns(session_id, created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_campaigns_running ON campaigns(lease_until) WHERE status = 'running';
+
+CREATE TRIGGER update_campaigns_updated_at
+    BEFORE UPDATE ON campaigns
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
+
+CREATE TABLE IF NOT EXISTS campaign_recipients (
+    id BIGSERIAL PRIMARY KEY,
+    campaign_id TEXT NOT NULL REFERENCES campaigns(id) ON DELETE CASCADE,
+    phone TEXT NOT NULL,
+    variables JSONB NOT NULL DEFAULT '{}'::jsonb,
+
+    status TEXT NOT NULL DEFAULT 'pending',
+    message_id TEXT,
+    error TEXT,
+    sent_at TIMESTAMPTZ,
+
+    CONSTRAINT campaign_recipients_status_check CHECK (status IN ('pending', 'sending', 'sent', 'failed', 'not_on_whatsapp')),
+    CONSTRAINT campaign_recipients_unique_phone UNIQUE (campaign_id, phone)
+);
+
+CREATE INDEX IF NOT EXISTS idx_campaign_recipients_status ON campaign_recipients(campaign_id, status, id);
+
+COMMENT ON TABLE campaigns IS 'Bulk/broadcast campaigns sent through a session with randomized delays';
+COMMENT ON COLUMN campaigns.template IS 'Message sent to each recipient: {type, text, mediaUrl, caption, fileName} with {{variable}} placeholders';
+COMMENT ON COLUMN campaigns.lease_owner IS 'Scheduler instance currently running the campaign';
+COMMENT ON TABLE campaign_recipients IS 'Campaign recipients with per-recipient template variables and outcome';
@@ -0,0 +1,10 @@
+-- Migration Rollback: Drop message templates
+-- Description: Removes message_templates and message_template_versions tables
+-- Author: zpwoot
+-- Date: 2025-11-15
+
+DROP TABLE IF EXISTS message_template_versions;
+
+DROP TRIGGER IF EXISTS update_message_templates_updated_at ON message_templates;
+DROP INDEX IF EXISTS idx_message_templates_scope_name;
+DROP TABLE IF EXISTS message_templates;
@@ -0,0 +1,40 @@
+-- Migration: Create message templates
+-- Description: Creates versioned message templates, global or scoped to a session
+-- Author: zpwoot
+-- Date: 2025-11-15
+
+CREATE TABLE IF NOT EXISTS message_templates (
+    id TEXT PRIMARY KEY,
+    -- NULL = template global (disponível para todas as sessões)
+    session_id TEXT REFERENCES sessions(id) ON DELETE CASCADE,
+    name TEXT NOT NULL,
+
+    -- Versão atual (a última de message_template_versions)
+    version INTEGER NOT NULL DEFAULT 1,
+
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+-- Nome único por escopo (globais compartilham o escopo '')
+CREATE UNIQUE INDEX IF NOT EXISTS idx_message_templates_scope_name ON message_templates(COALESCE(session_id, ''), name);
+
+CREATE TRIGGER update_message_templates_updated_at
+    BEFORE UPDATE ON message_templates
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
+
+CREATE TABLE IF NOT EXISTS message_template_versions (
+    template_id TEXT NOT NULL REFERENCES message_templates(id) ON DELETE CASCADE,
+    version INTEGER NOT NULL,
+
+    -- {text, mediaUrl, caption, fileName, buttons} com placeholders {{variavel}}
+    content JSONB NOT NULL,
+
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+
+    PRIMARY KEY (template_id, version)
+);
+
+COMMENT ON TABLE message_templates IS 'Reusable message templates with {{variable}} placeholders, global or per session';
+COMMENT ON TABLE message_template_versions IS 'Immutable template versions; every update creates a new version';
@@ -0,0 +1,11 @@
+-- Migration Rollback: Drop auto-reply rules
+-- Description: Removes autoreply_rules, autoreply_cooldowns and autoreply_contacts tables
+-- Author: zpwoot
+-- Date: 2025-11-16
+
+DROP TABLE IF EXISTS autoreply_contacts;
+DROP TABLE IF EXISTS autoreply_cooldowns;
+
+DROP TRIGGER IF EXISTS update_autoreply_rules_updated_at ON autoreply_rules;
+DROP INDEX IF EXISTS idx_autoreply_rules_session;
+DROP TABLE IF EXISTS autoreply_rules;
@@ -0,0 +1,56 @@
+-- Migration: Create auto-reply rules
+-- Description: Creates per-session auto-reply rules, cooldowns per contact and seen contacts
+-- Author: zpwoot
+-- Date: 2025-11-16
+
+CREATE TABLE IF NOT EXISTS autoreply_rules (
+    id TEXT PRIMARY KEY,
+    session_id TEXT NOT NULL REFERENCES sessions(id) ON DELETE CASCADE,
+    name TEXT NOT NULL,
+
+    -- Condição: palavra-chave exata, regex, primeira mensagem do contato ou fora do horário comercial
+    match_type TEXT NOT NULL CHECK (match_type IN ('keyword', 'regex', 'first_message', 'outside_hours')),
+    pattern TEXT NOT NULL DEFAULT '',
+    -- {timezone, ranges: [{weekdays, start, end}]} (apenas outside_hours)
+    business_hours JSONB,
+
+    -- {type, text, mediaUrl, caption, fileName, templateId, variables}
+    response JSONB NOT NULL,
+
+    -- Intervalo mínimo (segundos) entre respostas da regra para o mesmo contato
+    cooldown_seconds INTEGER NOT NULL DEFAULT 0,
+    enabled BOOLEAN NOT NULL DEFAULT TRUE,
+    -- Regras de maior prioridade são avaliadas primeiro
+    priority INTEGER NOT NULL DEFAULT 0,
+
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_autoreply_rules_session ON autoreply_rules(session_id, priority DESC);
+
+CREATE TRIGGER update_autoreply_rules_updated_at
+    BEFORE UPDATE ON autoreply_rules
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
+
+CREATE TABLE IF NOT EXISTS autoreply_cooldowns (
+    rule_id TEXT NOT NULL REFERENCES autoreply_rules(id) ON DELETE CASCADE,
+    contact TEXT NOT NULL,
+    last_sent_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+
+    PRIMARY KEY (rule_id, contact)
+);
+
+-- Contatos que já enviaram mensagem para a sessão (regra first_message)
+CREATE TABLE IF NOT EXISTS autoreply_contacts (
+    session_id TEXT NOT NULL REFERENCES sessions(id) ON DELETE CASCADE,
+    contact TEXT NOT NULL,
+    first_seen_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+
+    PRIMARY KEY (session_id, contact)
+);
+
+COMMENT ON TABLE autoreply_rules IS 'Per-session auto-reply rules evaluated on every incoming message';
+COMMENT ON TABLE autoreply_cooldowns IS 'Last auto-reply sent by each rule to each contact';
+COMMENT ON TABLE autoreply_contacts IS 'Contacts already seen by a session while auto-reply rules were active';
@@ -0,0 +1,12 @@
+-- Migration Rollback: Remove Chatwoot integration
+-- Description: Removes Chatwoot mapping tables and chatwoot_config from sessions
+-- Author: zpwoot
+-- Date: 2025-11-17
+
+DROP TABLE IF EXISTS chatwoot_messages;
+
+DROP TRIGGER IF EXISTS update_chatwoot_conversations_updated_at ON chatwoot_conversations;
+DROP TABLE IF EXISTS chatwoot_conversations;
+
+ALTER TABLE sessions
+DROP COLUMN IF EXISTS chatwoot_config;
@@ -0,0 +1,48 @@
+-- Migration: Add Chatwoot integration
+-- Description: Adds per-session Chatwoot config and the mapping of contacts, conversations and messages
+-- Author: zpwoot
+-- Date: 2025-11-17
+
+ALTER TABLE sessions
+ADD COLUMN IF NOT EXISTS chatwoot_config JSONB DEFAULT NULL;
+
+COMMENT ON COLUMN sessions.chatwoot_config IS 'JSON configuration for the Chatwoot inbox: {enabled, url, account_id, inbox_id, token, webhook_token}';
+
+-- Conversa do Chatwoot aberta para cada contato da sessão
+CREATE TABLE IF NOT EXISTS chatwoot_conversations (
+    session_id TEXT NOT NULL REFERENCES sessions(id) ON DELETE CASCADE,
+    phone TEXT NOT NULL,
+    contact_id BIGINT NOT NULL,
+    conversation_id BIGINT NOT NULL,
+
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+
+    PRIMARY KEY (session_id, phone)
+);
+
+CREATE INDEX IF NOT EXISTS idx_chatwoot_conversations_conversation ON chatwoot_conversations(session_id, conversation_id);
+
+CREATE TRIGGER update_chatwoot_conversations_updated_at
+    BEFORE UPDATE ON chatwoot_conversations
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
+
+-- Mensagem do WhatsApp <-> mensagem do Chatwoot (recibos de leitura e exclusões)
+CREATE TABLE IF NOT EXISTS chatwoot_messages (
+    session_id TEXT NOT NULL REFERENCES sessions(id) ON DELETE CASCADE,
+    message_id TEXT NOT NULL,
+    chatwoot_message_id BIGINT NOT NULL,
+    conversation_id BIGINT NOT NULL,
+    phone TEXT NOT NULL,
+    from_me BOOLEAN NOT NULL DEFAULT FALSE,
+
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+
+    PRIMARY KEY (session_id, message_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_chatwoot_messages_chatwoot ON chatwoot_messages(session_id, chatwoot_message_id);
+
+COMMENT ON TABLE chatwoot_conversations IS 'Chatwoot contact and conversation of each WhatsApp contact per session';
+COMMENT ON TABLE chatwoot_messages IS 'WhatsApp message id to Chatwoot message id mapping';
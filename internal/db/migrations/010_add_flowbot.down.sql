@@ -0,0 +1,10 @@
+-- Migration Rollback: Remove flow bot integration
+-- Description: Removes flowbot_sessions and flowbot_config from sessions
+-- Author: zpwoot
+-- Date: 2025-11-18
+
+DROP TRIGGER IF EXISTS update_flowbot_sessions_updated_at ON flowbot_sessions;
+DROP TABLE IF EXISTS flowbot_sessions;
+
+ALTER TABLE sessions
+DROP COLUMN IF EXISTS flowbot_config;
@@ -0,0 +1,34 @@
+-- Migration: Add flow bot integration
+-- Description: Adds per-session flow bot (Typebot-compatible) config and per-contact bot sessions
+-- Author: zpwoot
+-- Date: 2025-11-18
+
+ALTER TABLE sessions
+ADD COLUMN IF NOT EXISTS flowbot_config JSONB DEFAULT NULL;
+
+COMMENT ON COLUMN sessions.flowbot_config IS 'JSON configuration for the flow bot: {enabled, url, typebot_id, token, triggers, expire, finish_keyword, stop_on_from_me, message_delay}';
+
+-- Conversa de cada contato com o fluxo
+CREATE TABLE IF NOT EXISTS flowbot_sessions (
+    session_id TEXT NOT NULL REFERENCES sessions(id) ON DELETE CASCADE,
+    phone TEXT NOT NULL,
+
+    -- sessionId retornado pelo startChat do Typebot
+    bot_session_id TEXT NOT NULL DEFAULT '',
+    -- active (bot responde) ou paused (atendimento humano)
+    status TEXT NOT NULL CHECK (status IN ('active', 'paused')),
+    -- Opções do último bloco de escolha (o contato pode responder com o número)
+    choices JSONB,
+
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+
+    PRIMARY KEY (session_id, phone)
+);
+
+CREATE TRIGGER update_flowbot_sessions_updated_at
+    BEFORE UPDATE ON flowbot_sessions
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
+
+COMMENT ON TABLE flowbot_sessions IS 'Flow bot conversation state per contact; paused rows are handled by a human';
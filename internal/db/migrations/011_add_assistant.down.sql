@@ -0,0 +1,11 @@
+-- Migration Rollback: Remove LLM assistant responder
+-- Description: Removes assistant_messages, assistant_conversations and assistant_config from sessions
+-- Author: zpwoot
+-- Date: 2025-11-19
+
+DROP TABLE IF EXISTS assistant_messages;
+DROP TRIGGER IF EXISTS update_assistant_conversations_updated_at ON assistant_conversations;
+DROP TABLE IF EXISTS assistant_conversations;
+
+ALTER TABLE sessions
+DROP COLUMN IF EXISTS assistant_config;
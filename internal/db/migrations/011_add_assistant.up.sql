@@ -0,0 +1,46 @@
+-- Migration: Add LLM assistant responder
+-- Description: Adds per-session assistant (OpenAI-compatible) config and per-contact conversation memory
+-- Author: zpwoot
+-- Date: 2025-11-19
+
+ALTER TABLE sessions
+ADD COLUMN IF NOT EXISTS assistant_config JSONB DEFAULT NULL;
+
+COMMENT ON COLUMN sessions.assistant_config IS 'JSON configuration for the assistant: {enabled, base_url, model, api_key, system_prompt, triggers, history_size, expire, finish_keyword, stop_on_from_me, temperature, max_tokens}';
+
+-- Conversa de cada contato com o assistente
+CREATE TABLE IF NOT EXISTS assistant_conversations (
+    session_id TEXT NOT NULL REFERENCES sessions(id) ON DELETE CASCADE,
+    phone TEXT NOT NULL,
+
+    -- active (assistente responde) ou paused (atendimento humano)
+    status TEXT NOT NULL CHECK (status IN ('active', 'paused')),
+
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+
+    PRIMARY KEY (session_id, phone)
+);
+
+CREATE TRIGGER update_assistant_conversations_updated_at
+    BEFORE UPDATE ON assistant_conversations
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
+
+-- Memória da conversa (janela deslizante das últimas mensagens)
+CREATE TABLE IF NOT EXISTS assistant_messages (
+    id BIGSERIAL PRIMARY KEY,
+    session_id TEXT NOT NULL,
+    phone TEXT NOT NULL,
+    role TEXT NOT NULL CHECK (role IN ('user', 'assistant')),
+    content TEXT NOT NULL,
+
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+
+    FOREIGN KEY (session_id, phone) REFERENCES assistant_conversations(session_id, phone) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_assistant_messages_conversation ON assistant_messages(session_id, phone, id DESC);
+
+COMMENT ON TABLE assistant_conversations IS 'Assistant conversation state per contact; paused rows are handled by a human';
+COMMENT ON TABLE assistant_messages IS 'Assistant conversation memory per contact (sliding window)';
@@ -0,0 +1,10 @@
+-- Migration Rollback: Remove voice note transcription
+-- Description: Removes transcription from messages and transcription_config from sessions
+-- Author: zpwoot
+-- Date: 2025-11-20
+
+ALTER TABLE messages
+DROP COLUMN IF EXISTS transcription;
+
+ALTER TABLE sessions
+DROP COLUMN IF EXISTS transcription_config;
@@ -0,0 +1,14 @@
+-- Migration: Add voice note transcription
+-- Description: Adds per-session speech-to-text config and the transcription of stored messages
+-- Author: zpwoot
+-- Date: 2025-11-20
+
+ALTER TABLE sessions
+ADD COLUMN IF NOT EXISTS transcription_config JSONB DEFAULT NULL;
+
+COMMENT ON COLUMN sessions.transcription_config IS 'JSON configuration for voice note transcription: {enabled, base_url, model, api_key, language, from_me}';
+
+ALTER TABLE messages
+ADD COLUMN IF NOT EXISTS transcription TEXT DEFAULT NULL;
+
+COMMENT ON COLUMN messages.transcription IS 'Speech-to-text transcript of voice notes (PTT)';
@@ -0,0 +1,19 @@
+-- Migration Rollback: Drop tenants
+-- Description: Removes tenant scoping from sessions and templates and drops the tenants table
+-- Author: zpwoot
+-- Date: 2025-11-21
+
+DROP INDEX IF EXISTS idx_message_templates_scope_name;
+
+ALTER TABLE message_templates
+DROP COLUMN IF EXISTS tenant_id;
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_message_templates_scope_name ON message_templates(COALESCE(session_id, ''), name);
+
+DROP INDEX IF EXISTS idx_sessions_tenant_id;
+
+ALTER TABLE sessions
+DROP COLUMN IF EXISTS tenant_id;
+
+DROP TRIGGER IF EXISTS update_tenants_updated_at ON tenants;
+DROP TABLE IF EXISTS tenants;
@@ -0,0 +1,47 @@
+-- Migration: Create tenants
+-- Description: Creates organizations (tenants) with their own API key, session quota and webhook defaults, and scopes sessions and templates by tenant
+-- Author: zpwoot
+-- Date: 2025-11-21
+
+CREATE TABLE IF NOT EXISTS tenants (
+    id TEXT PRIMARY KEY,
+    name TEXT NOT NULL,
+
+    -- SHA-256 (hex) da API key; a chave em texto puro só é exibida na criação/rotação
+    api_key_hash TEXT NOT NULL UNIQUE,
+    -- Início da chave, para identificá-la sem expor o segredo
+    api_key_prefix TEXT NOT NULL,
+
+    -- Máximo de sessões do tenant (0 = sem limite)
+    max_sessions INTEGER NOT NULL DEFAULT 0,
+
+    -- Webhook aplicado às sessões criadas sem webhook: {enabled, url, events, token}
+    webhook_defaults JSONB DEFAULT NULL,
+
+    enabled BOOLEAN NOT NULL DEFAULT true,
+
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE TRIGGER update_tenants_updated_at
+    BEFORE UPDATE ON tenants
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
+
+-- NULL = sessão do servidor (visível apenas com a API key global)
+ALTER TABLE sessions
+ADD COLUMN IF NOT EXISTS tenant_id TEXT REFERENCES tenants(id) ON DELETE RESTRICT;
+
+CREATE INDEX IF NOT EXISTS idx_sessions_tenant_id ON sessions(tenant_id);
+
+ALTER TABLE message_templates
+ADD COLUMN IF NOT EXISTS tenant_id TEXT REFERENCES tenants(id) ON DELETE CASCADE;
+
+-- Nome único por tenant e escopo
+DROP INDEX IF EXISTS idx_message_templates_scope_name;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_message_templates_scope_name ON message_templates(COALESCE(tenant_id, ''), COALESCE(session_id, ''), name);
+
+COMMENT ON TABLE tenants IS 'Organizations that own sessions and templates, authenticated by their own API key';
+COMMENT ON COLUMN sessions.tenant_id IS 'Owning tenant (NULL = server session, managed with the global API key)';
+COMMENT ON COLUMN message_templates.tenant_id IS 'Owning tenant (NULL = server template)';
@@ -0,0 +1,7 @@
+-- Migration Rollback: Drop API keys
+-- Description: Drops the api_keys table
+-- Author: zpwoot
+-- Date: 2025-11-22
+
+DROP INDEX IF EXISTS idx_api_keys_tenant_id;
+DROP TABLE IF EXISTS api_keys;
@@ -0,0 +1,30 @@
+-- Migration: Create API keys
+-- Description: Creates scoped API keys (permissions, expiry and IP allow-list), global or owned by a tenant
+-- Author: zpwoot
+-- Date: 2025-11-22
+
+CREATE TABLE IF NOT EXISTS api_keys (
+    id TEXT PRIMARY KEY,
+    -- NULL = chave do servidor (acesso a todas as sessões, limitado pelos escopos)
+    tenant_id TEXT REFERENCES tenants(id) ON DELETE CASCADE,
+    name TEXT NOT NULL,
+
+    -- SHA-256 (hex) da chave; a chave em texto puro só é exibida na criação
+    key_hash TEXT NOT NULL UNIQUE,
+    key_prefix TEXT NOT NULL,
+
+    -- sessions:read, sessions:write, messages:send, webhooks:manage, admin
+    scopes TEXT[] NOT NULL,
+
+    -- IPs ou CIDRs de origem permitidos (vazio = qualquer origem)
+    allowed_ips TEXT[] NOT NULL DEFAULT '{}',
+
+    -- NULL = não expira
+    expires_at TIMESTAMPTZ DEFAULT NULL,
+
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_api_keys_tenant_id ON api_keys(tenant_id);
+
+COMMENT ON TABLE api_keys IS 'Scoped API keys with optional expiry and IP allow-list; only the SHA-256 hash is stored';
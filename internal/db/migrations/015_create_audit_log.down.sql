@@ -0,0 +1,10 @@
+-- Migration Rollback: Drop audit log
+-- Description: Drops the audit_log table
+-- Author: zpwoot
+-- Date: 2025-11-23
+
+DROP INDEX IF EXISTS idx_audit_log_actor_key_id;
+DROP INDEX IF EXISTS idx_audit_log_tenant_id;
+DROP INDEX IF EXISTS idx_audit_log_session_id;
+DROP INDEX IF EXISTS idx_audit_log_created_at;
+DROP TABLE IF EXISTS audit_log;
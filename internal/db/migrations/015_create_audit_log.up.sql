@@ -0,0 +1,44 @@
+-- Migration: Create audit log
+-- Description: Records every mutating API call (actor, IP, route, session, redacted request summary and result)
+-- Author: zpwoot
+-- Date: 2025-11-23
+
+CREATE TABLE IF NOT EXISTS audit_log (
+    id BIGSERIAL PRIMARY KEY,
+
+    -- Sem chave estrangeira: o registro sobrevive à remoção do tenant, da chave e da sessão
+    tenant_id TEXT,
+
+    -- Autor da chamada (vazio quando a autenticação falhou)
+    actor_key_id TEXT NOT NULL DEFAULT '',
+    actor_key_prefix TEXT NOT NULL DEFAULT '',
+    actor_name TEXT NOT NULL DEFAULT '',
+    ip TEXT NOT NULL,
+
+    -- Requisição: rota declarada (/sessions/:id/message/text) e caminho efetivo
+    method TEXT NOT NULL,
+    route TEXT NOT NULL,
+    path TEXT NOT NULL,
+    request_id TEXT NOT NULL,
+
+    -- Sessão afetada e número conectado nela no momento do registro
+    session_id TEXT,
+    session_jid TEXT,
+
+    -- Query e corpo JSON com segredos removidos e valores longos truncados
+    summary JSONB,
+
+    -- success, denied (401/403) ou failure
+    status INTEGER NOT NULL,
+    result TEXT NOT NULL,
+    duration_ms BIGINT NOT NULL DEFAULT 0,
+
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_audit_log_created_at ON audit_log(created_at);
+CREATE INDEX IF NOT EXISTS idx_audit_log_session_id ON audit_log(session_id, created_at);
+CREATE INDEX IF NOT EXISTS idx_audit_log_tenant_id ON audit_log(tenant_id, created_at);
+CREATE INDEX IF NOT EXISTS idx_audit_log_actor_key_id ON audit_log(actor_key_id, created_at);
+
+COMMENT ON TABLE audit_log IS 'Audit trail of mutating API calls; entries older than AUDIT_RETENTION are removed';
@@ -0,0 +1,87 @@
+package model
+
+import (
+	"net"
+	"strings"
+	"time"
+)
+
+// Scope é uma permissão concedida a uma API key
+type Scope string
+
+const (
+	ScopeSessionsRead   Scope = "sessions:read"   // Consultar sessões, templates, campanhas e eventos
+	ScopeSessionsWrite  Scope = "sessions:write"  // Criar, conectar, remover e configurar sessões
+	ScopeMessagesSend   Scope = "messages:send"   // Enviar mensagens, agendamentos, jobs e campanhas
+	ScopeWebhooksManage Scope = "webhooks:manage" // Configurar webhooks e a integração com o Chatwoot
+	ScopeAdmin          Scope = "admin"           // Todas as permissões e a gestão de API keys
+)
+
+// Scopes lista os escopos válidos
+var Scopes = []Scope{ScopeSessionsRead, ScopeSessionsWrite, ScopeMessagesSend, ScopeWebhooksManage, ScopeAdmin}
+
+// IsValid indica se o escopo é conhecido
+func (s Scope) IsValid() bool {
+	for _, scope := range Scopes {
+		if s == scope {
+			return true
+		}
+	}
+	return false
+}
+
+// APIKey é uma credencial de acesso à API. Além das chaves gravadas em api_keys, a API key
+// global e a API key principal de cada tenant são representadas como chaves com escopo admin,
+// e os tokens JWT como chaves com os escopos e sessões das claims.
+type APIKey struct {
+	ID         string
+	TenantID   string // Vazio = chave do servidor
+	Name       string
+	KeyHash    string // SHA-256 (hex) da chave
+	KeyPrefix  string // Início da chave, para identificá-la
+	Scopes     []Scope
+	AllowedIPs []string   // IPs ou CIDRs permitidos (vazio = qualquer origem)
+	ExpiresAt  *time.Time // nil = não expira
+	SessionIDs []string   // Sessões permitidas (nil = todas; definido pelos tokens JWT)
+
+	// Timestamps
+	CreatedAt time.Time
+}
+
+// HasScope indica se a chave concede o escopo (admin concede todos)
+func (k *APIKey) HasScope(scope Scope) bool {
+	for _, granted := range k.Scopes {
+		if granted == scope || granted == ScopeAdmin {
+			return true
+		}
+	}
+	return false
+}
+
+// IsExpired indica se a chave já expirou
+func (k *APIKey) IsExpired(now time.Time) bool {
+	return k.ExpiresAt != nil && !now.Before(*k.ExpiresAt)
+}
+
+// AllowsIP indica se a chave pode ser usada a partir do IP informado
+func (k *APIKey) AllowsIP(ip string) bool {
+	if len(k.AllowedIPs) == 0 {
+		return true
+	}
+
+	addr := net.ParseIP(ip)
+	if addr == nil {
+		return false
+	}
+
+	for _, allowed := range k.AllowedIPs {
+		if strings.Contains(allowed, "/") {
+			if _, network, err := net.ParseCIDR(allowed); err == nil && network.Contains(addr) {
+				return true
+			}
+		} else if allowedIP := net.ParseIP(allowed); allowedIP != nil && allowedIP.Equal(addr) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,29 @@
+package model
+
+import "time"
+
+type AssistantStatus string
+
+const (
+	AssistantStatusActive AssistantStatus = "active" // O assistente responde o contato
+	AssistantStatusPaused AssistantStatus = "paused" // Conversa entregue a um humano
+)
+
+// AssistantConversation é a conversa de um contato com o assistente da sessão
+type AssistantConversation struct {
+	SessionID string
+	Phone     string
+	Status    AssistantStatus
+
+	// Timestamps
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+// AssistantMessage é uma mensagem da memória da conversa
+type AssistantMessage struct {
+	Role    string // user ou assistant
+	Content string
+
+	CreatedAt time.Time
+}
@@ -0,0 +1,69 @@
+package model
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// AuditResult resume o desfecho da chamada auditada
+type AuditResult string
+
+const (
+	AuditResultSuccess AuditResult = "success" // Status 1xx a 3xx
+	AuditResultDenied  AuditResult = "denied"  // 401/403: autenticação ou permissão recusada
+	AuditResultFailure AuditResult = "failure" // Demais erros
+)
+
+// AuditResultFromStatus classifica o status HTTP da resposta
+func AuditResultFromStatus(status int) AuditResult {
+	switch {
+	case status == 401 || status == 403:
+		return AuditResultDenied
+	case status >= 400:
+		return AuditResultFailure
+	default:
+		return AuditResultSuccess
+	}
+}
+
+// AuditEntry registra uma chamada da API que altera estado
+type AuditEntry struct {
+	ID       int64
+	TenantID string // Tenant do autor (vazio = servidor ou não autenticado)
+
+	// Autor
+	ActorKeyID     string // ID da API key com escopos (vazio para a global, a principal do tenant e JWT)
+	ActorKeyPrefix string
+	ActorName      string // Nome da chave, do tenant ou "jwt:<sub>"
+	IP             string
+
+	// Requisição
+	Method    string
+	Route     string // Rota declarada, ex.: /sessions/:id/message/text
+	Path      string
+	RequestID string
+
+	SessionID  string
+	SessionJID string // Número conectado na sessão no momento do registro
+
+	Summary json.RawMessage // Query e corpo com segredos removidos
+
+	Status     int
+	Result     AuditResult
+	DurationMs int64
+	CreatedAt  time.Time
+}
+
+// AuditFilter filtra a consulta do audit log (campos vazios não filtram)
+type AuditFilter struct {
+	SessionID  string
+	ActorKeyID string
+	Method     string
+	Route      string
+	Result     AuditResult
+	RequestID  string
+	From       *time.Time
+	To         *time.Time
+	Limit      int
+	Offset     int
+}
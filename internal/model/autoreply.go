@@ -0,0 +1,109 @@
+package model
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"time"
+)
+
+type AutoReplyMatchType string
+
+const (
+	AutoReplyMatchKeyword      AutoReplyMatchType = "keyword"       // Texto igual ao padrão (sem diferenciar maiúsculas)
+	AutoReplyMatchRegex        AutoReplyMatchType = "regex"         // Texto casa com a expressão regular
+	AutoReplyMatchFirstMessage AutoReplyMatchType = "first_message" // Primeira mensagem do contato
+	AutoReplyMatchOutsideHours AutoReplyMatchType = "outside_hours" // Fora do horário comercial
+)
+
+// BusinessHoursRange é um intervalo de atendimento nos dias da semana informados (0 = domingo)
+type BusinessHoursRange struct {
+	Weekdays []int  `json:"weekdays"`
+	Start    string `json:"start"` // HH:MM
+	End      string `json:"end"`   // HH:MM
+}
+
+// BusinessHours define o horário comercial usado pelas regras outside_hours
+type BusinessHours struct {
+	Timezone string               `json:"timezone"`
+	Ranges   []BusinessHoursRange `json:"ranges"`
+}
+
+func (b *BusinessHours) Value() (driver.Value, error) {
+	if b == nil {
+		return nil, nil
+	}
+	return json.Marshal(b)
+}
+
+func (b *BusinessHours) Scan(value interface{}) error {
+	if value == nil {
+		return nil
+	}
+
+	var bytes []byte
+	switch v := value.(type) {
+	case []byte:
+		bytes = v
+	case string:
+		bytes = []byte(v)
+	default:
+		return nil
+	}
+
+	return json.Unmarshal(bytes, b)
+}
+
+// AutoReplyResponse é a mensagem enviada quando a regra casa. Com TemplateID, o conteúdo vem do
+// template (renderizado com Variables); os textos aceitam {{name}} e {{phone}} do contato.
+type AutoReplyResponse struct {
+	Type       string            `json:"type"` // text, image, video, audio, document
+	Text       string            `json:"text,omitempty"`
+	MediaURL   string            `json:"mediaUrl,omitempty"`
+	Caption    string            `json:"caption,omitempty"`
+	FileName   string            `json:"fileName,omitempty"`
+	TemplateID string            `json:"templateId,omitempty"`
+	Variables  map[string]string `json:"variables,omitempty"`
+}
+
+func (r *AutoReplyResponse) Value() (driver.Value, error) {
+	if r == nil {
+		return nil, nil
+	}
+	return json.Marshal(r)
+}
+
+func (r *AutoReplyResponse) Scan(value interface{}) error {
+	if value == nil {
+		return nil
+	}
+
+	var bytes []byte
+	switch v := value.(type) {
+	case []byte:
+		bytes = v
+	case string:
+		bytes = []byte(v)
+	default:
+		return nil
+	}
+
+	return json.Unmarshal(bytes, r)
+}
+
+type AutoReplyRule struct {
+	ID            string
+	SessionID     string
+	Name          string
+	MatchType     AutoReplyMatchType
+	Pattern       string         // Palavra-chave ou regex
+	BusinessHours *BusinessHours // Apenas outside_hours
+	Response      *AutoReplyResponse
+
+	Cooldown int // Segundos entre respostas da regra para o mesmo contato (0 = sempre responde)
+	Enabled  bool
+	Priority int // Maior prioridade é avaliada primeiro
+
+	// Timestamps
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
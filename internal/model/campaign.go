@@ -0,0 +1,129 @@
+package model
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"time"
+)
+
+type CampaignStatus string
+
+const (
+	CampaignStatusDraft     CampaignStatus = "draft"   // Criada, recebendo destinatários
+	CampaignStatusRunning   CampaignStatus = "running" // Enviando
+	CampaignStatusPaused    CampaignStatus = "paused"
+	CampaignStatusCompleted CampaignStatus = "completed"
+	CampaignStatusCanceled  CampaignStatus = "canceled"
+)
+
+type RecipientStatus string
+
+const (
+	RecipientStatusPending       RecipientStatus = "pending"
+	RecipientStatusSending       RecipientStatus = "sending"
+	RecipientStatusSent          RecipientStatus = "sent"
+	RecipientStatusFailed        RecipientStatus = "failed"
+	RecipientStatusNotOnWhatsApp RecipientStatus = "not_on_whatsapp"
+)
+
+// CampaignTemplate é a mensagem enviada a cada destinatário. Text, Caption, FileName e MediaURL
+// aceitam placeholders {{variavel}} preenchidos com as variáveis do destinatário.
+type CampaignTemplate struct {
+	Type     string `json:"type"` // text, image, video, audio, document
+	Text     string `json:"text,omitempty"`
+	MediaURL string `json:"mediaUrl,omitempty"` // URL ou data URL (base64)
+	Caption  string `json:"caption,omitempty"`
+	FileName string `json:"fileName,omitempty"` // Apenas document
+}
+
+func (t *CampaignTemplate) Value() (driver.Value, error) {
+	if t == nil {
+		return nil, nil
+	}
+	return json.Marshal(t)
+}
+
+func (t *CampaignTemplate) Scan(value interface{}) error {
+	if value == nil {
+		return nil
+	}
+
+	var bytes []byte
+	switch v := value.(type) {
+	case []byte:
+		bytes = v
+	case string:
+		bytes = []byte(v)
+	default:
+		return nil
+	}
+
+	return json.Unmarshal(bytes, t)
+}
+
+type Campaign struct {
+	ID        string
+	SessionID string
+	Name      string
+	Template  *CampaignTemplate
+
+	MinDelay int // Segundos mínimos entre envios
+	MaxDelay int // Segundos máximos entre envios
+
+	Status CampaignStatus
+
+	// Timestamps
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+	StartedAt   *time.Time
+	CompletedAt *time.Time
+}
+
+// CampaignProgress conta os destinatários por status
+type CampaignProgress struct {
+	Total         int
+	Pending       int // Inclui os que estão sendo enviados
+	Sent          int
+	Failed        int
+	NotOnWhatsApp int
+}
+
+// RecipientVariables são os valores dos placeholders do template para um destinatário
+type RecipientVariables map[string]string
+
+func (v RecipientVariables) Value() (driver.Value, error) {
+	if v == nil {
+		return []byte("{}"), nil
+	}
+	return json.Marshal(v)
+}
+
+func (v *RecipientVariables) Scan(value interface{}) error {
+	if value == nil {
+		return nil
+	}
+
+	var bytes []byte
+	switch val := value.(type) {
+	case []byte:
+		bytes = val
+	case string:
+		bytes = []byte(val)
+	default:
+		return nil
+	}
+
+	return json.Unmarshal(bytes, v)
+}
+
+type CampaignRecipient struct {
+	ID         int64
+	CampaignID string
+	Phone      string
+	Variables  RecipientVariables
+
+	Status    RecipientStatus
+	MessageID string
+	Error     string
+	SentAt    *time.Time
+}
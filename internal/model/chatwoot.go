@@ -0,0 +1,27 @@
+package model
+
+import "time"
+
+// ChatwootConversation é a conversa do Chatwoot aberta para um contato da sessão
+type ChatwootConversation struct {
+	SessionID      string
+	Phone          string
+	ContactID      int64
+	ConversationID int64
+
+	// Timestamps
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+// ChatwootMessage relaciona uma mensagem do WhatsApp à mensagem espelhada no Chatwoot
+type ChatwootMessage struct {
+	SessionID         string
+	MessageID         string // ID da mensagem no WhatsApp
+	ChatwootMessageID int64
+	ConversationID    int64
+	Phone             string
+	FromMe            bool // Enviada pela sessão (agente ou celular)
+
+	CreatedAt time.Time
+}
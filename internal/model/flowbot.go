@@ -0,0 +1,23 @@
+package model
+
+import "time"
+
+type FlowBotStatus string
+
+const (
+	FlowBotStatusActive FlowBotStatus = "active" // O fluxo responde o contato
+	FlowBotStatusPaused FlowBotStatus = "paused" // Conversa entregue a um humano
+)
+
+// FlowBotSession é a conversa de um contato com o fluxo da sessão
+type FlowBotSession struct {
+	SessionID    string
+	Phone        string
+	BotSessionID string // sessionId retornado pelo startChat
+	Status       FlowBotStatus
+	Choices      []string // Opções do último bloco de escolha
+
+	// Timestamps
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
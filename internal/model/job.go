@@ -0,0 +1,39 @@
+package model
+
+import (
+	"encoding/json"
+	"time"
+)
+
+type JobStatus string
+
+const (
+	JobStatusQueued  JobStatus = "queued"  // Aguardando na fila da sessão
+	JobStatusSending JobStatus = "sending" // Liberado pelo rate limit, enviando
+	JobStatusSent    JobStatus = "sent"
+	JobStatusFailed  JobStatus = "failed"
+)
+
+// MessageJob é um envio enfileirado na fila de saída da sessão
+type MessageJob struct {
+	ID        string // UUID gerado na criação
+	SessionID string
+	Type      string          // text, image, audio, ... (mesmos tipos dos endpoints de envio)
+	Phone     string          // Destinatário, usado no intervalo por destinatário
+	Payload   json.RawMessage // Corpo da requisição do endpoint de envio correspondente
+
+	Status    JobStatus
+	MessageID string // ID da mensagem no WhatsApp (status sent)
+	Error     string // Último erro de envio
+	Attempts  int
+
+	// Timestamps
+	CreatedAt time.Time
+	UpdatedAt time.Time
+	SentAt    *time.Time
+}
+
+// IsFinal indica se o job já terminou (sent ou failed)
+func (j *MessageJob) IsFinal() bool {
+	return j.Status == JobStatusSent || j.Status == JobStatusFailed
+}
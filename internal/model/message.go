@@ -0,0 +1,56 @@
+package model
+
+import "time"
+
+type MessageSource string
+
+const (
+	MessageSourceHistory MessageSource = "history"
+	MessageSourceLive    MessageSource = "live"
+)
+
+type Chat struct {
+	SessionID     string
+	JID           string
+	Name          string
+	UnreadCount   int
+	Archived      bool
+	LastMessageAt *time.Time
+
+	// Timestamps
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+type Message struct {
+	SessionID string
+	ID        string // ID da mensagem no WhatsApp
+	ChatJID   string
+	SenderJID string
+	FromMe    bool
+	PushName  string
+	Type      string // conversation, extended_text, image, video, audio, document, ...
+	Body      string
+	Source    MessageSource
+	Raw       JSONMap // Mensagem completa (protojson)
+	Timestamp time.Time
+
+	// Transcrição das mensagens de voz (vazio se não transcrita)
+	Transcription string
+
+	// Timestamps
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+type HistorySyncProgress struct {
+	SessionID       string
+	SyncType        string
+	ChunkOrder      int
+	Progress        int
+	ChunksProcessed int
+	Conversations   int // Total acumulado de conversas processadas
+	Messages        int // Total acumulado de mensagens armazenadas
+	StartedAt       time.Time
+	UpdatedAt       time.Time
+}
@@ -0,0 +1,19 @@
+package model
+
+import "time"
+
+// RequestRate define um token bucket da API: PerMinute requisições por minuto, acumulando até
+// Burst (PerMinute <= 0 = sem limite)
+type RequestRate struct {
+	PerMinute int
+	Burst     int
+}
+
+// RequestLimitResult é o estado do token bucket após a requisição (headers RateLimit-*)
+type RequestLimitResult struct {
+	Allowed    bool
+	Limit      int           // Capacidade do bucket (burst)
+	Remaining  int           // Requisições disponíveis agora
+	Reset      time.Duration // Tempo até o bucket encher novamente
+	RetryAfter time.Duration // Tempo até a próxima requisição ser permitida (quando negada)
+}
@@ -0,0 +1,37 @@
+package model
+
+import (
+	"encoding/json"
+	"time"
+)
+
+type ScheduleStatus string
+
+const (
+	ScheduleStatusScheduled  ScheduleStatus = "scheduled"  // Aguardando o horário
+	ScheduleStatusProcessing ScheduleStatus = "processing" // Reservado por uma réplica do scheduler
+	ScheduleStatusQueued     ScheduleStatus = "queued"     // Entregue à fila de envio (ver JobID)
+	ScheduleStatusFailed     ScheduleStatus = "failed"
+	ScheduleStatusCanceled   ScheduleStatus = "canceled"
+)
+
+// ScheduledMessage é um envio agendado; no horário vira um MessageJob da fila de saída
+type ScheduledMessage struct {
+	ID        string
+	SessionID string
+	Type      string          // text, image, audio, ... (mesmos tipos dos endpoints de envio)
+	Phone     string          // Destinatário
+	Payload   json.RawMessage // Corpo da requisição do endpoint de envio correspondente
+
+	SendAt   time.Time
+	Timezone string // Fuso usado para interpretar o horário informado
+
+	Status ScheduleStatus
+	JobID  string
+	Error  string
+
+	// Timestamps
+	CreatedAt  time.Time
+	UpdatedAt  time.Time
+	ExecutedAt *time.Time
+}
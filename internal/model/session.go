@@ -3,7 +3,10 @@ package model
 import (
 	"database/sql/driver"
 	"encoding/json"
+	"fmt"
 	"time"
+
+	"zpwoot/pkg/secrets"
 )
 
 type SessionStatus string
@@ -40,6 +43,87 @@ type WebhookConfig struct {
 	Token   string   `json:"token,omitempty"`
 }
 
+// ChatwootConfig liga a sessão a uma inbox do tipo API no Chatwoot
+type ChatwootConfig struct {
+	Enabled      bool   `json:"enabled"`
+	URL          string `json:"url"`                     // URL base do Chatwoot
+	AccountID    int64  `json:"account_id"`              // Conta do Chatwoot
+	InboxID      int64  `json:"inbox_id"`                // Inbox do tipo API mapeada para a sessão
+	Token        string `json:"token"`                   // api_access_token de um agente/bot da conta
+	WebhookToken string `json:"webhook_token,omitempty"` // Segredo exigido no webhook recebido do Chatwoot
+}
+
+// FlowBotConfig liga a sessão a um fluxo compatível com a API de chat do Typebot
+type FlowBotConfig struct {
+	Enabled       bool     `json:"enabled"`
+	URL           string   `json:"url"`                       // URL base do Typebot (viewer)
+	TypebotID     string   `json:"typebot_id"`                // ID público do fluxo
+	Token         string   `json:"token,omitempty"`           // Bearer token (fluxos privados)
+	Triggers      []string `json:"triggers,omitempty"`        // Palavras que iniciam o fluxo (vazio = qualquer mensagem)
+	Expire        int      `json:"expire,omitempty"`          // Minutos sem interação até o fluxo expirar (0 = não expira)
+	FinishKeyword string   `json:"finish_keyword,omitempty"`  // Palavra que encerra o fluxo (ex.: #sair)
+	StopOnFromMe  bool     `json:"stop_on_from_me,omitempty"` // Mensagem enviada pelo celular passa a conversa para um humano
+	MessageDelay  int      `json:"message_delay,omitempty"`   // Milissegundos entre os blocos enviados
+}
+
+// AssistantConfig liga a sessão a um modelo de linguagem compatível com a API /chat/completions da OpenAI
+type AssistantConfig struct {
+	Enabled       bool     `json:"enabled"`
+	BaseURL       string   `json:"base_url"`                  // Ex.: https://api.openai.com/v1
+	Model         string   `json:"model"`                     // Ex.: gpt-4o-mini
+	APIKey        string   `json:"api_key,omitempty"`         // Bearer token (opcional em servidores locais)
+	SystemPrompt  string   `json:"system_prompt,omitempty"`   // Instruções do assistente
+	Triggers      []string `json:"triggers,omitempty"`        // Palavras que iniciam a conversa (vazio = qualquer mensagem)
+	HistorySize   int      `json:"history_size,omitempty"`    // Mensagens lembradas por contato (0 = padrão)
+	Expire        int      `json:"expire,omitempty"`          // Minutos sem interação até a conversa expirar (0 = não expira)
+	FinishKeyword string   `json:"finish_keyword,omitempty"`  // Palavra que encerra a conversa (ex.: #sair)
+	StopOnFromMe  bool     `json:"stop_on_from_me,omitempty"` // Mensagem enviada pelo celular passa a conversa para um humano
+	Temperature   *float64 `json:"temperature,omitempty"`
+	MaxTokens     int      `json:"max_tokens,omitempty"`
+}
+
+// TranscriptionConfig liga a transcrição das mensagens de voz a um endpoint compatível com a API do Whisper
+type TranscriptionConfig struct {
+	Enabled  bool   `json:"enabled"`
+	BaseURL  string `json:"base_url"`           // Ex.: https://api.openai.com/v1
+	Model    string `json:"model"`              // Ex.: whisper-1
+	APIKey   string `json:"api_key,omitempty"`  // Bearer token (opcional em servidores locais)
+	Language string `json:"language,omitempty"` // Código ISO-639-1 (vazio = detecção automática)
+	FromMe   bool   `json:"from_me,omitempty"`  // Transcrever também as mensagens de voz enviadas pela sessão
+}
+
+type HistorySyncMode string
+
+const (
+	HistorySyncModeFull   HistorySyncMode = "full"   // Solicita o histórico completo no pareamento
+	HistorySyncModeRecent HistorySyncMode = "recent" // Apenas histórico recente (padrão do WhatsApp)
+	HistorySyncModeNone   HistorySyncMode = "none"   // Não armazena o histórico recebido
+)
+
+type HistorySyncConfig struct {
+	Mode HistorySyncMode `json:"mode"`           // full, recent, none
+	Days int             `json:"days,omitempty"` // Limite de dias para o modo recent (opcional)
+}
+
+type CallPolicy string
+
+const (
+	CallPolicyAccept        CallPolicy = "accept"         // Deixa a chamada tocar (padrão)
+	CallPolicyReject        CallPolicy = "reject"         // Rejeita automaticamente
+	CallPolicyRejectMessage CallPolicy = "reject_message" // Rejeita e responde com uma mensagem de texto
+)
+
+type CallConfig struct {
+	Policy  CallPolicy `json:"policy"`            // accept, reject, reject_message
+	Message string     `json:"message,omitempty"` // Texto enviado no modo reject_message
+}
+
+type RateLimitConfig struct {
+	MessagesPerMinute int `json:"messages_per_minute"`         // Envios por minuto na fila da sessão
+	Burst             int `json:"burst"`                       // Envios permitidos em sequência antes de aplicar o ritmo
+	RecipientSpacing  int `json:"recipient_spacing,omitempty"` // Segundos mínimos entre envios para o mesmo destinatário
+}
+
 type Session struct {
 	ID        string // UUID gerado automaticamente
 	Name      string
@@ -47,6 +131,9 @@ type Session struct {
 	Status    string // disconnected, connecting, connected, pairing, failed, logged_out
 	Connected bool   // Flag rápida de conexão
 
+	// Tenant
+	TenantID string // Organização dona da sessão (vazio = sessão do servidor)
+
 	// WhatsApp data
 	QRCode string // Base64 QR code para pareamento
 
@@ -54,8 +141,29 @@ type Session struct {
 	ProxyConfig   *ProxyConfig   // Configuração de proxy
 	WebhookConfig *WebhookConfig // Configuração de webhook
 
+	// Chatwoot
+	ChatwootConfig *ChatwootConfig // Inbox do Chatwoot da sessão
+
+	// Flow bot
+	FlowBotConfig *FlowBotConfig // Fluxo (Typebot) que atende os contatos
+
+	// Assistant
+	AssistantConfig *AssistantConfig // Assistente (LLM) que atende os contatos
+
+	// Transcription
+	TranscriptionConfig *TranscriptionConfig // Transcrição das mensagens de voz
+
+	// History sync
+	HistorySyncConfig *HistorySyncConfig // Preferências de sincronização de histórico
+
+	// Calls
+	CallConfig *CallConfig // Política para chamadas recebidas
+
+	// Outbound queue
+	RateLimitConfig *RateLimitConfig // Ritmo de envio da fila (nil = padrão do servidor)
+
 	// Authentication
-	APIKey *string // API key para autenticação da sessão (opcional)
+	APIKey *SecretString // API key para autenticação da sessão (opcional, cifrada no banco)
 
 	// Timestamps
 	CreatedAt time.Time
@@ -74,11 +182,70 @@ func (s *Session) NeedsPairing() bool {
 	return s.DeviceJID == ""
 }
 
+// encryptSecrets cifra os campos informados com a chave mestra (pkg/secrets) antes da gravação
+func encryptSecrets(fields ...*string) error {
+	for _, field := range fields {
+		value, err := secrets.Encrypt(*field)
+		if err != nil {
+			return fmt.Errorf("failed to encrypt secret: %w", err)
+		}
+		*field = value
+	}
+	return nil
+}
+
+// decryptSecrets decifra os campos lidos do banco (valores em texto puro são mantidos)
+func decryptSecrets(fields ...*string) error {
+	for _, field := range fields {
+		value, err := secrets.Decrypt(*field)
+		if err != nil {
+			return fmt.Errorf("failed to decrypt secret: %w", err)
+		}
+		*field = value
+	}
+	return nil
+}
+
+// SecretString é um texto gravado cifrado (envelope encryption) e decifrado na leitura
+type SecretString string
+
+func (s SecretString) Value() (driver.Value, error) {
+	value := string(s)
+	if err := encryptSecrets(&value); err != nil {
+		return nil, err
+	}
+	return value, nil
+}
+
+func (s *SecretString) Scan(value interface{}) error {
+	var text string
+	switch v := value.(type) {
+	case nil:
+		return nil
+	case []byte:
+		text = string(v)
+	case string:
+		text = v
+	default:
+		return fmt.Errorf("unsupported secret type %T", value)
+	}
+
+	if err := decryptSecrets(&text); err != nil {
+		return err
+	}
+	*s = SecretString(text)
+	return nil
+}
+
 func (p *ProxyConfig) Value() (driver.Value, error) {
 	if p == nil {
 		return nil, nil
 	}
-	return json.Marshal(p)
+	stored := *p
+	if err := encryptSecrets(&stored.Password); err != nil {
+		return nil, err
+	}
+	return json.Marshal(stored)
 }
 
 func (p *ProxyConfig) Scan(value interface{}) error {
@@ -96,14 +263,21 @@ func (p *ProxyConfig) Scan(value interface{}) error {
 		return nil
 	}
 
-	return json.Unmarshal(bytes, p)
+	if err := json.Unmarshal(bytes, p); err != nil {
+		return err
+	}
+	return decryptSecrets(&p.Password)
 }
 
 func (w *WebhookConfig) Value() (driver.Value, error) {
 	if w == nil {
 		return nil, nil
 	}
-	return json.Marshal(w)
+	stored := *w
+	if err := encryptSecrets(&stored.Token); err != nil {
+		return nil, err
+	}
+	return json.Marshal(stored)
 }
 
 func (w *WebhookConfig) Scan(value interface{}) error {
@@ -121,7 +295,249 @@ func (w *WebhookConfig) Scan(value interface{}) error {
 		return nil
 	}
 
-	return json.Unmarshal(bytes, w)
+	if err := json.Unmarshal(bytes, w); err != nil {
+		return err
+	}
+	return decryptSecrets(&w.Token)
+}
+
+func (h *HistorySyncConfig) Value() (driver.Value, error) {
+	if h == nil {
+		return nil, nil
+	}
+	return json.Marshal(h)
+}
+
+func (h *HistorySyncConfig) Scan(value interface{}) error {
+	if value == nil {
+		return nil
+	}
+
+	var bytes []byte
+	switch v := value.(type) {
+	case []byte:
+		bytes = v
+	case string:
+		bytes = []byte(v)
+	default:
+		return nil
+	}
+
+	return json.Unmarshal(bytes, h)
+}
+
+// HistoryMode retorna o modo de sincronização de histórico (recent por padrão)
+func (s *Session) HistoryMode() HistorySyncMode {
+	if s.HistorySyncConfig == nil || s.HistorySyncConfig.Mode == "" {
+		return HistorySyncModeRecent
+	}
+	return s.HistorySyncConfig.Mode
+}
+
+func (c *CallConfig) Value() (driver.Value, error) {
+	if c == nil {
+		return nil, nil
+	}
+	return json.Marshal(c)
+}
+
+func (c *CallConfig) Scan(value interface{}) error {
+	if value == nil {
+		return nil
+	}
+
+	var bytes []byte
+	switch v := value.(type) {
+	case []byte:
+		bytes = v
+	case string:
+		bytes = []byte(v)
+	default:
+		return nil
+	}
+
+	return json.Unmarshal(bytes, c)
+}
+
+func (c *ChatwootConfig) Value() (driver.Value, error) {
+	if c == nil {
+		return nil, nil
+	}
+	stored := *c
+	if err := encryptSecrets(&stored.Token, &stored.WebhookToken); err != nil {
+		return nil, err
+	}
+	return json.Marshal(stored)
+}
+
+func (c *ChatwootConfig) Scan(value interface{}) error {
+	if value == nil {
+		return nil
+	}
+
+	var bytes []byte
+	switch v := value.(type) {
+	case []byte:
+		bytes = v
+	case string:
+		bytes = []byte(v)
+	default:
+		return nil
+	}
+
+	if err := json.Unmarshal(bytes, c); err != nil {
+		return err
+	}
+	return decryptSecrets(&c.Token, &c.WebhookToken)
+}
+
+func (c *FlowBotConfig) Value() (driver.Value, error) {
+	if c == nil {
+		return nil, nil
+	}
+	stored := *c
+	if err := encryptSecrets(&stored.Token); err != nil {
+		return nil, err
+	}
+	return json.Marshal(stored)
+}
+
+func (c *FlowBotConfig) Scan(value interface{}) error {
+	if value == nil {
+		return nil
+	}
+
+	var bytes []byte
+	switch v := value.(type) {
+	case []byte:
+		bytes = v
+	case string:
+		bytes = []byte(v)
+	default:
+		return nil
+	}
+
+	if err := json.Unmarshal(bytes, c); err != nil {
+		return err
+	}
+	return decryptSecrets(&c.Token)
+}
+
+func (c *AssistantConfig) Value() (driver.Value, error) {
+	if c == nil {
+		return nil, nil
+	}
+	stored := *c
+	if err := encryptSecrets(&stored.APIKey); err != nil {
+		return nil, err
+	}
+	return json.Marshal(stored)
+}
+
+func (c *AssistantConfig) Scan(value interface{}) error {
+	if value == nil {
+		return nil
+	}
+
+	var bytes []byte
+	switch v := value.(type) {
+	case []byte:
+		bytes = v
+	case string:
+		bytes = []byte(v)
+	default:
+		return nil
+	}
+
+	if err := json.Unmarshal(bytes, c); err != nil {
+		return err
+	}
+	return decryptSecrets(&c.APIKey)
+}
+
+func (c *TranscriptionConfig) Value() (driver.Value, error) {
+	if c == nil {
+		return nil, nil
+	}
+	stored := *c
+	if err := encryptSecrets(&stored.APIKey); err != nil {
+		return nil, err
+	}
+	return json.Marshal(stored)
+}
+
+func (c *TranscriptionConfig) Scan(value interface{}) error {
+	if value == nil {
+		return nil
+	}
+
+	var bytes []byte
+	switch v := value.(type) {
+	case []byte:
+		bytes = v
+	case string:
+		bytes = []byte(v)
+	default:
+		return nil
+	}
+
+	if err := json.Unmarshal(bytes, c); err != nil {
+		return err
+	}
+	return decryptSecrets(&c.APIKey)
+}
+
+// TranscriptionEnabled informa se a sessão transcreve mensagens de voz
+func (s *Session) TranscriptionEnabled() bool {
+	return s.TranscriptionConfig != nil && s.TranscriptionConfig.Enabled
+}
+
+// AssistantEnabled informa se a sessão tem um assistente ativo
+func (s *Session) AssistantEnabled() bool {
+	return s.AssistantConfig != nil && s.AssistantConfig.Enabled
+}
+
+// FlowBotEnabled informa se a sessão tem um fluxo ativo
+func (s *Session) FlowBotEnabled() bool {
+	return s.FlowBotConfig != nil && s.FlowBotConfig.Enabled
+}
+
+// ChatwootEnabled informa se a sessão está ligada a uma inbox do Chatwoot
+func (s *Session) ChatwootEnabled() bool {
+	return s.ChatwootConfig != nil && s.ChatwootConfig.Enabled
+}
+
+// CallPolicy retorna a política de chamadas da sessão (accept por padrão)
+func (s *Session) CallPolicy() CallPolicy {
+	if s.CallConfig == nil || s.CallConfig.Policy == "" {
+		return CallPolicyAccept
+	}
+	return s.CallConfig.Policy
+}
+
+func (c *RateLimitConfig) Value() (driver.Value, error) {
+	if c == nil {
+		return nil, nil
+	}
+	return json.Marshal(c)
+}
+
+func (c *RateLimitConfig) Scan(value interface{}) error {
+	if value == nil {
+		return nil
+	}
+
+	var bytes []byte
+	switch v := value.(type) {
+	case []byte:
+		bytes = v
+	case string:
+		bytes = []byte(v)
+	default:
+		return nil
+	}
+
+	return json.Unmarshal(bytes, c)
 }
 
 type StringArray []string
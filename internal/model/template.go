@@ -0,0 +1,74 @@
+package model
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"time"
+)
+
+// TemplateButton é uma opção de resposta do template
+type TemplateButton struct {
+	ID   string `json:"id,omitempty"`
+	Text string `json:"text"`
+}
+
+// TemplateContent é o conteúdo de uma versão do template. Todos os textos aceitam
+// placeholders {{variavel}}.
+type TemplateContent struct {
+	Text     string           `json:"text,omitempty"`
+	MediaURL string           `json:"mediaUrl,omitempty"` // URL ou data URL (base64)
+	Caption  string           `json:"caption,omitempty"`
+	FileName string           `json:"fileName,omitempty"`
+	Buttons  []TemplateButton `json:"buttons,omitempty"`
+}
+
+func (t *TemplateContent) Value() (driver.Value, error) {
+	if t == nil {
+		return nil, nil
+	}
+	return json.Marshal(t)
+}
+
+func (t *TemplateContent) Scan(value interface{}) error {
+	if value == nil {
+		return nil
+	}
+
+	var bytes []byte
+	switch v := value.(type) {
+	case []byte:
+		bytes = v
+	case string:
+		bytes = []byte(v)
+	default:
+		return nil
+	}
+
+	return json.Unmarshal(bytes, t)
+}
+
+type MessageTemplate struct {
+	ID        string
+	TenantID  string // Vazio = template do servidor
+	SessionID string // Vazio = template global
+	Name      string
+	Version   int // Versão de Content
+	Content   *TemplateContent
+
+	// Timestamps
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+// IsAvailableTo indica se o template pode ser usado pela sessão
+func (t *MessageTemplate) IsAvailableTo(sessionID string) bool {
+	return t.SessionID == "" || t.SessionID == sessionID
+}
+
+// TemplateVersion é uma versão imutável do conteúdo do template
+type TemplateVersion struct {
+	TemplateID string
+	Version    int
+	Content    *TemplateContent
+	CreatedAt  time.Time
+}
@@ -0,0 +1,18 @@
+package model
+
+import "time"
+
+// Tenant é uma organização (ex.: agência) que possui suas próprias sessões, API key e cota
+type Tenant struct {
+	ID              string
+	Name            string
+	APIKeyHash      string         // SHA-256 (hex) da API key
+	APIKeyPrefix    string         // Início da API key, para identificá-la
+	MaxSessions     int            // Máximo de sessões (0 = sem limite)
+	WebhookDefaults *WebhookConfig // Webhook aplicado às sessões criadas sem webhook
+	Enabled         bool
+
+	// Timestamps
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
@@ -5,6 +5,7 @@ import (
 	"time"
 
 	"github.com/nats-io/nats.go"
+	"github.com/nats-io/nats.go/jetstream"
 	"zpwoot/pkg/logger"
 )
 
@@ -96,6 +97,20 @@ func (c *Client) QueueSubscribe(subject, queue string, handler nats.MsgHandler)
 	return sub, nil
 }
 
+// JetStream retorna o contexto JetStream da conexão (requer servidor com --js)
+func (c *Client) JetStream() (jetstream.JetStream, error) {
+	if c.conn == nil {
+		return nil, fmt.Errorf("NATS connection not established")
+	}
+
+	js, err := jetstream.New(c.conn)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create JetStream context: %w", err)
+	}
+
+	return js, nil
+}
+
 func (c *Client) IsConnected() bool {
 	return c.conn != nil && c.conn.IsConnected()
 }
@@ -0,0 +1,54 @@
+// Package natstest sobe um servidor NATS embutido (com JetStream) para os testes
+package natstest
+
+import (
+	"testing"
+	"time"
+
+	"github.com/nats-io/nats-server/v2/server"
+
+	natsclient "zpwoot/internal/nats"
+)
+
+// NewServer inicia o servidor embutido; ele é encerrado no fim do teste
+func NewServer(t testing.TB) *server.Server {
+	t.Helper()
+
+	srv, err := server.NewServer(&server.Options{
+		Host:      "127.0.0.1",
+		Port:      -1,
+		JetStream: true,
+		StoreDir:  t.TempDir(),
+		NoLog:     true,
+		NoSigs:    true,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	go srv.Start()
+	if !srv.ReadyForConnections(5 * time.Second) {
+		t.Fatal("NATS server not ready")
+	}
+	t.Cleanup(srv.Shutdown)
+
+	return srv
+}
+
+// Connect retorna um client conectado ao servidor, fechado no fim do teste
+func Connect(t testing.TB, url string) *natsclient.Client {
+	t.Helper()
+
+	client := natsclient.NewClient(natsclient.Config{URL: url, ReconnectWait: time.Second})
+	if err := client.Connect(); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(client.Close)
+
+	return client
+}
+
+// NewClient inicia um servidor embutido e retorna um client conectado a ele
+func NewClient(t testing.TB) *natsclient.Client {
+	t.Helper()
+	return Connect(t, NewServer(t).ClientURL())
+}
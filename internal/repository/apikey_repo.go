@@ -0,0 +1,163 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/lib/pq"
+
+	"zpwoot/internal/model"
+)
+
+const apiKeyColumns = `
+			id, COALESCE(tenant_id, ''), name, key_hash, key_prefix, scopes, allowed_ips,
+			expires_at, created_at`
+
+type APIKeyRepository struct {
+	db *sql.DB
+}
+
+func NewAPIKeyRepository(db *sql.DB) *APIKeyRepository {
+	return &APIKeyRepository{db: db}
+}
+
+func scanAPIKey(row rowScanner, key *model.APIKey) error {
+	var scopes []string
+	var expiresAt sql.NullTime
+
+	if err := row.Scan(
+		&key.ID, &key.TenantID, &key.Name, &key.KeyHash, &key.KeyPrefix, pq.Array(&scopes), pq.Array(&key.AllowedIPs),
+		&expiresAt, &key.CreatedAt,
+	); err != nil {
+		return err
+	}
+
+	key.Scopes = make([]model.Scope, 0, len(scopes))
+	for _, scope := range scopes {
+		key.Scopes = append(key.Scopes, model.Scope(scope))
+	}
+	if expiresAt.Valid {
+		key.ExpiresAt = &expiresAt.Time
+	}
+	return nil
+}
+
+// Create grava a chave no tenant do contexto quando ela não informa um
+func (r *APIKeyRepository) Create(ctx context.Context, key *model.APIKey) error {
+	if key.TenantID == "" {
+		key.TenantID = TenantFromContext(ctx)
+	}
+
+	scopes := make([]string, 0, len(key.Scopes))
+	for _, scope := range key.Scopes {
+		scopes = append(scopes, string(scope))
+	}
+	allowedIPs := key.AllowedIPs
+	if allowedIPs == nil {
+		allowedIPs = []string{}
+	}
+
+	query := `
+		INSERT INTO api_keys (
+			id, tenant_id, name, key_hash, key_prefix, scopes, allowed_ips, expires_at
+		) VALUES (
+			$1, NULLIF($2, ''), $3, $4, $5, $6, $7, $8
+		) RETURNING created_at
+	`
+
+	err := r.db.QueryRowContext(ctx, query,
+		key.ID, key.TenantID, key.Name, key.KeyHash, key.KeyPrefix, pq.Array(scopes), pq.Array(allowedIPs), key.ExpiresAt,
+	).Scan(&key.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create API key: %w", err)
+	}
+
+	return nil
+}
+
+// GetByID retorna a chave (restrita ao tenant do contexto) ou nil quando não existe
+func (r *APIKeyRepository) GetByID(ctx context.Context, id string) (*model.APIKey, error) {
+	query := `
+		SELECT ` + apiKeyColumns + `
+		FROM api_keys
+		WHERE id = $1 AND ($2 = '' OR tenant_id = $2)
+	`
+
+	key := &model.APIKey{}
+
+	err := scanAPIKey(r.db.QueryRowContext(ctx, query, id, TenantFromContext(ctx)), key)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get API key: %w", err)
+	}
+
+	return key, nil
+}
+
+// GetByHash retorna a chave com o hash informado ou nil quando nenhuma corresponde
+func (r *APIKeyRepository) GetByHash(ctx context.Context, hash string) (*model.APIKey, error) {
+	query := `
+		SELECT ` + apiKeyColumns + `
+		FROM api_keys
+		WHERE key_hash = $1
+	`
+
+	key := &model.APIKey{}
+
+	err := scanAPIKey(r.db.QueryRowContext(ctx, query, hash), key)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get API key: %w", err)
+	}
+
+	return key, nil
+}
+
+// List retorna as chaves do tenant do contexto (todas com a API key global)
+func (r *APIKeyRepository) List(ctx context.Context) ([]*model.APIKey, error) {
+	query := `
+		SELECT ` + apiKeyColumns + `
+		FROM api_keys
+		WHERE $1 = '' OR tenant_id = $1
+		ORDER BY created_at DESC
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, TenantFromContext(ctx))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list API keys: %w", err)
+	}
+	defer rows.Close()
+
+	keys := []*model.APIKey{}
+	for rows.Next() {
+		key := &model.APIKey{}
+		if err := scanAPIKey(rows, key); err != nil {
+			return nil, fmt.Errorf("failed to scan API key: %w", err)
+		}
+		keys = append(keys, key)
+	}
+
+	return keys, rows.Err()
+}
+
+// Delete revoga a chave (restrita ao tenant do contexto)
+func (r *APIKeyRepository) Delete(ctx context.Context, id string) (bool, error) {
+	result, err := r.db.ExecContext(ctx, `
+		DELETE FROM api_keys WHERE id = $1 AND ($2 = '' OR tenant_id = $2)
+	`, id, TenantFromContext(ctx))
+	if err != nil {
+		return false, fmt.Errorf("failed to delete API key: %w", err)
+	}
+
+	n, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	return n > 0, nil
+}
@@ -0,0 +1,176 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"zpwoot/internal/model"
+)
+
+const assistantConversationColumns = `
+			session_id, phone, status, created_at, updated_at`
+
+type AssistantRepository struct {
+	db *sql.DB
+}
+
+func NewAssistantRepository(db *sql.DB) *AssistantRepository {
+	return &AssistantRepository{db: db}
+}
+
+func scanAssistantConversation(row rowScanner, conversation *model.AssistantConversation) error {
+	return row.Scan(
+		&conversation.SessionID, &conversation.Phone, &conversation.Status,
+		&conversation.CreatedAt, &conversation.UpdatedAt,
+	)
+}
+
+// GetConversation retorna a conversa do contato com o assistente (nil se não existe)
+func (r *AssistantRepository) GetConversation(ctx context.Context, sessionID, phone string) (*model.AssistantConversation, error) {
+	query := `
+		SELECT ` + assistantConversationColumns + `
+		FROM assistant_conversations
+		WHERE session_id = $1 AND phone = $2
+	`
+
+	conversation := &model.AssistantConversation{}
+
+	err := scanAssistantConversation(r.db.QueryRowContext(ctx, query, sessionID, phone), conversation)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get assistant conversation: %w", err)
+	}
+
+	return conversation, nil
+}
+
+// SetStatus grava o status da conversa, criando-a se necessário. Também renova updated_at,
+// usado na expiração.
+func (r *AssistantRepository) SetStatus(ctx context.Context, sessionID, phone string, status model.AssistantStatus) error {
+	query := `
+		INSERT INTO assistant_conversations (session_id, phone, status)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (session_id, phone) DO UPDATE SET status = EXCLUDED.status
+	`
+
+	if _, err := r.db.ExecContext(ctx, query, sessionID, phone, status); err != nil {
+		return fmt.Errorf("failed to save assistant conversation: %w", err)
+	}
+
+	return nil
+}
+
+// DeleteConversation encerra a conversa do contato e apaga a memória
+func (r *AssistantRepository) DeleteConversation(ctx context.Context, sessionID, phone string) (bool, error) {
+	result, err := r.db.ExecContext(ctx, `
+		DELETE FROM assistant_conversations WHERE session_id = $1 AND phone = $2
+	`, sessionID, phone)
+	if err != nil {
+		return false, fmt.Errorf("failed to delete assistant conversation: %w", err)
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to get affected rows: %w", err)
+	}
+
+	return affected > 0, nil
+}
+
+// ListConversations retorna as conversas da sessão, das mais recentes para as mais antigas
+func (r *AssistantRepository) ListConversations(ctx context.Context, sessionID string, status model.AssistantStatus, limit, offset int) ([]*model.AssistantConversation, error) {
+	query := `
+		SELECT ` + assistantConversationColumns + `
+		FROM assistant_conversations
+		WHERE session_id = $1 AND ($2 = '' OR status = $2)
+		ORDER BY updated_at DESC
+		LIMIT $3 OFFSET $4
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, sessionID, status, limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list assistant conversations: %w", err)
+	}
+	defer rows.Close()
+
+	var conversations []*model.AssistantConversation
+	for rows.Next() {
+		conversation := &model.AssistantConversation{}
+		if err := scanAssistantConversation(rows, conversation); err != nil {
+			return nil, fmt.Errorf("failed to scan assistant conversation: %w", err)
+		}
+		conversations = append(conversations, conversation)
+	}
+
+	return conversations, rows.Err()
+}
+
+// ListMessages retorna as últimas mensagens da conversa, da mais antiga para a mais recente
+func (r *AssistantRepository) ListMessages(ctx context.Context, sessionID, phone string, limit int) ([]model.AssistantMessage, error) {
+	query := `
+		SELECT role, content, created_at FROM (
+			SELECT id, role, content, created_at
+			FROM assistant_messages
+			WHERE session_id = $1 AND phone = $2
+			ORDER BY id DESC
+			LIMIT $3
+		) recent
+		ORDER BY id ASC
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, sessionID, phone, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list assistant messages: %w", err)
+	}
+	defer rows.Close()
+
+	var messages []model.AssistantMessage
+	for rows.Next() {
+		var message model.AssistantMessage
+		if err := rows.Scan(&message.Role, &message.Content, &message.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan assistant message: %w", err)
+		}
+		messages = append(messages, message)
+	}
+
+	return messages, rows.Err()
+}
+
+// AppendMessages grava as mensagens na memória da conversa e descarta as que saíram da janela
+func (r *AssistantRepository) AppendMessages(ctx context.Context, sessionID, phone string, window int, messages ...model.AssistantMessage) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	for _, message := range messages {
+		if _, err := tx.ExecContext(ctx, `
+			INSERT INTO assistant_messages (session_id, phone, role, content)
+			VALUES ($1, $2, $3, $4)
+		`, sessionID, phone, message.Role, message.Content); err != nil {
+			return fmt.Errorf("failed to save assistant message: %w", err)
+		}
+	}
+
+	if _, err := tx.ExecContext(ctx, `
+		DELETE FROM assistant_messages
+		WHERE session_id = $1 AND phone = $2 AND id NOT IN (
+			SELECT id FROM assistant_messages
+			WHERE session_id = $1 AND phone = $2
+			ORDER BY id DESC
+			LIMIT $3
+		)
+	`, sessionID, phone, window); err != nil {
+		return fmt.Errorf("failed to trim assistant messages: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
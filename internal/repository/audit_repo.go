@@ -0,0 +1,137 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/lib/pq"
+
+	"zpwoot/internal/model"
+)
+
+const auditColumns = `
+			id, COALESCE(tenant_id, ''), actor_key_id, actor_key_prefix, actor_name, ip,
+			method, route, path, request_id, COALESCE(session_id, ''), COALESCE(session_jid, ''),
+			summary, status, result, duration_ms, created_at`
+
+type AuditRepository struct {
+	db *sql.DB
+}
+
+func NewAuditRepository(db *sql.DB) *AuditRepository {
+	return &AuditRepository{db: db}
+}
+
+func scanAuditEntry(row rowScanner, entry *model.AuditEntry) error {
+	var summary []byte
+
+	if err := row.Scan(
+		&entry.ID, &entry.TenantID, &entry.ActorKeyID, &entry.ActorKeyPrefix, &entry.ActorName, &entry.IP,
+		&entry.Method, &entry.Route, &entry.Path, &entry.RequestID, &entry.SessionID, &entry.SessionJID,
+		&summary, &entry.Status, &entry.Result, &entry.DurationMs, &entry.CreatedAt,
+	); err != nil {
+		return err
+	}
+
+	if len(summary) > 0 {
+		entry.Summary = summary
+	}
+	return nil
+}
+
+// Create grava o registro; o número da sessão é copiado da tabela sessions no mesmo INSERT
+func (r *AuditRepository) Create(ctx context.Context, entry *model.AuditEntry) error {
+	var summary interface{}
+	if len(entry.Summary) > 0 {
+		summary = []byte(entry.Summary)
+	}
+
+	query := `
+		INSERT INTO audit_log (
+			tenant_id, actor_key_id, actor_key_prefix, actor_name, ip,
+			method, route, path, request_id, session_id, session_jid,
+			summary, status, result, duration_ms
+		) VALUES (
+			NULLIF($1, ''), $2, $3, $4, $5,
+			$6, $7, $8, $9, NULLIF($10, ''),
+			(SELECT NULLIF(device_jid, '') FROM sessions WHERE id = $10),
+			$11, $12, $13, $14
+		) RETURNING id, COALESCE(session_jid, ''), created_at
+	`
+
+	err := r.db.QueryRowContext(ctx, query,
+		entry.TenantID, entry.ActorKeyID, entry.ActorKeyPrefix, entry.ActorName, entry.IP,
+		entry.Method, entry.Route, entry.Path, entry.RequestID, entry.SessionID,
+		summary, entry.Status, string(entry.Result), entry.DurationMs,
+	).Scan(&entry.ID, &entry.SessionJID, &entry.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create audit entry: %w", err)
+	}
+
+	return nil
+}
+
+// List retorna os registros mais recentes primeiro, restritos ao tenant e às sessões do contexto
+func (r *AuditRepository) List(ctx context.Context, filter model.AuditFilter) ([]*model.AuditEntry, error) {
+	query := `
+		SELECT ` + auditColumns + `
+		FROM audit_log
+		WHERE ($1 = '' OR tenant_id = $1)
+			AND ($2::text[] IS NULL OR session_id = ANY($2::text[]))
+			AND ($3 = '' OR session_id = $3)
+			AND ($4 = '' OR actor_key_id = $4)
+			AND ($5 = '' OR method = $5)
+			AND ($6 = '' OR route = $6)
+			AND ($7 = '' OR result = $7)
+			AND ($8 = '' OR request_id = $8)
+			AND ($9::timestamptz IS NULL OR created_at >= $9)
+			AND ($10::timestamptz IS NULL OR created_at < $10)
+		ORDER BY created_at DESC, id DESC
+		LIMIT $11 OFFSET $12
+	`
+
+	var sessionIDs interface{}
+	if ids := SessionIDsFromContext(ctx); ids != nil {
+		sessionIDs = pq.Array(ids)
+	}
+
+	rows, err := r.db.QueryContext(ctx, query,
+		TenantFromContext(ctx), sessionIDs, filter.SessionID, filter.ActorKeyID, filter.Method, filter.Route,
+		string(filter.Result), filter.RequestID, filter.From, filter.To, filter.Limit, filter.Offset,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list audit entries: %w", err)
+	}
+	defer rows.Close()
+
+	entries := []*model.AuditEntry{}
+	for rows.Next() {
+		entry := &model.AuditEntry{}
+		if err := scanAuditEntry(rows, entry); err != nil {
+			return nil, fmt.Errorf("failed to scan audit entry: %w", err)
+		}
+		entries = append(entries, entry)
+	}
+
+	return entries, rows.Err()
+}
+
+// DeleteBefore remove até limit registros anteriores a before e retorna quantos foram removidos
+func (r *AuditRepository) DeleteBefore(ctx context.Context, before time.Time, limit int) (int64, error) {
+	result, err := r.db.ExecContext(ctx, `
+		DELETE FROM audit_log
+		WHERE id IN (SELECT id FROM audit_log WHERE created_at < $1 ORDER BY id LIMIT $2)
+	`, before, limit)
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete audit entries: %w", err)
+	}
+
+	n, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	return n, nil
+}
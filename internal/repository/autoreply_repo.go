@@ -0,0 +1,173 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"zpwoot/internal/model"
+)
+
+const autoReplyColumns = `
+			id, session_id, name, match_type, pattern, business_hours, response,
+			cooldown_seconds, enabled, priority, created_at, updated_at`
+
+type AutoReplyRepository struct {
+	db *sql.DB
+}
+
+func NewAutoReplyRepository(db *sql.DB) *AutoReplyRepository {
+	return &AutoReplyRepository{db: db}
+}
+
+func scanAutoReplyRule(row rowScanner, rule *model.AutoReplyRule) error {
+	rule.Response = &model.AutoReplyResponse{}
+
+	return row.Scan(
+		&rule.ID, &rule.SessionID, &rule.Name, &rule.MatchType, &rule.Pattern,
+		&rule.BusinessHours, rule.Response,
+		&rule.Cooldown, &rule.Enabled, &rule.Priority, &rule.CreatedAt, &rule.UpdatedAt,
+	)
+}
+
+func (r *AutoReplyRepository) Create(ctx context.Context, rule *model.AutoReplyRule) error {
+	query := `
+		INSERT INTO autoreply_rules (
+			id, session_id, name, match_type, pattern, business_hours, response,
+			cooldown_seconds, enabled, priority
+		) VALUES (
+			$1, $2, $3, $4, $5, $6, $7, $8, $9, $10
+		) RETURNING created_at, updated_at
+	`
+
+	err := r.db.QueryRowContext(ctx, query,
+		rule.ID, rule.SessionID, rule.Name, rule.MatchType, rule.Pattern, rule.BusinessHours, rule.Response,
+		rule.Cooldown, rule.Enabled, rule.Priority,
+	).Scan(&rule.CreatedAt, &rule.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create auto-reply rule: %w", err)
+	}
+
+	return nil
+}
+
+func (r *AutoReplyRepository) GetByID(ctx context.Context, sessionID, id string) (*model.AutoReplyRule, error) {
+	query := `
+		SELECT ` + autoReplyColumns + `
+		FROM autoreply_rules
+		WHERE session_id = $1 AND id = $2
+	`
+
+	rule := &model.AutoReplyRule{}
+
+	err := scanAutoReplyRule(r.db.QueryRowContext(ctx, query, sessionID, id), rule)
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("auto-reply rule not found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get auto-reply rule: %w", err)
+	}
+
+	return rule, nil
+}
+
+// List retorna as regras da sessão na ordem de avaliação (prioridade decrescente)
+func (r *AutoReplyRepository) List(ctx context.Context, sessionID string, onlyEnabled bool) ([]*model.AutoReplyRule, error) {
+	query := `
+		SELECT ` + autoReplyColumns + `
+		FROM autoreply_rules
+		WHERE session_id = $1 AND (NOT $2 OR enabled)
+		ORDER BY priority DESC, created_at ASC
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, sessionID, onlyEnabled)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list auto-reply rules: %w", err)
+	}
+	defer rows.Close()
+
+	var rules []*model.AutoReplyRule
+	for rows.Next() {
+		rule := &model.AutoReplyRule{}
+		if err := scanAutoReplyRule(rows, rule); err != nil {
+			return nil, fmt.Errorf("failed to scan auto-reply rule: %w", err)
+		}
+		rules = append(rules, rule)
+	}
+
+	return rules, rows.Err()
+}
+
+func (r *AutoReplyRepository) Update(ctx context.Context, rule *model.AutoReplyRule) error {
+	query := `
+		UPDATE autoreply_rules SET
+			name = $3,
+			match_type = $4,
+			pattern = $5,
+			business_hours = $6,
+			response = $7,
+			cooldown_seconds = $8,
+			enabled = $9,
+			priority = $10
+		WHERE session_id = $1 AND id = $2
+		RETURNING created_at, updated_at
+	`
+
+	err := r.db.QueryRowContext(ctx, query,
+		rule.SessionID, rule.ID, rule.Name, rule.MatchType, rule.Pattern, rule.BusinessHours, rule.Response,
+		rule.Cooldown, rule.Enabled, rule.Priority,
+	).Scan(&rule.CreatedAt, &rule.UpdatedAt)
+	if err == sql.ErrNoRows {
+		return fmt.Errorf("auto-reply rule not found")
+	}
+	if err != nil {
+		return fmt.Errorf("failed to update auto-reply rule: %w", err)
+	}
+
+	return nil
+}
+
+func (r *AutoReplyRepository) Delete(ctx context.Context, sessionID, id string) error {
+	result, err := r.db.ExecContext(ctx, `DELETE FROM autoreply_rules WHERE session_id = $1 AND id = $2`, sessionID, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete auto-reply rule: %w", err)
+	}
+
+	if n, _ := result.RowsAffected(); n == 0 {
+		return fmt.Errorf("auto-reply rule not found")
+	}
+
+	return nil
+}
+
+// MarkContactSeen registra o contato na sessão e informa se é o primeiro registro (primeira mensagem)
+func (r *AutoReplyRepository) MarkContactSeen(ctx context.Context, sessionID, contact string) (bool, error) {
+	result, err := r.db.ExecContext(ctx, `
+		INSERT INTO autoreply_contacts (session_id, contact)
+		VALUES ($1, $2)
+		ON CONFLICT (session_id, contact) DO NOTHING
+	`, sessionID, contact)
+	if err != nil {
+		return false, fmt.Errorf("failed to mark contact as seen: %w", err)
+	}
+
+	n, _ := result.RowsAffected()
+	return n > 0, nil
+}
+
+// AcquireCooldown registra o envio da regra ao contato se o cooldown já expirou.
+// A verificação e o registro são atômicos, então réplicas concorrentes não respondem em dobro.
+func (r *AutoReplyRepository) AcquireCooldown(ctx context.Context, ruleID, contact string, cooldownSeconds int) (bool, error) {
+	result, err := r.db.ExecContext(ctx, `
+		INSERT INTO autoreply_cooldowns (rule_id, contact, last_sent_at)
+		VALUES ($1, $2, NOW())
+		ON CONFLICT (rule_id, contact) DO UPDATE SET last_sent_at = NOW()
+		WHERE autoreply_cooldowns.last_sent_at <= NOW() - make_interval(secs => $3)
+	`, ruleID, contact, cooldownSeconds)
+	if err != nil {
+		return false, fmt.Errorf("failed to acquire auto-reply cooldown: %w", err)
+	}
+
+	n, _ := result.RowsAffected()
+	return n > 0, nil
+}
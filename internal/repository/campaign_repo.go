@@ -0,0 +1,406 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/lib/pq"
+
+	"zpwoot/internal/model"
+)
+
+const campaignColumns = `
+			id, session_id, name, template, min_delay, max_delay, status,
+			created_at, updated_at, started_at, completed_at`
+
+const recipientColumns = `
+			id, campaign_id, phone, variables, status, message_id, error, sent_at`
+
+type CampaignRepository struct {
+	db *sql.DB
+}
+
+func NewCampaignRepository(db *sql.DB) *CampaignRepository {
+	return &CampaignRepository{db: db}
+}
+
+func scanCampaign(row rowScanner, campaign *model.Campaign) error {
+	campaign.Template = &model.CampaignTemplate{}
+
+	return row.Scan(
+		&campaign.ID, &campaign.SessionID, &campaign.Name, campaign.Template,
+		&campaign.MinDelay, &campaign.MaxDelay, &campaign.Status,
+		&campaign.CreatedAt, &campaign.UpdatedAt, &campaign.StartedAt, &campaign.CompletedAt,
+	)
+}
+
+func scanCampaigns(rows *sql.Rows) ([]*model.Campaign, error) {
+	defer rows.Close()
+
+	var campaigns []*model.Campaign
+	for rows.Next() {
+		campaign := &model.Campaign{}
+		if err := scanCampaign(rows, campaign); err != nil {
+			return nil, fmt.Errorf("failed to scan campaign: %w", err)
+		}
+		campaigns = append(campaigns, campaign)
+	}
+
+	return campaigns, rows.Err()
+}
+
+func scanRecipient(row rowScanner, recipient *model.CampaignRecipient) error {
+	var messageID, lastError sql.NullString
+
+	if err := row.Scan(
+		&recipient.ID, &recipient.CampaignID, &recipient.Phone, &recipient.Variables,
+		&recipient.Status, &messageID, &lastError, &recipient.SentAt,
+	); err != nil {
+		return err
+	}
+
+	recipient.MessageID = messageID.String
+	recipient.Error = lastError.String
+	return nil
+}
+
+func (r *CampaignRepository) Create(ctx context.Context, campaign *model.Campaign) error {
+	query := `
+		INSERT INTO campaigns (
+			id, session_id, name, template, min_delay, max_delay, status
+		) VALUES (
+			$1, $2, $3, $4, $5, $6, $7
+		) RETURNING created_at, updated_at
+	`
+
+	err := r.db.QueryRowContext(ctx, query,
+		campaign.ID, campaign.SessionID, campaign.Name, campaign.Template,
+		campaign.MinDelay, campaign.MaxDelay, campaign.Status,
+	).Scan(&campaign.CreatedAt, &campaign.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create campaign: %w", err)
+	}
+
+	return nil
+}
+
+func (r *CampaignRepository) GetByID(ctx context.Context, sessionID, id string) (*model.Campaign, error) {
+	query := `
+		SELECT ` + campaignColumns + `
+		FROM campaigns
+		WHERE session_id = $1 AND id = $2
+	`
+
+	campaign := &model.Campaign{}
+
+	err := scanCampaign(r.db.QueryRowContext(ctx, query, sessionID, id), campaign)
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("campaign not found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get campaign: %w", err)
+	}
+
+	return campaign, nil
+}
+
+func (r *CampaignRepository) List(ctx context.Context, sessionID string) ([]*model.Campaign, error) {
+	query := `
+		SELECT ` + campaignColumns + `
+		FROM campaigns
+		WHERE session_id = $1
+		ORDER BY created_at DESC
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, sessionID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list campaigns: %w", err)
+	}
+
+	return scanCampaigns(rows)
+}
+
+// UpdateStatus muda o status da campanha se o status atual estiver em from.
+// O lease é mantido: o worker percebe a mudança na próxima renovação e o libera.
+func (r *CampaignRepository) UpdateStatus(ctx context.Context, sessionID, id string, to model.CampaignStatus, from ...model.CampaignStatus) (*model.Campaign, error) {
+	allowed := make([]string, 0, len(from))
+	for _, status := range from {
+		allowed = append(allowed, string(status))
+	}
+
+	query := `
+		UPDATE campaigns SET
+			status = $3,
+			started_at = CASE WHEN $3 = 'running' THEN COALESCE(started_at, NOW()) ELSE started_at END,
+			completed_at = CASE WHEN $3 IN ('completed', 'canceled') THEN NOW() ELSE completed_at END
+		WHERE session_id = $1 AND id = $2 AND status = ANY(string_to_array($4, ','))
+		RETURNING ` + campaignColumns
+
+	campaign := &model.Campaign{}
+
+	err := scanCampaign(r.db.QueryRowContext(ctx, query, sessionID, id, string(to), strings.Join(allowed, ",")), campaign)
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("campaign not found or cannot change to %s", to)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to update campaign status: %w", err)
+	}
+
+	return campaign, nil
+}
+
+// AcquireLeases reserva para owner as campanhas em execução sem lease válido das sessões
+// informadas (as hospedadas pela réplica).
+// FOR UPDATE SKIP LOCKED e o lease garantem que cada campanha roda em uma única réplica.
+func (r *CampaignRepository) AcquireLeases(ctx context.Context, owner string, lease time.Duration, sessionIDs []string) ([]*model.Campaign, error) {
+	query := `
+		UPDATE campaigns SET
+			lease_owner = $1,
+			lease_until = NOW() + make_interval(secs => $2)
+		WHERE id IN (
+			SELECT id FROM campaigns
+			WHERE status = 'running'
+				AND session_id = ANY($3::text[])
+				AND (lease_until IS NULL OR lease_until < NOW())
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING ` + campaignColumns
+
+	rows, err := r.db.QueryContext(ctx, query, owner, lease.Seconds(), pq.Array(sessionIDs))
+	if err != nil {
+		return nil, fmt.Errorf("failed to acquire campaign leases: %w", err)
+	}
+
+	return scanCampaigns(rows)
+}
+
+// RenewLease estende o lease e retorna o status atual; ok=false se o lease foi perdido
+func (r *CampaignRepository) RenewLease(ctx context.Context, id, owner string, lease time.Duration) (model.CampaignStatus, bool, error) {
+	query := `
+		UPDATE campaigns SET
+			lease_until = NOW() + make_interval(secs => $3)
+		WHERE id = $1 AND lease_owner = $2
+		RETURNING status
+	`
+
+	var status model.CampaignStatus
+	err := r.db.QueryRowContext(ctx, query, id, owner, lease.Seconds()).Scan(&status)
+	if err == sql.ErrNoRows {
+		return "", false, nil
+	}
+	if err != nil {
+		return "", false, fmt.Errorf("failed to renew campaign lease: %w", err)
+	}
+
+	return status, true, nil
+}
+
+// ReleaseLease libera o lease para outra réplica assumir a campanha
+func (r *CampaignRepository) ReleaseLease(ctx context.Context, id, owner string) error {
+	query := `
+		UPDATE campaigns SET lease_owner = NULL, lease_until = NULL
+		WHERE id = $1 AND lease_owner = $2
+	`
+
+	if _, err := r.db.ExecContext(ctx, query, id, owner); err != nil {
+		return fmt.Errorf("failed to release campaign lease: %w", err)
+	}
+
+	return nil
+}
+
+// Complete finaliza a campanha em execução
+func (r *CampaignRepository) Complete(ctx context.Context, id string) error {
+	query := `
+		UPDATE campaigns SET
+			status = 'completed',
+			completed_at = NOW(),
+			lease_owner = NULL,
+			lease_until = NULL
+		WHERE id = $1 AND status = 'running'
+	`
+
+	if _, err := r.db.ExecContext(ctx, query, id); err != nil {
+		return fmt.Errorf("failed to complete campaign: %w", err)
+	}
+
+	return nil
+}
+
+// AddRecipients insere destinatários em uma única transação, ignorando telefones repetidos.
+// Retorna quantos foram inseridos.
+func (r *CampaignRepository) AddRecipients(ctx context.Context, campaignID string, recipients []*model.CampaignRecipient) (int, error) {
+	if len(recipients) == 0 {
+		return 0, nil
+	}
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	stmt, err := tx.PrepareContext(ctx, `
+		INSERT INTO campaign_recipients (
+			campaign_id, phone, variables
+		) VALUES (
+			$1, $2, $3
+		)
+		ON CONFLICT (campaign_id, phone) DO NOTHING
+	`)
+	if err != nil {
+		return 0, fmt.Errorf("failed to prepare recipient insert: %w", err)
+	}
+	defer stmt.Close()
+
+	added := 0
+	for _, recipient := range recipients {
+		result, err := stmt.ExecContext(ctx, campaignID, recipient.Phone, recipient.Variables)
+		if err != nil {
+			return 0, fmt.Errorf("failed to insert recipient %s: %w", recipient.Phone, err)
+		}
+		if n, err := result.RowsAffected(); err == nil {
+			added += int(n)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("failed to commit recipients: %w", err)
+	}
+
+	return added, nil
+}
+
+// ListRecipients lista os destinatários da campanha (status vazio = todos)
+func (r *CampaignRepository) ListRecipients(ctx context.Context, campaignID string, status model.RecipientStatus, limit, offset int) ([]*model.CampaignRecipient, error) {
+	query := `
+		SELECT ` + recipientColumns + `
+		FROM campaign_recipients
+		WHERE campaign_id = $1 AND ($2 = '' OR status = $2)
+		ORDER BY id ASC
+		LIMIT $3 OFFSET $4
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, campaignID, string(status), limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list recipients: %w", err)
+	}
+	defer rows.Close()
+
+	var recipients []*model.CampaignRecipient
+	for rows.Next() {
+		recipient := &model.CampaignRecipient{}
+		if err := scanRecipient(rows, recipient); err != nil {
+			return nil, fmt.Errorf("failed to scan recipient: %w", err)
+		}
+		recipients = append(recipients, recipient)
+	}
+
+	return recipients, rows.Err()
+}
+
+// Progress conta os destinatários da campanha por status
+func (r *CampaignRepository) Progress(ctx context.Context, campaignID string) (*model.CampaignProgress, error) {
+	query := `
+		SELECT status, COUNT(*)
+		FROM campaign_recipients
+		WHERE campaign_id = $1
+		GROUP BY status
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, campaignID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get campaign progress: %w", err)
+	}
+	defer rows.Close()
+
+	progress := &model.CampaignProgress{}
+	for rows.Next() {
+		var status model.RecipientStatus
+		var count int
+		if err := rows.Scan(&status, &count); err != nil {
+			return nil, fmt.Errorf("failed to scan campaign progress: %w", err)
+		}
+
+		progress.Total += count
+		switch status {
+		case model.RecipientStatusSent:
+			progress.Sent = count
+		case model.RecipientStatusFailed:
+			progress.Failed = count
+		case model.RecipientStatusNotOnWhatsApp:
+			progress.NotOnWhatsApp = count
+		default:
+			progress.Pending += count
+		}
+	}
+
+	return progress, rows.Err()
+}
+
+// NextRecipient reserva o próximo destinatário pendente marcando-o como sending.
+// Retorna nil quando não há mais pendentes.
+func (r *CampaignRepository) NextRecipient(ctx context.Context, campaignID string) (*model.CampaignRecipient, error) {
+	query := `
+		UPDATE campaign_recipients SET status = 'sending'
+		WHERE id = (
+			SELECT id FROM campaign_recipients
+			WHERE campaign_id = $1 AND status = 'pending'
+			ORDER BY id ASC
+			LIMIT 1
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING ` + recipientColumns
+
+	recipient := &model.CampaignRecipient{}
+
+	err := scanRecipient(r.db.QueryRowContext(ctx, query, campaignID), recipient)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to claim recipient: %w", err)
+	}
+
+	return recipient, nil
+}
+
+// FailInterrupted marca como failed os destinatários que ficaram em sending (réplica caiu durante
+// o envio). Não voltam para pending porque a mensagem pode ter sido entregue.
+func (r *CampaignRepository) FailInterrupted(ctx context.Context, campaignID string) error {
+	query := `
+		UPDATE campaign_recipients SET
+			status = 'failed',
+			error = 'interrupted before send confirmation'
+		WHERE campaign_id = $1 AND status = 'sending'
+	`
+
+	if _, err := r.db.ExecContext(ctx, query, campaignID); err != nil {
+		return fmt.Errorf("failed to fail interrupted recipients: %w", err)
+	}
+
+	return nil
+}
+
+// UpdateRecipient grava o resultado do envio
+func (r *CampaignRepository) UpdateRecipient(ctx context.Context, recipient *model.CampaignRecipient) error {
+	query := `
+		UPDATE campaign_recipients SET
+			status = $1,
+			message_id = NULLIF($2, ''),
+			error = NULLIF($3, ''),
+			sent_at = $4
+		WHERE id = $5
+	`
+
+	if _, err := r.db.ExecContext(ctx, query,
+		recipient.Status, recipient.MessageID, recipient.Error, recipient.SentAt, recipient.ID,
+	); err != nil {
+		return fmt.Errorf("failed to update recipient: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,193 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"zpwoot/internal/model"
+)
+
+const chatwootMessageColumns = `
+			session_id, message_id, chatwoot_message_id, conversation_id, phone, from_me, created_at`
+
+type ChatwootRepository struct {
+	db *sql.DB
+}
+
+func NewChatwootRepository(db *sql.DB) *ChatwootRepository {
+	return &ChatwootRepository{db: db}
+}
+
+func scanChatwootMessage(row rowScanner, message *model.ChatwootMessage) error {
+	return row.Scan(
+		&message.SessionID, &message.MessageID, &message.ChatwootMessageID, &message.ConversationID,
+		&message.Phone, &message.FromMe, &message.CreatedAt,
+	)
+}
+
+// GetConversation retorna a conversa do contato (nil se ainda não existe)
+func (r *ChatwootRepository) GetConversation(ctx context.Context, sessionID, phone string) (*model.ChatwootConversation, error) {
+	query := `
+		SELECT session_id, phone, contact_id, conversation_id, created_at, updated_at
+		FROM chatwoot_conversations
+		WHERE session_id = $1 AND phone = $2
+	`
+
+	conversation := &model.ChatwootConversation{}
+
+	err := r.db.QueryRowContext(ctx, query, sessionID, phone).Scan(
+		&conversation.SessionID, &conversation.Phone, &conversation.ContactID, &conversation.ConversationID,
+		&conversation.CreatedAt, &conversation.UpdatedAt,
+	)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get chatwoot conversation: %w", err)
+	}
+
+	return conversation, nil
+}
+
+// GetPhoneByConversation retorna o telefone do contato de uma conversa do Chatwoot
+func (r *ChatwootRepository) GetPhoneByConversation(ctx context.Context, sessionID string, conversationID int64) (string, error) {
+	var phone string
+
+	err := r.db.QueryRowContext(ctx, `
+		SELECT phone FROM chatwoot_conversations
+		WHERE session_id = $1 AND conversation_id = $2
+	`, sessionID, conversationID).Scan(&phone)
+	if err == sql.ErrNoRows {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to get chatwoot conversation: %w", err)
+	}
+
+	return phone, nil
+}
+
+// SaveConversation grava (ou substitui) a conversa do contato
+func (r *ChatwootRepository) SaveConversation(ctx context.Context, conversation *model.ChatwootConversation) error {
+	query := `
+		INSERT INTO chatwoot_conversations (session_id, phone, contact_id, conversation_id)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (session_id, phone) DO UPDATE SET
+			contact_id = EXCLUDED.contact_id,
+			conversation_id = EXCLUDED.conversation_id
+		RETURNING created_at, updated_at
+	`
+
+	err := r.db.QueryRowContext(ctx, query,
+		conversation.SessionID, conversation.Phone, conversation.ContactID, conversation.ConversationID,
+	).Scan(&conversation.CreatedAt, &conversation.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to save chatwoot conversation: %w", err)
+	}
+
+	return nil
+}
+
+func (r *ChatwootRepository) DeleteConversation(ctx context.Context, sessionID, phone string) error {
+	if _, err := r.db.ExecContext(ctx, `
+		DELETE FROM chatwoot_conversations WHERE session_id = $1 AND phone = $2
+	`, sessionID, phone); err != nil {
+		return fmt.Errorf("failed to delete chatwoot conversation: %w", err)
+	}
+	return nil
+}
+
+func (r *ChatwootRepository) SaveMessage(ctx context.Context, message *model.ChatwootMessage) error {
+	query := `
+		INSERT INTO chatwoot_messages (
+			session_id, message_id, chatwoot_message_id, conversation_id, phone, from_me
+		) VALUES (
+			$1, $2, $3, $4, $5, $6
+		)
+		ON CONFLICT (session_id, message_id) DO NOTHING
+	`
+
+	if _, err := r.db.ExecContext(ctx, query,
+		message.SessionID, message.MessageID, message.ChatwootMessageID, message.ConversationID,
+		message.Phone, message.FromMe,
+	); err != nil {
+		return fmt.Errorf("failed to save chatwoot message: %w", err)
+	}
+
+	return nil
+}
+
+// GetMessage retorna o mapeamento pelo ID da mensagem no WhatsApp (nil se não existe)
+func (r *ChatwootRepository) GetMessage(ctx context.Context, sessionID, messageID string) (*model.ChatwootMessage, error) {
+	query := `
+		SELECT ` + chatwootMessageColumns + `
+		FROM chatwoot_messages
+		WHERE session_id = $1 AND message_id = $2
+	`
+
+	message := &model.ChatwootMessage{}
+
+	err := scanChatwootMessage(r.db.QueryRowContext(ctx, query, sessionID, messageID), message)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get chatwoot message: %w", err)
+	}
+
+	return message, nil
+}
+
+// GetMessageByChatwootID retorna o mapeamento pelo ID da mensagem no Chatwoot (nil se não existe)
+func (r *ChatwootRepository) GetMessageByChatwootID(ctx context.Context, sessionID string, chatwootMessageID int64) (*model.ChatwootMessage, error) {
+	query := `
+		SELECT ` + chatwootMessageColumns + `
+		FROM chatwoot_messages
+		WHERE session_id = $1 AND chatwoot_message_id = $2
+	`
+
+	message := &model.ChatwootMessage{}
+
+	err := scanChatwootMessage(r.db.QueryRowContext(ctx, query, sessionID, chatwootMessageID), message)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get chatwoot message: %w", err)
+	}
+
+	return message, nil
+}
+
+// ListUnreadIncoming retorna as mensagens recebidas do contato depois da última resposta da sessão
+func (r *ChatwootRepository) ListUnreadIncoming(ctx context.Context, sessionID string, conversationID int64, limit int) ([]string, error) {
+	query := `
+		SELECT message_id
+		FROM chatwoot_messages
+		WHERE session_id = $1 AND conversation_id = $2 AND NOT from_me
+			AND created_at > COALESCE((
+				SELECT MAX(created_at) FROM chatwoot_messages
+				WHERE session_id = $1 AND conversation_id = $2 AND from_me
+			), '-infinity')
+		ORDER BY created_at ASC
+		LIMIT $3
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, sessionID, conversationID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list unread chatwoot messages: %w", err)
+	}
+	defer rows.Close()
+
+	var ids []string
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("failed to scan chatwoot message: %w", err)
+		}
+		ids = append(ids, id)
+	}
+
+	return ids, rows.Err()
+}
@@ -0,0 +1,152 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+
+	"zpwoot/internal/model"
+)
+
+const flowBotSessionColumns = `
+			session_id, phone, bot_session_id, status, choices, created_at, updated_at`
+
+type FlowBotRepository struct {
+	db *sql.DB
+}
+
+func NewFlowBotRepository(db *sql.DB) *FlowBotRepository {
+	return &FlowBotRepository{db: db}
+}
+
+func scanFlowBotSession(row rowScanner, session *model.FlowBotSession) error {
+	var choices []byte
+
+	if err := row.Scan(
+		&session.SessionID, &session.Phone, &session.BotSessionID, &session.Status, &choices,
+		&session.CreatedAt, &session.UpdatedAt,
+	); err != nil {
+		return err
+	}
+
+	if len(choices) > 0 {
+		if err := json.Unmarshal(choices, &session.Choices); err != nil {
+			return fmt.Errorf("failed to decode flow bot choices: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// Get retorna a conversa do contato com o fluxo (nil se não existe)
+func (r *FlowBotRepository) Get(ctx context.Context, sessionID, phone string) (*model.FlowBotSession, error) {
+	query := `
+		SELECT ` + flowBotSessionColumns + `
+		FROM flowbot_sessions
+		WHERE session_id = $1 AND phone = $2
+	`
+
+	session := &model.FlowBotSession{}
+
+	err := scanFlowBotSession(r.db.QueryRowContext(ctx, query, sessionID, phone), session)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get flow bot session: %w", err)
+	}
+
+	return session, nil
+}
+
+// Save grava (ou substitui) a conversa do contato
+func (r *FlowBotRepository) Save(ctx context.Context, session *model.FlowBotSession) error {
+	var choices []byte
+	if len(session.Choices) > 0 {
+		var err error
+		if choices, err = json.Marshal(session.Choices); err != nil {
+			return fmt.Errorf("failed to encode flow bot choices: %w", err)
+		}
+	}
+
+	query := `
+		INSERT INTO flowbot_sessions (session_id, phone, bot_session_id, status, choices)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (session_id, phone) DO UPDATE SET
+			bot_session_id = EXCLUDED.bot_session_id,
+			status = EXCLUDED.status,
+			choices = EXCLUDED.choices
+		RETURNING created_at, updated_at
+	`
+
+	err := r.db.QueryRowContext(ctx, query,
+		session.SessionID, session.Phone, session.BotSessionID, session.Status, choices,
+	).Scan(&session.CreatedAt, &session.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to save flow bot session: %w", err)
+	}
+
+	return nil
+}
+
+// SetStatus altera o status da conversa, criando-a se necessário (pausar um contato que
+// ainda não falou com o fluxo impede que ele seja iniciado)
+func (r *FlowBotRepository) SetStatus(ctx context.Context, sessionID, phone string, status model.FlowBotStatus) error {
+	query := `
+		INSERT INTO flowbot_sessions (session_id, phone, status)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (session_id, phone) DO UPDATE SET status = EXCLUDED.status
+	`
+
+	if _, err := r.db.ExecContext(ctx, query, sessionID, phone, status); err != nil {
+		return fmt.Errorf("failed to update flow bot session: %w", err)
+	}
+
+	return nil
+}
+
+// Delete encerra a conversa do contato; a próxima mensagem pode iniciar o fluxo novamente
+func (r *FlowBotRepository) Delete(ctx context.Context, sessionID, phone string) (bool, error) {
+	result, err := r.db.ExecContext(ctx, `
+		DELETE FROM flowbot_sessions WHERE session_id = $1 AND phone = $2
+	`, sessionID, phone)
+	if err != nil {
+		return false, fmt.Errorf("failed to delete flow bot session: %w", err)
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to get affected rows: %w", err)
+	}
+
+	return affected > 0, nil
+}
+
+// List retorna as conversas da sessão, das mais recentes para as mais antigas
+func (r *FlowBotRepository) List(ctx context.Context, sessionID string, status model.FlowBotStatus, limit, offset int) ([]*model.FlowBotSession, error) {
+	query := `
+		SELECT ` + flowBotSessionColumns + `
+		FROM flowbot_sessions
+		WHERE session_id = $1 AND ($2 = '' OR status = $2)
+		ORDER BY updated_at DESC
+		LIMIT $3 OFFSET $4
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, sessionID, status, limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list flow bot sessions: %w", err)
+	}
+	defer rows.Close()
+
+	var sessions []*model.FlowBotSession
+	for rows.Next() {
+		session := &model.FlowBotSession{}
+		if err := scanFlowBotSession(rows, session); err != nil {
+			return nil, fmt.Errorf("failed to scan flow bot session: %w", err)
+		}
+		sessions = append(sessions, session)
+	}
+
+	return sessions, rows.Err()
+}
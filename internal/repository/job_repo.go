@@ -0,0 +1,140 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"zpwoot/internal/model"
+)
+
+const jobColumns = `
+			id, session_id, type, phone, payload,
+			status, message_id, error, attempts,
+			created_at, updated_at, sent_at`
+
+// ErrJobNotFound é retornado quando o job não existe (ex: removido junto com a sessão)
+var ErrJobNotFound = errors.New("job not found")
+
+type JobRepository struct {
+	db *sql.DB
+}
+
+func NewJobRepository(db *sql.DB) *JobRepository {
+	return &JobRepository{db: db}
+}
+
+func scanJob(row rowScanner, job *model.MessageJob) error {
+	var messageID, lastError sql.NullString
+	var payload []byte
+
+	if err := row.Scan(
+		&job.ID, &job.SessionID, &job.Type, &job.Phone, &payload,
+		&job.Status, &messageID, &lastError, &job.Attempts,
+		&job.CreatedAt, &job.UpdatedAt, &job.SentAt,
+	); err != nil {
+		return err
+	}
+
+	job.Payload = payload
+	job.MessageID = messageID.String
+	job.Error = lastError.String
+	return nil
+}
+
+func (r *JobRepository) Create(ctx context.Context, job *model.MessageJob) error {
+	query := `
+		INSERT INTO message_jobs (
+			id, session_id, type, phone, payload, status
+		) VALUES (
+			$1, $2, $3, $4, $5, $6
+		) RETURNING created_at, updated_at
+	`
+
+	err := r.db.QueryRowContext(ctx, query,
+		job.ID, job.SessionID, job.Type, job.Phone, string(job.Payload), job.Status,
+	).Scan(&job.CreatedAt, &job.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create job: %w", err)
+	}
+
+	return nil
+}
+
+// CreateIfMissing grava o job com o ID informado. Se o ID já existir (nova tentativa de quem o
+// criou), carrega o job gravado em job e retorna created = false.
+func (r *JobRepository) CreateIfMissing(ctx context.Context, job *model.MessageJob) (bool, error) {
+	query := `
+		INSERT INTO message_jobs (
+			id, session_id, type, phone, payload, status
+		) VALUES (
+			$1, $2, $3, $4, $5, $6
+		)
+		ON CONFLICT (id) DO NOTHING
+		RETURNING created_at, updated_at
+	`
+
+	err := r.db.QueryRowContext(ctx, query,
+		job.ID, job.SessionID, job.Type, job.Phone, string(job.Payload), job.Status,
+	).Scan(&job.CreatedAt, &job.UpdatedAt)
+	if err == sql.ErrNoRows {
+		existing, err := r.GetByID(ctx, job.SessionID, job.ID)
+		if err != nil {
+			return false, err
+		}
+		*job = *existing
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to create job: %w", err)
+	}
+
+	return true, nil
+}
+
+func (r *JobRepository) GetByID(ctx context.Context, sessionID, id string) (*model.MessageJob, error) {
+	query := `
+		SELECT ` + jobColumns + `
+		FROM message_jobs
+		WHERE session_id = $1 AND id = $2
+	`
+
+	job := &model.MessageJob{}
+
+	err := scanJob(r.db.QueryRowContext(ctx, query, sessionID, id), job)
+	if err == sql.ErrNoRows {
+		return nil, ErrJobNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get job: %w", err)
+	}
+
+	return job, nil
+}
+
+// UpdateResult grava status, tentativas e resultado do envio
+func (r *JobRepository) UpdateResult(ctx context.Context, job *model.MessageJob) error {
+	query := `
+		UPDATE message_jobs SET
+			status = $1,
+			message_id = NULLIF($2, ''),
+			error = NULLIF($3, ''),
+			attempts = $4,
+			sent_at = $5
+		WHERE id = $6
+		RETURNING updated_at
+	`
+
+	err := r.db.QueryRowContext(ctx, query,
+		job.Status, job.MessageID, job.Error, job.Attempts, job.SentAt, job.ID,
+	).Scan(&job.UpdatedAt)
+	if err == sql.ErrNoRows {
+		return ErrJobNotFound
+	}
+	if err != nil {
+		return fmt.Errorf("failed to update job: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,173 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"zpwoot/internal/model"
+)
+
+type MessageRepository struct {
+	db *sql.DB
+}
+
+func NewMessageRepository(db *sql.DB) *MessageRepository {
+	return &MessageRepository{db: db}
+}
+
+// UpsertChats insere ou atualiza chats em uma única transação
+func (r *MessageRepository) UpsertChats(ctx context.Context, chats []*model.Chat) error {
+	if len(chats) == 0 {
+		return nil
+	}
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	stmt, err := tx.PrepareContext(ctx, `
+		INSERT INTO chats (
+			session_id, jid, name, unread_count, archived, last_message_at
+		) VALUES (
+			$1, $2, $3, $4, $5, $6
+		)
+		ON CONFLICT (session_id, jid) DO UPDATE SET
+			name = COALESCE(NULLIF(EXCLUDED.name, ''), chats.name),
+			unread_count = EXCLUDED.unread_count,
+			archived = EXCLUDED.archived,
+			last_message_at = GREATEST(chats.last_message_at, EXCLUDED.last_message_at)
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to prepare chat upsert: %w", err)
+	}
+	defer stmt.Close()
+
+	for _, chat := range chats {
+		if _, err := stmt.ExecContext(ctx,
+			chat.SessionID, chat.JID, chat.Name, chat.UnreadCount, chat.Archived, chat.LastMessageAt,
+		); err != nil {
+			return fmt.Errorf("failed to upsert chat %s: %w", chat.JID, err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit chats: %w", err)
+	}
+
+	return nil
+}
+
+// UpsertMessages insere ou atualiza mensagens em uma única transação
+func (r *MessageRepository) UpsertMessages(ctx context.Context, messages []*model.Message) error {
+	if len(messages) == 0 {
+		return nil
+	}
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	stmt, err := tx.PrepareContext(ctx, `
+		INSERT INTO messages (
+			session_id, chat_jid, id, sender_jid, from_me,
+			push_name, type, body, source, raw, timestamp, transcription
+		) VALUES (
+			$1, $2, $3, $4, $5,
+			$6, $7, $8, $9, $10, $11, NULLIF($12, '')
+		)
+		ON CONFLICT (session_id, chat_jid, id) DO UPDATE SET
+			sender_jid = EXCLUDED.sender_jid,
+			push_name = COALESCE(NULLIF(EXCLUDED.push_name, ''), messages.push_name),
+			type = EXCLUDED.type,
+			body = EXCLUDED.body,
+			raw = EXCLUDED.raw,
+			timestamp = EXCLUDED.timestamp,
+			transcription = COALESCE(EXCLUDED.transcription, messages.transcription)
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to prepare message upsert: %w", err)
+	}
+	defer stmt.Close()
+
+	for _, msg := range messages {
+		if _, err := stmt.ExecContext(ctx,
+			msg.SessionID, msg.ChatJID, msg.ID, msg.SenderJID, msg.FromMe,
+			msg.PushName, msg.Type, msg.Body, msg.Source, msg.Raw, msg.Timestamp, msg.Transcription,
+		); err != nil {
+			return fmt.Errorf("failed to upsert message %s: %w", msg.ID, err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit messages: %w", err)
+	}
+
+	return nil
+}
+
+// AddHistorySyncProgress registra um chunk processado, acumulando as contagens da sessão
+func (r *MessageRepository) AddHistorySyncProgress(ctx context.Context, progress *model.HistorySyncProgress) error {
+	query := `
+		INSERT INTO history_sync_progress (
+			session_id, sync_type, chunk_order, progress,
+			chunks_processed, conversations, messages, started_at, updated_at
+		) VALUES (
+			$1, $2, $3, $4,
+			1, $5, $6, NOW(), NOW()
+		)
+		ON CONFLICT (session_id) DO UPDATE SET
+			sync_type = EXCLUDED.sync_type,
+			chunk_order = EXCLUDED.chunk_order,
+			progress = GREATEST(history_sync_progress.progress, EXCLUDED.progress),
+			chunks_processed = history_sync_progress.chunks_processed + 1,
+			conversations = history_sync_progress.conversations + EXCLUDED.conversations,
+			messages = history_sync_progress.messages + EXCLUDED.messages,
+			updated_at = NOW()
+		RETURNING chunks_processed, conversations, messages, progress, started_at, updated_at
+	`
+
+	err := r.db.QueryRowContext(ctx, query,
+		progress.SessionID, progress.SyncType, progress.ChunkOrder, progress.Progress,
+		progress.Conversations, progress.Messages,
+	).Scan(
+		&progress.ChunksProcessed, &progress.Conversations, &progress.Messages,
+		&progress.Progress, &progress.StartedAt, &progress.UpdatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to update history sync progress: %w", err)
+	}
+
+	return nil
+}
+
+func (r *MessageRepository) GetHistorySyncProgress(ctx context.Context, sessionID string) (*model.HistorySyncProgress, error) {
+	query := `
+		SELECT
+			session_id, sync_type, chunk_order, progress,
+			chunks_processed, conversations, messages, started_at, updated_at
+		FROM history_sync_progress
+		WHERE session_id = $1
+	`
+
+	progress := &model.HistorySyncProgress{}
+
+	err := r.db.QueryRowContext(ctx, query, sessionID).Scan(
+		&progress.SessionID, &progress.SyncType, &progress.ChunkOrder, &progress.Progress,
+		&progress.ChunksProcessed, &progress.Conversations, &progress.Messages,
+		&progress.StartedAt, &progress.UpdatedAt,
+	)
+
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("history sync progress not found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get history sync progress: %w", err)
+	}
+
+	return progress, nil
+}
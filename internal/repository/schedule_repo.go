@@ -0,0 +1,203 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"zpwoot/internal/model"
+)
+
+const scheduleColumns = `
+			id, session_id, type, phone, payload,
+			send_at, timezone, status, job_id, error,
+			created_at, updated_at, executed_at`
+
+type ScheduleRepository struct {
+	db *sql.DB
+}
+
+func NewScheduleRepository(db *sql.DB) *ScheduleRepository {
+	return &ScheduleRepository{db: db}
+}
+
+func scanSchedule(row rowScanner, schedule *model.ScheduledMessage) error {
+	var jobID, lastError sql.NullString
+	var payload []byte
+
+	if err := row.Scan(
+		&schedule.ID, &schedule.SessionID, &schedule.Type, &schedule.Phone, &payload,
+		&schedule.SendAt, &schedule.Timezone, &schedule.Status, &jobID, &lastError,
+		&schedule.CreatedAt, &schedule.UpdatedAt, &schedule.ExecutedAt,
+	); err != nil {
+		return err
+	}
+
+	schedule.Payload = payload
+	schedule.JobID = jobID.String
+	schedule.Error = lastError.String
+	return nil
+}
+
+func scanSchedules(rows *sql.Rows) ([]*model.ScheduledMessage, error) {
+	defer rows.Close()
+
+	var schedules []*model.ScheduledMessage
+	for rows.Next() {
+		schedule := &model.ScheduledMessage{}
+		if err := scanSchedule(rows, schedule); err != nil {
+			return nil, fmt.Errorf("failed to scan schedule: %w", err)
+		}
+		schedules = append(schedules, schedule)
+	}
+
+	return schedules, rows.Err()
+}
+
+func (r *ScheduleRepository) Create(ctx context.Context, schedule *model.ScheduledMessage) error {
+	query := `
+		INSERT INTO scheduled_messages (
+			id, session_id, type, phone, payload, send_at, timezone, status
+		) VALUES (
+			$1, $2, $3, $4, $5, $6, $7, $8
+		) RETURNING created_at, updated_at
+	`
+
+	err := r.db.QueryRowContext(ctx, query,
+		schedule.ID, schedule.SessionID, schedule.Type, schedule.Phone, string(schedule.Payload),
+		schedule.SendAt, schedule.Timezone, schedule.Status,
+	).Scan(&schedule.CreatedAt, &schedule.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create schedule: %w", err)
+	}
+
+	return nil
+}
+
+func (r *ScheduleRepository) GetByID(ctx context.Context, sessionID, id string) (*model.ScheduledMessage, error) {
+	query := `
+		SELECT ` + scheduleColumns + `
+		FROM scheduled_messages
+		WHERE session_id = $1 AND id = $2
+	`
+
+	schedule := &model.ScheduledMessage{}
+
+	err := scanSchedule(r.db.QueryRowContext(ctx, query, sessionID, id), schedule)
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("schedule not found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get schedule: %w", err)
+	}
+
+	return schedule, nil
+}
+
+// List retorna os agendamentos da sessão ordenados pelo horário de envio (status vazio = todos)
+func (r *ScheduleRepository) List(ctx context.Context, sessionID string, status model.ScheduleStatus) ([]*model.ScheduledMessage, error) {
+	query := `
+		SELECT ` + scheduleColumns + `
+		FROM scheduled_messages
+		WHERE session_id = $1 AND ($2 = '' OR status = $2)
+		ORDER BY send_at ASC
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, sessionID, string(status))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list schedules: %w", err)
+	}
+
+	return scanSchedules(rows)
+}
+
+// Cancel cancela um agendamento que ainda não foi executado
+func (r *ScheduleRepository) Cancel(ctx context.Context, sessionID, id string) (*model.ScheduledMessage, error) {
+	query := `
+		UPDATE scheduled_messages SET status = 'canceled'
+		WHERE session_id = $1 AND id = $2 AND status = 'scheduled'
+		RETURNING ` + scheduleColumns
+
+	schedule := &model.ScheduledMessage{}
+
+	err := scanSchedule(r.db.QueryRowContext(ctx, query, sessionID, id), schedule)
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("schedule not found or already executed")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to cancel schedule: %w", err)
+	}
+
+	return schedule, nil
+}
+
+// Reschedule altera o horário de um agendamento que ainda não foi executado
+func (r *ScheduleRepository) Reschedule(ctx context.Context, sessionID, id string, sendAt time.Time, timezone string) (*model.ScheduledMessage, error) {
+	query := `
+		UPDATE scheduled_messages SET send_at = $3, timezone = $4
+		WHERE session_id = $1 AND id = $2 AND status = 'scheduled'
+		RETURNING ` + scheduleColumns
+
+	schedule := &model.ScheduledMessage{}
+
+	err := scanSchedule(r.db.QueryRowContext(ctx, query, sessionID, id, sendAt, timezone), schedule)
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("schedule not found or already executed")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to reschedule: %w", err)
+	}
+
+	return schedule, nil
+}
+
+// ClaimDue reserva até limit agendamentos vencidos marcando-os como processing.
+// FOR UPDATE SKIP LOCKED garante que réplicas concorrentes não peguem o mesmo agendamento;
+// reservas mais antigas que staleAfter (réplica que caiu no meio) são retomadas.
+func (r *ScheduleRepository) ClaimDue(ctx context.Context, limit int, staleAfter time.Duration) ([]*model.ScheduledMessage, error) {
+	query := `
+		UPDATE scheduled_messages SET status = 'processing'
+		WHERE id IN (
+			SELECT id FROM scheduled_messages
+			WHERE send_at <= NOW()
+				AND (status = 'scheduled'
+					OR (status = 'processing' AND updated_at < NOW() - make_interval(secs => $2)))
+			ORDER BY send_at ASC
+			LIMIT $1
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING ` + scheduleColumns
+
+	rows, err := r.db.QueryContext(ctx, query, limit, staleAfter.Seconds())
+	if err != nil {
+		return nil, fmt.Errorf("failed to claim schedules: %w", err)
+	}
+
+	return scanSchedules(rows)
+}
+
+// UpdateResult grava o resultado da execução (queued com o job ou failed com o erro)
+func (r *ScheduleRepository) UpdateResult(ctx context.Context, schedule *model.ScheduledMessage) error {
+	query := `
+		UPDATE scheduled_messages SET
+			status = $1,
+			job_id = NULLIF($2, ''),
+			error = NULLIF($3, ''),
+			executed_at = $4
+		WHERE id = $5
+		RETURNING updated_at
+	`
+
+	err := r.db.QueryRowContext(ctx, query,
+		schedule.Status, schedule.JobID, schedule.Error, schedule.ExecutedAt, schedule.ID,
+	).Scan(&schedule.UpdatedAt)
+	if err == sql.ErrNoRows {
+		return fmt.Errorf("schedule not found")
+	}
+	if err != nil {
+		return fmt.Errorf("failed to update schedule: %w", err)
+	}
+
+	return nil
+}
@@ -3,12 +3,40 @@ package repository
 import (
 	"context"
 	"database/sql"
+	"errors"
 	"fmt"
 	"time"
 
+	"github.com/lib/pq"
+
 	"zpwoot/internal/model"
 )
 
+// sessionColumns lista as colunas lidas em todas as consultas de sessão (mesma ordem de scanSession)
+const sessionColumns = `
+			id, name, device_jid, status, connected,
+			qr_code, proxy_config, webhook_config, history_sync_config, call_config,
+			rate_limit_config, chatwoot_config, flowbot_config, assistant_config, transcription_config, apikey, COALESCE(tenant_id, ''), created_at, updated_at`
+
+// ErrSessionLimitReached indica que o limite de sessões do servidor ou a cota do tenant foi atingida
+var ErrSessionLimitReached = errors.New("maximum number of sessions reached")
+
+// sessionLimitLockID identifica o advisory lock que serializa a criação de sessões com limite
+const sessionLimitLockID = 0x7a70776f // "zpwo"
+
+// rowScanner abstrai *sql.Row e *sql.Rows
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanSession(row rowScanner, session *model.Session) error {
+	return row.Scan(
+		&session.ID, &session.Name, &session.DeviceJID, &session.Status, &session.Connected,
+		&session.QRCode, &session.ProxyConfig, &session.WebhookConfig, &session.HistorySyncConfig, &session.CallConfig,
+		&session.RateLimitConfig, &session.ChatwootConfig, &session.FlowBotConfig, &session.AssistantConfig, &session.TranscriptionConfig, &session.APIKey, &session.TenantID, &session.CreatedAt, &session.UpdatedAt,
+	)
+}
+
 type SessionRepository struct {
 	db *sql.DB
 }
@@ -17,23 +45,88 @@ func NewSessionRepository(db *sql.DB) *SessionRepository {
 	return &SessionRepository{db: db}
 }
 
+// Create grava a sessão no tenant do contexto quando ela não informa um
 func (r *SessionRepository) Create(ctx context.Context, session *model.Session) error {
+	if session.TenantID == "" {
+		session.TenantID = TenantFromContext(ctx)
+	}
+
+	return insertSession(ctx, r.db, session)
+}
+
+// CreateWithinLimit cria a sessão se o total de sessões (maxSessions) e as do tenant
+// (tenantMaxSessions) ainda comportarem mais uma (0 = sem limite). A contagem e o INSERT rodam
+// sob um advisory lock da transação: criações concorrentes não ultrapassam o limite.
+func (r *SessionRepository) CreateWithinLimit(ctx context.Context, session *model.Session, maxSessions, tenantMaxSessions int) error {
+	if session.TenantID == "" {
+		session.TenantID = TenantFromContext(ctx)
+	}
+	if session.TenantID == "" {
+		tenantMaxSessions = 0
+	}
+	if maxSessions <= 0 && tenantMaxSessions <= 0 {
+		return insertSession(ctx, r.db, session)
+	}
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, `SELECT pg_advisory_xact_lock($1)`, sessionLimitLockID); err != nil {
+		return fmt.Errorf("failed to lock session limit: %w", err)
+	}
+
+	if maxSessions > 0 {
+		var count int
+		if err := tx.QueryRowContext(ctx, `SELECT COUNT(*) FROM sessions`).Scan(&count); err != nil {
+			return fmt.Errorf("failed to count sessions: %w", err)
+		}
+		if count >= maxSessions {
+			return fmt.Errorf("%w (%d)", ErrSessionLimitReached, maxSessions)
+		}
+	}
+
+	if tenantMaxSessions > 0 {
+		var count int
+		if err := tx.QueryRowContext(ctx, `SELECT COUNT(*) FROM sessions WHERE tenant_id = $1`, session.TenantID).Scan(&count); err != nil {
+			return fmt.Errorf("failed to count tenant sessions: %w", err)
+		}
+		if count >= tenantMaxSessions {
+			return fmt.Errorf("%w (tenant quota: %d)", ErrSessionLimitReached, tenantMaxSessions)
+		}
+	}
+
+	if err := insertSession(ctx, tx, session); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// rowQuerier é implementado por *sql.DB e *sql.Tx
+type rowQuerier interface {
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+}
+
+func insertSession(ctx context.Context, db rowQuerier, session *model.Session) error {
 	query := `
 		INSERT INTO sessions (
 			name, device_jid, status, connected,
-			qr_code, proxy_config, webhook_config,
-			apikey, created_at, updated_at
+			qr_code, proxy_config, webhook_config, history_sync_config, call_config,
+			rate_limit_config, chatwoot_config, flowbot_config, assistant_config, transcription_config, apikey, tenant_id, created_at, updated_at
 		) VALUES (
 			$1, $2, $3, $4,
-			$5, $6, $7,
-			$8, NOW(), NOW()
+			$5, $6, $7, $8, $9,
+			$10, $11, $12, $13, $14, $15, NULLIF($16, ''), NOW(), NOW()
 		) RETURNING id, created_at, updated_at
 	`
 
-	err := r.db.QueryRowContext(ctx, query,
+	err := db.QueryRowContext(ctx, query,
 		session.Name, session.DeviceJID, session.Status, session.Connected,
-		session.QRCode, session.ProxyConfig, session.WebhookConfig,
-		session.APIKey,
+		session.QRCode, session.ProxyConfig, session.WebhookConfig, session.HistorySyncConfig, session.CallConfig,
+		session.RateLimitConfig, session.ChatwootConfig, session.FlowBotConfig, session.AssistantConfig, session.TranscriptionConfig, session.APIKey, session.TenantID,
 	).Scan(&session.ID, &session.CreatedAt, &session.UpdatedAt)
 
 	if err != nil {
@@ -45,21 +138,14 @@ func (r *SessionRepository) Create(ctx context.Context, session *model.Session)
 
 func (r *SessionRepository) GetByID(ctx context.Context, id string) (*model.Session, error) {
 	query := `
-		SELECT
-			id, name, device_jid, status, connected,
-			qr_code, proxy_config, webhook_config,
-			apikey, created_at, updated_at
+		SELECT ` + sessionColumns + `
 		FROM sessions
-		WHERE id = $1
+		WHERE id = $1 AND ($2 = '' OR tenant_id = $2) AND ($3::text[] IS NULL OR id = ANY($3::text[]))
 	`
 
 	session := &model.Session{}
 
-	err := r.db.QueryRowContext(ctx, query, id).Scan(
-		&session.ID, &session.Name, &session.DeviceJID, &session.Status, &session.Connected,
-		&session.QRCode, &session.ProxyConfig, &session.WebhookConfig,
-		&session.APIKey, &session.CreatedAt, &session.UpdatedAt,
-	)
+	err := scanSession(r.db.QueryRowContext(ctx, query, id, TenantFromContext(ctx), pq.Array(SessionIDsFromContext(ctx))), session)
 
 	if err == sql.ErrNoRows {
 		return nil, fmt.Errorf("session not found")
@@ -73,21 +159,14 @@ func (r *SessionRepository) GetByID(ctx context.Context, id string) (*model.Sess
 
 func (r *SessionRepository) GetByDeviceJID(ctx context.Context, deviceJID string) (*model.Session, error) {
 	query := `
-		SELECT
-			id, name, device_jid, status, connected,
-			qr_code, proxy_config, webhook_config,
-			apikey, created_at, updated_at
+		SELECT ` + sessionColumns + `
 		FROM sessions
-		WHERE device_jid = $1
+		WHERE device_jid = $1 AND ($2 = '' OR tenant_id = $2)
 	`
 
 	session := &model.Session{}
 
-	err := r.db.QueryRowContext(ctx, query, deviceJID).Scan(
-		&session.ID, &session.Name, &session.DeviceJID, &session.Status, &session.Connected,
-		&session.QRCode, &session.ProxyConfig, &session.WebhookConfig,
-		&session.APIKey, &session.CreatedAt, &session.UpdatedAt,
-	)
+	err := scanSession(r.db.QueryRowContext(ctx, query, deviceJID, TenantFromContext(ctx)), session)
 
 	if err == sql.ErrNoRows {
 		return nil, fmt.Errorf("session not found")
@@ -101,15 +180,13 @@ func (r *SessionRepository) GetByDeviceJID(ctx context.Context, deviceJID string
 
 func (r *SessionRepository) List(ctx context.Context) ([]*model.Session, error) {
 	query := `
-		SELECT
-			id, name, device_jid, status, connected,
-			qr_code, proxy_config, webhook_config,
-			apikey, created_at, updated_at
+		SELECT ` + sessionColumns + `
 		FROM sessions
+		WHERE ($1 = '' OR tenant_id = $1) AND ($2::text[] IS NULL OR id = ANY($2::text[]))
 		ORDER BY created_at DESC
 	`
 
-	rows, err := r.db.QueryContext(ctx, query)
+	rows, err := r.db.QueryContext(ctx, query, TenantFromContext(ctx), pq.Array(SessionIDsFromContext(ctx)))
 	if err != nil {
 		return nil, fmt.Errorf("failed to list sessions: %w", err)
 	}
@@ -119,11 +196,7 @@ func (r *SessionRepository) List(ctx context.Context) ([]*model.Session, error)
 	for rows.Next() {
 		session := &model.Session{}
 
-		err := rows.Scan(
-			&session.ID, &session.Name, &session.DeviceJID, &session.Status, &session.Connected,
-			&session.QRCode, &session.ProxyConfig, &session.WebhookConfig,
-			&session.APIKey, &session.CreatedAt, &session.UpdatedAt,
-		)
+		err := scanSession(rows, session)
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan session: %w", err)
 		}
@@ -140,16 +213,13 @@ func (r *SessionRepository) List(ctx context.Context) ([]*model.Session, error)
 
 func (r *SessionRepository) ListConnected(ctx context.Context) ([]*model.Session, error) {
 	query := `
-		SELECT
-			id, name, device_jid, status, connected,
-			qr_code, proxy_config, webhook_config,
-			apikey, created_at, updated_at
+		SELECT ` + sessionColumns + `
 		FROM sessions
-		WHERE connected = true AND status = 'connected'
+		WHERE connected = true AND status = 'connected' AND ($1 = '' OR tenant_id = $1)
 		ORDER BY updated_at DESC
 	`
 
-	rows, err := r.db.QueryContext(ctx, query)
+	rows, err := r.db.QueryContext(ctx, query, TenantFromContext(ctx))
 	if err != nil {
 		return nil, fmt.Errorf("failed to list connected sessions: %w", err)
 	}
@@ -159,11 +229,7 @@ func (r *SessionRepository) ListConnected(ctx context.Context) ([]*model.Session
 	for rows.Next() {
 		session := &model.Session{}
 
-		err := rows.Scan(
-			&session.ID, &session.Name, &session.DeviceJID, &session.Status, &session.Connected,
-			&session.QRCode, &session.ProxyConfig, &session.WebhookConfig,
-			&session.APIKey, &session.CreatedAt, &session.UpdatedAt,
-		)
+		err := scanSession(rows, session)
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan session: %w", err)
 		}
@@ -188,15 +254,22 @@ func (r *SessionRepository) Update(ctx context.Context, session *model.Session)
 			qr_code = $5,
 			proxy_config = $6,
 			webhook_config = $7,
-			apikey = $8,
+			history_sync_config = $8,
+			call_config = $9,
+			rate_limit_config = $10,
+			chatwoot_config = $11,
+			flowbot_config = $12,
+			assistant_config = $13,
+			transcription_config = $14,
+			apikey = $15,
 			updated_at = NOW()
-		WHERE id = $9
+		WHERE id = $16 AND ($17 = '' OR tenant_id = $17)
 	`
 
 	result, err := r.db.ExecContext(ctx, query,
 		session.Name, session.DeviceJID, session.Status, session.Connected,
-		session.QRCode, session.ProxyConfig, session.WebhookConfig,
-		session.APIKey, session.ID,
+		session.QRCode, session.ProxyConfig, session.WebhookConfig, session.HistorySyncConfig, session.CallConfig,
+		session.RateLimitConfig, session.ChatwootConfig, session.FlowBotConfig, session.AssistantConfig, session.TranscriptionConfig, session.APIKey, session.ID, TenantFromContext(ctx),
 	)
 
 	if err != nil {
@@ -217,9 +290,9 @@ func (r *SessionRepository) Update(ctx context.Context, session *model.Session)
 }
 
 func (r *SessionRepository) Delete(ctx context.Context, id string) error {
-	query := `DELETE FROM sessions WHERE id = $1`
+	query := `DELETE FROM sessions WHERE id = $1 AND ($2 = '' OR tenant_id = $2)`
 
-	result, err := r.db.ExecContext(ctx, query, id)
+	result, err := r.db.ExecContext(ctx, query, id, TenantFromContext(ctx))
 	if err != nil {
 		return fmt.Errorf("failed to delete session: %w", err)
 	}
@@ -240,10 +313,10 @@ func (r *SessionRepository) UpdateStatus(ctx context.Context, id string, status
 	query := `
 		UPDATE sessions
 		SET status = $1, connected = $2, updated_at = NOW()
-		WHERE id = $3
+		WHERE id = $3 AND ($4 = '' OR tenant_id = $4)
 	`
 
-	result, err := r.db.ExecContext(ctx, query, status, connected, id)
+	result, err := r.db.ExecContext(ctx, query, status, connected, id, TenantFromContext(ctx))
 	if err != nil {
 		return fmt.Errorf("failed to update session status: %w", err)
 	}
@@ -264,10 +337,10 @@ func (r *SessionRepository) UpdateQRCode(ctx context.Context, id string, qrCode
 	query := `
 		UPDATE sessions
 		SET qr_code = $1, updated_at = NOW()
-		WHERE id = $2
+		WHERE id = $2 AND ($3 = '' OR tenant_id = $3)
 	`
 
-	result, err := r.db.ExecContext(ctx, query, qrCode, id)
+	result, err := r.db.ExecContext(ctx, query, qrCode, id, TenantFromContext(ctx))
 	if err != nil {
 		return fmt.Errorf("failed to update QR code: %w", err)
 	}
@@ -288,10 +361,10 @@ func (r *SessionRepository) UpdateDeviceJID(ctx context.Context, id string, devi
 	query := `
 		UPDATE sessions
 		SET device_jid = $1, updated_at = NOW()
-		WHERE id = $2
+		WHERE id = $2 AND ($3 = '' OR tenant_id = $3)
 	`
 
-	result, err := r.db.ExecContext(ctx, query, deviceJID, id)
+	result, err := r.db.ExecContext(ctx, query, deviceJID, id, TenantFromContext(ctx))
 	if err != nil {
 		return fmt.Errorf("failed to update device JID: %w", err)
 	}
@@ -308,14 +381,90 @@ func (r *SessionRepository) UpdateDeviceJID(ctx context.Context, id string, devi
 	return nil
 }
 
-func (r *SessionRepository) Count(ctx context.Context) (int, error) {
-	query := `SELECT COUNT(*) FROM sessions`
+// ReencryptSecrets regrava os segredos das sessões em que needsRotation aponta um valor em texto
+// puro ou cifrado por uma chave mestra anterior: a leitura decifra (Scan) e a gravação cifra com a
+// chave atual (Value). Retorna quantas sessões foram regravadas.
+func (r *SessionRepository) ReencryptSecrets(ctx context.Context, needsRotation func(string) bool) (int, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT id,
+			COALESCE(proxy_config->>'password', ''), COALESCE(webhook_config->>'token', ''),
+			COALESCE(chatwoot_config->>'token', ''), COALESCE(chatwoot_config->>'webhook_token', ''),
+			COALESCE(flowbot_config->>'token', ''), COALESCE(assistant_config->>'api_key', ''),
+			COALESCE(transcription_config->>'api_key', ''), COALESCE(apikey, '')
+		FROM sessions
+	`)
+	if err != nil {
+		return 0, fmt.Errorf("failed to list session secrets: %w", err)
+	}
+
+	var ids []string
+	for rows.Next() {
+		var id string
+		values := make([]string, 8)
+		if err := rows.Scan(&id, &values[0], &values[1], &values[2], &values[3], &values[4], &values[5], &values[6], &values[7]); err != nil {
+			rows.Close()
+			return 0, fmt.Errorf("failed to scan session secrets: %w", err)
+		}
+		for _, value := range values {
+			if needsRotation(value) {
+				ids = append(ids, id)
+				break
+			}
+		}
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, fmt.Errorf("failed to list session secrets: %w", err)
+	}
+
+	for i, id := range ids {
+		if err := r.reencryptSession(ctx, id); err != nil {
+			return i, err
+		}
+	}
+
+	return len(ids), nil
+}
+
+func (r *SessionRepository) reencryptSession(ctx context.Context, id string) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
 
-	var count int
-	err := r.db.QueryRowContext(ctx, query).Scan(&count)
+	session := &model.Session{ID: id}
+	err = tx.QueryRowContext(ctx, `
+		SELECT proxy_config, webhook_config, chatwoot_config, flowbot_config, assistant_config, transcription_config, apikey
+		FROM sessions
+		WHERE id = $1
+		FOR UPDATE
+	`, id).Scan(
+		&session.ProxyConfig, &session.WebhookConfig, &session.ChatwootConfig, &session.FlowBotConfig,
+		&session.AssistantConfig, &session.TranscriptionConfig, &session.APIKey,
+	)
+	if err == sql.ErrNoRows {
+		return nil
+	}
 	if err != nil {
-		return 0, fmt.Errorf("failed to count sessions: %w", err)
+		return fmt.Errorf("failed to read session %s secrets: %w", id, err)
+	}
+
+	if _, err := tx.ExecContext(ctx, `
+		UPDATE sessions SET
+			proxy_config = $2,
+			webhook_config = $3,
+			chatwoot_config = $4,
+			flowbot_config = $5,
+			assistant_config = $6,
+			transcription_config = $7,
+			apikey = $8
+		WHERE id = $1
+	`, id, session.ProxyConfig, session.WebhookConfig, session.ChatwootConfig, session.FlowBotConfig,
+		session.AssistantConfig, session.TranscriptionConfig, session.APIKey,
+	); err != nil {
+		return fmt.Errorf("failed to reencrypt session %s secrets: %w", id, err)
 	}
 
-	return count, nil
+	return tx.Commit()
 }
@@ -0,0 +1,225 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/lib/pq"
+
+	"zpwoot/internal/model"
+)
+
+// ErrTemplateNameTaken indica que já existe um template com o mesmo nome no escopo
+var ErrTemplateNameTaken = errors.New("template name already exists")
+
+// isUniqueViolation identifica violação de índice único no Postgres
+func isUniqueViolation(err error) bool {
+	var pqErr *pq.Error
+	return errors.As(err, &pqErr) && pqErr.Code == "23505"
+}
+
+type TemplateRepository struct {
+	db *sql.DB
+}
+
+func NewTemplateRepository(db *sql.DB) *TemplateRepository {
+	return &TemplateRepository{db: db}
+}
+
+func scanTemplate(row rowScanner, template *model.MessageTemplate) error {
+	var sessionID sql.NullString
+	template.Content = &model.TemplateContent{}
+
+	if err := row.Scan(
+		&template.ID, &template.TenantID, &sessionID, &template.Name, &template.Version, template.Content,
+		&template.CreatedAt, &template.UpdatedAt,
+	); err != nil {
+		return err
+	}
+
+	template.SessionID = sessionID.String
+	return nil
+}
+
+// Create grava o template (no tenant do contexto) e sua versão 1 em uma única transação
+func (r *TemplateRepository) Create(ctx context.Context, template *model.MessageTemplate) error {
+	if template.TenantID == "" {
+		template.TenantID = TenantFromContext(ctx)
+	}
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	template.Version = 1
+
+	err = tx.QueryRowContext(ctx, `
+		INSERT INTO message_templates (
+			id, tenant_id, session_id, name, version
+		) VALUES (
+			$1, NULLIF($2, ''), NULLIF($3, ''), $4, $5
+		) RETURNING created_at, updated_at
+	`, template.ID, template.TenantID, template.SessionID, template.Name, template.Version,
+	).Scan(&template.CreatedAt, &template.UpdatedAt)
+	if isUniqueViolation(err) {
+		return ErrTemplateNameTaken
+	}
+	if err != nil {
+		return fmt.Errorf("failed to create template: %w", err)
+	}
+
+	if _, err := tx.ExecContext(ctx, `
+		INSERT INTO message_template_versions (template_id, version, content)
+		VALUES ($1, $2, $3)
+	`, template.ID, template.Version, template.Content); err != nil {
+		return fmt.Errorf("failed to create template version: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit template: %w", err)
+	}
+
+	return nil
+}
+
+// GetByID retorna o template com o conteúdo da versão informada (0 = atual)
+func (r *TemplateRepository) GetByID(ctx context.Context, id string, version int) (*model.MessageTemplate, error) {
+	query := `
+		SELECT t.id, COALESCE(t.tenant_id, ''), t.session_id, t.name, v.version, v.content, t.created_at, t.updated_at
+		FROM message_templates t
+		JOIN message_template_versions v ON v.template_id = t.id
+		WHERE t.id = $1 AND v.version = CASE WHEN $2 > 0 THEN $2 ELSE t.version END
+			AND ($3 = '' OR t.tenant_id = $3)
+	`
+
+	template := &model.MessageTemplate{}
+
+	err := scanTemplate(r.db.QueryRowContext(ctx, query, id, version, TenantFromContext(ctx)), template)
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("template not found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get template: %w", err)
+	}
+
+	return template, nil
+}
+
+// List retorna os templates na versão atual. Com sessionID, retorna os globais e os da sessão;
+// sem sessionID, retorna todos.
+func (r *TemplateRepository) List(ctx context.Context, sessionID string) ([]*model.MessageTemplate, error) {
+	query := `
+		SELECT t.id, COALESCE(t.tenant_id, ''), t.session_id, t.name, v.version, v.content, t.created_at, t.updated_at
+		FROM message_templates t
+		JOIN message_template_versions v ON v.template_id = t.id AND v.version = t.version
+		WHERE ($1 = '' OR t.session_id IS NULL OR t.session_id = $1)
+			AND ($2 = '' OR t.tenant_id = $2)
+		ORDER BY t.name ASC
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, sessionID, TenantFromContext(ctx))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list templates: %w", err)
+	}
+	defer rows.Close()
+
+	var templates []*model.MessageTemplate
+	for rows.Next() {
+		template := &model.MessageTemplate{}
+		if err := scanTemplate(rows, template); err != nil {
+			return nil, fmt.Errorf("failed to scan template: %w", err)
+		}
+		templates = append(templates, template)
+	}
+
+	return templates, rows.Err()
+}
+
+// Update grava uma nova versão do conteúdo (e o novo nome) e a torna a atual
+func (r *TemplateRepository) Update(ctx context.Context, template *model.MessageTemplate) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	var sessionID sql.NullString
+	err = tx.QueryRowContext(ctx, `
+		UPDATE message_templates SET
+			name = $2,
+			version = version + 1
+		WHERE id = $1 AND ($3 = '' OR tenant_id = $3)
+		RETURNING COALESCE(tenant_id, ''), session_id, version, created_at, updated_at
+	`, template.ID, template.Name, TenantFromContext(ctx),
+	).Scan(&template.TenantID, &sessionID, &template.Version, &template.CreatedAt, &template.UpdatedAt)
+	if err == sql.ErrNoRows {
+		return fmt.Errorf("template not found")
+	}
+	if isUniqueViolation(err) {
+		return ErrTemplateNameTaken
+	}
+	if err != nil {
+		return fmt.Errorf("failed to update template: %w", err)
+	}
+	template.SessionID = sessionID.String
+
+	if _, err := tx.ExecContext(ctx, `
+		INSERT INTO message_template_versions (template_id, version, content)
+		VALUES ($1, $2, $3)
+	`, template.ID, template.Version, template.Content); err != nil {
+		return fmt.Errorf("failed to create template version: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit template: %w", err)
+	}
+
+	return nil
+}
+
+func (r *TemplateRepository) Delete(ctx context.Context, id string) error {
+	result, err := r.db.ExecContext(ctx, `
+		DELETE FROM message_templates WHERE id = $1 AND ($2 = '' OR tenant_id = $2)
+	`, id, TenantFromContext(ctx))
+	if err != nil {
+		return fmt.Errorf("failed to delete template: %w", err)
+	}
+
+	if n, _ := result.RowsAffected(); n == 0 {
+		return fmt.Errorf("template not found")
+	}
+
+	return nil
+}
+
+// ListVersions retorna todas as versões do template, da mais recente para a mais antiga
+func (r *TemplateRepository) ListVersions(ctx context.Context, id string) ([]*model.TemplateVersion, error) {
+	query := `
+		SELECT v.template_id, v.version, v.content, v.created_at
+		FROM message_template_versions v
+		JOIN message_templates t ON t.id = v.template_id
+		WHERE v.template_id = $1 AND ($2 = '' OR t.tenant_id = $2)
+		ORDER BY v.version DESC
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, id, TenantFromContext(ctx))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list template versions: %w", err)
+	}
+	defer rows.Close()
+
+	var versions []*model.TemplateVersion
+	for rows.Next() {
+		version := &model.TemplateVersion{Content: &model.TemplateContent{}}
+		if err := rows.Scan(&version.TemplateID, &version.Version, version.Content, &version.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan template version: %w", err)
+		}
+		versions = append(versions, version)
+	}
+
+	return versions, rows.Err()
+}
@@ -0,0 +1,230 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/lib/pq"
+
+	"zpwoot/internal/model"
+)
+
+// ErrTenantHasSessions indica que o tenant ainda possui sessões e não pode ser removido
+var ErrTenantHasSessions = errors.New("tenant still has sessions")
+
+const tenantColumns = `
+			id, name, api_key_hash, api_key_prefix, max_sessions, webhook_defaults,
+			enabled, created_at, updated_at`
+
+type TenantRepository struct {
+	db *sql.DB
+}
+
+func NewTenantRepository(db *sql.DB) *TenantRepository {
+	return &TenantRepository{db: db}
+}
+
+func scanTenant(row rowScanner, tenant *model.Tenant) error {
+	return row.Scan(
+		&tenant.ID, &tenant.Name, &tenant.APIKeyHash, &tenant.APIKeyPrefix, &tenant.MaxSessions, &tenant.WebhookDefaults,
+		&tenant.Enabled, &tenant.CreatedAt, &tenant.UpdatedAt,
+	)
+}
+
+func (r *TenantRepository) Create(ctx context.Context, tenant *model.Tenant) error {
+	query := `
+		INSERT INTO tenants (
+			id, name, api_key_hash, api_key_prefix, max_sessions, webhook_defaults, enabled
+		) VALUES (
+			$1, $2, $3, $4, $5, $6, $7
+		) RETURNING created_at, updated_at
+	`
+
+	err := r.db.QueryRowContext(ctx, query,
+		tenant.ID, tenant.Name, tenant.APIKeyHash, tenant.APIKeyPrefix, tenant.MaxSessions, tenant.WebhookDefaults, tenant.Enabled,
+	).Scan(&tenant.CreatedAt, &tenant.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create tenant: %w", err)
+	}
+
+	return nil
+}
+
+// GetByID retorna o tenant ou nil quando não existe
+func (r *TenantRepository) GetByID(ctx context.Context, id string) (*model.Tenant, error) {
+	return r.get(ctx, `WHERE id = $1`, id)
+}
+
+// GetByAPIKeyHash retorna o tenant dono da API key ou nil quando nenhum corresponde
+func (r *TenantRepository) GetByAPIKeyHash(ctx context.Context, hash string) (*model.Tenant, error) {
+	return r.get(ctx, `WHERE api_key_hash = $1`, hash)
+}
+
+func (r *TenantRepository) get(ctx context.Context, where string, arg string) (*model.Tenant, error) {
+	query := `
+		SELECT ` + tenantColumns + `
+		FROM tenants
+		` + where
+
+	tenant := &model.Tenant{}
+
+	err := scanTenant(r.db.QueryRowContext(ctx, query, arg), tenant)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get tenant: %w", err)
+	}
+
+	return tenant, nil
+}
+
+func (r *TenantRepository) List(ctx context.Context) ([]*model.Tenant, error) {
+	query := `
+		SELECT ` + tenantColumns + `
+		FROM tenants
+		ORDER BY name ASC
+	`
+
+	rows, err := r.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list tenants: %w", err)
+	}
+	defer rows.Close()
+
+	tenants := []*model.Tenant{}
+	for rows.Next() {
+		tenant := &model.Tenant{}
+		if err := scanTenant(rows, tenant); err != nil {
+			return nil, fmt.Errorf("failed to scan tenant: %w", err)
+		}
+		tenants = append(tenants, tenant)
+	}
+
+	return tenants, rows.Err()
+}
+
+// Update grava nome, cota, webhook padrão e status (a API key muda apenas via UpdateAPIKey)
+func (r *TenantRepository) Update(ctx context.Context, tenant *model.Tenant) error {
+	query := `
+		UPDATE tenants SET
+			name = $2,
+			max_sessions = $3,
+			webhook_defaults = $4,
+			enabled = $5
+		WHERE id = $1
+		RETURNING created_at, updated_at
+	`
+
+	err := r.db.QueryRowContext(ctx, query,
+		tenant.ID, tenant.Name, tenant.MaxSessions, tenant.WebhookDefaults, tenant.Enabled,
+	).Scan(&tenant.CreatedAt, &tenant.UpdatedAt)
+	if err == sql.ErrNoRows {
+		return fmt.Errorf("tenant not found")
+	}
+	if err != nil {
+		return fmt.Errorf("failed to update tenant: %w", err)
+	}
+
+	return nil
+}
+
+// UpdateAPIKey troca a API key do tenant; a anterior deixa de valer imediatamente
+func (r *TenantRepository) UpdateAPIKey(ctx context.Context, id, hash, prefix string) error {
+	result, err := r.db.ExecContext(ctx, `
+		UPDATE tenants SET api_key_hash = $2, api_key_prefix = $3 WHERE id = $1
+	`, id, hash, prefix)
+	if err != nil {
+		return fmt.Errorf("failed to update tenant API key: %w", err)
+	}
+
+	if n, _ := result.RowsAffected(); n == 0 {
+		return fmt.Errorf("tenant not found")
+	}
+
+	return nil
+}
+
+// Delete remove o tenant e seus templates. Falha com ErrTenantHasSessions se ainda houver sessões.
+func (r *TenantRepository) Delete(ctx context.Context, id string) (bool, error) {
+	result, err := r.db.ExecContext(ctx, `DELETE FROM tenants WHERE id = $1`, id)
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) && pqErr.Code == "23503" {
+		return false, ErrTenantHasSessions
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to delete tenant: %w", err)
+	}
+
+	n, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	return n > 0, nil
+}
+
+// ReencryptSecrets regrava o token do webhook padrão dos tenants em que needsRotation aponta um
+// valor em texto puro ou cifrado por uma chave mestra anterior. Retorna quantos foram regravados.
+func (r *TenantRepository) ReencryptSecrets(ctx context.Context, needsRotation func(string) bool) (int, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT id, COALESCE(webhook_defaults->>'token', '')
+		FROM tenants
+	`)
+	if err != nil {
+		return 0, fmt.Errorf("failed to list tenant secrets: %w", err)
+	}
+
+	var ids []string
+	for rows.Next() {
+		var id, token string
+		if err := rows.Scan(&id, &token); err != nil {
+			rows.Close()
+			return 0, fmt.Errorf("failed to scan tenant secrets: %w", err)
+		}
+		if needsRotation(token) {
+			ids = append(ids, id)
+		}
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, fmt.Errorf("failed to list tenant secrets: %w", err)
+	}
+
+	for i, id := range ids {
+		if err := r.reencryptTenant(ctx, id); err != nil {
+			return i, err
+		}
+	}
+
+	return len(ids), nil
+}
+
+func (r *TenantRepository) reencryptTenant(ctx context.Context, id string) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	var defaults *model.WebhookConfig
+	err = tx.QueryRowContext(ctx, `
+		SELECT webhook_defaults FROM tenants WHERE id = $1 FOR UPDATE
+	`, id).Scan(&defaults)
+	if err == sql.ErrNoRows {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read tenant %s secrets: %w", id, err)
+	}
+
+	if _, err := tx.ExecContext(ctx, `
+		UPDATE tenants SET webhook_defaults = $2 WHERE id = $1
+	`, id, defaults); err != nil {
+		return fmt.Errorf("failed to reencrypt tenant %s secrets: %w", id, err)
+	}
+
+	return tx.Commit()
+}
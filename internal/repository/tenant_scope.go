@@ -0,0 +1,31 @@
+package repository
+
+import "context"
+
+type tenantContextKey struct{}
+
+// WithTenant restringe as consultas feitas com o contexto às sessões e templates do tenant
+func WithTenant(ctx context.Context, tenantID string) context.Context {
+	return context.WithValue(ctx, tenantContextKey{}, tenantID)
+}
+
+// TenantFromContext retorna o tenant do contexto. Vazio = sem restrição (API key global e
+// rotinas internas do servidor).
+func TenantFromContext(ctx context.Context) string {
+	tenantID, _ := ctx.Value(tenantContextKey{}).(string)
+	return tenantID
+}
+
+type sessionsContextKey struct{}
+
+// WithSessionIDs restringe as consultas de sessão feitas com o contexto às sessões informadas
+// (tokens JWT com a lista de sessões permitidas)
+func WithSessionIDs(ctx context.Context, sessionIDs []string) context.Context {
+	return context.WithValue(ctx, sessionsContextKey{}, sessionIDs)
+}
+
+// SessionIDsFromContext retorna as sessões permitidas no contexto (nil = sem restrição)
+func SessionIDsFromContext(ctx context.Context) []string {
+	sessionIDs, _ := ctx.Value(sessionsContextKey{}).([]string)
+	return sessionIDs
+}
@@ -0,0 +1,166 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+
+	amqp "github.com/rabbitmq/amqp091-go"
+
+	"zpwoot/pkg/logger"
+)
+
+// AMQPSinkConfig configura o publisher AMQP 0.9.1 (RabbitMQ)
+type AMQPSinkConfig struct {
+	URL          string
+	Exchange     string
+	ExchangeType string // topic, direct, fanout ou headers
+	RoutingKey   string // Template com {session} e {event}, ex: zpwoot.{session}.{event}
+}
+
+// AMQPSink publica os eventos em uma exchange usando publisher confirms.
+// A conexão é aberta sob demanda e refeita na próxima publicação se cair.
+type AMQPSink struct {
+	config AMQPSinkConfig
+
+	mu      sync.Mutex
+	conn    *amqp.Connection
+	channel *amqp.Channel
+}
+
+func NewAMQPSink(config AMQPSinkConfig) *AMQPSink {
+	if config.ExchangeType == "" {
+		config.ExchangeType = amqp.ExchangeTopic
+	}
+	if config.RoutingKey == "" {
+		config.RoutingKey = "zpwoot.{session}.{event}"
+	}
+
+	return &AMQPSink{config: config}
+}
+
+func (s *AMQPSink) Name() string {
+	return "amqp"
+}
+
+// RoutingKey monta a routing key do evento a partir do template configurado
+func (s *AMQPSink) RoutingKey(event StreamEvent) string {
+	return strings.NewReplacer(
+		"{session}", event.SessionID,
+		"{event}", event.Event,
+	).Replace(s.config.RoutingKey)
+}
+
+// Connect abre a conexão, declara a exchange e habilita publisher confirms
+func (s *AMQPSink) Connect() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.connectLocked()
+}
+
+func (s *AMQPSink) connectLocked() error {
+	if s.channel != nil && !s.channel.IsClosed() {
+		return nil
+	}
+	s.closeLocked()
+
+	conn, err := amqp.Dial(s.config.URL)
+	if err != nil {
+		return fmt.Errorf("failed to connect to AMQP: %w", err)
+	}
+
+	channel, err := conn.Channel()
+	if err != nil {
+		conn.Close()
+		return fmt.Errorf("failed to open AMQP channel: %w", err)
+	}
+
+	if err := channel.ExchangeDeclare(s.config.Exchange, s.config.ExchangeType, true, false, false, false, nil); err != nil {
+		conn.Close()
+		return fmt.Errorf("failed to declare exchange %s: %w", s.config.Exchange, err)
+	}
+
+	if err := channel.Confirm(false); err != nil {
+		conn.Close()
+		return fmt.Errorf("failed to enable publisher confirms: %w", err)
+	}
+
+	s.conn = conn
+	s.channel = channel
+
+	logger.Log.Info().
+		Str("exchange", s.config.Exchange).
+		Str("exchange_type", s.config.ExchangeType).
+		Msg("AMQP event sink connected")
+
+	return nil
+}
+
+// Publish envia o evento e aguarda o ack do broker
+func (s *AMQPSink) Publish(ctx context.Context, event StreamEvent) error {
+	body, err := json.Marshal(event.Payload)
+	if err != nil {
+		return fmt.Errorf("failed to marshal event: %w", err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.connectLocked(); err != nil {
+		return err
+	}
+
+	confirmation, err := s.channel.PublishWithDeferredConfirmWithContext(ctx,
+		s.config.Exchange,
+		s.RoutingKey(event),
+		false,
+		false,
+		amqp.Publishing{
+			ContentType:  "application/json",
+			DeliveryMode: amqp.Persistent,
+			MessageId:    fmt.Sprintf("%s-%d", event.SessionID, event.ID),
+			Timestamp:    event.Payload.Timestamp,
+			Type:         event.Event,
+			AppId:        "zpwoot",
+			Headers: amqp.Table{
+				"session_id": event.SessionID,
+				"event":      event.Event,
+			},
+			Body: body,
+		},
+	)
+	if err != nil {
+		s.closeLocked()
+		return fmt.Errorf("failed to publish to AMQP: %w", err)
+	}
+
+	acked, err := confirmation.WaitContext(ctx)
+	if err != nil {
+		// Sem confirmação não sabemos o estado do canal: reabre na próxima publicação
+		s.closeLocked()
+		return fmt.Errorf("failed to wait for publisher confirm: %w", err)
+	}
+	if !acked {
+		return errors.New("message was nacked by broker")
+	}
+
+	return nil
+}
+
+func (s *AMQPSink) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.closeLocked()
+	return nil
+}
+
+func (s *AMQPSink) closeLocked() {
+	if s.conn != nil {
+		s.conn.Close()
+	}
+	s.conn = nil
+	s.channel = nil
+}
@@ -0,0 +1,192 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"zpwoot/internal/model"
+	"zpwoot/internal/repository"
+	"zpwoot/pkg/logger"
+)
+
+var (
+	ErrAPIKeyNotFound = errors.New("API key not found")
+	ErrInvalidAPIKey  = errors.New("invalid API key")
+)
+
+// apiKeyPrefix identifica as API keys geradas pelo servidor (as demais são comparadas apenas
+// com a API key global)
+const apiKeyPrefix = "zpw_"
+
+// HashAPIKey retorna o hash gravado para a API key
+func HashAPIKey(apiKey string) string {
+	sum := sha256.Sum256([]byte(apiKey))
+	return hex.EncodeToString(sum[:])
+}
+
+// generateAPIKey gera uma API key aleatória, com seu hash e prefixo
+func generateAPIKey() (key, hash, prefix string, err error) {
+	buf := make([]byte, 24)
+	if _, err := rand.Read(buf); err != nil {
+		return "", "", "", fmt.Errorf("failed to generate API key: %w", err)
+	}
+
+	key = apiKeyPrefix + hex.EncodeToString(buf)
+	return key, HashAPIKey(key), key[:len(apiKeyPrefix)+8], nil
+}
+
+// APIKeyService gerencia as API keys com escopos (ex.: um painel de relatórios com apenas
+// sessions:read) e identifica a chave de cada requisição: chaves de api_keys e a API key
+// principal dos tenants (escopo admin dentro do tenant).
+type APIKeyService struct {
+	apiKeyRepo *repository.APIKeyRepository
+	tenants    *TenantService
+}
+
+func NewAPIKeyService(apiKeyRepo *repository.APIKeyRepository, tenants *TenantService) *APIKeyService {
+	return &APIKeyService{
+		apiKeyRepo: apiKeyRepo,
+		tenants:    tenants,
+	}
+}
+
+func validateAPIKey(key *model.APIKey) error {
+	if len(key.Scopes) == 0 {
+		return fmt.Errorf("%w: at least one scope is required", ErrInvalidAPIKey)
+	}
+	for _, scope := range key.Scopes {
+		if !scope.IsValid() {
+			return fmt.Errorf("%w: unknown scope %q", ErrInvalidAPIKey, scope)
+		}
+	}
+	for _, allowed := range key.AllowedIPs {
+		if _, _, err := net.ParseCIDR(allowed); err != nil && net.ParseIP(allowed) == nil {
+			return fmt.Errorf("%w: invalid IP or CIDR %q", ErrInvalidAPIKey, allowed)
+		}
+	}
+	if key.IsExpired(time.Now()) {
+		return fmt.Errorf("%w: expires_at must be in the future", ErrInvalidAPIKey)
+	}
+	return nil
+}
+
+// Create grava a chave e retorna o segredo gerado (exibido apenas nesta resposta). Chamadas
+// autenticadas por um tenant sempre criam chaves do próprio tenant.
+func (s *APIKeyService) Create(ctx context.Context, key *model.APIKey) (string, error) {
+	if err := validateAPIKey(key); err != nil {
+		return "", err
+	}
+
+	if tenantID := repository.TenantFromContext(ctx); tenantID != "" {
+		key.TenantID = tenantID
+	} else if key.TenantID != "" {
+		if _, err := s.tenants.Get(ctx, key.TenantID); err != nil {
+			return "", err
+		}
+	}
+
+	secret, hash, prefix, err := generateAPIKey()
+	if err != nil {
+		return "", err
+	}
+
+	key.ID = uuid.New().String()
+	key.KeyHash = hash
+	key.KeyPrefix = prefix
+
+	if err := s.apiKeyRepo.Create(ctx, key); err != nil {
+		return "", err
+	}
+
+	logger.Log.Info().
+		Str("api_key_id", key.ID).
+		Str("tenant_id", key.TenantID).
+		Str("name", key.Name).
+		Msg("API key created")
+
+	return secret, nil
+}
+
+func (s *APIKeyService) Get(ctx context.Context, id string) (*model.APIKey, error) {
+	key, err := s.apiKeyRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if key == nil {
+		return nil, ErrAPIKeyNotFound
+	}
+	return key, nil
+}
+
+func (s *APIKeyService) List(ctx context.Context) ([]*model.APIKey, error) {
+	return s.apiKeyRepo.List(ctx)
+}
+
+// Revoke remove a chave; ela deixa de autenticar imediatamente
+func (s *APIKeyService) Revoke(ctx context.Context, id string) error {
+	deleted, err := s.apiKeyRepo.Delete(ctx, id)
+	if err != nil {
+		return err
+	}
+	if !deleted {
+		return ErrAPIKeyNotFound
+	}
+
+	logger.Log.Info().Str("api_key_id", id).Msg("API key revoked")
+	return nil
+}
+
+// ResolveAPIKey retorna a chave correspondente ao segredo, ou nil quando ele não é uma chave
+// gerada pelo servidor (a API key global é validada pelo middleware). Chaves de tenants
+// desativados não são aceitas.
+func (s *APIKeyService) ResolveAPIKey(ctx context.Context, apiKey string) (*model.APIKey, error) {
+	if !strings.HasPrefix(apiKey, apiKeyPrefix) {
+		return nil, nil
+	}
+
+	key, err := s.apiKeyRepo.GetByHash(ctx, HashAPIKey(apiKey))
+	if err != nil {
+		return nil, err
+	}
+
+	if key != nil {
+		if key.TenantID == "" {
+			return key, nil
+		}
+		tenant, err := s.tenants.find(ctx, key.TenantID)
+		if err != nil {
+			return nil, err
+		}
+		return keyOfActiveTenant(key, tenant), nil
+	}
+
+	// API key principal do tenant
+	tenant, err := s.tenants.findByAPIKey(ctx, apiKey)
+	if err != nil || tenant == nil {
+		return nil, err
+	}
+
+	return &model.APIKey{
+		TenantID:  tenant.ID,
+		Name:      tenant.Name,
+		KeyPrefix: tenant.APIKeyPrefix,
+		Scopes:    []model.Scope{model.ScopeAdmin},
+	}, nil
+}
+
+// keyOfActiveTenant retorna a chave apenas se o tenant dela existe e está ativo
+func keyOfActiveTenant(key *model.APIKey, tenant *model.Tenant) *model.APIKey {
+	if tenant == nil || !tenant.Enabled {
+		return nil
+	}
+	return key
+}
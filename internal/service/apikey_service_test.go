@@ -0,0 +1,101 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"zpwoot/internal/model"
+)
+
+func TestGenerateAPIKey(t *testing.T) {
+	key, hash, prefix, err := generateAPIKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !strings.HasPrefix(key, apiKeyPrefix) || len(key) != len(apiKeyPrefix)+48 {
+		t.Errorf("key = %q", key)
+	}
+	if hash != HashAPIKey(key) || len(hash) != 64 || strings.Contains(hash, key) {
+		t.Errorf("hash = %q", hash)
+	}
+	if !strings.HasPrefix(key, prefix) || prefix == key {
+		t.Errorf("prefix = %q", prefix)
+	}
+
+	other, _, _, _ := generateAPIKey()
+	if other == key {
+		t.Error("generated keys must be unique")
+	}
+}
+
+func TestResolveAPIKeyIgnoresGlobalKeys(t *testing.T) {
+	// Sem o prefixo a chave nem chega ao banco (os repositórios nil entrariam em pânico)
+	keys := &APIKeyService{}
+
+	key, err := keys.ResolveAPIKey(context.Background(), "global-api-key")
+	if key != nil || err != nil {
+		t.Errorf("ResolveAPIKey = %v, %v; want nil, nil", key, err)
+	}
+}
+
+func TestKeyOfActiveTenant(t *testing.T) {
+	key := &model.APIKey{ID: "key-1", TenantID: "tenant-1", Scopes: []model.Scope{model.ScopeAdmin}}
+
+	if got := keyOfActiveTenant(key, &model.Tenant{ID: "tenant-1", Enabled: true}); got != key {
+		t.Errorf("enabled tenant = %v, want key", got)
+	}
+	if got := keyOfActiveTenant(key, &model.Tenant{ID: "tenant-1"}); got != nil {
+		t.Errorf("disabled tenant = %v, want nil", got)
+	}
+	if got := keyOfActiveTenant(key, nil); got != nil {
+		t.Errorf("deleted tenant = %v, want nil", got)
+	}
+}
+
+func TestValidateAPIKey(t *testing.T) {
+	past := time.Now().Add(-time.Hour)
+
+	tests := []struct {
+		name  string
+		key   model.APIKey
+		valid bool
+	}{
+		{"read only", model.APIKey{Scopes: []model.Scope{model.ScopeSessionsRead}}, true},
+		{"ips and cidrs", model.APIKey{Scopes: []model.Scope{model.ScopeMessagesSend}, AllowedIPs: []string{"203.0.113.10", "10.0.0.0/8", "::1"}}, true},
+		{"no scopes", model.APIKey{}, false},
+		{"unknown scope", model.APIKey{Scopes: []model.Scope{"messages:delete"}}, false},
+		{"invalid ip", model.APIKey{Scopes: []model.Scope{model.ScopeAdmin}, AllowedIPs: []string{"10.0.0"}}, false},
+		{"expired", model.APIKey{Scopes: []model.Scope{model.ScopeAdmin}, ExpiresAt: &past}, false},
+	}
+
+	for _, tt := range tests {
+		err := validateAPIKey(&tt.key)
+		if tt.valid != (err == nil) || (err != nil && !errors.Is(err, ErrInvalidAPIKey)) {
+			t.Errorf("%s: validateAPIKey = %v", tt.name, err)
+		}
+	}
+}
+
+func TestAPIKeyPermissions(t *testing.T) {
+	dashboard := &model.APIKey{
+		Scopes:     []model.Scope{model.ScopeSessionsRead},
+		AllowedIPs: []string{"10.0.0.0/8", "203.0.113.10"},
+	}
+
+	if !dashboard.HasScope(model.ScopeSessionsRead) || dashboard.HasScope(model.ScopeMessagesSend) {
+		t.Errorf("dashboard scopes = %v", dashboard.Scopes)
+	}
+	if !(&model.APIKey{Scopes: []model.Scope{model.ScopeAdmin}}).HasScope(model.ScopeWebhooksManage) {
+		t.Error("admin must grant every scope")
+	}
+
+	for ip, allowed := range map[string]bool{"10.1.2.3": true, "203.0.113.10": true, "203.0.113.11": false, "invalid": false} {
+		if dashboard.AllowsIP(ip) != allowed {
+			t.Errorf("AllowsIP(%q) = %v, want %v", ip, !allowed, allowed)
+		}
+	}
+}
@@ -0,0 +1,111 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"zpwoot/internal/model"
+)
+
+// AssistantAPIError é uma resposta de erro do endpoint /chat/completions
+type AssistantAPIError struct {
+	StatusCode int
+	Body       string
+}
+
+func (e *AssistantAPIError) Error() string {
+	return fmt.Sprintf("assistant api returned status %d: %s", e.StatusCode, e.Body)
+}
+
+// ChatMessage é uma mensagem no formato da API de chat da OpenAI
+type ChatMessage struct {
+	Role    string `json:"role"` // system, user ou assistant
+	Content string `json:"content"`
+}
+
+// AssistantClient acessa um endpoint compatível com /chat/completions da OpenAI
+// (OpenAI, Azure, OpenRouter, Ollama, vLLM, LM Studio...)
+type AssistantClient struct {
+	baseURL     string
+	model       string
+	apiKey      string
+	temperature *float64
+	maxTokens   int
+	client      *http.Client
+}
+
+func NewAssistantClient(config *model.AssistantConfig, timeout time.Duration) *AssistantClient {
+	return &AssistantClient{
+		baseURL:     strings.TrimRight(config.BaseURL, "/"),
+		model:       config.Model,
+		apiKey:      config.APIKey,
+		temperature: config.Temperature,
+		maxTokens:   config.MaxTokens,
+		client:      &http.Client{Timeout: timeout},
+	}
+}
+
+// Complete envia a conversa ao modelo e retorna o texto da resposta
+func (c *AssistantClient) Complete(ctx context.Context, messages []ChatMessage) (string, error) {
+	payload := map[string]interface{}{
+		"model":    c.model,
+		"messages": messages,
+	}
+	if c.temperature != nil {
+		payload["temperature"] = *c.temperature
+	}
+	if c.maxTokens > 0 {
+		payload["max_tokens"] = c.maxTokens
+	}
+
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode assistant request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.baseURL+"/chat/completions", bytes.NewReader(data))
+	if err != nil {
+		return "", fmt.Errorf("failed to create assistant request: %w", err)
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "zpwoot-assistant/1.0")
+	if c.apiKey != "" {
+		req.Header.Set("Authorization", "Bearer "+c.apiKey)
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("failed to send assistant request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return "", fmt.Errorf("failed to read assistant response: %w", err)
+	}
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return "", &AssistantAPIError{StatusCode: resp.StatusCode, Body: string(body)}
+	}
+
+	var result struct {
+		Choices []struct {
+			Message ChatMessage `json:"message"`
+		} `json:"choices"`
+	}
+	if err := json.Unmarshal(body, &result); err != nil {
+		return "", fmt.Errorf("failed to decode assistant response: %w", err)
+	}
+	if len(result.Choices) == 0 {
+		return "", fmt.Errorf("assistant response has no choices")
+	}
+
+	return strings.TrimSpace(result.Choices[0].Message.Content), nil
+}
@@ -0,0 +1,78 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"zpwoot/internal/model"
+)
+
+// newFakeCompletions simula um servidor compatível com /chat/completions que responde
+// com o número de mensagens recebidas e o conteúdo da última
+func newFakeCompletions(t *testing.T) *httptest.Server {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.Path != "/v1/chat/completions" {
+			http.NotFound(w, r)
+			return
+		}
+		if r.Header.Get("Authorization") != "Bearer sk-test" {
+			w.WriteHeader(http.StatusUnauthorized)
+			json.NewEncoder(w).Encode(map[string]interface{}{"error": map[string]string{"message": "invalid api key"}})
+			return
+		}
+
+		var body struct {
+			Model       string        `json:"model"`
+			Messages    []ChatMessage `json:"messages"`
+			Temperature *float64      `json:"temperature"`
+		}
+		json.NewDecoder(r.Body).Decode(&body)
+
+		if body.Model != "local-model" || body.Temperature == nil || *body.Temperature != 0.2 {
+			http.Error(w, "unexpected request", http.StatusBadRequest)
+			return
+		}
+
+		last := body.Messages[len(body.Messages)-1]
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"choices": []map[string]interface{}{
+				{"message": ChatMessage{Role: "assistant", Content: " " + body.Messages[0].Role + "/" + last.Content + " "}},
+			},
+		})
+	}))
+	t.Cleanup(server.Close)
+
+	return server
+}
+
+func TestAssistantClientComplete(t *testing.T) {
+	server := newFakeCompletions(t)
+	temperature := 0.2
+	config := &model.AssistantConfig{BaseURL: server.URL + "/v1/", Model: "local-model", APIKey: "sk-test", Temperature: &temperature}
+
+	reply, err := NewAssistantClient(config, 5*time.Second).Complete(context.Background(), []ChatMessage{
+		{Role: "system", Content: "Seja breve"},
+		{Role: "user", Content: "oi"},
+		{Role: "assistant", Content: "Olá!"},
+		{Role: "user", Content: "qual o horário?"},
+	})
+	if err != nil {
+		t.Fatalf("Complete() error = %v", err)
+	}
+	if reply != "system/qual o horário?" {
+		t.Errorf("Complete() = %q", reply)
+	}
+
+	config.APIKey = "wrong"
+	_, err = NewAssistantClient(config, 5*time.Second).Complete(context.Background(), []ChatMessage{{Role: "user", Content: "oi"}})
+
+	var apiErr *AssistantAPIError
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusUnauthorized {
+		t.Errorf("Complete(wrong key) error = %v, want status 401", err)
+	}
+}
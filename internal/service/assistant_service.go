@@ -0,0 +1,244 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"go.mau.fi/whatsmeow/types/events"
+
+	"zpwoot/internal/model"
+	"zpwoot/internal/repository"
+	"zpwoot/pkg/logger"
+)
+
+var ErrAssistantConversationNotFound = errors.New("assistant conversation not found")
+
+// defaultAssistantHistorySize é a janela de memória quando a sessão não define history_size
+const defaultAssistantHistorySize = 20
+
+// AssistantServiceConfig define os limites do assistente
+type AssistantServiceConfig struct {
+	Timeout   time.Duration // Timeout de cada chamada ao modelo e de cada envio
+	QueueSize int           // Mensagens pendentes por sessão
+}
+
+// AssistantService responde as conversas individuais da sessão com um modelo de linguagem
+// compatível com /chat/completions. Cada contato tem uma memória própria (janela das últimas
+// mensagens) no Postgres; a conversa começa por uma palavra-gatilho, expira após um período sem
+// interação e pode ser pausada para que um humano assuma. As mensagens de cada sessão são
+// processadas em ordem por um worker próprio.
+type AssistantService struct {
+	sessionRepo    *repository.SessionRepository
+	assistantRepo  *repository.AssistantRepository
+	sessionManager *SessionManager
+	config         AssistantServiceConfig
+	workers        *sessionWorkers
+}
+
+func NewAssistantService(sessionRepo *repository.SessionRepository, assistantRepo *repository.AssistantRepository, sessionManager *SessionManager, config AssistantServiceConfig) *AssistantService {
+	if config.Timeout <= 0 {
+		config.Timeout = 60 * time.Second
+	}
+	if config.QueueSize <= 0 {
+		config.QueueSize = 1000
+	}
+
+	return &AssistantService{
+		sessionRepo:    sessionRepo,
+		assistantRepo:  assistantRepo,
+		sessionManager: sessionManager,
+		config:         config,
+		workers:        newSessionWorkers(config.QueueSize),
+	}
+}
+
+// SetAssistant liga o assistente ao processamento de eventos das sessões
+func (m *SessionManager) SetAssistant(assistant *AssistantService) {
+	m.eventHandler.assistant = assistant
+}
+
+// Stop encerra os workers; mensagens ainda na fila são descartadas
+func (s *AssistantService) Stop() {
+	s.workers.stop()
+	logger.Log.Info().Msg("Assistant stopped")
+}
+
+// List retorna as conversas da sessão com o assistente (status vazio = todas)
+func (s *AssistantService) List(ctx context.Context, sessionID string, status model.AssistantStatus, limit, offset int) ([]*model.AssistantConversation, error) {
+	return s.assistantRepo.ListConversations(ctx, sessionID, status, limit, offset)
+}
+
+// Pause entrega a conversa do contato a um humano: o assistente deixa de responder até Close
+// (ou até a conversa expirar)
+func (s *AssistantService) Pause(ctx context.Context, sessionID, phone string) error {
+	if err := s.assistantRepo.SetStatus(ctx, sessionID, cleanPhone(phone), model.AssistantStatusPaused); err != nil {
+		return err
+	}
+
+	logger.Log.Info().Str("session_id", sessionID).Str("phone", phone).Msg("Assistant paused, conversation handed to a human")
+	return nil
+}
+
+// Close encerra a conversa e apaga a memória; a próxima mensagem com um gatilho recomeça
+func (s *AssistantService) Close(ctx context.Context, sessionID, phone string) error {
+	deleted, err := s.assistantRepo.DeleteConversation(ctx, sessionID, cleanPhone(phone))
+	if err != nil {
+		return err
+	}
+	if !deleted {
+		return ErrAssistantConversationNotFound
+	}
+
+	logger.Log.Info().Str("session_id", sessionID).Str("phone", phone).Msg("Assistant conversation closed")
+	return nil
+}
+
+// sessionConfig retorna a configuração do assistente da sessão (nil se desativado). Sessões
+// salvas com o fluxo e o assistente ativos ficam só com o fluxo, para não responder duas vezes.
+func (s *AssistantService) sessionConfig(ctx context.Context, sessionID string) *model.AssistantConfig {
+	session, err := s.sessionRepo.GetByID(ctx, sessionID)
+	if err != nil {
+		logger.Log.Warn().Err(err).Str("session_id", sessionID).Msg("Failed to load session for assistant")
+		return nil
+	}
+	if !session.AssistantEnabled() || session.FlowBotEnabled() {
+		return nil
+	}
+	return session.AssistantConfig
+}
+
+// HandleMessage agenda a resposta do assistente à mensagem
+func (s *AssistantService) HandleMessage(sessionID string, evt *events.Message) {
+	if !isDirectChat(evt.Info) {
+		return
+	}
+
+	s.workers.enqueue(sessionID, func(ctx context.Context) {
+		s.process(ctx, sessionID, evt)
+	})
+}
+
+func (s *AssistantService) process(ctx context.Context, sessionID string, evt *events.Message) {
+	config := s.sessionConfig(ctx, sessionID)
+	if config == nil {
+		return
+	}
+
+	phone := chatPhone(evt.Info)
+	if phone == "" {
+		return
+	}
+
+	log := logger.Log.With().Str("session_id", sessionID).Str("phone", phone).Logger()
+
+	conversation, err := s.assistantRepo.GetConversation(ctx, sessionID, phone)
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to load assistant conversation")
+		return
+	}
+
+	// Conversas paradas há mais tempo que a expiração recomeçam sem memória (inclusive as pausadas)
+	if conversation != nil && config.Expire > 0 && time.Since(conversation.UpdatedAt) > time.Duration(config.Expire)*time.Minute {
+		if _, err := s.assistantRepo.DeleteConversation(ctx, sessionID, phone); err != nil {
+			log.Error().Err(err).Msg("Failed to expire assistant conversation")
+			return
+		}
+		log.Debug().Msg("Assistant conversation expired")
+		conversation = nil
+	}
+
+	// Mensagem enviada pelo celular: um humano assumiu a conversa
+	if evt.Info.IsFromMe {
+		if config.StopOnFromMe && conversation != nil && conversation.Status == model.AssistantStatusActive {
+			if err := s.Pause(ctx, sessionID, phone); err != nil {
+				log.Error().Err(err).Msg("Failed to pause assistant")
+			}
+		}
+		return
+	}
+
+	text := strings.TrimSpace(ExtractMessageText(evt.Message))
+	if text == "" {
+		return
+	}
+
+	if config.FinishKeyword != "" && strings.EqualFold(text, strings.TrimSpace(config.FinishKeyword)) {
+		if conversation != nil {
+			if _, err := s.assistantRepo.DeleteConversation(ctx, sessionID, phone); err != nil {
+				log.Error().Err(err).Msg("Failed to finish assistant conversation")
+			}
+			log.Info().Msg("Assistant conversation finished by contact")
+		}
+		return
+	}
+
+	if conversation != nil && conversation.Status == model.AssistantStatusPaused {
+		return
+	}
+	if conversation == nil && !matchesTrigger(config.Triggers, text) {
+		return
+	}
+
+	window := config.HistorySize
+	if window <= 0 {
+		window = defaultAssistantHistorySize
+	}
+
+	var history []model.AssistantMessage
+	if conversation != nil {
+		if history, err = s.assistantRepo.ListMessages(ctx, sessionID, phone, window); err != nil {
+			log.Error().Err(err).Msg("Failed to load assistant memory")
+			return
+		}
+	}
+
+	messages := make([]ChatMessage, 0, len(history)+2)
+	if config.SystemPrompt != "" {
+		messages = append(messages, ChatMessage{Role: "system", Content: config.SystemPrompt})
+	}
+	for _, message := range history {
+		messages = append(messages, ChatMessage{Role: message.Role, Content: message.Content})
+	}
+	messages = append(messages, ChatMessage{Role: "user", Content: text})
+
+	client, err := s.sessionManager.GetClient(sessionID)
+	if err != nil {
+		log.Warn().Err(err).Msg("Client not found for assistant")
+		return
+	}
+
+	// "Digitando..." enquanto o modelo gera a resposta
+	if err := s.sessionManager.SendPresence(ctx, client, phone, "composing"); err != nil {
+		log.Debug().Err(err).Msg("Failed to send assistant typing presence")
+	}
+
+	reply, err := NewAssistantClient(config, s.config.Timeout).Complete(ctx, messages)
+	if err != nil || reply == "" {
+		_ = s.sessionManager.SendPresence(ctx, client, phone, "paused")
+		log.Error().Err(err).Msg("Failed to get assistant reply")
+		return
+	}
+
+	if err := s.assistantRepo.SetStatus(ctx, sessionID, phone, model.AssistantStatusActive); err != nil {
+		log.Error().Err(err).Msg("Failed to save assistant conversation")
+		return
+	}
+	if err := s.assistantRepo.AppendMessages(ctx, sessionID, phone, window,
+		model.AssistantMessage{Role: "user", Content: text},
+		model.AssistantMessage{Role: "assistant", Content: reply},
+	); err != nil {
+		log.Error().Err(err).Msg("Failed to save assistant memory")
+	}
+
+	sendCtx, cancel := context.WithTimeout(ctx, s.config.Timeout)
+	defer cancel()
+
+	if _, _, err := s.sessionManager.SendTextMessage(sendCtx, client, phone, markdownToWhatsApp.Replace(reply)); err != nil {
+		log.Error().Err(err).Msg("Failed to send assistant reply")
+		return
+	}
+
+	log.Debug().Int("history", len(history)).Msg("Assistant reply sent")
+}
@@ -0,0 +1,241 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"zpwoot/internal/model"
+	"zpwoot/internal/repository"
+	"zpwoot/pkg/logger"
+)
+
+const (
+	auditMaxString  = 1024 // Caracteres mantidos de cada texto do corpo (mídia em base64 é truncada)
+	auditMaxItems   = 20   // Itens mantidos de cada lista (destinatários de campanha, por exemplo)
+	auditDeleteSize = 5000 // Registros removidos por DELETE na limpeza da retenção
+)
+
+// Campos cujo valor nunca é gravado (comparados sem maiúsculas, "_" e "-")
+var auditSecretFields = []string{"password", "passwd", "token", "secret", "apikey", "authorization", "credential", "privatekey"}
+
+// AuditConfig define a fila de gravação e a retenção do audit log
+type AuditConfig struct {
+	Retention       time.Duration // Registros mais antigos são removidos (0 = manter para sempre)
+	CleanupInterval time.Duration // Intervalo entre limpezas da retenção
+	BufferSize      int           // Registros aguardando gravação antes de gravar de forma síncrona
+}
+
+// AuditService grava o audit log fora do caminho da requisição: o resumo sem segredos é montado
+// ao receber o registro, e um worker grava no Postgres. Com a fila cheia o registro é gravado de
+// forma síncrona, então nenhuma chamada deixa de ser auditada.
+type AuditService struct {
+	auditRepo *repository.AuditRepository
+	config    AuditConfig
+
+	entries chan *model.AuditEntry
+	cancel  context.CancelFunc
+	wg      sync.WaitGroup
+}
+
+func NewAuditService(auditRepo *repository.AuditRepository, config AuditConfig) *AuditService {
+	if config.CleanupInterval <= 0 {
+		config.CleanupInterval = time.Hour
+	}
+	if config.BufferSize <= 0 {
+		config.BufferSize = 1000
+	}
+
+	return &AuditService{
+		auditRepo: auditRepo,
+		config:    config,
+		entries:   make(chan *model.AuditEntry, config.BufferSize),
+	}
+}
+
+func (s *AuditService) Start() {
+	ctx, cancel := context.WithCancel(context.Background())
+	s.cancel = cancel
+
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		s.run(ctx)
+	}()
+
+	logger.Log.Info().
+		Dur("retention", s.config.Retention).
+		Msg("✅ Audit log started")
+}
+
+// Stop grava os registros pendentes e encerra o worker
+func (s *AuditService) Stop() {
+	if s.cancel != nil {
+		s.cancel()
+	}
+	s.wg.Wait()
+}
+
+// Record resume query e corpo sem os segredos e enfileira o registro para gravação; a fila guarda
+// apenas o resumo, nunca o corpo bruto
+func (s *AuditService) Record(entry *model.AuditEntry, query url.Values, contentType string, body []byte) {
+	entry.Summary = summarizeRequest(query, contentType, body)
+
+	select {
+	case s.entries <- entry:
+	default:
+		logger.Log.Warn().Msg("Audit queue full, writing entry synchronously")
+		s.write(entry)
+	}
+}
+
+// List consulta o audit log (restrito ao tenant e às sessões do contexto)
+func (s *AuditService) List(ctx context.Context, filter model.AuditFilter) ([]*model.AuditEntry, error) {
+	return s.auditRepo.List(ctx, filter)
+}
+
+func (s *AuditService) run(ctx context.Context) {
+	ticker := time.NewTicker(s.config.CleanupInterval)
+	defer ticker.Stop()
+
+	s.cleanup(ctx)
+
+	for {
+		select {
+		case <-ctx.Done():
+			// Grava o que ainda está na fila antes de encerrar
+			for {
+				select {
+				case entry := <-s.entries:
+					s.write(entry)
+				default:
+					return
+				}
+			}
+		case entry := <-s.entries:
+			s.write(entry)
+		case <-ticker.C:
+			s.cleanup(ctx)
+		}
+	}
+}
+
+func (s *AuditService) write(entry *model.AuditEntry) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	if err := s.auditRepo.Create(ctx, entry); err != nil {
+		logger.Log.Error().
+			Err(err).
+			Str(logger.FieldRequestID, entry.RequestID).
+			Str("route", entry.Route).
+			Msg("Failed to write audit entry")
+	}
+}
+
+// cleanup remove em lotes os registros anteriores à retenção
+func (s *AuditService) cleanup(ctx context.Context) {
+	if s.config.Retention <= 0 {
+		return
+	}
+
+	before := time.Now().Add(-s.config.Retention)
+	var total int64
+	for {
+		n, err := s.auditRepo.DeleteBefore(ctx, before, auditDeleteSize)
+		if err != nil {
+			logger.Log.Error().Err(err).Msg("Failed to apply audit log retention")
+			return
+		}
+		total += n
+		if n < auditDeleteSize {
+			break
+		}
+	}
+
+	if total > 0 {
+		logger.Log.Info().Int64("deleted", total).Msg("Audit log retention applied")
+	}
+}
+
+// summarizeRequest monta o resumo gravado no audit log: a query e o corpo JSON com os campos
+// secretos mascarados, textos longos e listas grandes truncados. Corpos não JSON (multipart)
+// registram apenas o tipo.
+func summarizeRequest(query url.Values, contentType string, body []byte) json.RawMessage {
+	summary := map[string]interface{}{}
+
+	if len(query) > 0 {
+		values := map[string]interface{}{}
+		for key, value := range query {
+			values[key] = strings.Join(value, ",")
+		}
+		summary["query"] = redactValue(values)
+	}
+
+	var payload interface{}
+	if len(body) > 0 && json.Unmarshal(body, &payload) == nil {
+		summary["body"] = redactValue(payload)
+	} else if contentType != "" {
+		summary["content_type"] = contentType
+	}
+
+	if len(summary) == 0 {
+		return nil
+	}
+
+	data, err := json.Marshal(summary)
+	if err != nil {
+		return nil
+	}
+	return data
+}
+
+func redactValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, item := range v {
+			// Apenas textos são mascarados: max_tokens, por exemplo, continua visível
+			if text, ok := item.(string); ok && text != "" && isSecretField(key) {
+				v[key] = "***"
+				continue
+			}
+			v[key] = redactValue(item)
+		}
+		return v
+	case []interface{}:
+		if len(v) > auditMaxItems {
+			v = append(v[:auditMaxItems:auditMaxItems], fmt.Sprintf("... (%d more)", len(v)-auditMaxItems))
+		}
+		for i, item := range v {
+			v[i] = redactValue(item)
+		}
+		return v
+	case string:
+		if len(v) > auditMaxString {
+			// Corta no início de um caractere para não gravar UTF-8 inválido
+			cut := auditMaxString
+			for cut > 0 && !utf8.RuneStart(v[cut]) {
+				cut--
+			}
+			return fmt.Sprintf("%s... (%d bytes)", v[:cut], len(v))
+		}
+		return v
+	default:
+		return v
+	}
+}
+
+func isSecretField(key string) bool {
+	key = strings.NewReplacer("_", "", "-", "").Replace(strings.ToLower(key))
+	for _, field := range auditSecretFields {
+		if strings.Contains(key, field) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,93 @@
+package service
+
+import (
+	"encoding/json"
+	"net/url"
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"zpwoot/internal/model"
+)
+
+func TestSummarizeRequest(t *testing.T) {
+	body := `{
+		"phone": "5511999999999",
+		"text": "Pedido confirmado",
+		"image": "` + strings.Repeat("A", 5000) + `",
+		"proxy": {"host": "proxy.local", "password": "s3cr3t"},
+		"webhook": {"url": "https://example.com/hook", "token": "abc"},
+		"assistant": {"api_key": "sk-123", "max_tokens": 500},
+		"recipients": [` + strings.TrimSuffix(strings.Repeat(`"5511",`, 30), ",") + `]
+	}`
+	query := url.Values{"apikey": {"zpw_secret"}, "version": {"2"}}
+
+	raw := summarizeRequest(query, "application/json", []byte(body))
+	if strings.Contains(string(raw), "s3cr3t") || strings.Contains(string(raw), "sk-123") ||
+		strings.Contains(string(raw), "zpw_secret") || strings.Contains(string(raw), `"abc"`) {
+		t.Fatalf("summary leaks a secret: %s", raw)
+	}
+
+	var summary struct {
+		Query map[string]string `json:"query"`
+		Body  struct {
+			Phone      string                 `json:"phone"`
+			Text       string                 `json:"text"`
+			Image      string                 `json:"image"`
+			Assistant  map[string]interface{} `json:"assistant"`
+			Recipients []string               `json:"recipients"`
+		} `json:"body"`
+	}
+	if err := json.Unmarshal(raw, &summary); err != nil {
+		t.Fatal(err)
+	}
+
+	if summary.Body.Phone != "5511999999999" || summary.Body.Text != "Pedido confirmado" || summary.Query["version"] != "2" {
+		t.Errorf("summary lost plain values: %s", raw)
+	}
+	if len(summary.Body.Image) > auditMaxString+32 {
+		t.Errorf("long value not truncated: %d bytes", len(summary.Body.Image))
+	}
+	if summary.Body.Assistant["max_tokens"] != float64(500) {
+		t.Errorf("max_tokens = %v, want 500", summary.Body.Assistant["max_tokens"])
+	}
+	if len(summary.Body.Recipients) != auditMaxItems+1 || summary.Body.Recipients[auditMaxItems] != "... (10 more)" {
+		t.Errorf("recipients = %v", summary.Body.Recipients)
+	}
+
+	if raw := summarizeRequest(nil, "multipart/form-data", nil); string(raw) != `{"content_type":"multipart/form-data"}` {
+		t.Errorf("multipart summary = %s", raw)
+	}
+	if raw := summarizeRequest(nil, "", nil); raw != nil {
+		t.Errorf("empty summary = %s", raw)
+	}
+}
+
+func TestRedactValueKeepsValidUTF8(t *testing.T) {
+	// "ã" ocupa 2 bytes: o limite cai no meio de um caractere
+	text := "a" + strings.Repeat("ã", auditMaxString)
+
+	got, ok := redactValue(text).(string)
+	if !ok || !utf8.ValidString(got) {
+		t.Fatalf("truncated value is not valid UTF-8: %q", got)
+	}
+	if !strings.HasPrefix(got, "a"+strings.Repeat("ã", auditMaxString/2-1)+"...") {
+		t.Errorf("truncated value = %q", got[:32])
+	}
+}
+
+func TestAuditResultFromStatus(t *testing.T) {
+	cases := map[int]model.AuditResult{
+		200: model.AuditResultSuccess,
+		201: model.AuditResultSuccess,
+		401: model.AuditResultDenied,
+		403: model.AuditResultDenied,
+		404: model.AuditResultFailure,
+		500: model.AuditResultFailure,
+	}
+	for status, want := range cases {
+		if got := model.AuditResultFromStatus(status); got != want {
+			t.Errorf("AuditResultFromStatus(%d) = %s, want %s", status, got, want)
+		}
+	}
+}
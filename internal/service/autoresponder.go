@@ -0,0 +1,489 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"go.mau.fi/whatsmeow/types"
+	"go.mau.fi/whatsmeow/types/events"
+
+	"zpwoot/internal/model"
+	"zpwoot/internal/repository"
+	"zpwoot/pkg/logger"
+)
+
+// ErrInvalidAutoReplyRule indica condição, horário comercial ou resposta inválidos
+var ErrInvalidAutoReplyRule = errors.New("invalid auto-reply rule")
+
+// AutoResponderConfig define o cache de regras e o timeout de envio das respostas
+type AutoResponderConfig struct {
+	CacheTTL    time.Duration // Validade das regras em memória (alterações de outras réplicas)
+	SendTimeout time.Duration
+}
+
+// compiledRule é a regra com o regex já compilado
+type compiledRule struct {
+	rule  *model.AutoReplyRule
+	regex *regexp.Regexp
+}
+
+type autoReplyCacheEntry struct {
+	rules    []*compiledRule
+	loadedAt time.Time
+}
+
+// AutoResponder avalia as regras de resposta automática da sessão a cada mensagem recebida
+// e envia a resposta da primeira regra que casar (uma resposta por mensagem).
+type AutoResponder struct {
+	autoReplyRepo   *repository.AutoReplyRepository
+	sessionManager  *SessionManager
+	templateService *TemplateService
+	config          AutoResponderConfig
+
+	cacheMu sync.RWMutex
+	cache   map[string]*autoReplyCacheEntry
+}
+
+func NewAutoResponder(autoReplyRepo *repository.AutoReplyRepository, sessionManager *SessionManager, templateService *TemplateService, config AutoResponderConfig) *AutoResponder {
+	if config.CacheTTL <= 0 {
+		config.CacheTTL = 30 * time.Second
+	}
+	if config.SendTimeout <= 0 {
+		config.SendTimeout = 2 * time.Minute
+	}
+
+	return &AutoResponder{
+		autoReplyRepo:   autoReplyRepo,
+		sessionManager:  sessionManager,
+		templateService: templateService,
+		config:          config,
+		cache:           make(map[string]*autoReplyCacheEntry),
+	}
+}
+
+// SetAutoResponder liga as regras de resposta automática ao processamento de mensagens recebidas
+func (m *SessionManager) SetAutoResponder(responder *AutoResponder) {
+	m.eventHandler.autoResponder = responder
+}
+
+// parseClock converte HH:MM em minutos desde a meia-noite (24:00 é aceito como fim do dia)
+func parseClock(value string) (int, error) {
+	parts := strings.Split(value, ":")
+	if len(parts) != 2 {
+		return 0, fmt.Errorf("invalid time %q, expected HH:MM", value)
+	}
+
+	hours, err := strconv.Atoi(parts[0])
+	if err != nil {
+		return 0, fmt.Errorf("invalid time %q, expected HH:MM", value)
+	}
+	minutes, err := strconv.Atoi(parts[1])
+	if err != nil || minutes < 0 || minutes > 59 || hours < 0 || hours > 24 || (hours == 24 && minutes > 0) {
+		return 0, fmt.Errorf("invalid time %q, expected HH:MM", value)
+	}
+
+	return hours*60 + minutes, nil
+}
+
+func validateBusinessHours(hours *model.BusinessHours) error {
+	if hours == nil || len(hours.Ranges) == 0 {
+		return fmt.Errorf("%w: businessHours with at least one range is required", ErrInvalidAutoReplyRule)
+	}
+	if _, err := time.LoadLocation(hours.Timezone); err != nil {
+		return fmt.Errorf("%w: invalid timezone %q", ErrInvalidAutoReplyRule, hours.Timezone)
+	}
+
+	for i, r := range hours.Ranges {
+		if len(r.Weekdays) == 0 {
+			return fmt.Errorf("%w: range %d has no weekdays", ErrInvalidAutoReplyRule, i+1)
+		}
+		for _, day := range r.Weekdays {
+			if day < 0 || day > 6 {
+				return fmt.Errorf("%w: invalid weekday %d (0 = sunday, 6 = saturday)", ErrInvalidAutoReplyRule, day)
+			}
+		}
+
+		start, err := parseClock(r.Start)
+		if err != nil {
+			return fmt.Errorf("%w: %v", ErrInvalidAutoReplyRule, err)
+		}
+		end, err := parseClock(r.End)
+		if err != nil {
+			return fmt.Errorf("%w: %v", ErrInvalidAutoReplyRule, err)
+		}
+		if start >= end {
+			return fmt.Errorf("%w: range %d must start before it ends", ErrInvalidAutoReplyRule, i+1)
+		}
+	}
+
+	return nil
+}
+
+// WithinBusinessHours informa se t está dentro de algum intervalo do horário comercial
+func WithinBusinessHours(hours *model.BusinessHours, t time.Time) bool {
+	loc, err := time.LoadLocation(hours.Timezone)
+	if err != nil {
+		loc = time.UTC
+	}
+
+	local := t.In(loc)
+	weekday := int(local.Weekday())
+	minute := local.Hour()*60 + local.Minute()
+
+	for _, r := range hours.Ranges {
+		start, errStart := parseClock(r.Start)
+		end, errEnd := parseClock(r.End)
+		if errStart != nil || errEnd != nil {
+			continue
+		}
+		for _, day := range r.Weekdays {
+			if day == weekday && minute >= start && minute < end {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+func validateAutoReplyResponse(response *model.AutoReplyResponse) error {
+	if response == nil {
+		return fmt.Errorf("%w: response is required", ErrInvalidAutoReplyRule)
+	}
+
+	switch response.Type {
+	case "text", "image", "video", "audio", "document":
+	default:
+		return fmt.Errorf("%w: unsupported response type %q", ErrInvalidAutoReplyRule, response.Type)
+	}
+
+	// Com template, o conteúdo é validado no envio (o template pode mudar de versão)
+	if response.TemplateID != "" {
+		return nil
+	}
+	if response.Type == "text" && response.Text == "" {
+		return fmt.Errorf("%w: text is required for text responses", ErrInvalidAutoReplyRule)
+	}
+	if response.Type != "text" && response.MediaURL == "" {
+		return fmt.Errorf("%w: mediaUrl is required for %s responses", ErrInvalidAutoReplyRule, response.Type)
+	}
+
+	return nil
+}
+
+// compileRule valida a regra e compila o regex (se houver)
+func compileRule(rule *model.AutoReplyRule) (*compiledRule, error) {
+	if strings.TrimSpace(rule.Name) == "" {
+		return nil, fmt.Errorf("%w: name is required", ErrInvalidAutoReplyRule)
+	}
+	if rule.Cooldown < 0 {
+		return nil, fmt.Errorf("%w: cooldown must not be negative", ErrInvalidAutoReplyRule)
+	}
+
+	compiled := &compiledRule{rule: rule}
+
+	switch rule.MatchType {
+	case model.AutoReplyMatchKeyword:
+		if strings.TrimSpace(rule.Pattern) == "" {
+			return nil, fmt.Errorf("%w: pattern is required for keyword rules", ErrInvalidAutoReplyRule)
+		}
+	case model.AutoReplyMatchRegex:
+		re, err := regexp.Compile(rule.Pattern)
+		if err != nil || rule.Pattern == "" {
+			return nil, fmt.Errorf("%w: invalid regex %q", ErrInvalidAutoReplyRule, rule.Pattern)
+		}
+		compiled.regex = re
+	case model.AutoReplyMatchFirstMessage:
+	case model.AutoReplyMatchOutsideHours:
+		if err := validateBusinessHours(rule.BusinessHours); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("%w: unsupported match type %q", ErrInvalidAutoReplyRule, rule.MatchType)
+	}
+
+	if err := validateAutoReplyResponse(rule.Response); err != nil {
+		return nil, err
+	}
+
+	return compiled, nil
+}
+
+// matches avalia a condição da regra para o texto recebido
+func (c *compiledRule) matches(text string, firstMessage bool, now time.Time) bool {
+	switch c.rule.MatchType {
+	case model.AutoReplyMatchKeyword:
+		return strings.EqualFold(text, strings.TrimSpace(c.rule.Pattern))
+	case model.AutoReplyMatchRegex:
+		return text != "" && c.regex.MatchString(text)
+	case model.AutoReplyMatchFirstMessage:
+		return firstMessage
+	case model.AutoReplyMatchOutsideHours:
+		return !WithinBusinessHours(c.rule.BusinessHours, now)
+	}
+	return false
+}
+
+func (a *AutoResponder) validate(ctx context.Context, rule *model.AutoReplyRule) error {
+	if _, err := compileRule(rule); err != nil {
+		return err
+	}
+
+	if rule.Response.TemplateID != "" {
+		template, err := a.templateService.Get(ctx, rule.Response.TemplateID, 0)
+		if err != nil || !template.IsAvailableTo(rule.SessionID) {
+			return fmt.Errorf("%w: %s", ErrTemplateNotFound, rule.Response.TemplateID)
+		}
+	}
+
+	return nil
+}
+
+func (a *AutoResponder) Create(ctx context.Context, rule *model.AutoReplyRule) error {
+	if err := a.validate(ctx, rule); err != nil {
+		return err
+	}
+
+	rule.ID = uuid.New().String()
+
+	if err := a.autoReplyRepo.Create(ctx, rule); err != nil {
+		return err
+	}
+	a.invalidate(rule.SessionID)
+
+	logger.Log.Info().
+		Str("rule_id", rule.ID).
+		Str("session_id", rule.SessionID).
+		Str("match_type", string(rule.MatchType)).
+		Msg("Auto-reply rule created")
+
+	return nil
+}
+
+func (a *AutoResponder) Update(ctx context.Context, rule *model.AutoReplyRule) error {
+	if err := a.validate(ctx, rule); err != nil {
+		return err
+	}
+
+	if err := a.autoReplyRepo.Update(ctx, rule); err != nil {
+		return err
+	}
+	a.invalidate(rule.SessionID)
+
+	return nil
+}
+
+func (a *AutoResponder) Get(ctx context.Context, sessionID, ruleID string) (*model.AutoReplyRule, error) {
+	return a.autoReplyRepo.GetByID(ctx, sessionID, ruleID)
+}
+
+func (a *AutoResponder) List(ctx context.Context, sessionID string) ([]*model.AutoReplyRule, error) {
+	return a.autoReplyRepo.List(ctx, sessionID, false)
+}
+
+func (a *AutoResponder) Delete(ctx context.Context, sessionID, ruleID string) error {
+	if err := a.autoReplyRepo.Delete(ctx, sessionID, ruleID); err != nil {
+		return err
+	}
+	a.invalidate(sessionID)
+
+	return nil
+}
+
+func (a *AutoResponder) invalidate(sessionID string) {
+	a.cacheMu.Lock()
+	delete(a.cache, sessionID)
+	a.cacheMu.Unlock()
+}
+
+// rules retorna as regras ativas da sessão, recarregando do banco quando o cache expira
+func (a *AutoResponder) rules(ctx context.Context, sessionID string) ([]*compiledRule, error) {
+	a.cacheMu.RLock()
+	entry, ok := a.cache[sessionID]
+	a.cacheMu.RUnlock()
+	if ok && time.Since(entry.loadedAt) < a.config.CacheTTL {
+		return entry.rules, nil
+	}
+
+	rules, err := a.autoReplyRepo.List(ctx, sessionID, true)
+	if err != nil {
+		return nil, err
+	}
+
+	compiled := make([]*compiledRule, 0, len(rules))
+	for _, rule := range rules {
+		c, err := compileRule(rule)
+		if err != nil {
+			logger.Log.Warn().Err(err).Str("rule_id", rule.ID).Msg("Skipping invalid auto-reply rule")
+			continue
+		}
+		compiled = append(compiled, c)
+	}
+
+	a.cacheMu.Lock()
+	a.cache[sessionID] = &autoReplyCacheEntry{rules: compiled, loadedAt: time.Now()}
+	a.cacheMu.Unlock()
+
+	return compiled, nil
+}
+
+// contactPhone retorna o telefone do remetente (o endereço alternativo quando a mensagem vem de um LID)
+func contactPhone(info types.MessageInfo) string {
+	if info.Sender.Server == types.DefaultUserServer {
+		return info.Sender.User
+	}
+	if info.SenderAlt.Server == types.DefaultUserServer {
+		return info.SenderAlt.User
+	}
+	return ""
+}
+
+// HandleMessage avalia as regras da sessão para uma mensagem recebida. Mensagens próprias,
+// de grupos, listas de transmissão, status e canais são ignoradas.
+func (a *AutoResponder) HandleMessage(sessionID string, evt *events.Message) {
+	info := evt.Info
+	if info.IsFromMe || info.IsGroup {
+		return
+	}
+	if info.Chat.Server != types.DefaultUserServer && info.Chat.Server != types.HiddenUserServer {
+		return
+	}
+
+	ctx := context.Background()
+
+	rules, err := a.rules(ctx, sessionID)
+	if err != nil {
+		logger.Log.Error().Err(err).Str("session_id", sessionID).Msg("Failed to load auto-reply rules")
+		return
+	}
+	if len(rules) == 0 {
+		return
+	}
+
+	contact := contactPhone(info)
+	if contact == "" {
+		logger.Log.Debug().
+			Str("session_id", sessionID).
+			Str("from", info.Sender.String()).
+			Msg("Auto-reply skipped: sender phone unknown")
+		return
+	}
+
+	// Contatos são registrados enquanto houver regras ativas, então a primeira mensagem
+	// é relativa ao período em que a resposta automática está ligada
+	firstMessage, err := a.autoReplyRepo.MarkContactSeen(ctx, sessionID, contact)
+	if err != nil {
+		logger.Log.Warn().Err(err).Str("session_id", sessionID).Msg("Failed to mark auto-reply contact")
+	}
+
+	text := strings.TrimSpace(ExtractMessageText(evt.Message))
+	now := time.Now()
+
+	for _, rule := range rules {
+		if rule.matches(text, firstMessage, now) {
+			a.reply(ctx, sessionID, contact, info.PushName, rule.rule)
+			return
+		}
+	}
+}
+
+// content monta o conteúdo da resposta (do template, se houver) com as variáveis do contato
+func (a *AutoResponder) content(ctx context.Context, sessionID, contact, pushName string, response *model.AutoReplyResponse) (*model.TemplateContent, error) {
+	variables := map[string]string{"name": pushName, "phone": contact}
+	for name, value := range response.Variables {
+		variables[name] = value
+	}
+
+	content := &model.TemplateContent{
+		Text:     response.Text,
+		MediaURL: response.MediaURL,
+		Caption:  response.Caption,
+		FileName: response.FileName,
+	}
+	if response.TemplateID != "" {
+		template, err := a.templateService.Get(ctx, response.TemplateID, 0)
+		if err != nil || !template.IsAvailableTo(sessionID) {
+			return nil, fmt.Errorf("%w: %s", ErrTemplateNotFound, response.TemplateID)
+		}
+		content = template.Content
+	}
+
+	rendered, missing := RenderContent(content, variables)
+	if len(missing) > 0 {
+		return nil, fmt.Errorf("%w: %s", ErrMissingVariables, strings.Join(missing, ", "))
+	}
+
+	return rendered, nil
+}
+
+func (a *AutoResponder) reply(ctx context.Context, sessionID, contact, pushName string, rule *model.AutoReplyRule) {
+	log := logger.Log.With().
+		Str("session_id", sessionID).
+		Str("rule_id", rule.ID).
+		Str("phone", contact).
+		Logger()
+
+	content, err := a.content(ctx, sessionID, contact, pushName, rule.Response)
+	if err != nil {
+		log.Warn().Err(err).Msg("Failed to render auto-reply")
+		return
+	}
+	if (rule.Response.Type == "text" && content.Text == "") || (rule.Response.Type != "text" && content.MediaURL == "") {
+		log.Warn().Str("type", rule.Response.Type).Msg("Auto-reply content is empty for the response type")
+		return
+	}
+
+	if rule.Cooldown > 0 {
+		acquired, err := a.autoReplyRepo.AcquireCooldown(ctx, rule.ID, contact, rule.Cooldown)
+		if err != nil {
+			log.Error().Err(err).Msg("Failed to check auto-reply cooldown")
+			return
+		}
+		if !acquired {
+			log.Debug().Msg("Auto-reply skipped: cooldown active")
+			return
+		}
+	}
+
+	client, err := a.sessionManager.GetClient(sessionID)
+	if err != nil {
+		log.Warn().Err(err).Msg("Client not found for auto-reply")
+		return
+	}
+
+	sendCtx, cancel := context.WithTimeout(ctx, a.config.SendTimeout)
+	defer cancel()
+
+	caption := content.Caption
+	if caption == "" {
+		caption = content.Text
+	}
+
+	switch rule.Response.Type {
+	case "text":
+		_, _, err = a.sessionManager.SendTextMessage(sendCtx, client, contact, textWithButtons(content.Text, content.Buttons))
+	case "image":
+		_, _, err = a.sessionManager.SendImageFromURL(sendCtx, client, contact, content.MediaURL, caption)
+	case "video":
+		_, _, err = a.sessionManager.SendVideoFromURL(sendCtx, client, contact, content.MediaURL, caption)
+	case "audio":
+		_, _, err = a.sessionManager.SendAudioFromURL(sendCtx, client, contact, content.MediaURL)
+	case "document":
+		_, _, err = a.sessionManager.SendDocumentFromURL(sendCtx, client, contact, content.MediaURL, content.FileName, caption)
+	default:
+		err = fmt.Errorf("unsupported response type: %s", rule.Response.Type)
+	}
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to send auto-reply")
+		return
+	}
+
+	log.Info().Str("match_type", string(rule.MatchType)).Msg("Auto-reply sent")
+}
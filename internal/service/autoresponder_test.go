@@ -0,0 +1,75 @@
+package service
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"zpwoot/internal/model"
+)
+
+func TestAutoReplyRuleMatches(t *testing.T) {
+	response := &model.AutoReplyResponse{Type: "text", Text: "Menu"}
+	hours := &model.BusinessHours{
+		Timezone: "America/Sao_Paulo",
+		Ranges:   []model.BusinessHoursRange{{Weekdays: []int{1, 2, 3, 4, 5}, Start: "09:00", End: "18:00"}},
+	}
+
+	// Segunda-feira, 10:00 e 20:00 em São Paulo (UTC-3)
+	open := time.Date(2025, 11, 17, 13, 0, 0, 0, time.UTC)
+	closed := time.Date(2025, 11, 17, 23, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name         string
+		rule         *model.AutoReplyRule
+		text         string
+		firstMessage bool
+		now          time.Time
+		want         bool
+	}{
+		{"keyword ignores case", &model.AutoReplyRule{MatchType: model.AutoReplyMatchKeyword, Pattern: "oi"}, "Oi", false, open, true},
+		{"keyword is exact", &model.AutoReplyRule{MatchType: model.AutoReplyMatchKeyword, Pattern: "oi"}, "oi tudo bem", false, open, false},
+		{"regex", &model.AutoReplyRule{MatchType: model.AutoReplyMatchRegex, Pattern: `(?i)pre(ç|c)o`}, "qual o preço?", false, open, true},
+		{"regex ignores empty text", &model.AutoReplyRule{MatchType: model.AutoReplyMatchRegex, Pattern: `.*`}, "", false, open, false},
+		{"first message", &model.AutoReplyRule{MatchType: model.AutoReplyMatchFirstMessage}, "", true, open, true},
+		{"returning contact", &model.AutoReplyRule{MatchType: model.AutoReplyMatchFirstMessage}, "oi", false, open, false},
+		{"inside business hours", &model.AutoReplyRule{MatchType: model.AutoReplyMatchOutsideHours, BusinessHours: hours}, "oi", false, open, false},
+		{"outside business hours", &model.AutoReplyRule{MatchType: model.AutoReplyMatchOutsideHours, BusinessHours: hours}, "oi", false, closed, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.rule.Name = tt.name
+			tt.rule.Response = response
+
+			compiled, err := compileRule(tt.rule)
+			if err != nil {
+				t.Fatalf("compileRule() error = %v", err)
+			}
+			if got := compiled.matches(tt.text, tt.firstMessage, tt.now); got != tt.want {
+				t.Errorf("matches() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCompileRuleInvalid(t *testing.T) {
+	response := &model.AutoReplyResponse{Type: "text", Text: "Menu"}
+
+	rules := []*model.AutoReplyRule{
+		{Name: "regex", MatchType: model.AutoReplyMatchRegex, Pattern: "([", Response: response},
+		{Name: "keyword", MatchType: model.AutoReplyMatchKeyword, Response: response},
+		{Name: "hours", MatchType: model.AutoReplyMatchOutsideHours, Response: response},
+		{Name: "range", MatchType: model.AutoReplyMatchOutsideHours, Response: response, BusinessHours: &model.BusinessHours{
+			Timezone: "UTC",
+			Ranges:   []model.BusinessHoursRange{{Weekdays: []int{1}, Start: "18:00", End: "09:00"}},
+		}},
+		{Name: "media", MatchType: model.AutoReplyMatchFirstMessage, Response: &model.AutoReplyResponse{Type: "image"}},
+	}
+
+	for _, rule := range rules {
+		if _, err := compileRule(rule); !errors.Is(err, ErrInvalidAutoReplyRule) {
+			t.Errorf("compileRule(%s) error = %v, want ErrInvalidAutoReplyRule", rule.Name, err)
+		}
+	}
+}
@@ -0,0 +1,505 @@
+package service
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"go.mau.fi/whatsmeow"
+
+	"zpwoot/internal/model"
+	"zpwoot/internal/repository"
+	"zpwoot/pkg/logger"
+)
+
+// ErrInvalidCampaign indica template, intervalo ou lista de destinatários inválidos
+var ErrInvalidCampaign = errors.New("invalid campaign")
+
+// CampaignConfig define a varredura de campanhas em execução e o lease de cada réplica
+type CampaignConfig struct {
+	PollInterval  time.Duration // Intervalo para assumir campanhas em execução sem dono
+	LeaseDuration time.Duration // Validade do lease (renovado a cada destinatário)
+	SendTimeout   time.Duration
+}
+
+// CampaignService gerencia campanhas de envio em massa. As campanhas em execução são assumidas
+// por uma réplica via lease no Postgres, então sobrevivem a reinícios e não são enviadas em dobro
+// quando há várias réplicas. Cada campanha envia um destinatário por vez com intervalo aleatório.
+type CampaignService struct {
+	campaignRepo   *repository.CampaignRepository
+	sessionManager *SessionManager
+	config         CampaignConfig
+	owner          string // Identifica esta réplica nos leases
+
+	mu      sync.Mutex
+	workers map[string]bool
+	cancel  context.CancelFunc
+	wg      sync.WaitGroup
+}
+
+func NewCampaignService(campaignRepo *repository.CampaignRepository, sessionManager *SessionManager, config CampaignConfig) *CampaignService {
+	if config.PollInterval <= 0 {
+		config.PollInterval = 5 * time.Second
+	}
+	if config.LeaseDuration <= 0 {
+		config.LeaseDuration = 2 * time.Minute
+	}
+	if config.SendTimeout <= 0 {
+		config.SendTimeout = 2 * time.Minute
+	}
+
+	return &CampaignService{
+		campaignRepo:   campaignRepo,
+		sessionManager: sessionManager,
+		config:         config,
+		owner:          uuid.New().String(),
+		workers:        make(map[string]bool),
+	}
+}
+
+// validateTemplate verifica se o template tem o conteúdo exigido pelo tipo
+func validateTemplate(template *model.CampaignTemplate) error {
+	if template == nil {
+		return fmt.Errorf("%w: template is required", ErrInvalidCampaign)
+	}
+
+	switch template.Type {
+	case "text":
+		if template.Text == "" {
+			return fmt.Errorf("%w: text template requires text", ErrInvalidCampaign)
+		}
+	case "image", "video", "audio", "document":
+		if template.MediaURL == "" {
+			return fmt.Errorf("%w: %s template requires mediaUrl", ErrInvalidCampaign, template.Type)
+		}
+	default:
+		return fmt.Errorf("%w: unsupported template type %s", ErrInvalidCampaign, template.Type)
+	}
+
+	return nil
+}
+
+// normalizeRecipients limpa os telefones e garante que todos os destinatários tenham um
+func normalizeRecipients(recipients []*model.CampaignRecipient) error {
+	for i, recipient := range recipients {
+		recipient.Phone = cleanPhone(recipient.Phone)
+		if recipient.Phone == "" {
+			return fmt.Errorf("%w: recipient %d has no valid phone", ErrInvalidCampaign, i+1)
+		}
+	}
+	return nil
+}
+
+// ParseRecipientsCSV lê destinatários de um CSV com cabeçalho. A coluna "phone" (ou a primeira
+// coluna, se não houver) é o telefone; as demais viram variáveis do template pelo nome da coluna.
+func ParseRecipientsCSV(r io.Reader) ([]*model.CampaignRecipient, error) {
+	reader := csv.NewReader(r)
+	reader.TrimLeadingSpace = true
+	reader.FieldsPerRecord = -1
+
+	header, err := reader.Read()
+	if err == io.EOF {
+		return nil, fmt.Errorf("%w: empty CSV", ErrInvalidCampaign)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%w: failed to read CSV header: %v", ErrInvalidCampaign, err)
+	}
+
+	phoneColumn := 0
+	for i, name := range header {
+		header[i] = strings.TrimSpace(strings.TrimPrefix(name, "\ufeff"))
+		if strings.EqualFold(header[i], "phone") {
+			phoneColumn = i
+		}
+	}
+
+	var recipients []*model.CampaignRecipient
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%w: failed to read CSV: %v", ErrInvalidCampaign, err)
+		}
+		if phoneColumn >= len(record) {
+			continue
+		}
+
+		recipient := &model.CampaignRecipient{
+			Phone:     record[phoneColumn],
+			Variables: make(model.RecipientVariables),
+		}
+		for i, value := range record {
+			if i != phoneColumn && i < len(header) && header[i] != "" {
+				recipient.Variables[header[i]] = value
+			}
+		}
+
+		recipients = append(recipients, recipient)
+	}
+
+	return recipients, nil
+}
+
+// Create valida e grava a campanha (status draft) com os destinatários iniciais
+func (s *CampaignService) Create(ctx context.Context, campaign *model.Campaign, recipients []*model.CampaignRecipient) error {
+	if err := validateTemplate(campaign.Template); err != nil {
+		return err
+	}
+	if campaign.MinDelay < 0 || campaign.MaxDelay < campaign.MinDelay {
+		return fmt.Errorf("%w: delays must satisfy 0 <= minDelay <= maxDelay", ErrInvalidCampaign)
+	}
+	if err := normalizeRecipients(recipients); err != nil {
+		return err
+	}
+
+	campaign.ID = uuid.New().String()
+	campaign.Status = model.CampaignStatusDraft
+
+	if err := s.campaignRepo.Create(ctx, campaign); err != nil {
+		return err
+	}
+
+	if _, err := s.campaignRepo.AddRecipients(ctx, campaign.ID, recipients); err != nil {
+		return err
+	}
+
+	logger.Log.Info().
+		Str("session_id", campaign.SessionID).
+		Str("campaign_id", campaign.ID).
+		Int("recipients", len(recipients)).
+		Msg("Campaign created")
+
+	return nil
+}
+
+// AddRecipients adiciona destinatários a uma campanha ainda não finalizada; telefones repetidos são ignorados
+func (s *CampaignService) AddRecipients(ctx context.Context, sessionID, campaignID string, recipients []*model.CampaignRecipient) (int, error) {
+	campaign, err := s.campaignRepo.GetByID(ctx, sessionID, campaignID)
+	if err != nil {
+		return 0, err
+	}
+	if campaign.Status == model.CampaignStatusCompleted || campaign.Status == model.CampaignStatusCanceled {
+		return 0, fmt.Errorf("%w: campaign is %s", ErrInvalidCampaign, campaign.Status)
+	}
+
+	if err := normalizeRecipients(recipients); err != nil {
+		return 0, err
+	}
+
+	return s.campaignRepo.AddRecipients(ctx, campaignID, recipients)
+}
+
+func (s *CampaignService) Get(ctx context.Context, sessionID, campaignID string) (*model.Campaign, error) {
+	return s.campaignRepo.GetByID(ctx, sessionID, campaignID)
+}
+
+func (s *CampaignService) List(ctx context.Context, sessionID string) ([]*model.Campaign, error) {
+	return s.campaignRepo.List(ctx, sessionID)
+}
+
+func (s *CampaignService) Progress(ctx context.Context, campaignID string) (*model.CampaignProgress, error) {
+	return s.campaignRepo.Progress(ctx, campaignID)
+}
+
+func (s *CampaignService) ListRecipients(ctx context.Context, campaignID string, status model.RecipientStatus, limit, offset int) ([]*model.CampaignRecipient, error) {
+	return s.campaignRepo.ListRecipients(ctx, campaignID, status, limit, offset)
+}
+
+// StartCampaign inicia uma campanha em draft; a execução é assumida por uma réplica na próxima varredura
+func (s *CampaignService) StartCampaign(ctx context.Context, sessionID, campaignID string) (*model.Campaign, error) {
+	progress, err := s.campaignRepo.Progress(ctx, campaignID)
+	if err != nil {
+		return nil, err
+	}
+	if progress.Total == 0 {
+		return nil, fmt.Errorf("%w: campaign has no recipients", ErrInvalidCampaign)
+	}
+
+	return s.campaignRepo.UpdateStatus(ctx, sessionID, campaignID, model.CampaignStatusRunning, model.CampaignStatusDraft)
+}
+
+// PauseCampaign pausa a campanha; o envio em andamento é concluído antes de parar
+func (s *CampaignService) PauseCampaign(ctx context.Context, sessionID, campaignID string) (*model.Campaign, error) {
+	return s.campaignRepo.UpdateStatus(ctx, sessionID, campaignID, model.CampaignStatusPaused, model.CampaignStatusRunning)
+}
+
+func (s *CampaignService) ResumeCampaign(ctx context.Context, sessionID, campaignID string) (*model.Campaign, error) {
+	return s.campaignRepo.UpdateStatus(ctx, sessionID, campaignID, model.CampaignStatusRunning, model.CampaignStatusPaused)
+}
+
+// CancelCampaign cancela a campanha; destinatários ainda pendentes não recebem a mensagem
+func (s *CampaignService) CancelCampaign(ctx context.Context, sessionID, campaignID string) (*model.Campaign, error) {
+	return s.campaignRepo.UpdateStatus(ctx, sessionID, campaignID, model.CampaignStatusCanceled,
+		model.CampaignStatusDraft, model.CampaignStatusRunning, model.CampaignStatusPaused)
+}
+
+func (s *CampaignService) Start() {
+	ctx, cancel := context.WithCancel(context.Background())
+	s.cancel = cancel
+
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		s.run(ctx)
+	}()
+
+	logger.Log.Info().
+		Dur("poll_interval", s.config.PollInterval).
+		Msg("✅ Campaign runner started")
+}
+
+// Stop encerra os workers e libera os leases para outra réplica continuar as campanhas
+func (s *CampaignService) Stop() {
+	if s.cancel != nil {
+		s.cancel()
+	}
+	s.wg.Wait()
+}
+
+func (s *CampaignService) run(ctx context.Context) {
+	ticker := time.NewTicker(s.config.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		s.acquire(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// acquire assume as campanhas em execução sem lease válido das sessões hospedadas nesta réplica
+// (as únicas que ela consegue enviar) e inicia um worker para cada
+func (s *CampaignService) acquire(ctx context.Context) {
+	sessionIDs := s.sessionManager.hostedSessionIDs()
+	if len(sessionIDs) == 0 {
+		return
+	}
+
+	campaigns, err := s.campaignRepo.AcquireLeases(ctx, s.owner, s.config.LeaseDuration, sessionIDs)
+	if err != nil {
+		if ctx.Err() == nil {
+			logger.Log.Error().Err(err).Msg("Failed to acquire campaigns")
+		}
+		return
+	}
+
+	for _, campaign := range campaigns {
+		s.mu.Lock()
+		running := s.workers[campaign.ID]
+		if !running {
+			s.workers[campaign.ID] = true
+		}
+		s.mu.Unlock()
+
+		if running {
+			continue
+		}
+
+		s.wg.Add(1)
+		go func(campaign *model.Campaign) {
+			defer s.wg.Done()
+			defer func() {
+				s.mu.Lock()
+				delete(s.workers, campaign.ID)
+				s.mu.Unlock()
+			}()
+
+			s.runCampaign(ctx, campaign)
+		}(campaign)
+	}
+}
+
+// leaseFor garante que o lease cubra o maior intervalo entre envios mais o próprio envio
+func (s *CampaignService) leaseFor(campaign *model.Campaign) time.Duration {
+	return s.config.LeaseDuration + s.config.SendTimeout + time.Duration(campaign.MaxDelay)*time.Second
+}
+
+func (s *CampaignService) runCampaign(ctx context.Context, campaign *model.Campaign) {
+	log := logger.Log.With().
+		Str("session_id", campaign.SessionID).
+		Str("campaign_id", campaign.ID).
+		Logger()
+
+	defer func() {
+		if err := s.campaignRepo.ReleaseLease(context.Background(), campaign.ID, s.owner); err != nil {
+			log.Warn().Err(err).Msg("Failed to release campaign lease")
+		}
+	}()
+
+	if err := s.campaignRepo.FailInterrupted(ctx, campaign.ID); err != nil {
+		log.Warn().Err(err).Msg("Failed to check interrupted recipients")
+	}
+
+	log.Info().Msg("Campaign runner acquired campaign")
+
+	for {
+		status, ok, err := s.campaignRepo.RenewLease(ctx, campaign.ID, s.owner, s.leaseFor(campaign))
+		if err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			log.Warn().Err(err).Msg("Failed to renew campaign lease")
+			if !sleepContext(ctx, s.config.PollInterval) {
+				return
+			}
+			continue
+		}
+		if !ok {
+			log.Warn().Msg("Campaign lease lost")
+			return
+		}
+		if status != model.CampaignStatusRunning {
+			log.Info().Str("status", string(status)).Msg("Campaign runner stopped")
+			return
+		}
+
+		// Sessão saiu desta réplica: libera o lease para a réplica que a hospedar
+		if !s.sessionManager.IsClientActive(campaign.SessionID) {
+			log.Info().Msg("Campaign session not hosted on this replica, releasing lease")
+			return
+		}
+
+		// Sessão desconectada: aguarda reconectar sem consumir destinatários
+		client, err := s.sessionManager.GetClient(campaign.SessionID)
+		if err != nil {
+			if !sleepContext(ctx, s.config.PollInterval) {
+				return
+			}
+			continue
+		}
+
+		recipient, err := s.campaignRepo.NextRecipient(ctx, campaign.ID)
+		if err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			log.Warn().Err(err).Msg("Failed to get next campaign recipient")
+			if !sleepContext(ctx, s.config.PollInterval) {
+				return
+			}
+			continue
+		}
+
+		if recipient == nil {
+			if err := s.campaignRepo.Complete(ctx, campaign.ID); err != nil {
+				log.Error().Err(err).Msg("Failed to complete campaign")
+				return
+			}
+			log.Info().Msg("✅ Campaign completed")
+			return
+		}
+
+		s.sendToRecipient(ctx, client, campaign, recipient)
+
+		if !sleepContext(ctx, randomDelay(campaign.MinDelay, campaign.MaxDelay)) {
+			return
+		}
+	}
+}
+
+// randomDelay sorteia um intervalo entre minDelay e maxDelay segundos
+func randomDelay(minDelay, maxDelay int) time.Duration {
+	if maxDelay <= minDelay {
+		return time.Duration(minDelay) * time.Second
+	}
+	return time.Duration(minDelay)*time.Second + time.Duration(rand.Int63n(int64(maxDelay-minDelay)*int64(time.Second)))
+}
+
+// sleepContext espera d ou o cancelamento do contexto; retorna false se cancelado
+func sleepContext(ctx context.Context, d time.Duration) bool {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return false
+	case <-timer.C:
+		return true
+	}
+}
+
+func (s *CampaignService) sendToRecipient(ctx context.Context, client *whatsmeow.Client, campaign *model.Campaign, recipient *model.CampaignRecipient) {
+	defer func() {
+		if err := s.campaignRepo.UpdateRecipient(context.Background(), recipient); err != nil {
+			logger.Log.Error().Err(err).Int64("recipient_id", recipient.ID).Msg("Failed to save campaign recipient")
+		}
+	}()
+
+	template := campaign.Template
+	var missing []string
+	render := func(text string) string {
+		rendered, names := RenderPlaceholders(text, recipient.Variables)
+		missing = append(missing, names...)
+		return rendered
+	}
+
+	text := render(template.Text)
+	mediaURL := render(template.MediaURL)
+	caption := render(template.Caption)
+	fileName := render(template.FileName)
+
+	if len(missing) > 0 {
+		recipient.Status = model.RecipientStatusFailed
+		recipient.Error = fmt.Sprintf("missing variables: %s", strings.Join(missing, ", "))
+		return
+	}
+
+	sendCtx, cancel := context.WithTimeout(ctx, s.config.SendTimeout)
+	defer cancel()
+
+	// Números sem WhatsApp são marcados sem tentar o envio
+	if resp, err := client.IsOnWhatsApp(sendCtx, []string{"+" + recipient.Phone}); err == nil && len(resp) > 0 && !resp[0].IsIn {
+		recipient.Status = model.RecipientStatusNotOnWhatsApp
+		return
+	} else if err != nil {
+		logger.Log.Warn().Err(err).Str("campaign_id", campaign.ID).Msg("Failed to check recipient on WhatsApp")
+	}
+
+	var messageID string
+	var timestamp time.Time
+	var err error
+
+	switch template.Type {
+	case "text":
+		messageID, timestamp, err = s.sessionManager.SendTextMessage(sendCtx, client, recipient.Phone, text)
+	case "image":
+		messageID, timestamp, err = s.sessionManager.SendImageFromURL(sendCtx, client, recipient.Phone, mediaURL, caption)
+	case "video":
+		messageID, timestamp, err = s.sessionManager.SendVideoFromURL(sendCtx, client, recipient.Phone, mediaURL, caption)
+	case "audio":
+		messageID, timestamp, err = s.sessionManager.SendAudioFromURL(sendCtx, client, recipient.Phone, mediaURL)
+	case "document":
+		messageID, timestamp, err = s.sessionManager.SendDocumentFromURL(sendCtx, client, recipient.Phone, mediaURL, fileName, caption)
+	default:
+		err = fmt.Errorf("unsupported template type: %s", template.Type)
+	}
+
+	if err != nil {
+		recipient.Status = model.RecipientStatusFailed
+		recipient.Error = err.Error()
+
+		logger.Log.Warn().
+			Err(err).
+			Str("campaign_id", campaign.ID).
+			Str("phone", recipient.Phone).
+			Msg("Campaign message failed")
+		return
+	}
+
+	recipient.Status = model.RecipientStatusSent
+	recipient.MessageID = messageID
+	recipient.SentAt = &timestamp
+}
@@ -0,0 +1,57 @@
+package service
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestRenderPlaceholders(t *testing.T) {
+	got, missing := RenderPlaceholders("Olá {{name}}, consulta às {{ time }} com {{doctor}} ({{name}})", map[string]string{
+		"name": "Ana",
+		"time": "14h",
+	})
+
+	if want := "Olá Ana, consulta às 14h com {{doctor}} (Ana)"; got != want {
+		t.Errorf("RenderPlaceholders() = %q, want %q", got, want)
+	}
+	if !reflect.DeepEqual(missing, []string{"doctor"}) {
+		t.Errorf("missing = %v, want [doctor]", missing)
+	}
+}
+
+func TestParseRecipientsCSV(t *testing.T) {
+	csv := "name,Phone,city\nAna,+55 (11) 99999-9999,São Paulo\nBruno,5521988888888,Rio\n"
+
+	recipients, err := ParseRecipientsCSV(strings.NewReader(csv))
+	if err != nil {
+		t.Fatalf("ParseRecipientsCSV() error = %v", err)
+	}
+	if len(recipients) != 2 {
+		t.Fatalf("got %d recipients, want 2", len(recipients))
+	}
+
+	if err := normalizeRecipients(recipients); err != nil {
+		t.Fatalf("normalizeRecipients() error = %v", err)
+	}
+
+	first := recipients[0]
+	if first.Phone != "5511999999999" {
+		t.Errorf("phone = %q, want 5511999999999", first.Phone)
+	}
+	if first.Variables["name"] != "Ana" || first.Variables["city"] != "São Paulo" {
+		t.Errorf("variables = %v", first.Variables)
+	}
+	if _, ok := first.Variables["Phone"]; ok {
+		t.Error("phone column should not be a variable")
+	}
+}
+
+func TestRandomDelay(t *testing.T) {
+	for i := 0; i < 100; i++ {
+		d := randomDelay(2, 5)
+		if d.Seconds() < 2 || d.Seconds() > 5 {
+			t.Fatalf("randomDelay(2, 5) = %v, out of range", d)
+		}
+	}
+}
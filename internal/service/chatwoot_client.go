@@ -0,0 +1,301 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/textproto"
+	"net/url"
+	"strings"
+	"time"
+
+	"zpwoot/internal/model"
+)
+
+// ChatwootAPIError é uma resposta de erro da API do Chatwoot
+type ChatwootAPIError struct {
+	StatusCode int
+	Body       string
+}
+
+func (e *ChatwootAPIError) Error() string {
+	return fmt.Sprintf("chatwoot api returned status %d: %s", e.StatusCode, e.Body)
+}
+
+// isChatwootNotFound identifica recursos removidos no Chatwoot (ex.: conversa apagada por um agente)
+func isChatwootNotFound(err error) bool {
+	var apiErr *ChatwootAPIError
+	return errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusNotFound
+}
+
+type ChatwootContact struct {
+	ID          int64  `json:"id"`
+	Name        string `json:"name"`
+	PhoneNumber string `json:"phone_number"`
+}
+
+// ChatwootAttachment é um arquivo anexado a uma mensagem criada no Chatwoot
+type ChatwootAttachment struct {
+	FileName    string
+	ContentType string
+	Data        []byte
+}
+
+// ChatwootMessageRequest é uma mensagem criada em uma conversa do Chatwoot
+type ChatwootMessageRequest struct {
+	Content     string
+	MessageType string // incoming (contato) ou outgoing (enviada pelo celular)
+	SourceID    string // ID da mensagem no WhatsApp (evita eco no webhook)
+	Attachment  *ChatwootAttachment
+}
+
+// ChatwootClient acessa a Application API do Chatwoot de uma conta
+type ChatwootClient struct {
+	baseURL   string
+	accountID int64
+	token     string
+	client    *http.Client
+}
+
+func NewChatwootClient(config *model.ChatwootConfig, timeout time.Duration) *ChatwootClient {
+	return &ChatwootClient{
+		baseURL:   strings.TrimRight(config.URL, "/"),
+		accountID: config.AccountID,
+		token:     config.Token,
+		client:    &http.Client{Timeout: timeout},
+	}
+}
+
+func (c *ChatwootClient) accountURL(path string) string {
+	return fmt.Sprintf("%s/api/v1/accounts/%d%s", c.baseURL, c.accountID, path)
+}
+
+// do envia a requisição e decodifica a resposta em out (se informado)
+func (c *ChatwootClient) do(ctx context.Context, method, endpoint, contentType string, body io.Reader, out interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, method, endpoint, body)
+	if err != nil {
+		return fmt.Errorf("failed to create chatwoot request: %w", err)
+	}
+
+	req.Header.Set("api_access_token", c.token)
+	req.Header.Set("User-Agent", "zpwoot-chatwoot/1.0")
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send chatwoot request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return fmt.Errorf("failed to read chatwoot response: %w", err)
+	}
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return &ChatwootAPIError{StatusCode: resp.StatusCode, Body: string(data)}
+	}
+
+	if out != nil && len(data) > 0 {
+		if err := json.Unmarshal(data, out); err != nil {
+			return fmt.Errorf("failed to decode chatwoot response: %w", err)
+		}
+	}
+
+	return nil
+}
+
+func (c *ChatwootClient) doJSON(ctx context.Context, method, endpoint string, payload, out interface{}) error {
+	var body io.Reader
+	if payload != nil {
+		data, err := json.Marshal(payload)
+		if err != nil {
+			return fmt.Errorf("failed to encode chatwoot request: %w", err)
+		}
+		body = bytes.NewReader(data)
+	}
+
+	return c.do(ctx, method, endpoint, "application/json", body, out)
+}
+
+// FindContact procura o contato pelo telefone (E.164 sem o +). Retorna nil se não existe.
+func (c *ChatwootClient) FindContact(ctx context.Context, phone string) (*ChatwootContact, error) {
+	var result struct {
+		Payload []ChatwootContact `json:"payload"`
+	}
+
+	endpoint := c.accountURL("/contacts/search?q=" + url.QueryEscape("+"+phone))
+	if err := c.doJSON(ctx, http.MethodGet, endpoint, nil, &result); err != nil {
+		return nil, err
+	}
+
+	for i := range result.Payload {
+		if cleanPhone(result.Payload[i].PhoneNumber) == phone {
+			return &result.Payload[i], nil
+		}
+	}
+
+	return nil, nil
+}
+
+// CreateContact cria o contato já associado à inbox
+func (c *ChatwootClient) CreateContact(ctx context.Context, inboxID int64, phone, name string) (*ChatwootContact, error) {
+	if name == "" {
+		name = phone
+	}
+
+	payload := map[string]interface{}{
+		"inbox_id":     inboxID,
+		"name":         name,
+		"phone_number": "+" + phone,
+	}
+
+	// Versões recentes retornam {payload: {contact: {...}}}; as antigas, {payload: {...}}
+	var result struct {
+		Payload struct {
+			ChatwootContact
+			Contact *ChatwootContact `json:"contact"`
+		} `json:"payload"`
+	}
+	if err := c.doJSON(ctx, http.MethodPost, c.accountURL("/contacts"), payload, &result); err != nil {
+		return nil, err
+	}
+
+	if result.Payload.Contact != nil {
+		return result.Payload.Contact, nil
+	}
+	return &result.Payload.ChatwootContact, nil
+}
+
+// CreateConversation abre uma conversa do contato na inbox e retorna o ID
+func (c *ChatwootClient) CreateConversation(ctx context.Context, inboxID, contactID int64) (int64, error) {
+	payload := map[string]interface{}{
+		"inbox_id":   inboxID,
+		"contact_id": contactID,
+		"status":     "open",
+	}
+
+	var result struct {
+		ID int64 `json:"id"`
+	}
+	if err := c.doJSON(ctx, http.MethodPost, c.accountURL("/conversations"), payload, &result); err != nil {
+		return 0, err
+	}
+
+	return result.ID, nil
+}
+
+// CreateMessage cria a mensagem na conversa (multipart quando há anexo) e retorna o ID
+func (c *ChatwootClient) CreateMessage(ctx context.Context, conversationID int64, message *ChatwootMessageRequest) (int64, error) {
+	endpoint := c.accountURL(fmt.Sprintf("/conversations/%d/messages", conversationID))
+
+	var result struct {
+		ID int64 `json:"id"`
+	}
+
+	if message.Attachment == nil {
+		payload := map[string]interface{}{
+			"content":      message.Content,
+			"message_type": message.MessageType,
+			"private":      false,
+		}
+		if message.SourceID != "" {
+			payload["source_id"] = message.SourceID
+		}
+
+		if err := c.doJSON(ctx, http.MethodPost, endpoint, payload, &result); err != nil {
+			return 0, err
+		}
+		return result.ID, nil
+	}
+
+	var body bytes.Buffer
+	writer := multipart.NewWriter(&body)
+
+	fields := map[string]string{
+		"content":      message.Content,
+		"message_type": message.MessageType,
+		"private":      "false",
+	}
+	if message.SourceID != "" {
+		fields["source_id"] = message.SourceID
+	}
+	for name, value := range fields {
+		if err := writer.WriteField(name, value); err != nil {
+			return 0, fmt.Errorf("failed to write chatwoot field: %w", err)
+		}
+	}
+
+	header := make(textproto.MIMEHeader)
+	header.Set("Content-Disposition", fmt.Sprintf(`form-data; name="attachments[]"; filename="%s"`, strings.ReplaceAll(message.Attachment.FileName, `"`, "")))
+	header.Set("Content-Type", message.Attachment.ContentType)
+	part, err := writer.CreatePart(header)
+	if err != nil {
+		return 0, fmt.Errorf("failed to create chatwoot attachment: %w", err)
+	}
+	if _, err := part.Write(message.Attachment.Data); err != nil {
+		return 0, fmt.Errorf("failed to write chatwoot attachment: %w", err)
+	}
+	if err := writer.Close(); err != nil {
+		return 0, fmt.Errorf("failed to close chatwoot request: %w", err)
+	}
+
+	if err := c.do(ctx, http.MethodPost, endpoint, writer.FormDataContentType(), &body, &result); err != nil {
+		return 0, err
+	}
+
+	return result.ID, nil
+}
+
+// DeleteMessage apaga a mensagem no Chatwoot (exibida como "mensagem apagada")
+func (c *ChatwootClient) DeleteMessage(ctx context.Context, conversationID, messageID int64) error {
+	endpoint := c.accountURL(fmt.Sprintf("/conversations/%d/messages/%d", conversationID, messageID))
+	return c.doJSON(ctx, http.MethodDelete, endpoint, nil, nil)
+}
+
+// UpdateMessageStatus altera o status de entrega de uma mensagem (inboxes do tipo API)
+func (c *ChatwootClient) UpdateMessageStatus(ctx context.Context, conversationID, messageID int64, status string) error {
+	endpoint := c.accountURL(fmt.Sprintf("/conversations/%d/messages/%d", conversationID, messageID))
+	return c.doJSON(ctx, http.MethodPatch, endpoint, map[string]string{"status": status}, nil)
+}
+
+// ChatwootWebhookAttachment é um anexo de mensagem no webhook do Chatwoot
+type ChatwootWebhookAttachment struct {
+	FileType string `json:"file_type"` // image, audio, video, file
+	DataURL  string `json:"data_url"`
+}
+
+// ChatwootWebhookEvent são os campos usados dos eventos message_created e message_updated
+type ChatwootWebhookEvent struct {
+	Event       string                      `json:"event"`
+	ID          int64                       `json:"id"`
+	Content     string                      `json:"content"`
+	MessageType string                      `json:"message_type"`
+	Private     bool                        `json:"private"`
+	SourceID    string                      `json:"source_id"`
+	Attachments []ChatwootWebhookAttachment `json:"attachments"`
+
+	ContentAttributes struct {
+		Deleted bool `json:"deleted"`
+	} `json:"content_attributes"`
+
+	Conversation struct {
+		ID   int64 `json:"id"`
+		Meta struct {
+			Sender struct {
+				PhoneNumber string `json:"phone_number"`
+			} `json:"sender"`
+		} `json:"meta"`
+	} `json:"conversation"`
+
+	Inbox struct {
+		ID int64 `json:"id"`
+	} `json:"inbox"`
+}
@@ -0,0 +1,196 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"zpwoot/internal/model"
+)
+
+// fakeChatwoot simula os endpoints da Application API usados pela integração
+type fakeChatwoot struct {
+	mu       sync.Mutex
+	contacts []ChatwootContact
+	messages map[int64][]map[string]string // conversa -> mensagens (campos do formulário/JSON)
+	deleted  []string
+}
+
+func newFakeChatwoot(t *testing.T) (*fakeChatwoot, *httptest.Server) {
+	fake := &fakeChatwoot{messages: make(map[int64][]map[string]string)}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /api/v1/accounts/7/contacts/search", func(w http.ResponseWriter, r *http.Request) {
+		fake.mu.Lock()
+		defer fake.mu.Unlock()
+
+		var found []ChatwootContact
+		for _, contact := range fake.contacts {
+			if strings.Contains(contact.PhoneNumber, strings.TrimPrefix(r.URL.Query().Get("q"), "+")) {
+				found = append(found, contact)
+			}
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{"payload": found})
+	})
+	mux.HandleFunc("POST /api/v1/accounts/7/contacts", func(w http.ResponseWriter, r *http.Request) {
+		var body struct {
+			InboxID     int64  `json:"inbox_id"`
+			Name        string `json:"name"`
+			PhoneNumber string `json:"phone_number"`
+		}
+		json.NewDecoder(r.Body).Decode(&body)
+		if body.InboxID != 3 {
+			http.Error(w, "inbox not found", http.StatusNotFound)
+			return
+		}
+
+		fake.mu.Lock()
+		contact := ChatwootContact{ID: int64(len(fake.contacts) + 100), Name: body.Name, PhoneNumber: body.PhoneNumber}
+		fake.contacts = append(fake.contacts, contact)
+		fake.mu.Unlock()
+
+		json.NewEncoder(w).Encode(map[string]interface{}{"payload": map[string]interface{}{"contact": contact}})
+	})
+	mux.HandleFunc("POST /api/v1/accounts/7/conversations", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]interface{}{"id": 55})
+	})
+	mux.HandleFunc("POST /api/v1/accounts/7/conversations/{conversation}/messages", func(w http.ResponseWriter, r *http.Request) {
+		if r.PathValue("conversation") != "55" {
+			http.Error(w, "conversation not found", http.StatusNotFound)
+			return
+		}
+
+		fields := make(map[string]string)
+		if strings.HasPrefix(r.Header.Get("Content-Type"), "multipart/") {
+			if err := r.ParseMultipartForm(1 << 20); err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+			for name := range r.MultipartForm.Value {
+				fields[name] = r.FormValue(name)
+			}
+			file, header, err := r.FormFile("attachments[]")
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+			data, _ := io.ReadAll(file)
+			fields["file"] = header.Filename + ":" + string(data)
+		} else {
+			var body map[string]interface{}
+			json.NewDecoder(r.Body).Decode(&body)
+			for name, value := range body {
+				if s, ok := value.(string); ok {
+					fields[name] = s
+				}
+			}
+		}
+
+		fake.mu.Lock()
+		fake.messages[55] = append(fake.messages[55], fields)
+		id := len(fake.messages[55])
+		fake.mu.Unlock()
+
+		json.NewEncoder(w).Encode(map[string]interface{}{"id": id})
+	})
+	mux.HandleFunc("DELETE /api/v1/accounts/7/conversations/{conversation}/messages/{message}", func(w http.ResponseWriter, r *http.Request) {
+		fake.mu.Lock()
+		fake.deleted = append(fake.deleted, r.PathValue("message"))
+		fake.mu.Unlock()
+		w.WriteHeader(http.StatusOK)
+	})
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("api_access_token") != "secret" {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		mux.ServeHTTP(w, r)
+	}))
+	t.Cleanup(server.Close)
+
+	return fake, server
+}
+
+func TestChatwootClientConversationFlow(t *testing.T) {
+	fake, server := newFakeChatwoot(t)
+	client := NewChatwootClient(&model.ChatwootConfig{URL: server.URL + "/", AccountID: 7, Token: "secret"}, 5*time.Second)
+	ctx := context.Background()
+
+	contact, err := client.FindContact(ctx, "5511999999999")
+	if err != nil || contact != nil {
+		t.Fatalf("FindContact() = %v, %v; want nil, nil", contact, err)
+	}
+
+	contact, err = client.CreateContact(ctx, 3, "5511999999999", "Ana")
+	if err != nil {
+		t.Fatalf("CreateContact() error = %v", err)
+	}
+	if contact.ID != 100 || contact.PhoneNumber != "+5511999999999" {
+		t.Errorf("CreateContact() = %+v", contact)
+	}
+
+	found, err := client.FindContact(ctx, "5511999999999")
+	if err != nil || found == nil || found.ID != contact.ID {
+		t.Fatalf("FindContact() = %v, %v; want contact %d", found, err, contact.ID)
+	}
+
+	conversationID, err := client.CreateConversation(ctx, 3, contact.ID)
+	if err != nil || conversationID != 55 {
+		t.Fatalf("CreateConversation() = %d, %v; want 55", conversationID, err)
+	}
+
+	if _, err := client.CreateMessage(ctx, conversationID, &ChatwootMessageRequest{
+		Content:     "Olá",
+		MessageType: "incoming",
+		SourceID:    chatwootSourcePrefix + "ABC",
+	}); err != nil {
+		t.Fatalf("CreateMessage(text) error = %v", err)
+	}
+
+	id, err := client.CreateMessage(ctx, conversationID, &ChatwootMessageRequest{
+		Content:     "foto",
+		MessageType: "incoming",
+		Attachment:  &ChatwootAttachment{FileName: "foto.jpg", ContentType: "image/jpeg", Data: []byte("jpeg")},
+	})
+	if err != nil || id != 2 {
+		t.Fatalf("CreateMessage(attachment) = %d, %v; want 2", id, err)
+	}
+
+	if err := client.DeleteMessage(ctx, conversationID, id); err != nil {
+		t.Fatalf("DeleteMessage() error = %v", err)
+	}
+
+	messages := fake.messages[55]
+	if messages[0]["content"] != "Olá" || messages[0]["message_type"] != "incoming" || messages[0]["source_id"] != "WAID:ABC" {
+		t.Errorf("text message = %v", messages[0])
+	}
+	if messages[1]["content"] != "foto" || messages[1]["file"] != "foto.jpg:jpeg" {
+		t.Errorf("attachment message = %v", messages[1])
+	}
+	if len(fake.deleted) != 1 || fake.deleted[0] != "2" {
+		t.Errorf("deleted = %v, want [2]", fake.deleted)
+	}
+}
+
+func TestChatwootClientErrors(t *testing.T) {
+	_, server := newFakeChatwoot(t)
+	ctx := context.Background()
+
+	client := NewChatwootClient(&model.ChatwootConfig{URL: server.URL, AccountID: 7, Token: "secret"}, 5*time.Second)
+	_, err := client.CreateMessage(ctx, 99, &ChatwootMessageRequest{Content: "oi", MessageType: "incoming"})
+	if !isChatwootNotFound(err) {
+		t.Errorf("CreateMessage(deleted conversation) error = %v, want not found", err)
+	}
+
+	client = NewChatwootClient(&model.ChatwootConfig{URL: server.URL, AccountID: 7, Token: "wrong"}, 5*time.Second)
+	if _, err := client.FindContact(ctx, "5511999999999"); err == nil || isChatwootNotFound(err) {
+		t.Errorf("FindContact(wrong token) error = %v, want unauthorized", err)
+	}
+}
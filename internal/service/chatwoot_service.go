@@ -0,0 +1,517 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"mime"
+	"path"
+	"strings"
+	"time"
+
+	"go.mau.fi/whatsmeow"
+	waProto "go.mau.fi/whatsmeow/binary/proto"
+	"go.mau.fi/whatsmeow/types"
+	"go.mau.fi/whatsmeow/types/events"
+
+	"zpwoot/internal/model"
+	"zpwoot/internal/repository"
+	"zpwoot/pkg/logger"
+)
+
+var (
+	ErrChatwootDisabled     = errors.New("chatwoot integration is not enabled for this session")
+	ErrChatwootUnauthorized = errors.New("invalid chatwoot webhook token")
+)
+
+// chatwootSourcePrefix marca no Chatwoot as mensagens criadas pelo zpwoot, para que o webhook
+// de mensagens outgoing (enviadas pelo celular) não seja reenviado ao WhatsApp
+const chatwootSourcePrefix = "WAID:"
+
+// ChatwootServiceConfig define os limites da integração com o Chatwoot
+type ChatwootServiceConfig struct {
+	Timeout   time.Duration // Timeout de cada chamada à API do Chatwoot
+	QueueSize int           // Eventos pendentes por sessão
+}
+
+// ChatwootService espelha as conversas individuais da sessão em uma inbox do tipo API do Chatwoot:
+// mensagens recebidas (e as enviadas pelo celular) criam contato/conversa/mensagem no Chatwoot,
+// respostas dos agentes chegam pelo webhook do Chatwoot e são enviadas pela sessão. Recibos de
+// leitura e exclusões são espelhados nos dois sentidos. Os eventos de cada sessão são processados
+// em ordem por um worker próprio.
+type ChatwootService struct {
+	sessionRepo    *repository.SessionRepository
+	chatwootRepo   *repository.ChatwootRepository
+	sessionManager *SessionManager
+	config         ChatwootServiceConfig
+	workers        *sessionWorkers
+}
+
+func NewChatwootService(sessionRepo *repository.SessionRepository, chatwootRepo *repository.ChatwootRepository, sessionManager *SessionManager, config ChatwootServiceConfig) *ChatwootService {
+	if config.Timeout <= 0 {
+		config.Timeout = 30 * time.Second
+	}
+	if config.QueueSize <= 0 {
+		config.QueueSize = 1000
+	}
+
+	return &ChatwootService{
+		sessionRepo:    sessionRepo,
+		chatwootRepo:   chatwootRepo,
+		sessionManager: sessionManager,
+		config:         config,
+		workers:        newSessionWorkers(config.QueueSize),
+	}
+}
+
+// newChatwootWebhookToken gera o segredo exigido na URL do webhook do Chatwoot
+func newChatwootWebhookToken() string {
+	buf := make([]byte, 24)
+	if _, err := rand.Read(buf); err != nil {
+		panic(fmt.Sprintf("failed to generate chatwoot webhook token: %v", err))
+	}
+	return hex.EncodeToString(buf)
+}
+
+// SetChatwoot liga a integração com o Chatwoot ao processamento de eventos das sessões
+func (m *SessionManager) SetChatwoot(chatwoot *ChatwootService) {
+	m.eventHandler.chatwoot = chatwoot
+}
+
+// Stop encerra os workers; eventos ainda na fila são descartados
+func (s *ChatwootService) Stop() {
+	s.workers.stop()
+	logger.Log.Info().Msg("Chatwoot integration stopped")
+}
+
+// sessionConfig retorna a configuração do Chatwoot da sessão (nil se desativada)
+func (s *ChatwootService) sessionConfig(ctx context.Context, sessionID string) *model.ChatwootConfig {
+	session, err := s.sessionRepo.GetByID(ctx, sessionID)
+	if err != nil {
+		logger.Log.Warn().Err(err).Str("session_id", sessionID).Msg("Failed to load session for chatwoot")
+		return nil
+	}
+	if !session.ChatwootEnabled() {
+		return nil
+	}
+	return session.ChatwootConfig
+}
+
+// chatPhone retorna o telefone do contato da conversa individual (vazio se desconhecido)
+func chatPhone(info types.MessageInfo) string {
+	if !info.IsFromMe {
+		return contactPhone(info)
+	}
+	if info.Chat.Server == types.DefaultUserServer {
+		return info.Chat.User
+	}
+	if info.RecipientAlt.Server == types.DefaultUserServer {
+		return info.RecipientAlt.User
+	}
+	return ""
+}
+
+// isDirectChat informa se a mensagem é de uma conversa individual (sem grupos, status e canais)
+func isDirectChat(info types.MessageInfo) bool {
+	if info.IsGroup {
+		return false
+	}
+	return info.Chat.Server == types.DefaultUserServer || info.Chat.Server == types.HiddenUserServer
+}
+
+// HandleMessage agenda o espelhamento da mensagem no Chatwoot
+func (s *ChatwootService) HandleMessage(sessionID string, evt *events.Message) {
+	if !isDirectChat(evt.Info) {
+		return
+	}
+
+	s.workers.enqueue(sessionID, func(ctx context.Context) {
+		s.syncMessage(ctx, sessionID, evt)
+	})
+}
+
+// HandleReceipt agenda o espelhamento de recibos de leitura no Chatwoot
+func (s *ChatwootService) HandleReceipt(sessionID string, evt *events.Receipt) {
+	if evt.Type != types.ReceiptTypeRead || evt.IsGroup {
+		return
+	}
+
+	s.workers.enqueue(sessionID, func(ctx context.Context) {
+		s.syncRead(ctx, sessionID, evt.MessageIDs)
+	})
+}
+
+func (s *ChatwootService) syncMessage(ctx context.Context, sessionID string, evt *events.Message) {
+	config := s.sessionConfig(ctx, sessionID)
+	if config == nil {
+		return
+	}
+
+	log := logger.Log.With().Str("session_id", sessionID).Str("message_id", evt.Info.ID).Logger()
+
+	if protocol := evt.Message.GetProtocolMessage(); protocol != nil {
+		if protocol.GetType() == waProto.ProtocolMessage_REVOKE {
+			s.syncRevoke(ctx, config, sessionID, protocol.GetKey().GetID())
+		}
+		return
+	}
+
+	phone := chatPhone(evt.Info)
+	if phone == "" {
+		log.Debug().Str("chat", evt.Info.Chat.String()).Msg("Chatwoot sync skipped: contact phone unknown")
+		return
+	}
+
+	// Mensagens já espelhadas (ex.: respostas enviadas pelos agentes) não são duplicadas
+	if existing, err := s.chatwootRepo.GetMessage(ctx, sessionID, evt.Info.ID); err != nil || existing != nil {
+		return
+	}
+
+	request, err := s.buildMessageRequest(ctx, sessionID, evt)
+	if err != nil {
+		log.Warn().Err(err).Msg("Failed to prepare chatwoot message")
+		return
+	}
+	if request == nil {
+		return
+	}
+
+	client := NewChatwootClient(config, s.config.Timeout)
+
+	name := evt.Info.PushName
+	if evt.Info.IsFromMe {
+		name = ""
+	}
+
+	conversation, chatwootID, err := s.createMessage(ctx, client, config, sessionID, phone, name, request)
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to create chatwoot message")
+		return
+	}
+
+	if err := s.chatwootRepo.SaveMessage(ctx, &model.ChatwootMessage{
+		SessionID:         sessionID,
+		MessageID:         evt.Info.ID,
+		ChatwootMessageID: chatwootID,
+		ConversationID:    conversation.ConversationID,
+		Phone:             phone,
+		FromMe:            evt.Info.IsFromMe,
+	}); err != nil {
+		log.Error().Err(err).Msg("Failed to save chatwoot message mapping")
+	}
+}
+
+// buildMessageRequest converte a mensagem do WhatsApp (texto, mídia, localização ou contato).
+// Retorna nil para mensagens sem conteúdo a espelhar (reações, enquetes, etc.).
+func (s *ChatwootService) buildMessageRequest(ctx context.Context, sessionID string, evt *events.Message) (*ChatwootMessageRequest, error) {
+	msg := evt.Message
+	request := &ChatwootMessageRequest{
+		Content:     ExtractMessageText(msg),
+		MessageType: "incoming",
+		SourceID:    chatwootSourcePrefix + evt.Info.ID,
+	}
+	if evt.Info.IsFromMe {
+		request.MessageType = "outgoing"
+	}
+
+	var media whatsmeow.DownloadableMessage
+	var mimeType, fileName string
+
+	switch {
+	case msg.GetImageMessage() != nil:
+		media, mimeType = msg.GetImageMessage(), msg.GetImageMessage().GetMimetype()
+	case msg.GetVideoMessage() != nil:
+		media, mimeType = msg.GetVideoMessage(), msg.GetVideoMessage().GetMimetype()
+	case msg.GetAudioMessage() != nil:
+		media, mimeType = msg.GetAudioMessage(), msg.GetAudioMessage().GetMimetype()
+	case msg.GetDocumentMessage() != nil:
+		media, mimeType = msg.GetDocumentMessage(), msg.GetDocumentMessage().GetMimetype()
+		fileName = msg.GetDocumentMessage().GetFileName()
+	case msg.GetStickerMessage() != nil:
+		media, mimeType = msg.GetStickerMessage(), msg.GetStickerMessage().GetMimetype()
+	case msg.GetLocationMessage() != nil:
+		location := msg.GetLocationMessage()
+		request.Content = fmt.Sprintf("📍 %s https://maps.google.com/?q=%f,%f",
+			location.GetName(), location.GetDegreesLatitude(), location.GetDegreesLongitude())
+	case msg.GetContactMessage() != nil:
+		request.Content = "👤 " + msg.GetContactMessage().GetDisplayName() + "\n" + msg.GetContactMessage().GetVcard()
+	}
+
+	if media != nil {
+		client, err := s.sessionManager.GetClient(sessionID)
+		if err != nil {
+			return nil, fmt.Errorf("client not found: %w", err)
+		}
+
+		data, err := client.Download(ctx, media)
+		if err != nil {
+			return nil, fmt.Errorf("failed to download media: %w", err)
+		}
+
+		if fileName == "" {
+			fileName = evt.Info.ID
+			if exts, _ := mime.ExtensionsByType(strings.Split(mimeType, ";")[0]); len(exts) > 0 {
+				fileName += exts[0]
+			}
+		}
+
+		request.Attachment = &ChatwootAttachment{
+			FileName:    fileName,
+			ContentType: mimeType,
+			Data:        data,
+		}
+	}
+
+	if request.Content == "" && request.Attachment == nil {
+		return nil, nil
+	}
+
+	return request, nil
+}
+
+// conversation retorna a conversa do contato, criando contato e conversa no Chatwoot se necessário
+func (s *ChatwootService) conversation(ctx context.Context, client *ChatwootClient, config *model.ChatwootConfig, sessionID, phone, name string) (*model.ChatwootConversation, error) {
+	conversation, err := s.chatwootRepo.GetConversation(ctx, sessionID, phone)
+	if err != nil || conversation != nil {
+		return conversation, err
+	}
+
+	contact, err := client.FindContact(ctx, phone)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find chatwoot contact: %w", err)
+	}
+	if contact == nil {
+		if contact, err = client.CreateContact(ctx, config.InboxID, phone, name); err != nil {
+			return nil, fmt.Errorf("failed to create chatwoot contact: %w", err)
+		}
+	}
+
+	conversationID, err := client.CreateConversation(ctx, config.InboxID, contact.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create chatwoot conversation: %w", err)
+	}
+
+	conversation = &model.ChatwootConversation{
+		SessionID:      sessionID,
+		Phone:          phone,
+		ContactID:      contact.ID,
+		ConversationID: conversationID,
+	}
+	if err := s.chatwootRepo.SaveConversation(ctx, conversation); err != nil {
+		return nil, err
+	}
+
+	logger.Log.Info().
+		Str("session_id", sessionID).
+		Str("phone", phone).
+		Int64("conversation_id", conversationID).
+		Msg("Chatwoot conversation created")
+
+	return conversation, nil
+}
+
+// createMessage cria a mensagem na conversa do contato. Se a conversa foi apagada no Chatwoot,
+// uma nova é aberta e o envio é repetido.
+func (s *ChatwootService) createMessage(ctx context.Context, client *ChatwootClient, config *model.ChatwootConfig, sessionID, phone, name string, request *ChatwootMessageRequest) (*model.ChatwootConversation, int64, error) {
+	for attempt := 0; ; attempt++ {
+		conversation, err := s.conversation(ctx, client, config, sessionID, phone, name)
+		if err != nil {
+			return nil, 0, err
+		}
+
+		id, err := client.CreateMessage(ctx, conversation.ConversationID, request)
+		if err == nil {
+			return conversation, id, nil
+		}
+		if !isChatwootNotFound(err) || attempt > 0 {
+			return nil, 0, err
+		}
+
+		if err := s.chatwootRepo.DeleteConversation(ctx, sessionID, phone); err != nil {
+			return nil, 0, err
+		}
+	}
+}
+
+// syncRevoke apaga no Chatwoot a mensagem apagada no WhatsApp
+func (s *ChatwootService) syncRevoke(ctx context.Context, config *model.ChatwootConfig, sessionID, messageID string) {
+	mapping, err := s.chatwootRepo.GetMessage(ctx, sessionID, messageID)
+	if err != nil || mapping == nil {
+		return
+	}
+
+	client := NewChatwootClient(config, s.config.Timeout)
+	if err := client.DeleteMessage(ctx, mapping.ConversationID, mapping.ChatwootMessageID); err != nil && !isChatwootNotFound(err) {
+		logger.Log.Error().Err(err).Str("session_id", sessionID).Str("message_id", messageID).Msg("Failed to delete chatwoot message")
+	}
+}
+
+// syncRead marca como lidas no Chatwoot as mensagens da sessão lidas pelo contato
+func (s *ChatwootService) syncRead(ctx context.Context, sessionID string, messageIDs []types.MessageID) {
+	config := s.sessionConfig(ctx, sessionID)
+	if config == nil {
+		return
+	}
+
+	client := NewChatwootClient(config, s.config.Timeout)
+
+	for _, messageID := range messageIDs {
+		mapping, err := s.chatwootRepo.GetMessage(ctx, sessionID, messageID)
+		if err != nil || mapping == nil || !mapping.FromMe {
+			continue
+		}
+
+		if err := client.UpdateMessageStatus(ctx, mapping.ConversationID, mapping.ChatwootMessageID, "read"); err != nil {
+			logger.Log.Debug().Err(err).Str("session_id", sessionID).Str("message_id", messageID).Msg("Failed to update chatwoot message status")
+		}
+	}
+}
+
+// HandleWebhook valida o webhook recebido do Chatwoot e agenda o processamento do evento
+func (s *ChatwootService) HandleWebhook(ctx context.Context, sessionID, token string, evt *ChatwootWebhookEvent) error {
+	config := s.sessionConfig(ctx, sessionID)
+	if config == nil {
+		return ErrChatwootDisabled
+	}
+	if config.WebhookToken == "" || subtle.ConstantTimeCompare([]byte(config.WebhookToken), []byte(token)) != 1 {
+		return ErrChatwootUnauthorized
+	}
+	if evt.Inbox.ID != 0 && evt.Inbox.ID != config.InboxID {
+		return nil
+	}
+
+	switch {
+	case evt.Event == "message_created" && evt.MessageType == "outgoing" && !evt.Private &&
+		!strings.HasPrefix(evt.SourceID, chatwootSourcePrefix):
+		s.workers.enqueue(sessionID, func(ctx context.Context) {
+			s.sendAgentReply(ctx, sessionID, evt)
+		})
+	case evt.Event == "message_updated" && evt.ContentAttributes.Deleted:
+		s.workers.enqueue(sessionID, func(ctx context.Context) {
+			s.revokeAgentMessage(ctx, sessionID, evt)
+		})
+	}
+
+	return nil
+}
+
+// sendAgentReply envia pela sessão a resposta de um agente e marca como lidas as mensagens do contato
+func (s *ChatwootService) sendAgentReply(ctx context.Context, sessionID string, evt *ChatwootWebhookEvent) {
+	log := logger.Log.With().
+		Str("session_id", sessionID).
+		Int64("conversation_id", evt.Conversation.ID).
+		Int64("chatwoot_message_id", evt.ID).
+		Logger()
+
+	if existing, err := s.chatwootRepo.GetMessageByChatwootID(ctx, sessionID, evt.ID); err != nil || existing != nil {
+		return
+	}
+
+	phone, err := s.chatwootRepo.GetPhoneByConversation(ctx, sessionID, evt.Conversation.ID)
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to resolve chatwoot conversation")
+		return
+	}
+	if phone == "" {
+		phone = cleanPhone(evt.Conversation.Meta.Sender.PhoneNumber)
+	}
+	if phone == "" {
+		log.Warn().Msg("Chatwoot reply skipped: conversation has no phone number")
+		return
+	}
+
+	client, err := s.sessionManager.GetClient(sessionID)
+	if err != nil {
+		log.Warn().Err(err).Msg("Client not found for chatwoot reply")
+		return
+	}
+
+	sendCtx, cancel := context.WithTimeout(ctx, s.config.Timeout)
+	defer cancel()
+
+	// A resposta do agente indica que a conversa foi lida
+	if unread, err := s.chatwootRepo.ListUnreadIncoming(ctx, sessionID, evt.Conversation.ID, 100); err == nil && len(unread) > 0 {
+		if err := s.sessionManager.MarkAsRead(sendCtx, client, phone, unread); err != nil {
+			log.Warn().Err(err).Msg("Failed to mark chatwoot conversation as read")
+		}
+	}
+
+	var messageIDs []string
+	caption := evt.Content
+
+	for _, attachment := range evt.Attachments {
+		var messageID string
+		var err error
+
+		switch attachment.FileType {
+		case "image":
+			messageID, _, err = s.sessionManager.SendImageFromURL(sendCtx, client, phone, attachment.DataURL, caption)
+		case "video":
+			messageID, _, err = s.sessionManager.SendVideoFromURL(sendCtx, client, phone, attachment.DataURL, caption)
+		case "audio":
+			messageID, _, err = s.sessionManager.SendAudioFromURL(sendCtx, client, phone, attachment.DataURL)
+		default:
+			fileName := path.Base(strings.SplitN(attachment.DataURL, "?", 2)[0])
+			messageID, _, err = s.sessionManager.SendDocumentFromURL(sendCtx, client, phone, attachment.DataURL, fileName, caption)
+		}
+		if err != nil {
+			log.Error().Err(err).Str("file_type", attachment.FileType).Msg("Failed to send chatwoot attachment")
+			continue
+		}
+
+		messageIDs = append(messageIDs, messageID)
+		if attachment.FileType != "audio" {
+			caption = ""
+		}
+	}
+
+	if caption != "" {
+		messageID, _, err := s.sessionManager.SendTextMessage(sendCtx, client, phone, caption)
+		if err != nil {
+			log.Error().Err(err).Msg("Failed to send chatwoot reply")
+		} else {
+			messageIDs = append(messageIDs, messageID)
+		}
+	}
+
+	for _, messageID := range messageIDs {
+		if err := s.chatwootRepo.SaveMessage(ctx, &model.ChatwootMessage{
+			SessionID:         sessionID,
+			MessageID:         messageID,
+			ChatwootMessageID: evt.ID,
+			ConversationID:    evt.Conversation.ID,
+			Phone:             phone,
+			FromMe:            true,
+		}); err != nil {
+			log.Error().Err(err).Msg("Failed to save chatwoot message mapping")
+		}
+	}
+
+	if len(messageIDs) > 0 {
+		log.Info().Str("phone", phone).Int("messages", len(messageIDs)).Msg("Chatwoot reply sent")
+	}
+}
+
+// revokeAgentMessage apaga no WhatsApp a mensagem apagada por um agente no Chatwoot
+func (s *ChatwootService) revokeAgentMessage(ctx context.Context, sessionID string, evt *ChatwootWebhookEvent) {
+	mapping, err := s.chatwootRepo.GetMessageByChatwootID(ctx, sessionID, evt.ID)
+	if err != nil || mapping == nil || !mapping.FromMe {
+		return
+	}
+
+	client, err := s.sessionManager.GetClient(sessionID)
+	if err != nil {
+		logger.Log.Warn().Err(err).Str("session_id", sessionID).Msg("Client not found for chatwoot deletion")
+		return
+	}
+
+	sendCtx, cancel := context.WithTimeout(ctx, s.config.Timeout)
+	defer cancel()
+
+	if _, _, err := s.sessionManager.RevokeMessage(sendCtx, client, mapping.Phone, mapping.MessageID); err != nil {
+		logger.Log.Error().Err(err).Str("session_id", sessionID).Str("message_id", mapping.MessageID).Msg("Failed to revoke message deleted in chatwoot")
+	}
+}
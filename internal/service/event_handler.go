@@ -6,10 +6,13 @@ import (
 
 	"go.mau.fi/whatsmeow"
 	"go.mau.fi/whatsmeow/appstate"
+	waProto "go.mau.fi/whatsmeow/binary/proto"
 	"go.mau.fi/whatsmeow/types"
 	"go.mau.fi/whatsmeow/types/events"
+	"google.golang.org/protobuf/proto"
 
 	"zpwoot/internal/constants"
+	"zpwoot/internal/model"
 	"zpwoot/internal/repository"
 	"zpwoot/pkg/logger"
 )
@@ -19,6 +22,12 @@ type EventHandler struct {
 	sessionRepo      *repository.SessionRepository
 	webhookProcessor *WebhookProcessor
 	webhookFormatter *WebhookFormatter
+	historySync      *HistorySyncService
+	autoResponder    *AutoResponder        // Opcional: regras de resposta automática
+	chatwoot         *ChatwootService      // Opcional: inbox do Chatwoot
+	flowBot          *FlowBotService       // Opcional: fluxo (Typebot) por sessão
+	assistant        *AssistantService     // Opcional: assistente (LLM) por sessão
+	transcription    *TranscriptionService // Opcional: transcrição de mensagens de voz
 }
 
 func NewEventHandler(
@@ -26,12 +35,14 @@ func NewEventHandler(
 	sessionRepo *repository.SessionRepository,
 	webhookProcessor *WebhookProcessor,
 	webhookFormatter *WebhookFormatter,
+	historySync *HistorySyncService,
 ) *EventHandler {
 	return &EventHandler{
 		manager:          manager,
 		sessionRepo:      sessionRepo,
 		webhookProcessor: webhookProcessor,
 		webhookFormatter: webhookFormatter,
+		historySync:      historySync,
 	}
 }
 
@@ -47,6 +58,8 @@ func (h *EventHandler) handleEvent(sessionID string, evt interface{}) {
 		h.handleAppStateSyncComplete(sessionID, v)
 	case *events.PairSuccess:
 		h.handlePairSuccess(sessionID, v)
+	case *events.PairError:
+		h.handlePairError(sessionID, v)
 	case *events.Connected:
 		h.handleConnected(sessionID, v)
 	case *events.PushNameSetting:
@@ -55,6 +68,12 @@ func (h *EventHandler) handleEvent(sessionID string, evt interface{}) {
 		h.handleDisconnected(sessionID, v)
 	case *events.LoggedOut:
 		h.handleLoggedOut(sessionID, v)
+	case *events.TemporaryBan:
+		h.handleTemporaryBan(sessionID, v)
+	case *events.KeepAliveTimeout:
+		h.handleKeepAliveTimeout(sessionID, v)
+	case *events.KeepAliveRestored:
+		h.handleKeepAliveRestored(sessionID, v)
 	case *events.Message:
 		h.handleMessage(sessionID, v)
 	case *events.Receipt:
@@ -82,6 +101,14 @@ func (h *EventHandler) handleEvent(sessionID string, evt interface{}) {
 		h.handleCallAccept(sessionID, v)
 	case *events.CallTerminate:
 		h.handleCallTerminate(sessionID, v)
+	case *events.NewsletterJoin:
+		h.handleNewsletterJoin(sessionID, v)
+	case *events.NewsletterLeave:
+		h.handleNewsletterLeave(sessionID, v)
+	case *events.NewsletterMuteChange:
+		h.handleNewsletterMuteChange(sessionID, v)
+	case *events.NewsletterLiveUpdate:
+		h.handleNewsletterLiveUpdate(sessionID, v)
 	default:
 		logger.Log.Debug().
 			Str("session_id", sessionID).
@@ -247,6 +274,9 @@ func (h *EventHandler) handleDisconnected(sessionID string, evt *events.Disconne
 	// Atualizar status no banco
 	h.updateSessionStatus(ctx, sessionID, "disconnected", false)
 
+	// Avisar o supervisor para reconectar
+	h.manager.notifyDisconnected(sessionID)
+
 	// Enviar webhook de desconexão
 	payload := h.webhookFormatter.FormatDisconnected(sessionID, evt)
 	if err := h.webhookProcessor.ProcessEvent(sessionID, constants.EventDisconnected, payload); err != nil {
@@ -273,6 +303,9 @@ func (h *EventHandler) handleLoggedOut(sessionID string, evt *events.LoggedOut)
 		logger.Log.Warn().Err(err).Msg("Failed to clear QR code after logout")
 	}
 
+	// Sessão deslogada não deve ser reconectada
+	h.manager.stopSupervisor(sessionID, model.SessionStatusLoggedOut)
+
 	// Enviar webhook de logout
 	payload := h.webhookFormatter.FormatDisconnected(sessionID, &events.Disconnected{})
 	if err := h.webhookProcessor.ProcessEvent(sessionID, constants.EventDisconnected, payload); err != nil {
@@ -283,6 +316,83 @@ func (h *EventHandler) handleLoggedOut(sessionID string, evt *events.LoggedOut)
 	}
 }
 
+func (h *EventHandler) handlePairError(sessionID string, evt *events.PairError) {
+	logger.Log.Error().
+		Err(evt.Error).
+		Str("session_id", sessionID).
+		Str("jid", evt.ID.String()).
+		Msg("Pairing failed")
+
+	h.notifyPairError(sessionID, evt)
+}
+
+// notifyPairError envia o webhook pair_error (falha local ou timeout de pareamento)
+func (h *EventHandler) notifyPairError(sessionID string, evt *events.PairError) {
+	payload := h.webhookFormatter.FormatPairError(sessionID, evt)
+	if err := h.webhookProcessor.ProcessEvent(sessionID, constants.EventPairError, payload); err != nil {
+		logger.Log.Error().
+			Err(err).
+			Str("session_id", sessionID).
+			Msg("Failed to process pair error webhook")
+	}
+}
+
+func (h *EventHandler) handleTemporaryBan(sessionID string, evt *events.TemporaryBan) {
+	logger.Log.Error().
+		Str("session_id", sessionID).
+		Str("reason", evt.String()).
+		Msg("WhatsApp account temporarily banned")
+
+	// Não reconectar durante o banimento
+	h.manager.stopSupervisor(sessionID, model.SessionStatusFailed)
+
+	// Enviar webhook
+	payload := h.webhookFormatter.FormatTemporaryBan(sessionID, evt)
+	if err := h.webhookProcessor.ProcessEvent(sessionID, constants.EventTemporaryBan, payload); err != nil {
+		logger.Log.Error().
+			Err(err).
+			Str("session_id", sessionID).
+			Msg("Failed to process temporary ban webhook")
+	}
+}
+
+func (h *EventHandler) handleKeepAliveTimeout(sessionID string, evt *events.KeepAliveTimeout) {
+	logger.Log.Warn().
+		Str("session_id", sessionID).
+		Int("error_count", evt.ErrorCount).
+		Time("last_success", evt.LastSuccess).
+		Msg("Keepalive timeout")
+
+	// Socket sem resposta não gera Disconnected: reconectar pelo supervisor após falhas seguidas
+	if h.manager.handleKeepAliveFailure(sessionID, evt.ErrorCount) {
+		h.updateSessionStatus(context.Background(), sessionID, "disconnected", false)
+	}
+
+	// Enviar webhook
+	payload := h.webhookFormatter.FormatKeepAliveTimeout(sessionID, evt)
+	if err := h.webhookProcessor.ProcessEvent(sessionID, constants.EventKeepAliveTimeout, payload); err != nil {
+		logger.Log.Error().
+			Err(err).
+			Str("session_id", sessionID).
+			Msg("Failed to process keepalive timeout webhook")
+	}
+}
+
+func (h *EventHandler) handleKeepAliveRestored(sessionID string, evt *events.KeepAliveRestored) {
+	logger.Log.Info().
+		Str("session_id", sessionID).
+		Msg("Keepalive restored")
+
+	// Enviar webhook
+	payload := h.webhookFormatter.FormatKeepAliveRestored(sessionID, evt)
+	if err := h.webhookProcessor.ProcessEvent(sessionID, constants.EventKeepAliveRestored, payload); err != nil {
+		logger.Log.Error().
+			Err(err).
+			Str("session_id", sessionID).
+			Msg("Failed to process keepalive restored webhook")
+	}
+}
+
 func (h *EventHandler) handleMessage(sessionID string, evt *events.Message) {
 	logger.Log.Info().
 		Str("session_id", sessionID).
@@ -290,8 +400,40 @@ func (h *EventHandler) handleMessage(sessionID string, evt *events.Message) {
 		Str("message_id", evt.Info.ID).
 		Msg("💬 Message received")
 
-	// Enviar webhook de mensagem
-	payload := h.webhookFormatter.FormatMessage(sessionID, evt)
+	// Mensagens de voz a transcrever: o webhook é enviado quando a transcrição termina,
+	// sem bloquear os demais eventos da sessão
+	var transcriptionConfig *model.TranscriptionConfig
+	if h.transcription != nil {
+		transcriptionConfig = h.transcription.sessionConfig(sessionID, evt)
+	}
+	if transcriptionConfig != nil {
+		go func() {
+			h.sendMessageWebhook(sessionID, evt, h.transcription.Transcribe(sessionID, evt, transcriptionConfig))
+		}()
+	} else {
+		h.sendMessageWebhook(sessionID, evt, "")
+	}
+
+	if h.autoResponder != nil {
+		go h.autoResponder.HandleMessage(sessionID, evt)
+	}
+
+	if h.chatwoot != nil {
+		h.chatwoot.HandleMessage(sessionID, evt)
+	}
+
+	if h.flowBot != nil {
+		h.flowBot.HandleMessage(sessionID, evt)
+	}
+
+	if h.assistant != nil {
+		h.assistant.HandleMessage(sessionID, evt)
+	}
+}
+
+// sendMessageWebhook envia o webhook de mensagem (com a transcrição, se houver)
+func (h *EventHandler) sendMessageWebhook(sessionID string, evt *events.Message, transcription string) {
+	payload := h.webhookFormatter.FormatMessage(sessionID, evt, transcription)
 	if err := h.webhookProcessor.ProcessEvent(sessionID, constants.EventMessage, payload); err != nil {
 		logger.Log.Error().
 			Err(err).
@@ -314,6 +456,10 @@ func (h *EventHandler) handleReceipt(sessionID string, evt *events.Receipt) {
 			Str("session_id", sessionID).
 			Msg("Failed to process receipt webhook")
 	}
+
+	if h.chatwoot != nil {
+		h.chatwoot.HandleReceipt(sessionID, evt)
+	}
 }
 
 func (h *EventHandler) handlePresence(sessionID string, evt *events.Presence) {
@@ -331,7 +477,58 @@ func (h *EventHandler) handleHistorySync(sessionID string, evt *events.HistorySy
 		Str("type", evt.Data.SyncType.String()).
 		Msg("History sync")
 
-	// TODO: Processar histórico
+	ctx := context.Background()
+
+	session, err := h.sessionRepo.GetByID(ctx, sessionID)
+	if err != nil {
+		logger.Log.Error().
+			Err(err).
+			Str("session_id", sessionID).
+			Msg("Failed to load session for history sync")
+		return
+	}
+
+	if session.HistoryMode() == model.HistorySyncModeNone {
+		logger.Log.Debug().
+			Str("session_id", sessionID).
+			Msg("History sync disabled for session, skipping")
+		return
+	}
+
+	client, err := h.manager.GetClient(sessionID)
+	if err != nil {
+		logger.Log.Warn().
+			Err(err).
+			Str("session_id", sessionID).
+			Msg("Client not found for history sync")
+		return
+	}
+
+	summary, err := h.historySync.Process(ctx, sessionID, client, evt)
+	if err != nil {
+		logger.Log.Error().
+			Err(err).
+			Str("session_id", sessionID).
+			Msg("Failed to store history sync")
+		return
+	}
+
+	logger.Log.Info().
+		Str("session_id", sessionID).
+		Int("chunk_order", summary.ChunkOrder).
+		Int("progress", summary.Progress).
+		Int("conversations", summary.Conversations).
+		Int("messages", summary.Messages).
+		Msg("History sync chunk stored")
+
+	// Enviar webhook
+	payload := h.webhookFormatter.FormatHistorySync(sessionID, summary)
+	if err := h.webhookProcessor.ProcessEvent(sessionID, constants.EventHistorySync, payload); err != nil {
+		logger.Log.Error().
+			Err(err).
+			Str("session_id", sessionID).
+			Msg("Failed to process history sync webhook")
+	}
 }
 
 func (h *EventHandler) handlePushName(sessionID string, evt *events.PushName) {
@@ -395,26 +592,174 @@ func (h *EventHandler) handleMediaRetry(sessionID string, evt *events.MediaRetry
 func (h *EventHandler) handleCallOffer(sessionID string, evt *events.CallOffer) {
 	logger.Log.Info().
 		Str("session_id", sessionID).
-		Str("event", fmt.Sprintf("%+v", evt)).
+		Str("from", evt.From.String()).
+		Str("call_id", evt.CallID).
 		Msg("Got call offer")
 
-	// TODO: Processar oferta de chamada e enviar webhook
+	action := h.applyCallPolicy(sessionID, evt)
+
+	// Enviar webhook
+	payload := h.webhookFormatter.FormatCallOffer(sessionID, evt, string(action))
+	if err := h.webhookProcessor.ProcessEvent(sessionID, constants.EventCallOffer, payload); err != nil {
+		logger.Log.Error().
+			Err(err).
+			Str("session_id", sessionID).
+			Msg("Failed to process call offer webhook")
+	}
+}
+
+// applyCallPolicy aplica a política de chamadas da sessão e retorna a ação executada
+func (h *EventHandler) applyCallPolicy(sessionID string, evt *events.CallOffer) model.CallPolicy {
+	ctx := context.Background()
+
+	session, err := h.sessionRepo.GetByID(ctx, sessionID)
+	if err != nil {
+		logger.Log.Warn().
+			Err(err).
+			Str("session_id", sessionID).
+			Msg("Failed to load session for call policy")
+		return model.CallPolicyAccept
+	}
+
+	policy := session.CallPolicy()
+	if policy == model.CallPolicyAccept {
+		return policy
+	}
+
+	client, err := h.manager.GetClient(sessionID)
+	if err != nil {
+		logger.Log.Warn().
+			Err(err).
+			Str("session_id", sessionID).
+			Msg("Client not found for call policy")
+		return model.CallPolicyAccept
+	}
+
+	if err := client.RejectCall(ctx, evt.From, evt.CallID); err != nil {
+		logger.Log.Error().
+			Err(err).
+			Str("session_id", sessionID).
+			Str("call_id", evt.CallID).
+			Msg("Failed to reject call")
+		return model.CallPolicyAccept
+	}
+
+	logger.Log.Info().
+		Str("session_id", sessionID).
+		Str("from", evt.From.String()).
+		Str("call_id", evt.CallID).
+		Msg("Call rejected")
+
+	if policy == model.CallPolicyRejectMessage && session.CallConfig.Message != "" {
+		msg := &waProto.Message{
+			Conversation: proto.String(session.CallConfig.Message),
+		}
+		if _, err := client.SendMessage(ctx, evt.From.ToNonAD(), msg); err != nil {
+			logger.Log.Error().
+				Err(err).
+				Str("session_id", sessionID).
+				Str("to", evt.From.String()).
+				Msg("Failed to send call reject message")
+		}
+	}
+
+	return policy
 }
 
 func (h *EventHandler) handleCallAccept(sessionID string, evt *events.CallAccept) {
 	logger.Log.Info().
 		Str("session_id", sessionID).
-		Str("event", fmt.Sprintf("%+v", evt)).
+		Str("from", evt.From.String()).
+		Str("call_id", evt.CallID).
 		Msg("Got call accept")
 
-	// TODO: Processar aceitação de chamada e enviar webhook
+	// Enviar webhook
+	payload := h.webhookFormatter.FormatCallAccept(sessionID, evt)
+	if err := h.webhookProcessor.ProcessEvent(sessionID, constants.EventCallAccept, payload); err != nil {
+		logger.Log.Error().
+			Err(err).
+			Str("session_id", sessionID).
+			Msg("Failed to process call accept webhook")
+	}
 }
 
 func (h *EventHandler) handleCallTerminate(sessionID string, evt *events.CallTerminate) {
 	logger.Log.Info().
 		Str("session_id", sessionID).
-		Str("event", fmt.Sprintf("%+v", evt)).
+		Str("from", evt.From.String()).
+		Str("call_id", evt.CallID).
+		Str("reason", evt.Reason).
 		Msg("Got call terminate")
 
-	// TODO: Processar término de chamada e enviar webhook
+	// Enviar webhook
+	payload := h.webhookFormatter.FormatCallTerminate(sessionID, evt)
+	if err := h.webhookProcessor.ProcessEvent(sessionID, constants.EventCallTerminate, payload); err != nil {
+		logger.Log.Error().
+			Err(err).
+			Str("session_id", sessionID).
+			Msg("Failed to process call terminate webhook")
+	}
+}
+
+func (h *EventHandler) handleNewsletterJoin(sessionID string, evt *events.NewsletterJoin) {
+	logger.Log.Info().
+		Str("session_id", sessionID).
+		Str("newsletter", evt.ID.String()).
+		Str("name", evt.ThreadMeta.Name.Text).
+		Msg("Joined newsletter")
+
+	payload := h.webhookFormatter.FormatNewsletterJoin(sessionID, evt)
+	if err := h.webhookProcessor.ProcessEvent(sessionID, constants.EventNewsletterJoin, payload); err != nil {
+		logger.Log.Error().
+			Err(err).
+			Str("session_id", sessionID).
+			Msg("Failed to process newsletter join webhook")
+	}
+}
+
+func (h *EventHandler) handleNewsletterLeave(sessionID string, evt *events.NewsletterLeave) {
+	logger.Log.Info().
+		Str("session_id", sessionID).
+		Str("newsletter", evt.ID.String()).
+		Msg("Left newsletter")
+
+	payload := h.webhookFormatter.FormatNewsletterLeave(sessionID, evt)
+	if err := h.webhookProcessor.ProcessEvent(sessionID, constants.EventNewsletterLeave, payload); err != nil {
+		logger.Log.Error().
+			Err(err).
+			Str("session_id", sessionID).
+			Msg("Failed to process newsletter leave webhook")
+	}
+}
+
+func (h *EventHandler) handleNewsletterMuteChange(sessionID string, evt *events.NewsletterMuteChange) {
+	logger.Log.Info().
+		Str("session_id", sessionID).
+		Str("newsletter", evt.ID.String()).
+		Str("mute", string(evt.Mute)).
+		Msg("Newsletter mute changed")
+
+	payload := h.webhookFormatter.FormatNewsletterMuteChange(sessionID, evt)
+	if err := h.webhookProcessor.ProcessEvent(sessionID, constants.EventNewsletterMuteChange, payload); err != nil {
+		logger.Log.Error().
+			Err(err).
+			Str("session_id", sessionID).
+			Msg("Failed to process newsletter mute webhook")
+	}
+}
+
+func (h *EventHandler) handleNewsletterLiveUpdate(sessionID string, evt *events.NewsletterLiveUpdate) {
+	logger.Log.Debug().
+		Str("session_id", sessionID).
+		Str("newsletter", evt.JID.String()).
+		Int("messages", len(evt.Messages)).
+		Msg("Newsletter live update")
+
+	payload := h.webhookFormatter.FormatNewsletterLiveUpdate(sessionID, evt)
+	if err := h.webhookProcessor.ProcessEvent(sessionID, constants.EventNewsletterLiveUpdate, payload); err != nil {
+		logger.Log.Error().
+			Err(err).
+			Str("session_id", sessionID).
+			Msg("Failed to process newsletter live update webhook")
+	}
 }
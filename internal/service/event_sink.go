@@ -0,0 +1,184 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/nats-io/nats.go/jetstream"
+	"github.com/rs/zerolog"
+
+	natsclient "zpwoot/internal/nats"
+	"zpwoot/pkg/logger"
+)
+
+const eventSinkStreamName = "EVENT_SINKS"
+
+// EventSink é um destino externo para os eventos das sessões (AMQP, Kafka, ...).
+// Os eventos chegam pelo mesmo subject events.<session> usado pelo stream SSE/WebSocket,
+// retidos no JetStream até a entrega.
+type EventSink interface {
+	// Name identifica o sink nos logs e nos consumers do JetStream
+	Name() string
+	// Publish entrega o evento ao destino. Sinks síncronos (AMQP) só retornam nil após a
+	// confirmação; sinks com batching (Kafka) retornam ao enfileirar e registram falhas no log.
+	Publish(ctx context.Context, event StreamEvent) error
+	Close() error
+}
+
+// EventSinkConfig controla as partições e as novas tentativas de entrega de um sink
+type EventSinkConfig struct {
+	Partitions     int // Consumers por sink; eventos de uma sessão caem sempre na mesma partição
+	PublishTimeout time.Duration
+	MaxRetries     int
+	RetryBaseDelay time.Duration
+}
+
+// EventSinkWorker consome os eventos pelo stream EVENT_SINKS do JetStream. O stream copia
+// events.<session> para sinkevents.<partição>.<session> e cada sink tem um consumer durável por
+// partição com MaxAckPending=1: os eventos de uma sessão são entregues um por vez e na ordem de
+// publicação, qualquer que seja a réplica que os consome. Falhas voltam para o stream com atraso
+// exponencial (NakWithDelay) e são descartadas após MaxRetries novas tentativas.
+type EventSinkWorker struct {
+	natsClient *natsclient.Client
+	sink       EventSink
+	config     EventSinkConfig
+	consumers  []jetstream.ConsumeContext
+	log        zerolog.Logger
+}
+
+func NewEventSinkWorker(natsClient *natsclient.Client, sink EventSink, config EventSinkConfig) *EventSinkWorker {
+	if config.Partitions <= 0 {
+		config.Partitions = 8
+	}
+	if config.PublishTimeout <= 0 {
+		config.PublishTimeout = 10 * time.Second
+	}
+	if config.RetryBaseDelay <= 0 {
+		config.RetryBaseDelay = time.Second
+	}
+
+	return &EventSinkWorker{
+		natsClient: natsClient,
+		sink:       sink,
+		config:     config,
+		log:        logger.WithComponent("sink-" + sink.Name()),
+	}
+}
+
+// Start cria o stream (compartilhado entre os sinks) e os consumers de cada partição
+func (w *EventSinkWorker) Start(ctx context.Context) error {
+	js, err := w.natsClient.JetStream()
+	if err != nil {
+		return err
+	}
+
+	// Com InterestPolicy o evento sai do stream quando todos os sinks confirmam a entrega
+	stream, err := js.CreateOrUpdateStream(ctx, jetstream.StreamConfig{
+		Name:        eventSinkStreamName,
+		Description: "zpwoot event sink delivery",
+		Subjects:    []string{"events.*"},
+		Retention:   jetstream.InterestPolicy,
+		Storage:     jetstream.FileStorage,
+		MaxAge:      24 * time.Hour,
+		SubjectTransform: &jetstream.SubjectTransformConfig{
+			Source:      "events.*",
+			Destination: fmt.Sprintf("sinkevents.{{partition(%d,1)}}.{{wildcard(1)}}", w.config.Partitions),
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create event sink stream: %w", err)
+	}
+
+	for partition := 0; partition < w.config.Partitions; partition++ {
+		consumer, err := stream.CreateOrUpdateConsumer(ctx, jetstream.ConsumerConfig{
+			Durable:       fmt.Sprintf("sink-%s-%d", w.sink.Name(), partition),
+			FilterSubject: fmt.Sprintf("sinkevents.%d.*", partition),
+			AckPolicy:     jetstream.AckExplicitPolicy,
+			AckWait:       w.config.PublishTimeout + 30*time.Second,
+			MaxAckPending: 1,
+			MaxDeliver:    -1,
+		})
+		if err != nil {
+			w.stopConsumers()
+			return fmt.Errorf("failed to create event sink consumer: %w", err)
+		}
+
+		consumeCtx, err := consumer.Consume(w.handleMessage)
+		if err != nil {
+			w.stopConsumers()
+			return fmt.Errorf("failed to consume event sink partition %d: %w", partition, err)
+		}
+		w.consumers = append(w.consumers, consumeCtx)
+	}
+
+	return nil
+}
+
+func (w *EventSinkWorker) Stop() error {
+	w.stopConsumers()
+	return w.sink.Close()
+}
+
+func (w *EventSinkWorker) stopConsumers() {
+	for _, consumeCtx := range w.consumers {
+		consumeCtx.Stop()
+	}
+	w.consumers = nil
+}
+
+func (w *EventSinkWorker) handleMessage(msg jetstream.Msg) {
+	var evt StreamEvent
+	if err := json.Unmarshal(msg.Data(), &evt); err != nil {
+		w.log.Error().
+			Err(err).
+			Str(logger.FieldSubject, msg.Subject()).
+			Msg("Failed to unmarshal sink event")
+		msg.Term()
+		return
+	}
+
+	eventLog := w.log.With().
+		Str(logger.FieldSessionID, evt.SessionID).
+		Str(logger.FieldEvent, evt.Event).
+		Int64("event_id", evt.ID).
+		Logger()
+
+	attempt := 1
+	if meta, err := msg.Metadata(); err == nil {
+		attempt = int(meta.NumDelivered)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), w.config.PublishTimeout)
+	err := w.sink.Publish(ctx, evt)
+	cancel()
+
+	if err == nil {
+		msg.Ack()
+		eventLog.Debug().Int(logger.FieldAttempt, attempt).Msg("Event delivered to sink")
+		return
+	}
+
+	if attempt > w.config.MaxRetries {
+		msg.Term()
+		eventLog.Error().
+			Err(err).
+			Int("attempts", attempt).
+			Msg("❌ Failed to deliver event to sink, dropping")
+		return
+	}
+
+	// A mensagem continua pendente (MaxAckPending=1): a partição aguarda a nova tentativa
+	delay := w.config.RetryBaseDelay << (attempt - 1)
+	if delay <= 0 || delay > 10*time.Minute {
+		delay = 10 * time.Minute
+	}
+	eventLog.Warn().
+		Err(err).
+		Int(logger.FieldAttempt, attempt).
+		Dur("retry_delay", delay).
+		Msg("⚠️ Sink delivery failed, retrying")
+
+	msg.NakWithDelay(delay)
+}
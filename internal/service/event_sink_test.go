@@ -0,0 +1,101 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	natsclient "zpwoot/internal/nats"
+	"zpwoot/internal/nats/natstest"
+)
+
+// fakeSink falha as primeiras entregas de cada evento conforme failures[id] (-1 = sempre)
+type fakeSink struct {
+	mu        sync.Mutex
+	failures  map[int64]int
+	attempts  map[int64]int
+	delivered map[string][]int64
+	done      chan struct{}
+	expected  int
+}
+
+func (s *fakeSink) Name() string { return "fake" }
+
+func (s *fakeSink) Publish(ctx context.Context, event StreamEvent) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.attempts[event.ID]++
+	if fail := s.failures[event.ID]; fail < 0 || s.attempts[event.ID] <= fail {
+		return errors.New("sink unavailable")
+	}
+
+	s.delivered[event.SessionID] = append(s.delivered[event.SessionID], event.ID)
+	if s.expected--; s.expected == 0 {
+		close(s.done)
+	}
+	return nil
+}
+
+func (s *fakeSink) Close() error { return nil }
+
+func publishSinkEvent(t *testing.T, client *natsclient.Client, sessionID string, id int64) {
+	t.Helper()
+	data, err := json.Marshal(StreamEvent{ID: id, SessionID: sessionID, Event: "message"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := client.Publish("events."+sessionID, data); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestEventSinkWorkerOrderAndRetries(t *testing.T) {
+	client := natstest.NewClient(t)
+	sink := &fakeSink{
+		failures:  map[int64]int{1: 2, 5: -1},
+		attempts:  make(map[int64]int),
+		delivered: make(map[string][]int64),
+		done:      make(chan struct{}),
+		expected:  5,
+	}
+
+	// Duas "réplicas" do mesmo sink dividem os consumers das partições
+	config := EventSinkConfig{Partitions: 4, PublishTimeout: time.Second, MaxRetries: 2, RetryBaseDelay: 20 * time.Millisecond}
+	for i := 0; i < 2; i++ {
+		worker := NewEventSinkWorker(client, sink, config)
+		if err := worker.Start(context.Background()); err != nil {
+			t.Fatal(err)
+		}
+		defer worker.stopConsumers()
+	}
+
+	// O evento 1 falha duas vezes: 2 e 3 (mesma sessão) esperam; o 5 é descartado após 3 tentativas
+	for id := int64(1); id <= 3; id++ {
+		publishSinkEvent(t, client, "s1", id)
+	}
+	publishSinkEvent(t, client, "s2", 4)
+	publishSinkEvent(t, client, "s2", 5)
+	publishSinkEvent(t, client, "s2", 6)
+
+	select {
+	case <-sink.done:
+	case <-time.After(10 * time.Second):
+		t.Fatal("timeout waiting for sink deliveries")
+	}
+
+	sink.mu.Lock()
+	defer sink.mu.Unlock()
+	if got := sink.delivered["s1"]; len(got) != 3 || got[0] != 1 || got[1] != 2 || got[2] != 3 {
+		t.Errorf("s1 deliveries = %v, want [1 2 3]", got)
+	}
+	if got := sink.delivered["s2"]; len(got) != 2 || got[0] != 4 || got[1] != 6 {
+		t.Errorf("s2 deliveries = %v, want [4 6]", got)
+	}
+	if sink.attempts[1] != 3 || sink.attempts[5] != 3 {
+		t.Errorf("attempts = %v, want 3 for events 1 and 5", sink.attempts)
+	}
+}
@@ -0,0 +1,263 @@
+package service
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/nats-io/nats.go"
+
+	natsclient "zpwoot/internal/nats"
+	"zpwoot/pkg/logger"
+)
+
+// StreamEventReset avisa o stream que eventos foram perdidos (cliente lento ou Last-Event-ID fora do
+// buffer): o cliente deve recarregar o estado em vez de confiar apenas nos eventos recebidos
+const StreamEventReset = "reset"
+
+// eventStreamDropSubject recebe o ID das sessões removidas, para que todas as réplicas liberem o buffer
+const eventStreamDropSubject = "eventstream.drop"
+
+// StreamEvent é o envelope publicado em events.<session> e entregue aos streams SSE/WebSocket
+type StreamEvent struct {
+	ID        int64           `json:"id"` // Crescente por sessão; usado como Last-Event-ID
+	SessionID string          `json:"session_id"`
+	Event     string          `json:"event"`
+	Payload   *WebhookPayload `json:"payload"`
+}
+
+// EventStreamHub publica os eventos das sessões no NATS e os distribui aos streams desta réplica.
+// Toda réplica assina events.* e guarda os últimos eventos de cada sessão em memória,
+// então qualquer réplica pode atender um stream e retomar a partir do Last-Event-ID.
+type EventStreamHub struct {
+	natsClient    *natsclient.Client
+	bufferSize    int
+	subscriptions []*nats.Subscription
+
+	lastID atomic.Int64
+
+	mu          sync.RWMutex
+	buffers     map[string][]StreamEvent
+	evicted     map[string]int64 // ID do evento mais recente que já saiu do buffer, por sessão
+	subscribers map[string]map[*EventSubscriber]struct{}
+}
+
+// EventSubscriber representa um stream conectado
+type EventSubscriber struct {
+	events chan StreamEvent
+	filter map[string]bool
+
+	mu     sync.Mutex
+	lostID int64 // ID do último evento descartado ainda não avisado com reset (0 = nenhum)
+}
+
+// Events retorna o canal de eventos do stream
+func (s *EventSubscriber) Events() <-chan StreamEvent {
+	return s.events
+}
+
+func (s *EventSubscriber) accepts(event string) bool {
+	return len(s.filter) == 0 || s.filter[event]
+}
+
+// deliver entrega o evento sem bloquear. Com o canal cheio o evento é descartado, e antes do
+// próximo evento entregue o stream recebe um reset com o ID do último descartado.
+func (s *EventSubscriber) deliver(evt StreamEvent) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.lostID > 0 {
+		select {
+		case s.events <- StreamEvent{ID: s.lostID, SessionID: evt.SessionID, Event: StreamEventReset}:
+			s.lostID = 0
+		default:
+			s.lostID = evt.ID
+			return false
+		}
+	}
+
+	select {
+	case s.events <- evt:
+		return true
+	default:
+		s.lostID = evt.ID
+		return false
+	}
+}
+
+func NewEventStreamHub(natsClient *natsclient.Client, bufferSize int) *EventStreamHub {
+	if bufferSize <= 0 {
+		bufferSize = 500
+	}
+
+	return &EventStreamHub{
+		natsClient:  natsClient,
+		bufferSize:  bufferSize,
+		buffers:     make(map[string][]StreamEvent),
+		evicted:     make(map[string]int64),
+		subscribers: make(map[string]map[*EventSubscriber]struct{}),
+	}
+}
+
+// Start assina events.* e as remoções de sessão (sem queue group: todas as réplicas recebem tudo)
+func (h *EventStreamHub) Start() error {
+	sub, err := h.natsClient.Subscribe("events.*", h.handleMessage)
+	if err != nil {
+		return fmt.Errorf("failed to subscribe to event stream: %w", err)
+	}
+	h.subscriptions = append(h.subscriptions, sub)
+
+	dropSub, err := h.natsClient.Subscribe(eventStreamDropSubject, func(msg *nats.Msg) {
+		h.dropBuffer(string(msg.Data))
+	})
+	if err != nil {
+		return fmt.Errorf("failed to subscribe to event stream drops: %w", err)
+	}
+	h.subscriptions = append(h.subscriptions, dropSub)
+
+	return nil
+}
+
+func (h *EventStreamHub) Stop() error {
+	for _, sub := range h.subscriptions {
+		if err := sub.Unsubscribe(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// SetEventStream libera os buffers de replay das sessões removidas
+func (m *SessionManager) SetEventStream(hub *EventStreamHub) {
+	m.eventStream = hub
+}
+
+// DropSession libera o buffer de replay da sessão removida em todas as réplicas
+func (h *EventStreamHub) DropSession(sessionID string) error {
+	return h.natsClient.Publish(eventStreamDropSubject, []byte(sessionID))
+}
+
+func (h *EventStreamHub) dropBuffer(sessionID string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	delete(h.buffers, sessionID)
+	delete(h.evicted, sessionID)
+}
+
+// Publish envia o evento para o NATS. O ID é baseado no relógio (nanossegundos) e estritamente
+// crescente nesta réplica, que é a única a produzir eventos da sessão.
+func (h *EventStreamHub) Publish(sessionID string, payload *WebhookPayload) error {
+	evt := StreamEvent{
+		ID:        h.nextID(),
+		SessionID: sessionID,
+		Event:     payload.Event,
+		Payload:   payload,
+	}
+
+	data, err := json.Marshal(evt)
+	if err != nil {
+		return fmt.Errorf("failed to marshal stream event: %w", err)
+	}
+
+	return h.natsClient.Publish("events."+sessionID, data)
+}
+
+func (h *EventStreamHub) nextID() int64 {
+	for {
+		last := h.lastID.Load()
+		next := time.Now().UnixNano()
+		if next <= last {
+			next = last + 1
+		}
+		if h.lastID.CompareAndSwap(last, next) {
+			return next
+		}
+	}
+}
+
+func (h *EventStreamHub) handleMessage(msg *nats.Msg) {
+	var evt StreamEvent
+	if err := json.Unmarshal(msg.Data, &evt); err != nil {
+		logger.Log.Error().
+			Err(err).
+			Str("subject", msg.Subject).
+			Msg("Failed to unmarshal stream event")
+		return
+	}
+
+	h.mu.Lock()
+	buffer := append(h.buffers[evt.SessionID], evt)
+	if len(buffer) > h.bufferSize {
+		h.evicted[evt.SessionID] = buffer[len(buffer)-h.bufferSize-1].ID
+		buffer = buffer[len(buffer)-h.bufferSize:]
+	}
+	h.buffers[evt.SessionID] = buffer
+
+	subscribers := make([]*EventSubscriber, 0, len(h.subscribers[evt.SessionID]))
+	for sub := range h.subscribers[evt.SessionID] {
+		subscribers = append(subscribers, sub)
+	}
+	h.mu.Unlock()
+
+	for _, sub := range subscribers {
+		if !sub.accepts(evt.Event) {
+			continue
+		}
+		if !sub.deliver(evt) {
+			logger.Log.Warn().
+				Str("session_id", evt.SessionID).
+				Int64("event_id", evt.ID).
+				Msg("Stream subscriber is too slow, dropping event")
+		}
+	}
+}
+
+// Subscribe registra um stream da sessão. Eventos com ID maior que lastEventID ainda em memória
+// são retornados em replay; os seguintes chegam pelo canal do subscriber. Se parte dos eventos
+// após lastEventID já saiu do buffer, o replay começa com um reset.
+func (h *EventStreamHub) Subscribe(sessionID string, events []string, lastEventID int64) ([]StreamEvent, *EventSubscriber, func()) {
+	sub := &EventSubscriber{
+		events: make(chan StreamEvent, 64),
+		filter: make(map[string]bool, len(events)),
+	}
+	for _, e := range events {
+		if e = strings.TrimSpace(e); e != "" {
+			sub.filter[e] = true
+		}
+	}
+
+	h.mu.Lock()
+	var replay []StreamEvent
+	if lastEventID > 0 {
+		if evicted := h.evicted[sessionID]; evicted > lastEventID {
+			replay = append(replay, StreamEvent{ID: evicted, SessionID: sessionID, Event: StreamEventReset})
+		}
+		for _, evt := range h.buffers[sessionID] {
+			if evt.ID > lastEventID && sub.accepts(evt.Event) {
+				replay = append(replay, evt)
+			}
+		}
+	}
+
+	if h.subscribers[sessionID] == nil {
+		h.subscribers[sessionID] = make(map[*EventSubscriber]struct{})
+	}
+	h.subscribers[sessionID][sub] = struct{}{}
+	h.mu.Unlock()
+
+	unsubscribe := func() {
+		h.mu.Lock()
+		defer h.mu.Unlock()
+		if subs, ok := h.subscribers[sessionID]; ok {
+			delete(subs, sub)
+			if len(subs) == 0 {
+				delete(h.subscribers, sessionID)
+			}
+		}
+	}
+
+	return replay, sub, unsubscribe
+}
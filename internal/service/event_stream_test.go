@@ -0,0 +1,68 @@
+package service
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/nats-io/nats.go"
+)
+
+func publishStreamEvent(t *testing.T, h *EventStreamHub, sessionID string, id int64) {
+	t.Helper()
+	data, err := json.Marshal(StreamEvent{ID: id, SessionID: sessionID, Event: "message"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	h.handleMessage(&nats.Msg{Subject: "events." + sessionID, Data: data})
+}
+
+func TestEventStreamSlowSubscriberReset(t *testing.T) {
+	h := NewEventStreamHub(nil, 500)
+	_, sub, unsubscribe := h.Subscribe("s1", nil, 0)
+	defer unsubscribe()
+
+	// O canal comporta 64 eventos: os 6 seguintes são descartados
+	for id := int64(1); id <= 70; id++ {
+		publishStreamEvent(t, h, "s1", id)
+	}
+	for i := 0; i < 64; i++ {
+		<-sub.Events()
+	}
+
+	publishStreamEvent(t, h, "s1", 71)
+	if evt := <-sub.Events(); evt.Event != StreamEventReset || evt.ID != 70 {
+		t.Fatalf("first event after drop = %+v, want reset with id 70", evt)
+	}
+	if evt := <-sub.Events(); evt.ID != 71 {
+		t.Fatalf("event after reset = %+v, want id 71", evt)
+	}
+}
+
+func TestEventStreamReplayGapAndDrop(t *testing.T) {
+	h := NewEventStreamHub(nil, 3)
+	for id := int64(1); id <= 5; id++ {
+		publishStreamEvent(t, h, "s1", id)
+	}
+
+	// Buffer com 3..5: retomar de 1 perdeu o evento 2
+	replay, _, unsubscribe := h.Subscribe("s1", nil, 1)
+	unsubscribe()
+	if len(replay) != 4 || replay[0].Event != StreamEventReset || replay[0].ID != 2 || replay[1].ID != 3 {
+		t.Fatalf("replay from 1 = %+v, want reset(2) followed by 3..5", replay)
+	}
+
+	replay, _, unsubscribe = h.Subscribe("s1", nil, 2)
+	unsubscribe()
+	if len(replay) != 3 || replay[0].ID != 3 {
+		t.Fatalf("replay from 2 = %+v, want 3..5 without reset", replay)
+	}
+
+	h.dropBuffer("s1")
+	if replay, _, unsubscribe = h.Subscribe("s1", nil, 1); len(replay) != 0 {
+		t.Errorf("replay after drop = %+v, want empty", replay)
+	}
+	unsubscribe()
+	if len(h.buffers) != 0 || len(h.evicted) != 0 {
+		t.Errorf("buffers not released: %d buffers, %d evicted", len(h.buffers), len(h.evicted))
+	}
+}
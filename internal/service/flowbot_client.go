@@ -0,0 +1,177 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"zpwoot/internal/model"
+)
+
+// FlowBotAPIError é uma resposta de erro da API do fluxo
+type FlowBotAPIError struct {
+	StatusCode int
+	Body       string
+}
+
+func (e *FlowBotAPIError) Error() string {
+	return fmt.Sprintf("flow bot api returned status %d: %s", e.StatusCode, e.Body)
+}
+
+// isFlowBotNotFound identifica sessões do fluxo que não existem mais (expiradas ou concluídas)
+func isFlowBotNotFound(err error) bool {
+	var apiErr *FlowBotAPIError
+	return errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusNotFound
+}
+
+// FlowBotBlock é um bloco de mensagem retornado pelo fluxo
+type FlowBotBlock struct {
+	Type string // text, image, video, audio
+	Text string // Texto (markdown) dos blocos de texto
+	URL  string // URL da mídia
+}
+
+// FlowBotReply é a resposta do fluxo a uma mensagem do contato
+type FlowBotReply struct {
+	SessionID string         // Preenchido apenas pelo startChat
+	Blocks    []FlowBotBlock // Mensagens a enviar, em ordem
+	Choices   []string       // Opções do bloco de escolha que aguarda resposta
+	Waiting   bool           // O fluxo aguarda uma resposta do contato (false = fluxo concluído)
+}
+
+// FlowBotClient acessa a API de chat de um fluxo compatível com o Typebot (startChat/continueChat)
+type FlowBotClient struct {
+	baseURL   string
+	typebotID string
+	token     string
+	client    *http.Client
+}
+
+func NewFlowBotClient(config *model.FlowBotConfig, timeout time.Duration) *FlowBotClient {
+	return &FlowBotClient{
+		baseURL:   strings.TrimRight(config.URL, "/"),
+		typebotID: config.TypebotID,
+		token:     config.Token,
+		client:    &http.Client{Timeout: timeout},
+	}
+}
+
+// flowBotResponse são os campos usados das respostas de startChat e continueChat
+type flowBotResponse struct {
+	SessionID string `json:"sessionId"`
+	Messages  []struct {
+		Type    string `json:"type"`
+		Content struct {
+			Type     string `json:"type"`
+			Markdown string `json:"markdown"`
+			URL      string `json:"url"`
+		} `json:"content"`
+	} `json:"messages"`
+	Input *struct {
+		Type  string `json:"type"`
+		Items []struct {
+			Content string `json:"content"`
+		} `json:"items"`
+	} `json:"input"`
+}
+
+func (c *FlowBotClient) post(ctx context.Context, endpoint string, payload interface{}) (*FlowBotReply, error) {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode flow bot request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create flow bot request: %w", err)
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "zpwoot-flowbot/1.0")
+	if c.token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.token)
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to send flow bot request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read flow bot response: %w", err)
+	}
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return nil, &FlowBotAPIError{StatusCode: resp.StatusCode, Body: string(body)}
+	}
+
+	var result flowBotResponse
+	if err := json.Unmarshal(body, &result); err != nil {
+		return nil, fmt.Errorf("failed to decode flow bot response: %w", err)
+	}
+
+	reply := &FlowBotReply{
+		SessionID: result.SessionID,
+		Waiting:   result.Input != nil,
+	}
+
+	for _, message := range result.Messages {
+		switch message.Type {
+		case "text":
+			if text := strings.TrimSpace(message.Content.Markdown); text != "" {
+				reply.Blocks = append(reply.Blocks, FlowBotBlock{Type: "text", Text: text})
+			}
+		case "image", "video", "audio":
+			if message.Content.URL != "" {
+				reply.Blocks = append(reply.Blocks, FlowBotBlock{Type: message.Type, URL: message.Content.URL})
+			}
+		case "embed":
+			// Embeds não existem no WhatsApp; o link é enviado como texto
+			if message.Content.URL != "" {
+				reply.Blocks = append(reply.Blocks, FlowBotBlock{Type: "text", Text: message.Content.URL})
+			}
+		}
+	}
+
+	if result.Input != nil && result.Input.Type == "choice input" {
+		for _, item := range result.Input.Items {
+			if item.Content != "" {
+				reply.Choices = append(reply.Choices, item.Content)
+			}
+		}
+	}
+
+	return reply, nil
+}
+
+// Start inicia uma sessão do fluxo para o contato. variables preenche variáveis do fluxo
+// (ex.: remoteJid, pushName e a mensagem que disparou o fluxo).
+func (c *FlowBotClient) Start(ctx context.Context, variables map[string]string) (*FlowBotReply, error) {
+	payload := map[string]interface{}{
+		"prefilledVariables":      variables,
+		"textBubbleContentFormat": "markdown",
+	}
+
+	endpoint := fmt.Sprintf("%s/api/v1/typebots/%s/startChat", c.baseURL, url.PathEscape(c.typebotID))
+	return c.post(ctx, endpoint, payload)
+}
+
+// Continue envia a resposta do contato para a sessão do fluxo
+func (c *FlowBotClient) Continue(ctx context.Context, sessionID, message string) (*FlowBotReply, error) {
+	payload := map[string]interface{}{
+		"message":                 map[string]string{"type": "text", "text": message},
+		"textBubbleContentFormat": "markdown",
+	}
+
+	endpoint := fmt.Sprintf("%s/api/v1/sessions/%s/continueChat", c.baseURL, url.PathEscape(sessionID))
+	return c.post(ctx, endpoint, payload)
+}
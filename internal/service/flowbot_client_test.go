@@ -0,0 +1,125 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+
+	"zpwoot/internal/model"
+)
+
+// newFakeTypebot simula os endpoints startChat e continueChat de um fluxo com uma escolha
+func newFakeTypebot(t *testing.T) *httptest.Server {
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /api/v1/typebots/atendimento/startChat", func(w http.ResponseWriter, r *http.Request) {
+		var body struct {
+			PrefilledVariables map[string]string `json:"prefilledVariables"`
+		}
+		json.NewDecoder(r.Body).Decode(&body)
+
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"sessionId": "bot-1",
+			"messages": []map[string]interface{}{
+				{"type": "text", "content": map[string]string{"type": "markdown", "markdown": "Olá **" + body.PrefilledVariables["pushName"] + "**"}},
+				{"type": "image", "content": map[string]string{"url": "https://cdn.exemplo.com/logo.png"}},
+				{"type": "text", "content": map[string]string{"type": "markdown", "markdown": "Escolha uma opção"}},
+			},
+			"input": map[string]interface{}{
+				"type":  "choice input",
+				"items": []map[string]string{{"id": "a", "content": "Vendas"}, {"id": "b", "content": "Suporte"}},
+			},
+		})
+	})
+	mux.HandleFunc("POST /api/v1/sessions/{session}/continueChat", func(w http.ResponseWriter, r *http.Request) {
+		if r.PathValue("session") != "bot-1" {
+			http.Error(w, `{"message":"Session not found."}`, http.StatusNotFound)
+			return
+		}
+
+		var body struct {
+			Message struct {
+				Text string `json:"text"`
+			} `json:"message"`
+		}
+		json.NewDecoder(r.Body).Decode(&body)
+
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"messages": []map[string]interface{}{
+				{"type": "text", "content": map[string]string{"type": "markdown", "markdown": "Você escolheu " + body.Message.Text}},
+			},
+		})
+	})
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer secret" {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		mux.ServeHTTP(w, r)
+	}))
+	t.Cleanup(server.Close)
+
+	return server
+}
+
+func TestFlowBotClientChat(t *testing.T) {
+	server := newFakeTypebot(t)
+	client := NewFlowBotClient(&model.FlowBotConfig{URL: server.URL + "/", TypebotID: "atendimento", Token: "secret"}, 5*time.Second)
+	ctx := context.Background()
+
+	reply, err := client.Start(ctx, map[string]string{"pushName": "Ana"})
+	if err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+
+	wantBlocks := []FlowBotBlock{
+		{Type: "text", Text: "Olá **Ana**"},
+		{Type: "image", URL: "https://cdn.exemplo.com/logo.png"},
+		{Type: "text", Text: "Escolha uma opção"},
+	}
+	if reply.SessionID != "bot-1" || !reply.Waiting || !reflect.DeepEqual(reply.Blocks, wantBlocks) {
+		t.Errorf("Start() = %+v", reply)
+	}
+	if !reflect.DeepEqual(reply.Choices, []string{"Vendas", "Suporte"}) {
+		t.Errorf("Start() choices = %v", reply.Choices)
+	}
+
+	reply, err = client.Continue(ctx, reply.SessionID, choiceAnswer(reply.Choices, "2"))
+	if err != nil {
+		t.Fatalf("Continue() error = %v", err)
+	}
+	if reply.Waiting || len(reply.Blocks) != 1 || reply.Blocks[0].Text != "Você escolheu Suporte" {
+		t.Errorf("Continue() = %+v", reply)
+	}
+
+	if _, err := client.Continue(ctx, "expired", "oi"); !isFlowBotNotFound(err) {
+		t.Errorf("Continue(expired) error = %v, want not found", err)
+	}
+}
+
+func TestFlowBotTriggers(t *testing.T) {
+	tests := []struct {
+		triggers []string
+		text     string
+		want     bool
+	}{
+		{nil, "qualquer coisa", true},
+		{nil, "", false},
+		{[]string{"menu", "oi"}, "Oi", true},
+		{[]string{"menu"}, "quero o menu", false},
+	}
+
+	for _, tt := range tests {
+		if got := matchesTrigger(tt.triggers, tt.text); got != tt.want {
+			t.Errorf("matchesTrigger(%v, %q) = %v, want %v", tt.triggers, tt.text, got, tt.want)
+		}
+	}
+
+	if got := choiceAnswer([]string{"Vendas"}, "3"); got != "3" {
+		t.Errorf("choiceAnswer(out of range) = %q, want 3", got)
+	}
+}
@@ -0,0 +1,326 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.mau.fi/whatsmeow/types/events"
+
+	"zpwoot/internal/model"
+	"zpwoot/internal/repository"
+	"zpwoot/pkg/logger"
+)
+
+var ErrFlowBotSessionNotFound = errors.New("flow bot session not found")
+
+// FlowBotServiceConfig define os limites da integração com o fluxo
+type FlowBotServiceConfig struct {
+	Timeout   time.Duration // Timeout de cada chamada à API do fluxo e de cada envio
+	QueueSize int           // Mensagens pendentes por sessão
+}
+
+// FlowBotService encaminha as mensagens das conversas individuais para um fluxo compatível com a
+// API de chat do Typebot e envia os blocos retornados ao contato. O estado de cada contato fica no
+// Postgres: o fluxo começa por uma palavra-gatilho, expira após um período sem interação e pode ser
+// pausado para que um humano assuma a conversa. As mensagens de cada sessão são processadas em
+// ordem por um worker próprio.
+type FlowBotService struct {
+	sessionRepo    *repository.SessionRepository
+	flowBotRepo    *repository.FlowBotRepository
+	sessionManager *SessionManager
+	config         FlowBotServiceConfig
+	workers        *sessionWorkers
+}
+
+func NewFlowBotService(sessionRepo *repository.SessionRepository, flowBotRepo *repository.FlowBotRepository, sessionManager *SessionManager, config FlowBotServiceConfig) *FlowBotService {
+	if config.Timeout <= 0 {
+		config.Timeout = 30 * time.Second
+	}
+	if config.QueueSize <= 0 {
+		config.QueueSize = 1000
+	}
+
+	return &FlowBotService{
+		sessionRepo:    sessionRepo,
+		flowBotRepo:    flowBotRepo,
+		sessionManager: sessionManager,
+		config:         config,
+		workers:        newSessionWorkers(config.QueueSize),
+	}
+}
+
+// SetFlowBot liga o fluxo ao processamento de eventos das sessões
+func (m *SessionManager) SetFlowBot(flowBot *FlowBotService) {
+	m.eventHandler.flowBot = flowBot
+}
+
+// Stop encerra os workers; mensagens ainda na fila são descartadas
+func (s *FlowBotService) Stop() {
+	s.workers.stop()
+	logger.Log.Info().Msg("Flow bot stopped")
+}
+
+// List retorna as conversas da sessão com o fluxo (status vazio = todas)
+func (s *FlowBotService) List(ctx context.Context, sessionID string, status model.FlowBotStatus, limit, offset int) ([]*model.FlowBotSession, error) {
+	return s.flowBotRepo.List(ctx, sessionID, status, limit, offset)
+}
+
+// Pause entrega a conversa do contato a um humano: o fluxo deixa de responder até Close
+// (ou até a conversa expirar)
+func (s *FlowBotService) Pause(ctx context.Context, sessionID, phone string) error {
+	if err := s.flowBotRepo.SetStatus(ctx, sessionID, cleanPhone(phone), model.FlowBotStatusPaused); err != nil {
+		return err
+	}
+
+	logger.Log.Info().Str("session_id", sessionID).Str("phone", phone).Msg("Flow bot paused, conversation handed to a human")
+	return nil
+}
+
+// Close encerra a conversa (inclusive o atendimento humano); a próxima mensagem do contato com um gatilho inicia o fluxo
+func (s *FlowBotService) Close(ctx context.Context, sessionID, phone string) error {
+	deleted, err := s.flowBotRepo.Delete(ctx, sessionID, cleanPhone(phone))
+	if err != nil {
+		return err
+	}
+	if !deleted {
+		return ErrFlowBotSessionNotFound
+	}
+
+	logger.Log.Info().Str("session_id", sessionID).Str("phone", phone).Msg("Flow bot session closed")
+	return nil
+}
+
+// sessionConfig retorna a configuração do fluxo da sessão (nil se desativado)
+func (s *FlowBotService) sessionConfig(ctx context.Context, sessionID string) *model.FlowBotConfig {
+	session, err := s.sessionRepo.GetByID(ctx, sessionID)
+	if err != nil {
+		logger.Log.Warn().Err(err).Str("session_id", sessionID).Msg("Failed to load session for flow bot")
+		return nil
+	}
+	if !session.FlowBotEnabled() {
+		return nil
+	}
+	return session.FlowBotConfig
+}
+
+// matchesTrigger informa se a mensagem inicia o fluxo (sem gatilhos, qualquer mensagem inicia)
+func matchesTrigger(triggers []string, text string) bool {
+	if len(triggers) == 0 {
+		return text != ""
+	}
+	for _, trigger := range triggers {
+		if strings.EqualFold(strings.TrimSpace(trigger), text) {
+			return true
+		}
+	}
+	return false
+}
+
+// choiceAnswer troca o número digitado pelo contato pelo texto da opção correspondente
+func choiceAnswer(choices []string, text string) string {
+	if n, err := strconv.Atoi(text); err == nil && n >= 1 && n <= len(choices) {
+		return choices[n-1]
+	}
+	return text
+}
+
+// markdownToWhatsApp converte a formatação markdown dos blocos de texto para a do WhatsApp
+var markdownToWhatsApp = strings.NewReplacer("**", "*", "~~", "~", `\`, "")
+
+// HandleMessage agenda o encaminhamento da mensagem para o fluxo
+func (s *FlowBotService) HandleMessage(sessionID string, evt *events.Message) {
+	if !isDirectChat(evt.Info) {
+		return
+	}
+
+	s.workers.enqueue(sessionID, func(ctx context.Context) {
+		s.process(ctx, sessionID, evt)
+	})
+}
+
+func (s *FlowBotService) process(ctx context.Context, sessionID string, evt *events.Message) {
+	config := s.sessionConfig(ctx, sessionID)
+	if config == nil {
+		return
+	}
+
+	phone := chatPhone(evt.Info)
+	if phone == "" {
+		return
+	}
+
+	log := logger.Log.With().Str("session_id", sessionID).Str("phone", phone).Logger()
+
+	state, err := s.flowBotRepo.Get(ctx, sessionID, phone)
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to load flow bot session")
+		return
+	}
+
+	// Conversas paradas há mais tempo que a expiração recomeçam (inclusive as pausadas)
+	if state != nil && config.Expire > 0 && time.Since(state.UpdatedAt) > time.Duration(config.Expire)*time.Minute {
+		if _, err := s.flowBotRepo.Delete(ctx, sessionID, phone); err != nil {
+			log.Error().Err(err).Msg("Failed to expire flow bot session")
+			return
+		}
+		log.Debug().Msg("Flow bot session expired")
+		state = nil
+	}
+
+	// Mensagem enviada pelo celular: um humano assumiu a conversa
+	if evt.Info.IsFromMe {
+		if config.StopOnFromMe && state != nil && state.Status == model.FlowBotStatusActive {
+			if err := s.Pause(ctx, sessionID, phone); err != nil {
+				log.Error().Err(err).Msg("Failed to pause flow bot")
+			}
+		}
+		return
+	}
+
+	text := strings.TrimSpace(ExtractMessageText(evt.Message))
+	if text == "" {
+		return
+	}
+
+	if config.FinishKeyword != "" && strings.EqualFold(text, strings.TrimSpace(config.FinishKeyword)) {
+		if state != nil {
+			if _, err := s.flowBotRepo.Delete(ctx, sessionID, phone); err != nil {
+				log.Error().Err(err).Msg("Failed to finish flow bot session")
+			}
+			log.Info().Msg("Flow bot session finished by contact")
+		}
+		return
+	}
+
+	if state != nil && state.Status == model.FlowBotStatusPaused {
+		return
+	}
+
+	client := NewFlowBotClient(config, s.config.Timeout)
+
+	var reply *FlowBotReply
+
+	if state != nil {
+		reply, err = client.Continue(ctx, state.BotSessionID, choiceAnswer(state.Choices, text))
+		if isFlowBotNotFound(err) {
+			// A sessão terminou no fluxo; a mensagem pode iniciar uma nova
+			log.Debug().Msg("Flow bot session no longer exists, restarting")
+			if _, err := s.flowBotRepo.Delete(ctx, sessionID, phone); err != nil {
+				log.Error().Err(err).Msg("Failed to delete flow bot session")
+				return
+			}
+			state = nil
+		} else if err != nil {
+			log.Error().Err(err).Msg("Failed to continue flow bot chat")
+			return
+		}
+	}
+
+	if state == nil {
+		if !matchesTrigger(config.Triggers, text) {
+			return
+		}
+
+		reply, err = client.Start(ctx, map[string]string{
+			"remoteJid": phone,
+			"pushName":  evt.Info.PushName,
+			"message":   text,
+		})
+		if err != nil {
+			log.Error().Err(err).Msg("Failed to start flow bot chat")
+			return
+		}
+
+		state = &model.FlowBotSession{
+			SessionID:    sessionID,
+			Phone:        phone,
+			BotSessionID: reply.SessionID,
+			Status:       model.FlowBotStatusActive,
+		}
+		log.Info().Str("bot_session_id", reply.SessionID).Msg("Flow bot session started")
+	}
+
+	// O estado é salvo antes do envio para que uma resposta rápida do contato continue o fluxo
+	if reply.Waiting {
+		state.Choices = reply.Choices
+		if err := s.flowBotRepo.Save(ctx, state); err != nil {
+			log.Error().Err(err).Msg("Failed to save flow bot session")
+			return
+		}
+	} else if _, err := s.flowBotRepo.Delete(ctx, sessionID, phone); err != nil {
+		log.Error().Err(err).Msg("Failed to close flow bot session")
+	}
+
+	s.send(ctx, config, sessionID, phone, reply)
+}
+
+// send envia os blocos do fluxo em ordem. As opções de escolha são enviadas como lista numerada,
+// junto ao último bloco de texto.
+func (s *FlowBotService) send(ctx context.Context, config *model.FlowBotConfig, sessionID, phone string, reply *FlowBotReply) {
+	blocks := reply.Blocks
+	if len(reply.Choices) > 0 {
+		buttons := make([]model.TemplateButton, len(reply.Choices))
+		for i, choice := range reply.Choices {
+			buttons[i] = model.TemplateButton{Text: choice}
+		}
+
+		if last := len(blocks) - 1; last >= 0 && blocks[last].Type == "text" {
+			blocks[last].Text = textWithButtons(blocks[last].Text, buttons)
+		} else {
+			blocks = append(blocks, FlowBotBlock{Type: "text", Text: strings.TrimSpace(textWithButtons("", buttons))})
+		}
+	}
+	if len(blocks) == 0 {
+		return
+	}
+
+	log := logger.Log.With().Str("session_id", sessionID).Str("phone", phone).Logger()
+
+	client, err := s.sessionManager.GetClient(sessionID)
+	if err != nil {
+		log.Warn().Err(err).Msg("Client not found for flow bot")
+		return
+	}
+
+	delay := time.Duration(config.MessageDelay) * time.Millisecond
+
+	for i, block := range blocks {
+		if delay > 0 {
+			if block.Type == "text" {
+				_ = s.sessionManager.SendPresence(ctx, client, phone, "composing")
+			}
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(delay):
+			}
+		}
+
+		sendCtx, cancel := context.WithTimeout(ctx, s.config.Timeout)
+
+		switch block.Type {
+		case "text":
+			_, _, err = s.sessionManager.SendTextMessage(sendCtx, client, phone, markdownToWhatsApp.Replace(block.Text))
+		case "image":
+			_, _, err = s.sessionManager.SendImageFromURL(sendCtx, client, phone, block.URL, "")
+		case "video":
+			_, _, err = s.sessionManager.SendVideoFromURL(sendCtx, client, phone, block.URL, "")
+		case "audio":
+			_, _, err = s.sessionManager.SendAudioFromURL(sendCtx, client, phone, block.URL)
+		default:
+			err = fmt.Errorf("unsupported block type: %s", block.Type)
+		}
+		cancel()
+
+		if err != nil {
+			log.Error().Err(err).Int("block", i).Str("type", block.Type).Msg("Failed to send flow bot block")
+			return
+		}
+	}
+
+	log.Debug().Int("blocks", len(blocks)).Msg("Flow bot reply sent")
+}
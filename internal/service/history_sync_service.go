@@ -0,0 +1,183 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"go.mau.fi/whatsmeow"
+	"go.mau.fi/whatsmeow/proto/waCompanionReg"
+	"go.mau.fi/whatsmeow/proto/waWa6"
+	"go.mau.fi/whatsmeow/store"
+	"go.mau.fi/whatsmeow/types"
+	"go.mau.fi/whatsmeow/types/events"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+
+	"zpwoot/internal/model"
+	"zpwoot/internal/repository"
+	"zpwoot/pkg/logger"
+)
+
+// HistorySyncSummary resume o processamento de um chunk de histórico
+type HistorySyncSummary struct {
+	SyncType      string
+	ChunkOrder    int
+	Progress      int
+	Conversations int
+	Messages      int
+	Skipped       int
+	Totals        *model.HistorySyncProgress // Totais acumulados da sessão após este chunk
+}
+
+type HistorySyncService struct {
+	messageRepo *repository.MessageRepository
+}
+
+func NewHistorySyncService(messageRepo *repository.MessageRepository) *HistorySyncService {
+	return &HistorySyncService{
+		messageRepo: messageRepo,
+	}
+}
+
+// Process converte as conversas de um events.HistorySync e grava chats e mensagens no banco
+func (s *HistorySyncService) Process(ctx context.Context, sessionID string, client *whatsmeow.Client, evt *events.HistorySync) (*HistorySyncSummary, error) {
+	data := evt.Data
+	summary := &HistorySyncSummary{
+		SyncType:   data.GetSyncType().String(),
+		ChunkOrder: int(data.GetChunkOrder()),
+		Progress:   int(data.GetProgress()),
+	}
+
+	chats := make([]*model.Chat, 0, len(data.GetConversations()))
+	messages := make([]*model.Message, 0)
+
+	for _, conv := range data.GetConversations() {
+		chatJID, err := types.ParseJID(conv.GetID())
+		if err != nil {
+			logger.Log.Debug().
+				Err(err).
+				Str("session_id", sessionID).
+				Str("chat", conv.GetID()).
+				Msg("Skipping conversation with invalid JID")
+			continue
+		}
+
+		name := conv.GetName()
+		if name == "" {
+			name = conv.GetDisplayName()
+		}
+
+		chat := &model.Chat{
+			SessionID:   sessionID,
+			JID:         chatJID.String(),
+			Name:        name,
+			UnreadCount: int(conv.GetUnreadCount()),
+			Archived:    conv.GetArchived(),
+		}
+		if ts := conv.GetConversationTimestamp(); ts > 0 {
+			lastMessageAt := time.Unix(int64(ts), 0)
+			chat.LastMessageAt = &lastMessageAt
+		}
+		chats = append(chats, chat)
+
+		for _, historyMsg := range conv.GetMessages() {
+			parsed, err := client.ParseWebMessage(chatJID, historyMsg.GetMessage())
+			if err != nil || parsed.Message == nil {
+				summary.Skipped++
+				continue
+			}
+
+			messages = append(messages, buildStoredMessage(sessionID, parsed, model.MessageSourceHistory))
+		}
+	}
+
+	if err := s.messageRepo.UpsertChats(ctx, chats); err != nil {
+		return nil, fmt.Errorf("failed to store chats: %w", err)
+	}
+
+	if err := s.messageRepo.UpsertMessages(ctx, messages); err != nil {
+		return nil, fmt.Errorf("failed to store messages: %w", err)
+	}
+
+	summary.Conversations = len(chats)
+	summary.Messages = len(messages)
+
+	progress := &model.HistorySyncProgress{
+		SessionID:     sessionID,
+		SyncType:      summary.SyncType,
+		ChunkOrder:    summary.ChunkOrder,
+		Progress:      summary.Progress,
+		Conversations: summary.Conversations,
+		Messages:      summary.Messages,
+	}
+	if err := s.messageRepo.AddHistorySyncProgress(ctx, progress); err != nil {
+		return nil, err
+	}
+	summary.Totals = progress
+
+	return summary, nil
+}
+
+// GetProgress retorna o progresso acumulado da sincronização de histórico da sessão
+func (s *HistorySyncService) GetProgress(ctx context.Context, sessionID string) (*model.HistorySyncProgress, error) {
+	return s.messageRepo.GetHistorySyncProgress(ctx, sessionID)
+}
+
+// buildStoredMessage converte um events.Message no modelo persistido
+func buildStoredMessage(sessionID string, evt *events.Message, source model.MessageSource) *model.Message {
+	msg := &model.Message{
+		SessionID: sessionID,
+		ID:        evt.Info.ID,
+		ChatJID:   evt.Info.Chat.String(),
+		SenderJID: evt.Info.Sender.String(),
+		FromMe:    evt.Info.IsFromMe,
+		PushName:  evt.Info.PushName,
+		Type:      ExtractMessageType(evt.Message),
+		Body:      ExtractMessageText(evt.Message),
+		Source:    source,
+		Timestamp: evt.Info.Timestamp,
+	}
+
+	if raw, err := protojson.Marshal(evt.Message); err == nil {
+		var rawMap model.JSONMap
+		if json.Unmarshal(raw, &rawMap) == nil {
+			msg.Raw = rawMap
+		}
+	}
+
+	return msg
+}
+
+// applyHistorySyncConfig ajusta o payload de pareamento conforme o modo de histórico da sessão.
+// As DeviceProps só são enviadas no registro (pareamento), então não têm efeito em sessões já pareadas.
+func applyHistorySyncConfig(client *whatsmeow.Client, config *model.HistorySyncConfig) {
+	if config == nil || config.Mode == "" || config.Mode == model.HistorySyncModeNone {
+		return
+	}
+
+	deviceStore := client.Store
+	client.GetClientPayload = func() *waWa6.ClientPayload {
+		payload := deviceStore.GetClientPayload()
+		if payload.GetDevicePairingData() == nil {
+			return payload
+		}
+
+		props := proto.Clone(store.DeviceProps).(*waCompanionReg.DeviceProps)
+		switch config.Mode {
+		case model.HistorySyncModeFull:
+			props.RequireFullSync = proto.Bool(true)
+		case model.HistorySyncModeRecent:
+			props.RequireFullSync = proto.Bool(false)
+			if config.Days > 0 && props.HistorySyncConfig != nil {
+				props.HistorySyncConfig.RecentSyncDaysLimit = proto.Uint32(uint32(config.Days))
+			}
+		}
+
+		if encoded, err := proto.Marshal(props); err == nil {
+			payload.DevicePairingData.DeviceProps = encoded
+		}
+		return payload
+	}
+}
@@ -0,0 +1,93 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"go.mau.fi/whatsmeow"
+	waProto "go.mau.fi/whatsmeow/binary/proto"
+	"go.mau.fi/whatsmeow/proto/waCompanionReg"
+	"go.mau.fi/whatsmeow/store"
+	"go.mau.fi/whatsmeow/types"
+	"go.mau.fi/whatsmeow/types/events"
+	"go.mau.fi/whatsmeow/util/keys"
+	"google.golang.org/protobuf/proto"
+
+	"zpwoot/internal/model"
+)
+
+// pairingDeviceProps retorna as DeviceProps enviadas no pareamento pelo client
+func pairingDeviceProps(t *testing.T, client *whatsmeow.Client) *waCompanionReg.DeviceProps {
+	t.Helper()
+
+	payload := client.GetClientPayload()
+	props := &waCompanionReg.DeviceProps{}
+	if err := proto.Unmarshal(payload.GetDevicePairingData().GetDeviceProps(), props); err != nil {
+		t.Fatal(err)
+	}
+	return props
+}
+
+func newPairingClient() *whatsmeow.Client {
+	identity := keys.NewKeyPair()
+	device := &store.Device{
+		IdentityKey:  identity,
+		SignedPreKey: identity.CreateSignedPreKey(1),
+	}
+	client := &whatsmeow.Client{Store: device}
+	client.GetClientPayload = device.GetClientPayload
+	return client
+}
+
+func TestApplyHistorySyncConfig(t *testing.T) {
+	client := newPairingClient()
+	applyHistorySyncConfig(client, &model.HistorySyncConfig{Mode: model.HistorySyncModeFull})
+	if props := pairingDeviceProps(t, client); !props.GetRequireFullSync() {
+		t.Errorf("full mode RequireFullSync = false, want true")
+	}
+
+	client = newPairingClient()
+	applyHistorySyncConfig(client, &model.HistorySyncConfig{Mode: model.HistorySyncModeRecent, Days: 7})
+	props := pairingDeviceProps(t, client)
+	if props.GetRequireFullSync() || props.GetHistorySyncConfig().GetRecentSyncDaysLimit() != 7 {
+		t.Errorf("recent mode props = %v, want 7 days without full sync", props)
+	}
+
+	// O padrão global não é alterado pelas sessões
+	if store.DeviceProps.GetRequireFullSync() || store.DeviceProps.GetHistorySyncConfig().GetRecentSyncDaysLimit() == 7 {
+		t.Error("applyHistorySyncConfig modified store.DeviceProps")
+	}
+
+	// Sessões já pareadas enviam o payload de login, sem DeviceProps
+	client = newPairingClient()
+	jid := types.NewJID("5511999999999", types.DefaultUserServer)
+	client.Store.ID = &jid
+	applyHistorySyncConfig(client, &model.HistorySyncConfig{Mode: model.HistorySyncModeFull})
+	if client.GetClientPayload().GetDevicePairingData() != nil {
+		t.Error("login payload has device pairing data")
+	}
+}
+
+func TestBuildStoredMessage(t *testing.T) {
+	chat := types.NewJID("5511999999999", types.DefaultUserServer)
+	evt := &events.Message{
+		Info: types.MessageInfo{
+			MessageSource: types.MessageSource{Chat: chat, Sender: chat, IsFromMe: true},
+			ID:            "ABC",
+			PushName:      "Ana",
+			Timestamp:     time.Unix(1700000000, 0),
+		},
+		Message: &waProto.Message{ImageMessage: &waProto.ImageMessage{Caption: proto.String("foto")}},
+	}
+
+	msg := buildStoredMessage("s1", evt, model.MessageSourceHistory)
+	if msg.SessionID != "s1" || msg.ID != "ABC" || msg.ChatJID != chat.String() || !msg.FromMe {
+		t.Errorf("message = %+v", msg)
+	}
+	if msg.Type != "image" || msg.Body != "foto" || msg.Source != model.MessageSourceHistory {
+		t.Errorf("type/body/source = %s/%s/%s, want image/foto/history", msg.Type, msg.Body, msg.Source)
+	}
+	if _, ok := msg.Raw["imageMessage"]; !ok {
+		t.Errorf("raw = %v, want imageMessage", msg.Raw)
+	}
+}
@@ -0,0 +1,481 @@
+package service
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"zpwoot/internal/model"
+	"zpwoot/pkg/logger"
+)
+
+var ErrInvalidToken = errors.New("invalid token")
+
+// jwksMinRefresh evita buscar o JWKS a cada token com kid desconhecido
+const jwksMinRefresh = time.Minute
+
+// JWTConfig define como os bearer tokens do IdP são validados e mapeados para permissões
+type JWTConfig struct {
+	JWKSURL       string        // URL do JWKS do IdP (ex.: https://idp/.well-known/jwks.json)
+	PublicKey     string        // Chave pública PEM (ou caminho do arquivo) quando não há JWKS
+	Issuer        string        // Valor exigido em iss (vazio = não verifica)
+	Audience      string        // Valor exigido em aud (vazio = não verifica)
+	ScopesClaim   string        // Claim com os escopos (string separada por espaços ou lista)
+	SessionsClaim string        // Claim com as sessões permitidas (ausente = todas)
+	TenantClaim   string        // Claim com o tenant (ausente = servidor)
+	JWKSRefresh   time.Duration // Intervalo de atualização do JWKS
+	Leeway        time.Duration // Tolerância de relógio para exp e nbf
+	Timeout       time.Duration // Timeout da busca do JWKS
+}
+
+// JWTVerifier valida bearer tokens (RS*, PS*, ES* e EdDSA) com a chave pública estática ou com
+// as chaves do JWKS, verifica exp, nbf, iss e aud e converte as claims em uma APIKey com os
+// escopos, o tenant e as sessões permitidas.
+type JWTVerifier struct {
+	config     JWTConfig
+	tenants    *TenantService
+	httpClient *http.Client
+	staticKey  crypto.PublicKey
+
+	mu        sync.RWMutex
+	keys      map[string]crypto.PublicKey // kid -> chave do JWKS
+	fetchedAt time.Time
+}
+
+// NewJWTVerifier cria o verificador; com tenants != nil o tenant da claim precisa existir e estar ativo
+func NewJWTVerifier(config JWTConfig, tenants *TenantService) (*JWTVerifier, error) {
+	if config.JWKSURL == "" && config.PublicKey == "" {
+		return nil, errors.New("JWKS URL or public key is required")
+	}
+	if config.ScopesClaim == "" {
+		config.ScopesClaim = "scope"
+	}
+	if config.SessionsClaim == "" {
+		config.SessionsClaim = "sessions"
+	}
+	if config.JWKSRefresh <= 0 {
+		config.JWKSRefresh = time.Hour
+	}
+	if config.Timeout <= 0 {
+		config.Timeout = 10 * time.Second
+	}
+
+	verifier := &JWTVerifier{
+		config:     config,
+		tenants:    tenants,
+		httpClient: &http.Client{Timeout: config.Timeout},
+	}
+
+	if config.PublicKey != "" {
+		key, err := parsePublicKeyPEM(config.PublicKey)
+		if err != nil {
+			return nil, err
+		}
+		verifier.staticKey = key
+	}
+
+	return verifier, nil
+}
+
+// parsePublicKeyPEM aceita o PEM ou o caminho do arquivo (PUBLIC KEY, RSA PUBLIC KEY ou CERTIFICATE)
+func parsePublicKeyPEM(value string) (crypto.PublicKey, error) {
+	data := []byte(value)
+	if !strings.Contains(value, "-----BEGIN") {
+		fileData, err := os.ReadFile(value)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read JWT public key: %w", err)
+		}
+		data = fileData
+	}
+
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, errors.New("failed to decode JWT public key PEM")
+	}
+
+	switch block.Type {
+	case "RSA PUBLIC KEY":
+		return x509.ParsePKCS1PublicKey(block.Bytes)
+	case "CERTIFICATE":
+		cert, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse JWT certificate: %w", err)
+		}
+		return cert.PublicKey, nil
+	default:
+		key, err := x509.ParsePKIXPublicKey(block.Bytes)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse JWT public key: %w", err)
+		}
+		return key, nil
+	}
+}
+
+type jwtHeader struct {
+	Alg string `json:"alg"`
+	Kid string `json:"kid"`
+}
+
+// VerifyToken valida o token e retorna a APIKey equivalente às suas claims
+func (v *JWTVerifier) VerifyToken(ctx context.Context, token string) (*model.APIKey, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, fmt.Errorf("%w: malformed token", ErrInvalidToken)
+	}
+
+	var header jwtHeader
+	if err := decodeSegment(parts[0], &header); err != nil {
+		return nil, fmt.Errorf("%w: invalid header", ErrInvalidToken)
+	}
+
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, fmt.Errorf("%w: invalid signature encoding", ErrInvalidToken)
+	}
+
+	key, err := v.publicKey(ctx, header.Kid)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := verifySignature(header.Alg, key, []byte(parts[0]+"."+parts[1]), signature); err != nil {
+		return nil, err
+	}
+
+	var claims map[string]interface{}
+	if err := decodeSegment(parts[1], &claims); err != nil {
+		return nil, fmt.Errorf("%w: invalid claims", ErrInvalidToken)
+	}
+
+	if err := v.validateClaims(claims, time.Now()); err != nil {
+		return nil, err
+	}
+
+	principal, err := v.principal(claims)
+	if err != nil {
+		return nil, err
+	}
+
+	// Como nas API keys, tokens de tenants removidos ou desativados não autenticam
+	if principal.TenantID != "" && v.tenants != nil {
+		tenant, err := v.tenants.find(ctx, principal.TenantID)
+		if err != nil {
+			return nil, err
+		}
+		if keyOfActiveTenant(principal, tenant) == nil {
+			return nil, fmt.Errorf("%w: unknown or disabled tenant", ErrInvalidToken)
+		}
+	}
+
+	return principal, nil
+}
+
+func decodeSegment(segment string, v interface{}) error {
+	data, err := base64.RawURLEncoding.DecodeString(segment)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, v)
+}
+
+// publicKey retorna a chave estática ou a chave do JWKS com o kid do token. Um kid desconhecido
+// força a atualização do JWKS (no máximo uma vez por minuto), cobrindo a rotação de chaves do IdP.
+func (v *JWTVerifier) publicKey(ctx context.Context, kid string) (crypto.PublicKey, error) {
+	if v.staticKey != nil {
+		return v.staticKey, nil
+	}
+
+	v.mu.RLock()
+	key, found := v.keys[kid]
+	stale := time.Since(v.fetchedAt) > v.config.JWKSRefresh
+	recent := time.Since(v.fetchedAt) < jwksMinRefresh
+	v.mu.RUnlock()
+
+	if (found && !stale) || (!found && recent) {
+		if !found {
+			return nil, fmt.Errorf("%w: unknown key id %q", ErrInvalidToken, kid)
+		}
+		return key, nil
+	}
+
+	if err := v.refreshKeys(ctx); err != nil {
+		if found {
+			// JWKS indisponível: mantém a chave conhecida até a próxima tentativa
+			logger.Log.Warn().Err(err).Msg("Failed to refresh JWKS, using cached keys")
+			return key, nil
+		}
+		return nil, err
+	}
+
+	v.mu.RLock()
+	key, found = v.keys[kid]
+	v.mu.RUnlock()
+	if !found {
+		return nil, fmt.Errorf("%w: unknown key id %q", ErrInvalidToken, kid)
+	}
+	return key, nil
+}
+
+type jsonWebKey struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+}
+
+func (v *JWTVerifier) refreshKeys(ctx context.Context) error {
+	v.mu.Lock()
+	v.fetchedAt = time.Now()
+	v.mu.Unlock()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, v.config.JWKSURL, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create JWKS request: %w", err)
+	}
+
+	resp, err := v.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to fetch JWKS: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("failed to fetch JWKS: status %d", resp.StatusCode)
+	}
+
+	var jwks struct {
+		Keys []jsonWebKey `json:"keys"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&jwks); err != nil {
+		return fmt.Errorf("failed to decode JWKS: %w", err)
+	}
+
+	keys := make(map[string]crypto.PublicKey, len(jwks.Keys))
+	for _, jwk := range jwks.Keys {
+		if jwk.Use != "" && jwk.Use != "sig" {
+			continue
+		}
+		key, err := jwk.publicKey()
+		if err != nil {
+			logger.Log.Warn().Err(err).Str("kid", jwk.Kid).Msg("Ignoring invalid JWKS key")
+			continue
+		}
+		keys[jwk.Kid] = key
+	}
+
+	v.mu.Lock()
+	v.keys = keys
+	v.mu.Unlock()
+
+	logger.Log.Debug().Int("keys", len(keys)).Msg("JWKS refreshed")
+	return nil
+}
+
+func (k jsonWebKey) publicKey() (crypto.PublicKey, error) {
+	switch k.Kty {
+	case "RSA":
+		n, err := decodeBigInt(k.N)
+		if err != nil {
+			return nil, err
+		}
+		e, err := decodeBigInt(k.E)
+		if err != nil {
+			return nil, err
+		}
+		return &rsa.PublicKey{N: n, E: int(e.Int64())}, nil
+
+	case "EC":
+		var curve elliptic.Curve
+		switch k.Crv {
+		case "P-256":
+			curve = elliptic.P256()
+		case "P-384":
+			curve = elliptic.P384()
+		case "P-521":
+			curve = elliptic.P521()
+		default:
+			return nil, fmt.Errorf("unsupported curve %q", k.Crv)
+		}
+		x, err := decodeBigInt(k.X)
+		if err != nil {
+			return nil, err
+		}
+		y, err := decodeBigInt(k.Y)
+		if err != nil {
+			return nil, err
+		}
+		return &ecdsa.PublicKey{Curve: curve, X: x, Y: y}, nil
+
+	case "OKP":
+		if k.Crv != "Ed25519" {
+			return nil, fmt.Errorf("unsupported curve %q", k.Crv)
+		}
+		x, err := base64.RawURLEncoding.DecodeString(k.X)
+		if err != nil || len(x) != ed25519.PublicKeySize {
+			return nil, errors.New("invalid Ed25519 key")
+		}
+		return ed25519.PublicKey(x), nil
+
+	default:
+		return nil, fmt.Errorf("unsupported key type %q", k.Kty)
+	}
+}
+
+func decodeBigInt(value string) (*big.Int, error) {
+	data, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil || len(data) == 0 {
+		return nil, errors.New("invalid key parameter")
+	}
+	return new(big.Int).SetBytes(data), nil
+}
+
+// verifySignature verifica a assinatura com o algoritmo do header. Algoritmos simétricos (HS*) e
+// "none" não são aceitos: o servidor só conhece chaves públicas.
+func verifySignature(alg string, key crypto.PublicKey, signed, signature []byte) error {
+	var hash crypto.Hash
+	switch alg {
+	case "RS256", "PS256", "ES256":
+		hash = crypto.SHA256
+	case "RS384", "PS384", "ES384":
+		hash = crypto.SHA384
+	case "RS512", "PS512", "ES512":
+		hash = crypto.SHA512
+	case "EdDSA":
+		edKey, ok := key.(ed25519.PublicKey)
+		if !ok || !ed25519.Verify(edKey, signed, signature) {
+			return fmt.Errorf("%w: bad signature", ErrInvalidToken)
+		}
+		return nil
+	default:
+		return fmt.Errorf("%w: unsupported algorithm %q", ErrInvalidToken, alg)
+	}
+
+	h := hash.New()
+	h.Write(signed)
+	digest := h.Sum(nil)
+
+	var valid bool
+	switch alg[:2] {
+	case "RS":
+		rsaKey, ok := key.(*rsa.PublicKey)
+		valid = ok && rsa.VerifyPKCS1v15(rsaKey, hash, digest, signature) == nil
+	case "PS":
+		rsaKey, ok := key.(*rsa.PublicKey)
+		valid = ok && rsa.VerifyPSS(rsaKey, hash, digest, signature, nil) == nil
+	case "ES":
+		ecKey, ok := key.(*ecdsa.PublicKey)
+		if ok {
+			size := (ecKey.Curve.Params().BitSize + 7) / 8
+			if len(signature) == 2*size {
+				r := new(big.Int).SetBytes(signature[:size])
+				s := new(big.Int).SetBytes(signature[size:])
+				valid = ecdsa.Verify(ecKey, digest, r, s)
+			}
+		}
+	}
+
+	if !valid {
+		return fmt.Errorf("%w: bad signature", ErrInvalidToken)
+	}
+	return nil
+}
+
+// validateClaims verifica validade (exp obrigatório, nbf), emissor e audiência
+func (v *JWTVerifier) validateClaims(claims map[string]interface{}, now time.Time) error {
+	exp, ok := claims["exp"].(float64)
+	if !ok {
+		return fmt.Errorf("%w: missing exp", ErrInvalidToken)
+	}
+	if now.After(time.Unix(int64(exp), 0).Add(v.config.Leeway)) {
+		return fmt.Errorf("%w: token expired", ErrInvalidToken)
+	}
+	if nbf, ok := claims["nbf"].(float64); ok && now.Add(v.config.Leeway).Before(time.Unix(int64(nbf), 0)) {
+		return fmt.Errorf("%w: token not valid yet", ErrInvalidToken)
+	}
+
+	if v.config.Issuer != "" {
+		if iss, _ := claims["iss"].(string); iss != v.config.Issuer {
+			return fmt.Errorf("%w: unexpected issuer", ErrInvalidToken)
+		}
+	}
+
+	if v.config.Audience != "" {
+		audiences := claimStrings(claims["aud"])
+		found := false
+		for _, aud := range audiences {
+			if aud == v.config.Audience {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return fmt.Errorf("%w: unexpected audience", ErrInvalidToken)
+		}
+	}
+
+	return nil
+}
+
+// principal converte as claims em escopos, tenant e sessões permitidas. Escopos desconhecidos
+// (ex.: openid, profile) são ignorados.
+func (v *JWTVerifier) principal(claims map[string]interface{}) (*model.APIKey, error) {
+	key := &model.APIKey{Name: "jwt"}
+
+	// Claim presente restringe as sessões, mesmo vazia (nenhuma sessão)
+	if sessions, restricted := claims[v.config.SessionsClaim]; restricted {
+		key.SessionIDs = append([]string{}, claimStrings(sessions)...)
+	}
+
+	if sub, _ := claims["sub"].(string); sub != "" {
+		key.Name = "jwt:" + sub
+	}
+	if v.config.TenantClaim != "" {
+		key.TenantID, _ = claims[v.config.TenantClaim].(string)
+	}
+
+	for _, scope := range claimStrings(claims[v.config.ScopesClaim]) {
+		if model.Scope(scope).IsValid() {
+			key.Scopes = append(key.Scopes, model.Scope(scope))
+		}
+	}
+	if len(key.Scopes) == 0 {
+		return nil, fmt.Errorf("%w: token grants no scopes", ErrInvalidToken)
+	}
+
+	return key, nil
+}
+
+// claimStrings aceita uma string (valores separados por espaço ou vírgula) ou uma lista de strings
+func claimStrings(value interface{}) []string {
+	var values []string
+	switch v := value.(type) {
+	case string:
+		values = strings.FieldsFunc(v, func(r rune) bool { return r == ' ' || r == ',' })
+	case []interface{}:
+		for _, item := range v {
+			if s, ok := item.(string); ok && s != "" {
+				values = append(values, s)
+			}
+		}
+	}
+	return values
+}
@@ -0,0 +1,169 @@
+package service
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+
+	"zpwoot/internal/model"
+)
+
+func signJWT(t *testing.T, header, claims map[string]interface{}, sign func(digest []byte) []byte) string {
+	t.Helper()
+
+	encode := func(v interface{}) string {
+		data, err := json.Marshal(v)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return base64.RawURLEncoding.EncodeToString(data)
+	}
+
+	signed := encode(header) + "." + encode(claims)
+	digest := sha256.Sum256([]byte(signed))
+	return signed + "." + base64.RawURLEncoding.EncodeToString(sign(digest[:]))
+}
+
+func TestJWTVerifierStaticKey(t *testing.T) {
+	privateKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	der, _ := x509.MarshalPKIXPublicKey(&privateKey.PublicKey)
+	publicPEM := pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der})
+
+	verifier, err := NewJWTVerifier(JWTConfig{
+		PublicKey: string(publicPEM),
+		Issuer:    "https://idp.example.com",
+		Audience:  "zpwoot",
+	}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rs256 := func(digest []byte) []byte {
+		sig, err := rsa.SignPKCS1v15(rand.Reader, privateKey, crypto.SHA256, digest)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return sig
+	}
+	claims := func(overrides map[string]interface{}) map[string]interface{} {
+		c := map[string]interface{}{
+			"sub":       "ana@agencia.com",
+			"iss":       "https://idp.example.com",
+			"aud":       []string{"dashboard", "zpwoot"},
+			"exp":       time.Now().Add(time.Hour).Unix(),
+			"scope":     "openid sessions:read",
+			"sessions":  []string{"s1", "s2"},
+			"tenant_id": "t1",
+		}
+		for k, v := range overrides {
+			c[k] = v
+		}
+		return c
+	}
+	header := map[string]interface{}{"alg": "RS256", "typ": "JWT"}
+
+	key, err := verifier.VerifyToken(context.Background(), signJWT(t, header, claims(nil), rs256))
+	if err != nil {
+		t.Fatalf("VerifyToken: %v", err)
+	}
+	if !reflect.DeepEqual(key.Scopes, []model.Scope{model.ScopeSessionsRead}) || key.HasScope(model.ScopeMessagesSend) {
+		t.Errorf("scopes = %v", key.Scopes)
+	}
+	if !reflect.DeepEqual(key.SessionIDs, []string{"s1", "s2"}) || key.TenantID != "" || key.Name != "jwt:ana@agencia.com" {
+		t.Errorf("key = %+v", key)
+	}
+
+	invalid := map[string]string{
+		"expired":        signJWT(t, header, claims(map[string]interface{}{"exp": time.Now().Add(-time.Hour).Unix()}), rs256),
+		"wrong issuer":   signJWT(t, header, claims(map[string]interface{}{"iss": "https://other"}), rs256),
+		"wrong audience": signJWT(t, header, claims(map[string]interface{}{"aud": "other"}), rs256),
+		"no scopes":      signJWT(t, header, claims(map[string]interface{}{"scope": "openid profile"}), rs256),
+		"alg none":       signJWT(t, map[string]interface{}{"alg": "none"}, claims(nil), func([]byte) []byte { return nil }),
+		"alg HS256":      signJWT(t, map[string]interface{}{"alg": "HS256"}, claims(nil), rs256),
+	}
+	tampered := signJWT(t, header, claims(nil), rs256)
+	invalid["tampered"] = tampered[:len(tampered)-4] + "AAAA"
+
+	for name, token := range invalid {
+		if _, err := verifier.VerifyToken(context.Background(), token); !errors.Is(err, ErrInvalidToken) {
+			t.Errorf("%s: VerifyToken error = %v, want ErrInvalidToken", name, err)
+		}
+	}
+}
+
+func TestJWTVerifierJWKS(t *testing.T) {
+	privateKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	fetches := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fetches++
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"keys": []map[string]string{{
+				"kty": "EC",
+				"kid": "key-1",
+				"use": "sig",
+				"crv": "P-256",
+				"x":   base64.RawURLEncoding.EncodeToString(privateKey.X.FillBytes(make([]byte, 32))),
+				"y":   base64.RawURLEncoding.EncodeToString(privateKey.Y.FillBytes(make([]byte, 32))),
+			}},
+		})
+	}))
+	defer server.Close()
+
+	verifier, err := NewJWTVerifier(JWTConfig{JWKSURL: server.URL, TenantClaim: "org"}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	es256 := func(digest []byte) []byte {
+		r, s, err := ecdsa.Sign(rand.Reader, privateKey, digest)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return append(r.FillBytes(make([]byte, 32)), s.FillBytes(make([]byte, 32))...)
+	}
+	claims := map[string]interface{}{
+		"exp":   time.Now().Add(time.Hour).Unix(),
+		"scope": []string{"sessions:read", "messages:send"},
+		"org":   "t1",
+	}
+
+	for i := 0; i < 2; i++ {
+		key, err := verifier.VerifyToken(context.Background(), signJWT(t, map[string]interface{}{"alg": "ES256", "kid": "key-1"}, claims, es256))
+		if err != nil {
+			t.Fatalf("VerifyToken: %v", err)
+		}
+		if key.TenantID != "t1" || !key.HasScope(model.ScopeMessagesSend) || key.SessionIDs != nil {
+			t.Errorf("key = %+v", key)
+		}
+	}
+
+	// Kid desconhecido logo após a busca não dispara outra requisição ao JWKS
+	_, err = verifier.VerifyToken(context.Background(), signJWT(t, map[string]interface{}{"alg": "ES256", "kid": "key-2"}, claims, es256))
+	if !errors.Is(err, ErrInvalidToken) {
+		t.Errorf("unknown kid: error = %v", err)
+	}
+	if fetches != 1 {
+		t.Errorf("JWKS fetched %d times, want 1", fetches)
+	}
+}
@@ -0,0 +1,159 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/twmb/franz-go/pkg/kgo"
+
+	"zpwoot/pkg/logger"
+)
+
+// KafkaSinkConfig configura o producer Kafka
+type KafkaSinkConfig struct {
+	Brokers         []string
+	Topic           string
+	ClientID        string
+	BatchMaxBytes   int32         // Tamanho máximo de um batch por partição
+	Linger          time.Duration // Espera para acumular registros antes de enviar o batch
+	Compression     string        // none, gzip, snappy, lz4 ou zstd
+	Idempotent      bool          // Producer idempotente (sem duplicatas nas novas tentativas)
+	DeliveryTimeout time.Duration // Tempo máximo para um registro ser confirmado
+}
+
+// KafkaSink escreve cada WebhookPayload no tópico configurado, usando o ID da sessão como chave:
+// todos os eventos de uma sessão vão para a mesma partição. A ordem vem do EventSinkWorker, que
+// entrega os eventos de uma sessão um por vez, e do producer, que não reordena nas novas tentativas
+// (idempotente ou, sem idempotência, com uma requisição em andamento por broker).
+type KafkaSink struct {
+	config KafkaSinkConfig
+	client *kgo.Client
+}
+
+func NewKafkaSink(config KafkaSinkConfig) (*KafkaSink, error) {
+	if len(config.Brokers) == 0 {
+		return nil, fmt.Errorf("at least one Kafka broker is required")
+	}
+	if config.Topic == "" {
+		return nil, fmt.Errorf("Kafka topic is required")
+	}
+
+	compression, err := kafkaCompression(config.Compression)
+	if err != nil {
+		return nil, err
+	}
+
+	opts := []kgo.Opt{
+		kgo.SeedBrokers(config.Brokers...),
+		kgo.DefaultProduceTopic(config.Topic),
+		kgo.RequiredAcks(kgo.AllISRAcks()),
+		kgo.ProducerBatchCompression(compression),
+	}
+	if config.ClientID != "" {
+		opts = append(opts, kgo.ClientID(config.ClientID))
+	}
+	if config.BatchMaxBytes > 0 {
+		opts = append(opts, kgo.ProducerBatchMaxBytes(config.BatchMaxBytes))
+	}
+	if config.Linger > 0 {
+		opts = append(opts, kgo.ProducerLinger(config.Linger))
+	}
+	if config.DeliveryTimeout > 0 {
+		opts = append(opts, kgo.RecordDeliveryTimeout(config.DeliveryTimeout))
+	}
+	if !config.Idempotent {
+		// Mais de uma requisição em andamento reordenaria os registros nas novas tentativas
+		opts = append(opts, kgo.DisableIdempotentWrite(), kgo.MaxProduceRequestsInflightPerBroker(1))
+	}
+
+	client, err := kgo.NewClient(opts...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create Kafka client: %w", err)
+	}
+
+	return &KafkaSink{
+		config: config,
+		client: client,
+	}, nil
+}
+
+// kafkaCompression converte o nome do codec da configuração
+func kafkaCompression(name string) (kgo.CompressionCodec, error) {
+	switch strings.ToLower(name) {
+	case "", "none":
+		return kgo.NoCompression(), nil
+	case "gzip":
+		return kgo.GzipCompression(), nil
+	case "snappy":
+		return kgo.SnappyCompression(), nil
+	case "lz4":
+		return kgo.Lz4Compression(), nil
+	case "zstd":
+		return kgo.ZstdCompression(), nil
+	default:
+		return kgo.CompressionCodec{}, fmt.Errorf("unsupported Kafka compression: %s", name)
+	}
+}
+
+func (s *KafkaSink) Name() string {
+	return "kafka"
+}
+
+// Ping verifica se algum broker responde
+func (s *KafkaSink) Ping(ctx context.Context) error {
+	return s.client.Ping(ctx)
+}
+
+// Publish enfileira o registro no batch da partição da sessão. O envio e as novas tentativas
+// ficam a cargo do client; falhas definitivas (ex: DeliveryTimeout) são registradas no log.
+func (s *KafkaSink) Publish(ctx context.Context, event StreamEvent) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	value, err := json.Marshal(event.Payload)
+	if err != nil {
+		return fmt.Errorf("failed to marshal event: %w", err)
+	}
+
+	record := &kgo.Record{
+		Key:       []byte(event.SessionID),
+		Value:     value,
+		Timestamp: event.Payload.Timestamp,
+		Headers: []kgo.RecordHeader{
+			{Key: "event", Value: []byte(event.Event)},
+			{Key: "event_id", Value: []byte(fmt.Sprintf("%d", event.ID))},
+		},
+	}
+
+	// O contexto do registro não pode ser o da chamada: cancelá-lo descartaria o batch
+	s.client.Produce(context.Background(), record, func(r *kgo.Record, err error) {
+		if err != nil {
+			logger.Log.Error().
+				Err(err).
+				Str(logger.FieldSessionID, event.SessionID).
+				Str(logger.FieldEvent, event.Event).
+				Int64("event_id", event.ID).
+				Str("topic", s.config.Topic).
+				Msg("❌ Failed to produce event to Kafka")
+		}
+	})
+
+	return nil
+}
+
+// Close envia os registros pendentes antes de fechar o client
+func (s *KafkaSink) Close() error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	err := s.client.Flush(ctx)
+	s.client.Close()
+	if err != nil {
+		return fmt.Errorf("failed to flush Kafka producer: %w", err)
+	}
+	return nil
+}
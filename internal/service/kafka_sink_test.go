@@ -0,0 +1,33 @@
+package service
+
+import "testing"
+
+func TestKafkaCompression(t *testing.T) {
+	for _, name := range []string{"", "none", "gzip", "snappy", "LZ4", "zstd"} {
+		if _, err := kafkaCompression(name); err != nil {
+			t.Errorf("kafkaCompression(%q) error = %v", name, err)
+		}
+	}
+
+	if _, err := kafkaCompression("brotli"); err == nil {
+		t.Error("kafkaCompression(\"brotli\") expected error")
+	}
+}
+
+func TestNewKafkaSinkValidation(t *testing.T) {
+	if _, err := NewKafkaSink(KafkaSinkConfig{Topic: "events"}); err == nil {
+		t.Error("expected error without brokers")
+	}
+	if _, err := NewKafkaSink(KafkaSinkConfig{Brokers: []string{"localhost:9092"}}); err == nil {
+		t.Error("expected error without topic")
+	}
+	if _, err := NewKafkaSink(KafkaSinkConfig{Brokers: []string{"localhost:9092"}, Topic: "events", Compression: "brotli"}); err == nil {
+		t.Error("expected error with unsupported compression")
+	}
+
+	sink, err := NewKafkaSink(KafkaSinkConfig{Brokers: []string{"localhost:9092"}, Topic: "events", Idempotent: true})
+	if err != nil {
+		t.Fatalf("NewKafkaSink() error = %v", err)
+	}
+	sink.client.Close()
+}
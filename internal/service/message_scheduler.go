@@ -0,0 +1,271 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	"zpwoot/internal/model"
+	"zpwoot/internal/repository"
+	"zpwoot/pkg/logger"
+)
+
+// Tentativas de gravar o resultado de um agendamento antes de desistir
+const scheduleSaveAttempts = 3
+
+// ErrInvalidSendAt indica horário ou fuso de agendamento inválido
+var ErrInvalidSendAt = errors.New("invalid schedule time")
+
+// Formatos aceitos para horários sem offset, interpretados no fuso informado
+var scheduleLocalLayouts = []string{
+	"2006-01-02T15:04:05",
+	"2006-01-02T15:04",
+	"2006-01-02 15:04:05",
+	"2006-01-02 15:04",
+}
+
+// ParseSendAt interpreta o horário de envio. Horários com offset (RFC3339) são usados como estão;
+// horários locais são interpretados no fuso IANA informado (ou defaultTimezone).
+func ParseSendAt(value, timezone, defaultTimezone string) (time.Time, string, error) {
+	if timezone == "" {
+		timezone = defaultTimezone
+	}
+	if timezone == "" {
+		timezone = "UTC"
+	}
+
+	location, err := time.LoadLocation(timezone)
+	if err != nil {
+		return time.Time{}, "", fmt.Errorf("%w: unknown timezone %s", ErrInvalidSendAt, timezone)
+	}
+
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, timezone, nil
+	}
+
+	for _, layout := range scheduleLocalLayouts {
+		if t, err := time.ParseInLocation(layout, value, location); err == nil {
+			return t, timezone, nil
+		}
+	}
+
+	return time.Time{}, "", fmt.Errorf("%w: %s (use RFC3339 or YYYY-MM-DDTHH:MM:SS)", ErrInvalidSendAt, value)
+}
+
+// MessageSchedulerConfig define o intervalo de varredura e o tamanho dos lotes do scheduler
+type MessageSchedulerConfig struct {
+	Interval        time.Duration // Intervalo entre varreduras de agendamentos vencidos
+	BatchSize       int           // Agendamentos reservados por varredura
+	StaleAfter      time.Duration // Reservas processing mais antigas que isso são retomadas
+	DefaultTimezone string        // Fuso usado quando a requisição não informa timezone
+}
+
+// MessageScheduler guarda envios agendados no Postgres e, no horário, os entrega à fila de saída.
+// Cada réplica varre a tabela periodicamente e reserva os vencidos com FOR UPDATE SKIP LOCKED,
+// então várias réplicas podem rodar ao mesmo tempo sem envio duplicado.
+type MessageScheduler struct {
+	scheduleRepo *repository.ScheduleRepository
+	queue        *OutboundQueue
+	config       MessageSchedulerConfig
+
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+func NewMessageScheduler(scheduleRepo *repository.ScheduleRepository, queue *OutboundQueue, config MessageSchedulerConfig) *MessageScheduler {
+	if config.Interval <= 0 {
+		config.Interval = 5 * time.Second
+	}
+	if config.BatchSize <= 0 {
+		config.BatchSize = 100
+	}
+	if config.StaleAfter <= 0 {
+		config.StaleAfter = 5 * time.Minute
+	}
+	if config.DefaultTimezone == "" {
+		config.DefaultTimezone = "UTC"
+	}
+
+	return &MessageScheduler{
+		scheduleRepo: scheduleRepo,
+		queue:        queue,
+		config:       config,
+	}
+}
+
+func (s *MessageScheduler) Start() {
+	ctx, cancel := context.WithCancel(context.Background())
+	s.cancel = cancel
+
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		s.run(ctx)
+	}()
+
+	logger.Log.Info().
+		Dur("interval", s.config.Interval).
+		Msg("✅ Message scheduler started")
+}
+
+func (s *MessageScheduler) Stop() {
+	if s.cancel != nil {
+		s.cancel()
+	}
+	s.wg.Wait()
+}
+
+// Schedule valida o corpo e grava o agendamento
+func (s *MessageScheduler) Schedule(ctx context.Context, sessionID, kind string, payload []byte, sendAt, timezone string) (*model.ScheduledMessage, error) {
+	phone, err := s.queue.Validate(kind, payload)
+	if err != nil {
+		return nil, err
+	}
+
+	at, timezone, err := ParseSendAt(sendAt, timezone, s.config.DefaultTimezone)
+	if err != nil {
+		return nil, err
+	}
+
+	schedule := &model.ScheduledMessage{
+		ID:        uuid.New().String(),
+		SessionID: sessionID,
+		Type:      kind,
+		Phone:     phone,
+		Payload:   payload,
+		SendAt:    at,
+		Timezone:  timezone,
+		Status:    model.ScheduleStatusScheduled,
+	}
+
+	if err := s.scheduleRepo.Create(ctx, schedule); err != nil {
+		return nil, err
+	}
+
+	logger.Log.Info().
+		Str("session_id", sessionID).
+		Str("schedule_id", schedule.ID).
+		Time("send_at", schedule.SendAt).
+		Msg("Message scheduled")
+
+	return schedule, nil
+}
+
+// Reschedule altera o horário de um agendamento ainda não executado
+func (s *MessageScheduler) Reschedule(ctx context.Context, sessionID, id, sendAt, timezone string) (*model.ScheduledMessage, error) {
+	at, timezone, err := ParseSendAt(sendAt, timezone, s.config.DefaultTimezone)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.scheduleRepo.Reschedule(ctx, sessionID, id, at, timezone)
+}
+
+func (s *MessageScheduler) Cancel(ctx context.Context, sessionID, id string) (*model.ScheduledMessage, error) {
+	return s.scheduleRepo.Cancel(ctx, sessionID, id)
+}
+
+func (s *MessageScheduler) Get(ctx context.Context, sessionID, id string) (*model.ScheduledMessage, error) {
+	return s.scheduleRepo.GetByID(ctx, sessionID, id)
+}
+
+func (s *MessageScheduler) List(ctx context.Context, sessionID string, status model.ScheduleStatus) ([]*model.ScheduledMessage, error) {
+	return s.scheduleRepo.List(ctx, sessionID, status)
+}
+
+func (s *MessageScheduler) run(ctx context.Context) {
+	ticker := time.NewTicker(s.config.Interval)
+	defer ticker.Stop()
+
+	for {
+		s.processDue(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// processDue reserva os agendamentos vencidos e os entrega à fila de saída
+func (s *MessageScheduler) processDue(ctx context.Context) {
+	for {
+		schedules, err := s.scheduleRepo.ClaimDue(ctx, s.config.BatchSize, s.config.StaleAfter)
+		if err != nil {
+			if ctx.Err() == nil {
+				logger.Log.Error().Err(err).Msg("Failed to claim scheduled messages")
+			}
+			return
+		}
+
+		for _, schedule := range schedules {
+			s.execute(ctx, schedule)
+		}
+
+		// Lote incompleto: não há mais agendamentos vencidos
+		if len(schedules) < s.config.BatchSize {
+			return
+		}
+	}
+}
+
+func (s *MessageScheduler) execute(ctx context.Context, schedule *model.ScheduledMessage) {
+	now := time.Now()
+	schedule.ExecutedAt = &now
+
+	// O ID do job vem do agendamento: se o resultado não for gravado e o agendamento for retomado
+	// após StaleAfter, o job já criado é reaproveitado em vez de enviar a mensagem de novo
+	job, err := s.queue.EnqueueOnce(ctx, scheduleJobID(schedule.ID), schedule.SessionID, schedule.Type, schedule.Payload)
+	if err != nil {
+		schedule.Status = model.ScheduleStatusFailed
+		schedule.Error = err.Error()
+
+		logger.Log.Error().
+			Err(err).
+			Str("session_id", schedule.SessionID).
+			Str("schedule_id", schedule.ID).
+			Msg("❌ Failed to execute scheduled message")
+	} else {
+		schedule.Status = model.ScheduleStatusQueued
+		schedule.JobID = job.ID
+
+		logger.Log.Info().
+			Str("session_id", schedule.SessionID).
+			Str("schedule_id", schedule.ID).
+			Str("job_id", job.ID).
+			Msg("Scheduled message queued")
+	}
+
+	s.saveResult(schedule)
+}
+
+// saveResult grava o resultado do agendamento, tentando de novo em falhas temporárias do banco
+func (s *MessageScheduler) saveResult(schedule *model.ScheduledMessage) {
+	for attempt := 1; ; attempt++ {
+		err := s.scheduleRepo.UpdateResult(context.Background(), schedule)
+		if err == nil {
+			return
+		}
+		if attempt == scheduleSaveAttempts {
+			logger.Log.Error().Err(err).Str("schedule_id", schedule.ID).Msg("Failed to save schedule result")
+			return
+		}
+
+		logger.Log.Warn().
+			Err(err).
+			Str("schedule_id", schedule.ID).
+			Int(logger.FieldAttempt, attempt).
+			Msg("Failed to save schedule result, retrying")
+		time.Sleep(time.Duration(attempt) * time.Second)
+	}
+}
+
+// scheduleJobID deriva o ID do job do agendamento (UUID v5), igual em todas as execuções
+func scheduleJobID(scheduleID string) string {
+	return uuid.NewSHA1(uuid.NameSpaceURL, []byte("zpwoot:schedule:"+scheduleID)).String()
+}
@@ -0,0 +1,61 @@
+package service
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func TestParseSendAt(t *testing.T) {
+	tests := []struct {
+		name     string
+		value    string
+		timezone string
+		want     time.Time
+		wantTZ   string
+	}{
+		{"local time in timezone", "2025-11-20T09:00:00", "America/Sao_Paulo", time.Date(2025, 11, 20, 12, 0, 0, 0, time.UTC), "America/Sao_Paulo"},
+		{"local time without seconds", "2025-11-20 09:00", "America/Sao_Paulo", time.Date(2025, 11, 20, 12, 0, 0, 0, time.UTC), "America/Sao_Paulo"},
+		{"default timezone", "2025-11-20T09:00:00", "", time.Date(2025, 11, 20, 9, 0, 0, 0, time.UTC), "UTC"},
+		{"explicit offset wins", "2025-11-20T09:00:00-05:00", "America/Sao_Paulo", time.Date(2025, 11, 20, 14, 0, 0, 0, time.UTC), "America/Sao_Paulo"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, tz, err := ParseSendAt(tt.value, tt.timezone, "UTC")
+			if err != nil {
+				t.Fatalf("ParseSendAt() error = %v", err)
+			}
+			if !got.Equal(tt.want) {
+				t.Errorf("ParseSendAt() = %v, want %v", got.UTC(), tt.want)
+			}
+			if tz != tt.wantTZ {
+				t.Errorf("ParseSendAt() timezone = %q, want %q", tz, tt.wantTZ)
+			}
+		})
+	}
+}
+
+func TestParseSendAtInvalid(t *testing.T) {
+	if _, _, err := ParseSendAt("2025-11-20T09:00:00", "Mars/Olympus", "UTC"); !errors.Is(err, ErrInvalidSendAt) {
+		t.Errorf("expected ErrInvalidSendAt for unknown timezone, got %v", err)
+	}
+	if _, _, err := ParseSendAt("amanhã às 9", "UTC", "UTC"); !errors.Is(err, ErrInvalidSendAt) {
+		t.Errorf("expected ErrInvalidSendAt for invalid time, got %v", err)
+	}
+}
+
+func TestScheduleJobID(t *testing.T) {
+	// Execuções repetidas do mesmo agendamento usam o mesmo job
+	if scheduleJobID("sched-1") != scheduleJobID("sched-1") {
+		t.Error("scheduleJobID is not deterministic")
+	}
+	if scheduleJobID("sched-1") == scheduleJobID("sched-2") {
+		t.Error("different schedules got the same job ID")
+	}
+	if _, err := uuid.Parse(scheduleJobID("sched-1")); err != nil {
+		t.Errorf("job ID is not a UUID: %v", err)
+	}
+}
@@ -34,6 +34,64 @@ func parseJID(phone string) (types.JID, error) {
 	return types.NewJID(cleaned, types.DefaultUserServer), nil
 }
 
+// ExtractMessageText retorna o texto (ou legenda) de uma mensagem, se houver
+func ExtractMessageText(msg *waProto.Message) string {
+	if msg == nil {
+		return ""
+	}
+
+	switch {
+	case msg.GetConversation() != "":
+		return msg.GetConversation()
+	case msg.GetExtendedTextMessage() != nil:
+		return msg.GetExtendedTextMessage().GetText()
+	case msg.GetImageMessage() != nil:
+		return msg.GetImageMessage().GetCaption()
+	case msg.GetVideoMessage() != nil:
+		return msg.GetVideoMessage().GetCaption()
+	case msg.GetDocumentMessage() != nil:
+		return msg.GetDocumentMessage().GetCaption()
+	}
+
+	return ""
+}
+
+// ExtractMessageType retorna o tipo da mensagem (mesma nomenclatura do webhook de mensagem)
+func ExtractMessageType(msg *waProto.Message) string {
+	if msg == nil {
+		return "unknown"
+	}
+
+	switch {
+	case msg.Conversation != nil:
+		return "conversation"
+	case msg.ExtendedTextMessage != nil:
+		return "extended_text"
+	case msg.ImageMessage != nil:
+		return "image"
+	case msg.VideoMessage != nil:
+		return "video"
+	case msg.AudioMessage != nil:
+		return "audio"
+	case msg.DocumentMessage != nil:
+		return "document"
+	case msg.StickerMessage != nil:
+		return "sticker"
+	case msg.ContactMessage != nil, msg.ContactsArrayMessage != nil:
+		return "contact"
+	case msg.LocationMessage != nil:
+		return "location"
+	case msg.ReactionMessage != nil:
+		return "reaction"
+	case msg.PollCreationMessage != nil:
+		return "poll"
+	case msg.ProtocolMessage != nil:
+		return "protocol"
+	}
+
+	return "unknown"
+}
+
 // buildImageMessage cria uma mensagem de imagem
 func buildImageMessage(uploaded whatsmeow.UploadResponse, imageData []byte, caption, mimeType string) *waProto.Message {
 	return &waProto.Message{
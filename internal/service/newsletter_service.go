@@ -0,0 +1,265 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"go.mau.fi/whatsmeow"
+	waProto "go.mau.fi/whatsmeow/binary/proto"
+	"go.mau.fi/whatsmeow/types"
+	"google.golang.org/protobuf/proto"
+
+	"zpwoot/pkg/logger"
+)
+
+// parseNewsletterJID valida e converte um JID de canal (xxx@newsletter)
+func parseNewsletterJID(jid string) (types.JID, error) {
+	jid = strings.TrimSpace(jid)
+	if jid == "" {
+		return types.JID{}, fmt.Errorf("newsletter JID is required")
+	}
+
+	// Aceitar apenas o ID numérico, completando o servidor
+	if !strings.Contains(jid, "@") {
+		jid = jid + "@" + types.NewsletterServer
+	}
+
+	parsed, err := types.ParseJID(jid)
+	if err != nil {
+		return types.JID{}, fmt.Errorf("invalid newsletter JID: %w", err)
+	}
+
+	if parsed.Server != types.NewsletterServer {
+		return types.JID{}, fmt.Errorf("JID %s is not a newsletter", jid)
+	}
+
+	return parsed, nil
+}
+
+func (m *SessionManager) ListNewsletters(ctx context.Context, client *whatsmeow.Client) ([]*types.NewsletterMetadata, error) {
+	newsletters, err := client.GetSubscribedNewsletters(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list newsletters: %w", err)
+	}
+
+	return newsletters, nil
+}
+
+func (m *SessionManager) GetNewsletterInfo(ctx context.Context, client *whatsmeow.Client, jid string) (*types.NewsletterMetadata, error) {
+	newsletterJID, err := parseNewsletterJID(jid)
+	if err != nil {
+		return nil, err
+	}
+
+	info, err := client.GetNewsletterInfo(ctx, newsletterJID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get newsletter info: %w", err)
+	}
+
+	return info, nil
+}
+
+func (m *SessionManager) GetNewsletterInfoWithInvite(ctx context.Context, client *whatsmeow.Client, invite string) (*types.NewsletterMetadata, error) {
+	invite = strings.TrimSpace(invite)
+	if invite == "" {
+		return nil, fmt.Errorf("invite is required")
+	}
+
+	info, err := client.GetNewsletterInfoWithInvite(ctx, invite)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get newsletter info: %w", err)
+	}
+
+	return info, nil
+}
+
+func (m *SessionManager) FollowNewsletter(ctx context.Context, client *whatsmeow.Client, jid string) error {
+	newsletterJID, err := parseNewsletterJID(jid)
+	if err != nil {
+		return err
+	}
+
+	if err := client.FollowNewsletter(ctx, newsletterJID); err != nil {
+		return fmt.Errorf("failed to follow newsletter: %w", err)
+	}
+
+	logger.Log.Info().Str("newsletter", newsletterJID.String()).Msg("Newsletter followed")
+	return nil
+}
+
+func (m *SessionManager) UnfollowNewsletter(ctx context.Context, client *whatsmeow.Client, jid string) error {
+	newsletterJID, err := parseNewsletterJID(jid)
+	if err != nil {
+		return err
+	}
+
+	if err := client.UnfollowNewsletter(ctx, newsletterJID); err != nil {
+		return fmt.Errorf("failed to unfollow newsletter: %w", err)
+	}
+
+	logger.Log.Info().Str("newsletter", newsletterJID.String()).Msg("Newsletter unfollowed")
+	return nil
+}
+
+func (m *SessionManager) MuteNewsletter(ctx context.Context, client *whatsmeow.Client, jid string, mute bool) error {
+	newsletterJID, err := parseNewsletterJID(jid)
+	if err != nil {
+		return err
+	}
+
+	if err := client.NewsletterToggleMute(ctx, newsletterJID, mute); err != nil {
+		return fmt.Errorf("failed to change newsletter mute: %w", err)
+	}
+
+	logger.Log.Info().Str("newsletter", newsletterJID.String()).Bool("mute", mute).Msg("Newsletter mute changed")
+	return nil
+}
+
+func (m *SessionManager) CreateNewsletter(ctx context.Context, client *whatsmeow.Client, name, description, pictureURL string) (*types.NewsletterMetadata, error) {
+	params := whatsmeow.CreateNewsletterParams{
+		Name:        name,
+		Description: description,
+	}
+
+	if pictureURL != "" {
+		picture, _, err := downloadOrDecodeMedia(pictureURL)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get picture: %w", err)
+		}
+		params.Picture = picture
+	}
+
+	info, err := client.CreateNewsletter(ctx, params)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create newsletter: %w", err)
+	}
+
+	logger.Log.Info().Str("newsletter", info.ID.String()).Str("name", name).Msg("Newsletter created")
+	return info, nil
+}
+
+func (m *SessionManager) SendNewsletterText(ctx context.Context, client *whatsmeow.Client, jid string, text string) (string, time.Time, error) {
+	newsletterJID, err := parseNewsletterJID(jid)
+	if err != nil {
+		return "", time.Time{}, err
+	}
+
+	msg := &waProto.Message{
+		Conversation: proto.String(text),
+	}
+
+	resp, err := client.SendMessage(ctx, newsletterJID, msg)
+	if err != nil {
+		return "", time.Time{}, fmt.Errorf("failed to send newsletter message: %w", err)
+	}
+
+	logger.Log.Info().Str("message_id", resp.ID).Str("newsletter", newsletterJID.String()).Msg("Newsletter text sent")
+	return resp.ID, resp.Timestamp, nil
+}
+
+// SendNewsletterMedia publica imagem, vídeo ou documento em um canal.
+// Mídias de canais não são criptografadas, então usam UploadNewsletter e o MediaHandle no envio.
+func (m *SessionManager) SendNewsletterMedia(ctx context.Context, client *whatsmeow.Client, jid string, mediaURL string, caption string, fileName string) (string, time.Time, error) {
+	newsletterJID, err := parseNewsletterJID(jid)
+	if err != nil {
+		return "", time.Time{}, err
+	}
+
+	data, mimeType, err := downloadOrDecodeMedia(mediaURL)
+	if err != nil {
+		return "", time.Time{}, fmt.Errorf("failed to get media: %w", err)
+	}
+
+	mediaType := whatsmeow.MediaDocument
+	switch {
+	case strings.HasPrefix(mimeType, "image/"):
+		mediaType = whatsmeow.MediaImage
+	case strings.HasPrefix(mimeType, "video/"):
+		mediaType = whatsmeow.MediaVideo
+	}
+
+	uploaded, err := client.UploadNewsletter(ctx, data, mediaType)
+	if err != nil {
+		return "", time.Time{}, fmt.Errorf("failed to upload media: %w", err)
+	}
+
+	var msg *waProto.Message
+	switch mediaType {
+	case whatsmeow.MediaImage:
+		msg = &waProto.Message{
+			ImageMessage: &waProto.ImageMessage{
+				Caption:    proto.String(caption),
+				Mimetype:   proto.String(mimeType),
+				URL:        proto.String(uploaded.URL),
+				DirectPath: proto.String(uploaded.DirectPath),
+				FileSHA256: uploaded.FileSHA256,
+				FileLength: proto.Uint64(uploaded.FileLength),
+			},
+		}
+	case whatsmeow.MediaVideo:
+		msg = &waProto.Message{
+			VideoMessage: &waProto.VideoMessage{
+				Caption:    proto.String(caption),
+				Mimetype:   proto.String(mimeType),
+				URL:        proto.String(uploaded.URL),
+				DirectPath: proto.String(uploaded.DirectPath),
+				FileSHA256: uploaded.FileSHA256,
+				FileLength: proto.Uint64(uploaded.FileLength),
+			},
+		}
+	default:
+		if fileName == "" {
+			fileName = "document"
+		}
+		msg = &waProto.Message{
+			DocumentMessage: &waProto.DocumentMessage{
+				Caption:    proto.String(caption),
+				FileName:   proto.String(fileName),
+				Mimetype:   proto.String(mimeType),
+				URL:        proto.String(uploaded.URL),
+				DirectPath: proto.String(uploaded.DirectPath),
+				FileSHA256: uploaded.FileSHA256,
+				FileLength: proto.Uint64(uploaded.FileLength),
+			},
+		}
+	}
+
+	resp, err := client.SendMessage(ctx, newsletterJID, msg, whatsmeow.SendRequestExtra{
+		MediaHandle: uploaded.Handle,
+	})
+	if err != nil {
+		return "", time.Time{}, fmt.Errorf("failed to send newsletter media: %w", err)
+	}
+
+	logger.Log.Info().
+		Str("message_id", resp.ID).
+		Str("newsletter", newsletterJID.String()).
+		Int("size", len(data)).
+		Str("mime", mimeType).
+		Msg("Newsletter media sent")
+
+	return resp.ID, resp.Timestamp, nil
+}
+
+func (m *SessionManager) GetNewsletterMessages(ctx context.Context, client *whatsmeow.Client, jid string, count int, before int) ([]*types.NewsletterMessage, error) {
+	newsletterJID, err := parseNewsletterJID(jid)
+	if err != nil {
+		return nil, err
+	}
+
+	if count <= 0 {
+		count = 20
+	}
+
+	messages, err := client.GetNewsletterMessages(ctx, newsletterJID, &whatsmeow.GetNewsletterMessagesParams{
+		Count:  count,
+		Before: types.MessageServerID(before),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get newsletter messages: %w", err)
+	}
+
+	return messages, nil
+}
@@ -0,0 +1,75 @@
+package service
+
+import (
+	"testing"
+
+	"go.mau.fi/whatsmeow/types"
+	"go.mau.fi/whatsmeow/types/events"
+
+	"zpwoot/internal/constants"
+)
+
+func TestParseNewsletterJID(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    string
+		wantErr bool
+	}{
+		{"120363144038483540@newsletter", "120363144038483540@newsletter", false},
+		{" 120363144038483540 ", "120363144038483540@newsletter", false},
+		{"5511999999999@s.whatsapp.net", "", true},
+		{"120363025246125486@g.us", "", true},
+		{"", "", true},
+	}
+
+	for _, tt := range tests {
+		got, err := parseNewsletterJID(tt.input)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("parseNewsletterJID(%q) error = %v, wantErr %v", tt.input, err, tt.wantErr)
+			continue
+		}
+		if !tt.wantErr && got.String() != tt.want {
+			t.Errorf("parseNewsletterJID(%q) = %s, want %s", tt.input, got, tt.want)
+		}
+	}
+}
+
+func TestFormatNewsletterEvents(t *testing.T) {
+	f := NewWebhookFormatter()
+	jid := types.NewJID("120363144038483540", types.NewsletterServer)
+
+	join := &events.NewsletterJoin{NewsletterMetadata: types.NewsletterMetadata{
+		ID: jid,
+		ThreadMeta: types.NewsletterThreadMetadata{
+			Name:            types.NewsletterText{Text: "Avisos"},
+			InviteCode:      "abc",
+			SubscriberCount: 42,
+		},
+		ViewerMeta: &types.NewsletterViewerMetadata{Role: types.NewsletterRoleSubscriber, Mute: types.NewsletterMuteOn},
+	}}
+	payload := f.FormatNewsletterJoin("s1", join)
+	if payload.Event != string(constants.EventNewsletterJoin) || payload.SessionID != "s1" {
+		t.Errorf("join payload = %+v", payload)
+	}
+	if payload.Data["jid"] != jid.String() || payload.Data["name"] != "Avisos" || payload.Data["subscriber_count"] != 42 ||
+		payload.Data["role"] != "subscriber" || payload.Data["mute"] != "on" {
+		t.Errorf("join data = %v", payload.Data)
+	}
+
+	// Sem ViewerMeta não há role/mute
+	join.ViewerMeta = nil
+	if payload := f.FormatNewsletterJoin("s1", join); payload.Data["role"] != nil {
+		t.Errorf("join without viewer meta data = %v", payload.Data)
+	}
+
+	// Mensagens nulas da atualização são ignoradas
+	update := &events.NewsletterLiveUpdate{JID: jid, Messages: []*types.NewsletterMessage{
+		{MessageServerID: 10, MessageID: "m1", ViewsCount: 5},
+		nil,
+	}}
+	payload = f.FormatNewsletterLiveUpdate("s1", update)
+	messages, ok := payload.Data["messages"].([]map[string]interface{})
+	if !ok || len(messages) != 1 || messages[0]["message_id"] != types.MessageID("m1") || messages[0]["views_count"] != 5 {
+		t.Errorf("live update messages = %v", payload.Data["messages"])
+	}
+}
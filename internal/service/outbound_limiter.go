@@ -0,0 +1,140 @@
+package service
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"golang.org/x/time/rate"
+
+	"zpwoot/internal/model"
+)
+
+// OutboundLimiter controla o ritmo de envio de cada sessão: um token bucket
+// (mensagens/minuto + burst) e um intervalo mínimo entre envios ao mesmo destinatário.
+type OutboundLimiter struct {
+	defaults model.RateLimitConfig
+
+	mu     sync.Mutex
+	pacers map[string]*sessionPacer
+}
+
+type sessionPacer struct {
+	limiter  *rate.Limiter
+	config   model.RateLimitConfig
+	lastSent map[string]time.Time // Último envio por destinatário
+}
+
+func NewOutboundLimiter(defaults model.RateLimitConfig) *OutboundLimiter {
+	return &OutboundLimiter{
+		defaults: defaults,
+		pacers:   make(map[string]*sessionPacer),
+	}
+}
+
+// effectiveConfig aplica os padrões do servidor aos campos não definidos na sessão
+func (l *OutboundLimiter) effectiveConfig(override *model.RateLimitConfig) model.RateLimitConfig {
+	config := l.defaults
+	if override != nil {
+		if override.MessagesPerMinute > 0 {
+			config.MessagesPerMinute = override.MessagesPerMinute
+		}
+		if override.Burst > 0 {
+			config.Burst = override.Burst
+		}
+		if override.RecipientSpacing > 0 {
+			config.RecipientSpacing = override.RecipientSpacing
+		}
+	}
+	if config.Burst <= 0 {
+		config.Burst = 1
+	}
+	return config
+}
+
+func limitFor(config model.RateLimitConfig) rate.Limit {
+	if config.MessagesPerMinute <= 0 {
+		return rate.Inf
+	}
+	return rate.Limit(float64(config.MessagesPerMinute) / 60)
+}
+
+func (l *OutboundLimiter) pacer(sessionID string, override *model.RateLimitConfig) *sessionPacer {
+	config := l.effectiveConfig(override)
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	p, ok := l.pacers[sessionID]
+	if !ok {
+		p = &sessionPacer{
+			limiter:  rate.NewLimiter(limitFor(config), config.Burst),
+			config:   config,
+			lastSent: make(map[string]time.Time),
+		}
+		l.pacers[sessionID] = p
+		return p
+	}
+
+	// Configuração da sessão alterada: ajusta o bucket sem perder os tokens atuais
+	if p.config != config {
+		p.limiter.SetLimit(limitFor(config))
+		p.limiter.SetBurst(config.Burst)
+		p.config = config
+	}
+
+	return p
+}
+
+// Wait bloqueia até o envio para phone ser permitido (ou ctx ser cancelado)
+func (l *OutboundLimiter) Wait(ctx context.Context, sessionID, phone string, override *model.RateLimitConfig) error {
+	p := l.pacer(sessionID, override)
+
+	if spacing := time.Duration(p.config.RecipientSpacing) * time.Second; spacing > 0 {
+		l.mu.Lock()
+		last, ok := p.lastSent[phone]
+		l.mu.Unlock()
+
+		if ok {
+			if wait := time.Until(last.Add(spacing)); wait > 0 {
+				select {
+				case <-ctx.Done():
+					return ctx.Err()
+				case <-time.After(wait):
+				}
+			}
+		}
+	}
+
+	return p.limiter.Wait(ctx)
+}
+
+// MarkSent registra o envio para o intervalo por destinatário
+func (l *OutboundLimiter) MarkSent(sessionID, phone string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	p, ok := l.pacers[sessionID]
+	if !ok {
+		return
+	}
+
+	now := time.Now()
+	spacing := time.Duration(p.config.RecipientSpacing) * time.Second
+
+	// Remove destinatários cujo intervalo já passou para o mapa não crescer indefinidamente
+	for recipient, last := range p.lastSent {
+		if now.Sub(last) >= spacing {
+			delete(p.lastSent, recipient)
+		}
+	}
+
+	if spacing > 0 {
+		p.lastSent[phone] = now
+	}
+}
+
+// Config retorna a configuração efetiva da sessão
+func (l *OutboundLimiter) Config(override *model.RateLimitConfig) model.RateLimitConfig {
+	return l.effectiveConfig(override)
+}
@@ -0,0 +1,49 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"zpwoot/internal/model"
+)
+
+func TestOutboundLimiterConfig(t *testing.T) {
+	limiter := NewOutboundLimiter(model.RateLimitConfig{MessagesPerMinute: 20, Burst: 5, RecipientSpacing: 3})
+
+	got := limiter.Config(nil)
+	if got.MessagesPerMinute != 20 || got.Burst != 5 || got.RecipientSpacing != 3 {
+		t.Errorf("Config(nil) = %+v, want server defaults", got)
+	}
+
+	got = limiter.Config(&model.RateLimitConfig{MessagesPerMinute: 6})
+	if got.MessagesPerMinute != 6 || got.Burst != 5 || got.RecipientSpacing != 3 {
+		t.Errorf("Config(override) = %+v, want messages_per_minute overridden only", got)
+	}
+}
+
+func TestOutboundLimiterRecipientSpacing(t *testing.T) {
+	limiter := NewOutboundLimiter(model.RateLimitConfig{MessagesPerMinute: 6000, Burst: 10, RecipientSpacing: 1})
+	ctx := context.Background()
+
+	if err := limiter.Wait(ctx, "s1", "5511999999999", nil); err != nil {
+		t.Fatalf("first Wait() error = %v", err)
+	}
+	limiter.MarkSent("s1", "5511999999999")
+
+	// Outro destinatário não espera
+	start := time.Now()
+	if err := limiter.Wait(ctx, "s1", "5511888888888", nil); err != nil {
+		t.Fatalf("Wait() error = %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 200*time.Millisecond {
+		t.Errorf("other recipient waited %v", elapsed)
+	}
+
+	// Mesmo destinatário respeita o intervalo
+	shortCtx, cancel := context.WithTimeout(ctx, 100*time.Millisecond)
+	defer cancel()
+	if err := limiter.Wait(shortCtx, "s1", "5511999999999", nil); err == nil {
+		t.Error("expected same recipient to wait for spacing")
+	}
+}
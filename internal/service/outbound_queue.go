@@ -0,0 +1,473 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/nats-io/nats.go/jetstream"
+	"go.mau.fi/whatsmeow"
+
+	"zpwoot/internal/constants"
+	"zpwoot/internal/model"
+	natsclient "zpwoot/internal/nats"
+	"zpwoot/internal/repository"
+	"zpwoot/pkg/logger"
+)
+
+const outboundStreamName = "OUTBOUND"
+
+// OutboundSender valida e envia o corpo de um endpoint de envio (implementado pela camada de API)
+type OutboundSender interface {
+	Validate(kind string, payload []byte) (string, error)
+	Send(ctx context.Context, client *whatsmeow.Client, kind string, payload []byte) (string, time.Time, error)
+}
+
+// OutboundQueueConfig define o ritmo padrão e as novas tentativas da fila de saída
+type OutboundQueueConfig struct {
+	RateLimit   model.RateLimitConfig // Padrão para sessões sem configuração própria
+	MaxAttempts int                   // Tentativas de envio antes de marcar o job como failed
+	RetryDelay  time.Duration         // Espera antes de nova tentativa (multiplicada pela tentativa)
+	SendTimeout time.Duration
+}
+
+// OutboundQueue enfileira envios no JetStream (outbound.<session>) e os entrega respeitando
+// o rate limit da sessão. Cada sessão tem um consumer durável com MaxAckPending=1,
+// então os jobs de uma sessão são enviados um por vez, na ordem de chegada. O consumer só é
+// consumido pela réplica que hospeda a sessão (SessionHostListener), de modo que o ritmo
+// controlado pelo OutboundLimiter, que é local, vale para a sessão inteira.
+type OutboundQueue struct {
+	natsClient       *natsclient.Client
+	jobRepo          *repository.JobRepository
+	sessionManager   *SessionManager
+	webhookProcessor *WebhookProcessor
+	webhookFormatter *WebhookFormatter
+	sender           OutboundSender
+	limiter          *OutboundLimiter
+	config           OutboundQueueConfig
+
+	stream jetstream.Stream
+	js     jetstream.JetStream
+
+	mu      sync.Mutex
+	workers map[string]context.CancelFunc
+	wg      sync.WaitGroup
+}
+
+func NewOutboundQueue(
+	natsClient *natsclient.Client,
+	jobRepo *repository.JobRepository,
+	sessionManager *SessionManager,
+	webhookProcessor *WebhookProcessor,
+	webhookFormatter *WebhookFormatter,
+	sender OutboundSender,
+	config OutboundQueueConfig,
+) *OutboundQueue {
+	if config.MaxAttempts <= 0 {
+		config.MaxAttempts = 3
+	}
+	if config.RetryDelay <= 0 {
+		config.RetryDelay = 30 * time.Second
+	}
+	if config.SendTimeout <= 0 {
+		config.SendTimeout = 2 * time.Minute
+	}
+
+	return &OutboundQueue{
+		natsClient:       natsClient,
+		jobRepo:          jobRepo,
+		sessionManager:   sessionManager,
+		webhookProcessor: webhookProcessor,
+		webhookFormatter: webhookFormatter,
+		sender:           sender,
+		limiter:          NewOutboundLimiter(config.RateLimit),
+		config:           config,
+		workers:          make(map[string]context.CancelFunc),
+	}
+}
+
+// Start cria o stream e passa a consumir a fila das sessões hospedadas nesta réplica
+func (q *OutboundQueue) Start(ctx context.Context) error {
+	js, err := q.natsClient.JetStream()
+	if err != nil {
+		return err
+	}
+
+	stream, err := js.CreateOrUpdateStream(ctx, jetstream.StreamConfig{
+		Name:        outboundStreamName,
+		Description: "zpwoot outbound message queue",
+		Subjects:    []string{"outbound.>"},
+		Retention:   jetstream.WorkQueuePolicy,
+		Storage:     jetstream.FileStorage,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create outbound stream: %w", err)
+	}
+
+	q.js = js
+	q.stream = stream
+
+	q.sessionManager.AddHostListener(q)
+
+	logger.Log.Info().Msg("✅ Outbound queue started")
+
+	return nil
+}
+
+// SetOutboundQueue remove a fila de envio das sessões deletadas
+func (m *SessionManager) SetOutboundQueue(queue *OutboundQueue) {
+	m.outboundQueue = queue
+}
+
+// SessionHosted inicia o worker da sessão que passou a rodar nesta réplica. O consumer é criado
+// fora do aviso para não segurar a conexão da sessão numa chamada ao JetStream.
+func (q *OutboundQueue) SessionHosted(sessionID string) {
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		defer cancel()
+
+		if err := q.ensureWorker(ctx, sessionID); err != nil {
+			logger.Log.Error().Err(err).Str("session_id", sessionID).Msg("Failed to start outbound queue worker")
+		}
+	}()
+}
+
+// SessionReleased encerra o worker da sessão que deixou esta réplica; os jobs pendentes ficam
+// no stream até outra réplica hospedar a sessão
+func (q *OutboundQueue) SessionReleased(sessionID string) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if cancel, ok := q.workers[sessionID]; ok {
+		cancel()
+		delete(q.workers, sessionID)
+	}
+}
+
+// DropSession remove o consumer e as mensagens da sessão deletada
+func (q *OutboundQueue) DropSession(ctx context.Context, sessionID string) error {
+	q.SessionReleased(sessionID)
+
+	if err := q.stream.DeleteConsumer(ctx, "outbound-"+sessionID); err != nil && !errors.Is(err, jetstream.ErrConsumerNotFound) {
+		return fmt.Errorf("failed to delete outbound consumer: %w", err)
+	}
+	if err := q.stream.Purge(ctx, jetstream.WithPurgeSubject("outbound."+sessionID)); err != nil {
+		return fmt.Errorf("failed to purge outbound queue: %w", err)
+	}
+	return nil
+}
+
+func (q *OutboundQueue) Stop() {
+	q.mu.Lock()
+	for _, cancel := range q.workers {
+		cancel()
+	}
+	q.workers = make(map[string]context.CancelFunc)
+	q.mu.Unlock()
+
+	q.wg.Wait()
+}
+
+// Enqueue valida o corpo, grava o job e o publica na fila da sessão
+func (q *OutboundQueue) Enqueue(ctx context.Context, sessionID, kind string, payload []byte) (*model.MessageJob, error) {
+	job, err := q.newJob(uuid.New().String(), sessionID, kind, payload)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := q.jobRepo.Create(ctx, job); err != nil {
+		return nil, err
+	}
+
+	if err := q.publish(ctx, job); err != nil {
+		return nil, err
+	}
+
+	return job, nil
+}
+
+// EnqueueOnce enfileira o job com um ID definido pelo chamador (ex: derivado do agendamento).
+// Chamadas repetidas com o mesmo ID retornam o job existente; ele só é publicado de novo enquanto
+// estiver queued, e o Msg-Id descarta a publicação se a anterior ainda estiver na janela de duplicatas.
+func (q *OutboundQueue) EnqueueOnce(ctx context.Context, jobID, sessionID, kind string, payload []byte) (*model.MessageJob, error) {
+	job, err := q.newJob(jobID, sessionID, kind, payload)
+	if err != nil {
+		return nil, err
+	}
+
+	created, err := q.jobRepo.CreateIfMissing(ctx, job)
+	if err != nil {
+		return nil, err
+	}
+	if !created && job.Status != model.JobStatusQueued {
+		return job, nil
+	}
+
+	if err := q.publish(ctx, job); err != nil {
+		return nil, err
+	}
+
+	return job, nil
+}
+
+func (q *OutboundQueue) newJob(jobID, sessionID, kind string, payload []byte) (*model.MessageJob, error) {
+	phone, err := q.sender.Validate(kind, payload)
+	if err != nil {
+		return nil, err
+	}
+
+	return &model.MessageJob{
+		ID:        jobID,
+		SessionID: sessionID,
+		Type:      kind,
+		Phone:     phone,
+		Payload:   payload,
+		Status:    model.JobStatusQueued,
+	}, nil
+}
+
+// publish entrega o ID do job à fila da sessão; se falhar, o job é marcado como failed
+func (q *OutboundQueue) publish(ctx context.Context, job *model.MessageJob) error {
+	// O ID do job é usado como Msg-Id para o JetStream descartar publicações duplicadas
+	if _, err := q.js.Publish(ctx, "outbound."+job.SessionID, []byte(job.ID), jetstream.WithMsgID(job.ID)); err != nil {
+		job.Status = model.JobStatusFailed
+		job.Error = err.Error()
+		q.jobRepo.UpdateResult(context.Background(), job)
+		return fmt.Errorf("failed to enqueue job: %w", err)
+	}
+
+	logger.Log.Info().
+		Str("session_id", job.SessionID).
+		Str("job_id", job.ID).
+		Str("type", job.Type).
+		Msg("Message job queued")
+
+	return nil
+}
+
+// Validate valida o corpo de envio sem enfileirar, retornando o destinatário
+func (q *OutboundQueue) Validate(kind string, payload []byte) (string, error) {
+	return q.sender.Validate(kind, payload)
+}
+
+// GetJob retorna o job da sessão
+func (q *OutboundQueue) GetJob(ctx context.Context, sessionID, jobID string) (*model.MessageJob, error) {
+	return q.jobRepo.GetByID(ctx, sessionID, jobID)
+}
+
+// RateLimitConfig retorna o ritmo efetivo da sessão (padrão do servidor + configuração da sessão)
+func (q *OutboundQueue) RateLimitConfig(session *model.Session) model.RateLimitConfig {
+	return q.limiter.Config(session.RateLimitConfig)
+}
+
+// ensureWorker inicia o consumer da sessão se ainda não estiver rodando e a sessão continuar
+// nesta réplica (o aviso de SessionReleased pode chegar antes do worker iniciar)
+func (q *OutboundQueue) ensureWorker(ctx context.Context, sessionID string) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if _, ok := q.workers[sessionID]; ok || !q.sessionManager.IsClientActive(sessionID) {
+		return nil
+	}
+
+	consumer, err := q.stream.CreateOrUpdateConsumer(ctx, jetstream.ConsumerConfig{
+		Durable:       "outbound-" + sessionID,
+		FilterSubject: "outbound." + sessionID,
+		AckPolicy:     jetstream.AckExplicitPolicy,
+		AckWait:       time.Minute,
+		MaxAckPending: 1,
+		MaxDeliver:    -1,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create outbound consumer: %w", err)
+	}
+
+	workerCtx, cancel := context.WithCancel(context.Background())
+	q.workers[sessionID] = cancel
+
+	q.wg.Add(1)
+	go func() {
+		defer q.wg.Done()
+		q.runWorker(workerCtx, sessionID, consumer)
+	}()
+
+	return nil
+}
+
+func (q *OutboundQueue) runWorker(ctx context.Context, sessionID string, consumer jetstream.Consumer) {
+	iter, err := consumer.Messages()
+	if err != nil {
+		logger.Log.Error().Err(err).Str("session_id", sessionID).Msg("Failed to consume outbound queue")
+		q.mu.Lock()
+		delete(q.workers, sessionID)
+		q.mu.Unlock()
+		return
+	}
+
+	go func() {
+		<-ctx.Done()
+		iter.Stop()
+	}()
+
+	for {
+		msg, err := iter.Next()
+		if err != nil {
+			if errors.Is(err, jetstream.ErrMsgIteratorClosed) {
+				return
+			}
+			logger.Log.Warn().Err(err).Str("session_id", sessionID).Msg("Outbound queue fetch failed")
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(time.Second):
+			}
+			continue
+		}
+
+		q.processMessage(ctx, sessionID, msg)
+	}
+}
+
+// keepInProgress renova o AckWait enquanto o job aguarda o rate limit ou o envio
+func keepInProgress(msg jetstream.Msg) func() {
+	done := make(chan struct{})
+
+	go func() {
+		ticker := time.NewTicker(20 * time.Second)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+				msg.InProgress()
+			}
+		}
+	}()
+
+	return func() { close(done) }
+}
+
+func (q *OutboundQueue) processMessage(ctx context.Context, sessionID string, msg jetstream.Msg) {
+	jobID := string(msg.Data())
+
+	job, err := q.jobRepo.GetByID(ctx, sessionID, jobID)
+	if errors.Is(err, repository.ErrJobNotFound) {
+		// Job removido (ex: sessão deletada): descarta a mensagem
+		logger.Log.Warn().Err(err).Str("session_id", sessionID).Str("job_id", jobID).Msg("Dropping outbound message")
+		msg.Term()
+		return
+	}
+	if err != nil {
+		// Falha temporária do banco (ou worker encerrado): o job continua na fila
+		logger.Log.Warn().Err(err).Str("session_id", sessionID).Str("job_id", jobID).Msg("Failed to load outbound job, retrying")
+		msg.NakWithDelay(q.config.RetryDelay)
+		return
+	}
+	if job.IsFinal() {
+		msg.Ack()
+		return
+	}
+
+	// Sessão desconectada: o job continua na fila até a sessão voltar
+	client, err := q.sessionManager.GetClient(sessionID)
+	if err != nil {
+		msg.NakWithDelay(q.config.RetryDelay)
+		return
+	}
+
+	session, err := q.sessionManager.GetSession(ctx, sessionID)
+	if err != nil {
+		msg.NakWithDelay(q.config.RetryDelay)
+		return
+	}
+
+	stop := keepInProgress(msg)
+	defer stop()
+
+	if err := q.limiter.Wait(ctx, sessionID, job.Phone, session.RateLimitConfig); err != nil {
+		// Worker encerrado: a mensagem volta para a fila
+		msg.Nak()
+		return
+	}
+
+	job.Status = model.JobStatusSending
+	job.Attempts++
+	if err := q.jobRepo.UpdateResult(ctx, job); err != nil {
+		logger.Log.Warn().Err(err).Str("job_id", job.ID).Msg("Failed to update job status")
+	}
+
+	sendCtx, cancel := context.WithTimeout(ctx, q.config.SendTimeout)
+	messageID, timestamp, err := q.sender.Send(sendCtx, client, job.Type, job.Payload)
+	cancel()
+
+	if err != nil {
+		job.Error = err.Error()
+
+		if job.Attempts < q.config.MaxAttempts {
+			job.Status = model.JobStatusQueued
+			q.saveJob(job)
+
+			delay := q.config.RetryDelay * time.Duration(job.Attempts)
+			logger.Log.Warn().
+				Err(err).
+				Str("session_id", sessionID).
+				Str("job_id", job.ID).
+				Int(logger.FieldAttempt, job.Attempts).
+				Dur("retry_delay", delay).
+				Msg("⚠️ Message job failed, retrying")
+
+			msg.NakWithDelay(delay)
+			return
+		}
+
+		job.Status = model.JobStatusFailed
+		q.saveJob(job)
+		msg.Ack()
+
+		logger.Log.Error().
+			Err(err).
+			Str("session_id", sessionID).
+			Str("job_id", job.ID).
+			Int("attempts", job.Attempts).
+			Msg("❌ Message job failed permanently")
+
+		q.notify(job)
+		return
+	}
+
+	q.limiter.MarkSent(sessionID, job.Phone)
+
+	sentAt := timestamp
+	job.Status = model.JobStatusSent
+	job.MessageID = messageID
+	job.Error = ""
+	job.SentAt = &sentAt
+	q.saveJob(job)
+	msg.Ack()
+
+	logger.Log.Info().
+		Str("session_id", sessionID).
+		Str("job_id", job.ID).
+		Str("message_id", messageID).
+		Msg("Message job sent")
+
+	q.notify(job)
+}
+
+func (q *OutboundQueue) saveJob(job *model.MessageJob) {
+	if err := q.jobRepo.UpdateResult(context.Background(), job); err != nil {
+		logger.Log.Error().Err(err).Str("job_id", job.ID).Msg("Failed to save job result")
+	}
+}
+
+// notify envia o webhook message_job com o resultado final
+func (q *OutboundQueue) notify(job *model.MessageJob) {
+	payload := q.webhookFormatter.FormatMessageJob(job.SessionID, job)
+	if err := q.webhookProcessor.ProcessEvent(job.SessionID, constants.EventMessageJob, payload); err != nil {
+		logger.Log.Warn().Err(err).Str("job_id", job.ID).Msg("Failed to send message_job webhook")
+	}
+}
@@ -0,0 +1,65 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/nats-io/nats.go/jetstream"
+	"go.mau.fi/whatsmeow"
+
+	"zpwoot/internal/nats/natstest"
+)
+
+func TestOutboundQueueFollowsHostedSessions(t *testing.T) {
+	ctx := context.Background()
+	m := &SessionManager{clients: map[string]*whatsmeow.Client{"s1": nil}}
+	q := NewOutboundQueue(natstest.NewClient(t), nil, m, nil, nil, nil, OutboundQueueConfig{})
+	if err := q.Start(ctx); err != nil {
+		t.Fatal(err)
+	}
+	defer q.Stop()
+
+	hasWorker := func(sessionID string) bool {
+		q.mu.Lock()
+		defer q.mu.Unlock()
+		_, ok := q.workers[sessionID]
+		return ok
+	}
+	waitWorker := func(sessionID string) {
+		t.Helper()
+		for deadline := time.Now().Add(5 * time.Second); !hasWorker(sessionID); {
+			if time.Now().After(deadline) {
+				t.Fatalf("worker for %s not started", sessionID)
+			}
+			time.Sleep(10 * time.Millisecond)
+		}
+	}
+
+	// Sessão já hospedada ao iniciar a fila
+	waitWorker("s1")
+
+	// Aviso atrasado de uma sessão que não está mais nesta réplica não cria worker
+	q.SessionHosted("s2")
+	m.hostClient("s3", nil)
+	waitWorker("s3")
+	if hasWorker("s2") {
+		t.Error("worker started for session hosted elsewhere")
+	}
+
+	m.releaseClient("s3")
+	if hasWorker("s3") {
+		t.Error("worker kept after session released")
+	}
+
+	if err := q.DropSession(ctx, "s1"); err != nil {
+		t.Fatal(err)
+	}
+	if hasWorker("s1") {
+		t.Error("worker kept after session dropped")
+	}
+	if _, err := q.stream.Consumer(ctx, "outbound-s1"); !errors.Is(err, jetstream.ErrConsumerNotFound) {
+		t.Errorf("consumer after drop error = %v, want ErrConsumerNotFound", err)
+	}
+}
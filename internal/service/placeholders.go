@@ -0,0 +1,44 @@
+package service
+
+import "regexp"
+
+// placeholderPattern casa {{nome}}, aceitando espaços internos ({{ nome }})
+var placeholderPattern = regexp.MustCompile(`\{\{\s*([A-Za-z0-9_.-]+)\s*\}\}`)
+
+// RenderPlaceholders substitui os placeholders {{nome}} pelos valores informados.
+// Retorna também os nomes sem valor (sem repetição, na ordem em que aparecem); eles ficam no texto.
+func RenderPlaceholders(text string, variables map[string]string) (string, []string) {
+	var missing []string
+	seen := make(map[string]bool)
+
+	rendered := placeholderPattern.ReplaceAllStringFunc(text, func(match string) string {
+		name := placeholderPattern.FindStringSubmatch(match)[1]
+		if value, ok := variables[name]; ok {
+			return value
+		}
+		if !seen[name] {
+			seen[name] = true
+			missing = append(missing, name)
+		}
+		return match
+	})
+
+	return rendered, missing
+}
+
+// PlaceholderNames retorna os nomes dos placeholders usados nos textos, sem repetição
+func PlaceholderNames(texts ...string) []string {
+	var names []string
+	seen := make(map[string]bool)
+
+	for _, text := range texts {
+		for _, match := range placeholderPattern.FindAllStringSubmatch(text, -1) {
+			if !seen[match[1]] {
+				seen[match[1]] = true
+				names = append(names, match[1])
+			}
+		}
+	}
+
+	return names
+}
@@ -0,0 +1,100 @@
+package service
+
+import (
+	"sync"
+	"time"
+)
+
+// Tipos de evento do stream de QR code
+const (
+	QREventCode    = "code"
+	QREventSuccess = "success"
+	QREventTimeout = "timeout"
+	QREventError   = "error"
+)
+
+// QREvent é enviado aos clientes conectados ao stream de QR code da sessão
+type QREvent struct {
+	Event     string     `json:"event"`                // code, success, timeout, error
+	Code      string     `json:"code,omitempty"`       // Conteúdo bruto do QR code
+	QRCode    string     `json:"qr_code,omitempty"`    // Imagem PNG em data URL base64
+	ExpiresAt *time.Time `json:"expires_at,omitempty"` // Quando o código atual expira
+	JID       string     `json:"jid,omitempty"`        // JID pareado (evento success)
+	Error     string     `json:"error,omitempty"`
+}
+
+// IsFinal indica se o evento encerra o pareamento
+func (e QREvent) IsFinal() bool {
+	return e.Event != QREventCode
+}
+
+// QRBroadcaster distribui os eventos do loop de QR code para os streams (SSE/WebSocket)
+type QRBroadcaster struct {
+	mu          sync.Mutex
+	subscribers map[string]map[chan QREvent]struct{}
+	last        map[string]QREvent // Último código por sessão, enviado a quem conectar depois
+}
+
+func NewQRBroadcaster() *QRBroadcaster {
+	return &QRBroadcaster{
+		subscribers: make(map[string]map[chan QREvent]struct{}),
+		last:        make(map[string]QREvent),
+	}
+}
+
+// Subscribe registra um ouvinte para a sessão. O código atual (se houver) é entregue imediatamente.
+// A função retornada remove o ouvinte e deve sempre ser chamada.
+func (b *QRBroadcaster) Subscribe(sessionID string) (<-chan QREvent, func()) {
+	ch := make(chan QREvent, 8)
+
+	b.mu.Lock()
+	if b.subscribers[sessionID] == nil {
+		b.subscribers[sessionID] = make(map[chan QREvent]struct{})
+	}
+	b.subscribers[sessionID][ch] = struct{}{}
+	if last, ok := b.last[sessionID]; ok {
+		ch <- last
+	}
+	b.mu.Unlock()
+
+	unsubscribe := func() {
+		b.mu.Lock()
+		defer b.mu.Unlock()
+		if subs, ok := b.subscribers[sessionID]; ok {
+			delete(subs, ch)
+			if len(subs) == 0 {
+				delete(b.subscribers, sessionID)
+			}
+		}
+	}
+
+	return ch, unsubscribe
+}
+
+// Publish envia o evento para todos os ouvintes da sessão sem bloquear o loop de QR
+func (b *QRBroadcaster) Publish(sessionID string, evt QREvent) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if evt.IsFinal() {
+		delete(b.last, sessionID)
+	} else {
+		b.last[sessionID] = evt
+	}
+
+	for ch := range b.subscribers[sessionID] {
+		select {
+		case ch <- evt:
+		default:
+			// Ouvinte lento: descarta o evento mais antigo para entregar o mais recente
+			select {
+			case <-ch:
+			default:
+			}
+			select {
+			case ch <- evt:
+			default:
+			}
+		}
+	}
+}
@@ -0,0 +1,56 @@
+package service
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestQRBroadcaster(t *testing.T) {
+	b := NewQRBroadcaster()
+
+	b.Publish("s1", QREvent{Event: QREventCode, Code: "code-1"})
+
+	// Quem conecta depois recebe o código atual
+	events, unsubscribe := b.Subscribe("s1")
+	defer unsubscribe()
+	if evt := <-events; evt.Code != "code-1" {
+		t.Fatalf("first event = %+v, want code-1", evt)
+	}
+
+	// Ouvinte lento: os códigos mais antigos são descartados e o mais recente é entregue
+	for i := 2; i <= 20; i++ {
+		b.Publish("s1", QREvent{Event: QREventCode, Code: fmt.Sprintf("code-%d", i)})
+	}
+	var last QREvent
+	for len(events) > 0 {
+		last = <-events
+	}
+	if last.Code != "code-20" {
+		t.Errorf("last buffered event = %+v, want code-20", last)
+	}
+
+	// Eventos de outra sessão não chegam
+	b.Publish("s2", QREvent{Event: QREventCode, Code: "other"})
+	if len(events) != 0 {
+		t.Errorf("received event of another session")
+	}
+
+	// O evento final encerra o pareamento: não é repetido para novos ouvintes
+	b.Publish("s1", QREvent{Event: QREventSuccess, JID: "5511999999999@s.whatsapp.net"})
+	if evt := <-events; !evt.IsFinal() || evt.JID == "" {
+		t.Errorf("final event = %+v, want success", evt)
+	}
+	late, unsubscribeLate := b.Subscribe("s1")
+	defer unsubscribeLate()
+	if len(late) != 0 {
+		t.Errorf("late subscriber got %+v after final event", <-late)
+	}
+
+	unsubscribe()
+	unsubscribeLate()
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if _, ok := b.subscribers["s1"]; ok {
+		t.Error("subscribers of s1 kept after unsubscribe")
+	}
+}
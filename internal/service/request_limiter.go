@@ -0,0 +1,226 @@
+package service
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/nats-io/nats.go/jetstream"
+
+	"zpwoot/internal/model"
+	natsclient "zpwoot/internal/nats"
+)
+
+const (
+	requestLimitBucket     = "zpwoot_ratelimit"
+	requestLimitMaxRetries = 5 // Tentativas de compare-and-set no KV antes de negar a requisição
+)
+
+// RequestLimitStore guarda os token buckets da API
+type RequestLimitStore interface {
+	Take(ctx context.Context, key string, rate model.RequestRate, now time.Time) (model.RequestLimitResult, error)
+}
+
+// RequestLimiterConfig define os limites por API key (envios e demais chamadas) e por IP
+type RequestLimiterConfig struct {
+	Read model.RequestRate // Leituras e demais chamadas, por API key
+	Send model.RequestRate // Envios de mensagem (rotas com escopo messages:send), por API key
+	IP   model.RequestRate // Todas as chamadas, por IP de origem (inclusive sem autenticação)
+}
+
+// RequestLimiter limita as requisições da API com token buckets em memória (por réplica) ou no
+// NATS KV (compartilhados entre réplicas)
+type RequestLimiter struct {
+	store  RequestLimitStore
+	config RequestLimiterConfig
+}
+
+func NewRequestLimiter(store RequestLimitStore, config RequestLimiterConfig) *RequestLimiter {
+	return &RequestLimiter{store: store, config: config}
+}
+
+// AllowIP consome uma requisição do bucket do IP
+func (l *RequestLimiter) AllowIP(ctx context.Context, ip string) (model.RequestLimitResult, error) {
+	return l.take(ctx, "ip:"+ip, l.config.IP)
+}
+
+// AllowKey consome uma requisição do bucket da API key (send = envio de mensagem)
+func (l *RequestLimiter) AllowKey(ctx context.Context, key string, send bool) (model.RequestLimitResult, error) {
+	if send {
+		return l.take(ctx, "send:"+key, l.config.Send)
+	}
+	return l.take(ctx, "read:"+key, l.config.Read)
+}
+
+func (l *RequestLimiter) take(ctx context.Context, key string, rate model.RequestRate) (model.RequestLimitResult, error) {
+	if rate.PerMinute <= 0 {
+		return model.RequestLimitResult{Allowed: true}, nil
+	}
+	return l.store.Take(ctx, key, rate, time.Now())
+}
+
+// requestBucket é o estado de um token bucket
+type requestBucket struct {
+	Tokens  float64   `json:"tokens"`
+	Updated time.Time `json:"updated"`
+}
+
+// takeToken reabastece o bucket pelo tempo decorrido e consome um token, se houver
+func takeToken(bucket requestBucket, rate model.RequestRate, now time.Time) (requestBucket, model.RequestLimitResult) {
+	perSecond := float64(rate.PerMinute) / 60
+	burst := float64(rate.Burst)
+	if burst < 1 {
+		burst = 1
+	}
+
+	tokens := burst
+	if !bucket.Updated.IsZero() {
+		// Relógios de réplicas diferentes podem divergir: tempo negativo não reabastece
+		elapsed := math.Max(now.Sub(bucket.Updated).Seconds(), 0)
+		tokens = math.Min(burst, bucket.Tokens+elapsed*perSecond)
+	}
+
+	result := model.RequestLimitResult{Limit: int(burst)}
+	if tokens >= 1 {
+		tokens--
+		result.Allowed = true
+	} else {
+		result.RetryAfter = time.Duration((1 - tokens) / perSecond * float64(time.Second))
+	}
+	result.Remaining = int(tokens)
+	result.Reset = time.Duration((burst - tokens) / perSecond * float64(time.Second))
+
+	return requestBucket{Tokens: tokens, Updated: now}, result
+}
+
+// MemoryLimitStore guarda os buckets na memória da réplica
+type MemoryLimitStore struct {
+	mu        sync.Mutex
+	buckets   map[string]requestBucket
+	lastSweep time.Time
+}
+
+func NewMemoryLimitStore() *MemoryLimitStore {
+	return &MemoryLimitStore{buckets: make(map[string]requestBucket)}
+}
+
+func (s *MemoryLimitStore) Take(ctx context.Context, key string, rate model.RequestRate, now time.Time) (model.RequestLimitResult, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	bucket, result := takeToken(s.buckets[key], rate, now)
+	s.buckets[key] = bucket
+
+	// Buckets parados há mais de 10 minutos já estão cheios: removê-los equivale a mantê-los
+	if now.Sub(s.lastSweep) > time.Minute {
+		for k, b := range s.buckets {
+			if now.Sub(b.Updated) > 10*time.Minute {
+				delete(s.buckets, k)
+			}
+		}
+		s.lastSweep = now
+	}
+
+	return result, nil
+}
+
+// NATSLimitStore guarda os buckets no NATS KV, com compare-and-set pela revisão da entrada para
+// que réplicas concorrentes não consumam o mesmo token
+type NATSLimitStore struct {
+	kv jetstream.KeyValue
+}
+
+// NewNATSLimitStore cria (ou reutiliza) o bucket KV; ttl deve cobrir o tempo de reabastecer um
+// bucket vazio, pois entradas expiradas voltam cheias
+func NewNATSLimitStore(ctx context.Context, natsClient *natsclient.Client, ttl time.Duration) (*NATSLimitStore, error) {
+	js, err := natsClient.JetStream()
+	if err != nil {
+		return nil, err
+	}
+
+	kv, err := js.CreateOrUpdateKeyValue(ctx, jetstream.KeyValueConfig{
+		Bucket:      requestLimitBucket,
+		Description: "zpwoot API rate limit buckets",
+		TTL:         ttl,
+		History:     1,
+		Storage:     jetstream.MemoryStorage,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create rate limit bucket: %w", err)
+	}
+
+	return &NATSLimitStore{kv: kv}, nil
+}
+
+func (s *NATSLimitStore) Take(ctx context.Context, key string, rate model.RequestRate, now time.Time) (model.RequestLimitResult, error) {
+	// IPs (IPv6 tem ":") e nomes de chave nem sempre são válidos como chave do KV
+	sum := sha256.Sum256([]byte(key))
+	kvKey := hex.EncodeToString(sum[:16])
+
+	var result model.RequestLimitResult
+	for attempt := 0; attempt < requestLimitMaxRetries; attempt++ {
+		var bucket requestBucket
+		var revision uint64
+
+		entry, err := s.kv.Get(ctx, kvKey)
+		switch {
+		case errors.Is(err, jetstream.ErrKeyNotFound):
+		case err != nil:
+			return model.RequestLimitResult{}, fmt.Errorf("failed to get rate limit bucket: %w", err)
+		default:
+			revision = entry.Revision()
+			if err := json.Unmarshal(entry.Value(), &bucket); err != nil {
+				bucket = requestBucket{}
+			}
+		}
+
+		bucket, result = takeToken(bucket, rate, now)
+		data, err := json.Marshal(bucket)
+		if err != nil {
+			return model.RequestLimitResult{}, err
+		}
+
+		if revision == 0 {
+			_, err = s.kv.Create(ctx, kvKey, data)
+		} else {
+			_, err = s.kv.Update(ctx, kvKey, data, revision)
+		}
+		if err == nil {
+			return result, nil
+		}
+
+		// Outra réplica gravou o bucket entre a leitura e a escrita: tenta de novo
+		var apiErr *jetstream.APIError
+		if !errors.Is(err, jetstream.ErrKeyExists) && !(errors.As(err, &apiErr) && apiErr.ErrorCode == jetstream.JSErrCodeStreamWrongLastSequence) {
+			return model.RequestLimitResult{}, fmt.Errorf("failed to update rate limit bucket: %w", err)
+		}
+	}
+
+	// Bucket disputado por muitas requisições ao mesmo tempo: nega em vez de liberar sem contar
+	return model.RequestLimitResult{
+		Limit:      result.Limit,
+		Reset:      result.Reset,
+		RetryAfter: time.Second,
+	}, nil
+}
+
+// RequestLimitTTL retorna o tempo para reabastecer o bucket mais lento (mínimo de 1 minuto)
+func RequestLimitTTL(config RequestLimiterConfig) time.Duration {
+	ttl := time.Minute
+	for _, rate := range []model.RequestRate{config.Read, config.Send, config.IP} {
+		if rate.PerMinute <= 0 {
+			continue
+		}
+		burst := math.Max(float64(rate.Burst), 1)
+		if refill := time.Duration(burst / float64(rate.PerMinute) * float64(time.Minute)); refill > ttl {
+			ttl = refill
+		}
+	}
+	return ttl
+}
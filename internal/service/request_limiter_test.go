@@ -0,0 +1,121 @@
+package service
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"zpwoot/internal/model"
+	"zpwoot/internal/nats/natstest"
+)
+
+func TestTakeToken(t *testing.T) {
+	rate := model.RequestRate{PerMinute: 60, Burst: 3}
+	now := time.Now()
+
+	var bucket requestBucket
+	var result model.RequestLimitResult
+	for i := 0; i < 3; i++ {
+		bucket, result = takeToken(bucket, rate, now)
+		if !result.Allowed || result.Remaining != 2-i {
+			t.Fatalf("request %d = %+v, want allowed with %d remaining", i+1, result, 2-i)
+		}
+	}
+
+	bucket, result = takeToken(bucket, rate, now)
+	if result.Allowed || result.RetryAfter != time.Second || result.Reset != 3*time.Second || result.Limit != 3 {
+		t.Fatalf("burst exceeded = %+v, want denied with 1s retry and 3s reset", result)
+	}
+
+	// Um token por segundo: depois de 1,5s há uma requisição disponível, e nunca mais que o burst
+	if _, result = takeToken(bucket, rate, now.Add(1500*time.Millisecond)); !result.Allowed {
+		t.Errorf("refill after 1.5s = %+v, want allowed", result)
+	}
+	if _, result = takeToken(bucket, rate, now.Add(time.Hour)); result.Remaining != 2 {
+		t.Errorf("refill after 1h remaining = %d, want 2 (burst - 1)", result.Remaining)
+	}
+
+	// Relógio de outra réplica atrasado não gera tokens
+	if _, result = takeToken(bucket, rate, now.Add(-time.Minute)); result.Allowed {
+		t.Errorf("negative elapsed = %+v, want denied", result)
+	}
+}
+
+func TestRequestLimiterBuckets(t *testing.T) {
+	limiter := NewRequestLimiter(NewMemoryLimitStore(), RequestLimiterConfig{
+		Read: model.RequestRate{PerMinute: 600, Burst: 5},
+		Send: model.RequestRate{PerMinute: 60, Burst: 1},
+	})
+	ctx := context.Background()
+
+	if result, _ := limiter.AllowKey(ctx, "key-1", true); !result.Allowed {
+		t.Fatal("first send denied")
+	}
+	if result, _ := limiter.AllowKey(ctx, "key-1", true); result.Allowed || result.RetryAfter <= 0 {
+		t.Errorf("second send = %+v, want denied", result)
+	}
+
+	// Envios esgotados não afetam as leituras da mesma chave nem os envios de outra chave
+	if result, _ := limiter.AllowKey(ctx, "key-1", false); !result.Allowed || result.Remaining != 4 {
+		t.Errorf("read after sends = %+v, want allowed with 4 remaining", result)
+	}
+	if result, _ := limiter.AllowKey(ctx, "key-2", true); !result.Allowed {
+		t.Error("send from another key denied")
+	}
+
+	// Limite por IP desativado (PerMinute 0)
+	if result, _ := limiter.AllowIP(ctx, "203.0.113.7"); !result.Allowed || result.Limit != 0 {
+		t.Errorf("disabled IP limit = %+v, want allowed without limit", result)
+	}
+}
+
+func TestNATSLimitStore(t *testing.T) {
+	ctx := context.Background()
+	store, err := NewNATSLimitStore(ctx, natstest.NewClient(t), time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rate := model.RequestRate{PerMinute: 60, Burst: 3}
+	now := time.Now()
+
+	for i := 0; i < 3; i++ {
+		result, err := store.Take(ctx, "key-1", rate, now)
+		if err != nil || !result.Allowed || result.Remaining != 2-i {
+			t.Fatalf("request %d = %+v, %v, want allowed with %d remaining", i+1, result, err, 2-i)
+		}
+	}
+	if result, err := store.Take(ctx, "key-1", rate, now); err != nil || result.Allowed {
+		t.Fatalf("burst exceeded = %+v, %v, want denied", result, err)
+	}
+
+	// Requisições simultâneas na mesma chave (IPv6 inclusive) nunca passam do burst; as que
+	// perdem o compare-and-set várias vezes são negadas, não liberadas
+	rate.Burst = 20
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	allowed := 0
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			result, err := store.Take(ctx, "ip:2001:db8::1", rate, now)
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			if result.Allowed {
+				mu.Lock()
+				allowed++
+				mu.Unlock()
+			} else if result.RetryAfter <= 0 || result.Limit != rate.Burst {
+				t.Errorf("denied result = %+v, want retry after and limit %d", result, rate.Burst)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if allowed == 0 || allowed > rate.Burst {
+		t.Errorf("allowed = %d, want between 1 and %d", allowed, rate.Burst)
+	}
+}
@@ -0,0 +1,35 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	"zpwoot/internal/repository"
+	"zpwoot/pkg/logger"
+	"zpwoot/pkg/secrets"
+)
+
+// RotateSecrets recifra com a chave mestra atual os segredos das sessões e dos tenants gravados em
+// texto puro (antes da configuração da chave) ou com uma chave anterior. É idempotente, então
+// várias réplicas podem executá-lo na inicialização; depois disso as chaves anteriores podem ser
+// removidas de SECRETS_PREVIOUS_KEYS.
+func RotateSecrets(ctx context.Context, keyring *secrets.Keyring, sessionRepo *repository.SessionRepository, tenantRepo *repository.TenantRepository) error {
+	sessions, err := sessionRepo.ReencryptSecrets(ctx, keyring.NeedsRotation)
+	if err != nil {
+		return fmt.Errorf("failed to reencrypt session secrets: %w", err)
+	}
+
+	tenants, err := tenantRepo.ReencryptSecrets(ctx, keyring.NeedsRotation)
+	if err != nil {
+		return fmt.Errorf("failed to reencrypt tenant secrets: %w", err)
+	}
+
+	if sessions > 0 || tenants > 0 {
+		logger.Log.Info().
+			Int("sessions", sessions).
+			Int("tenants", tenants).
+			Msg("Secrets reencrypted with the current master key")
+	}
+
+	return nil
+}
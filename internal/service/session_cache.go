@@ -99,7 +99,8 @@ func (sc *SessionCache) UpdateSessionInfo(sessionID, key, value string) *Session
 	return sessionInfo
 }
 
-// CreateSessionInfoFromModel cria SessionInfo a partir do modelo Session
+// CreateSessionInfoFromModel cria SessionInfo a partir do modelo Session. Segredos (token do
+// webhook, senha do proxy) não são copiados para o cache.
 func CreateSessionInfoFromModel(session *model.Session) *SessionInfo {
 	sessionInfo := NewSessionInfo()
 	sessionInfo.Set("Id", session.ID)
@@ -115,7 +116,6 @@ func CreateSessionInfoFromModel(session *model.Session) *SessionInfo {
 		if len(session.WebhookConfig.Events) > 0 {
 			sessionInfo.Set("Events", strings.Join(session.WebhookConfig.Events, ","))
 		}
-		sessionInfo.Set("WebhookToken", session.WebhookConfig.Token)
 	}
 	
 	// Proxy config
@@ -125,7 +125,6 @@ func CreateSessionInfoFromModel(session *model.Session) *SessionInfo {
 		sessionInfo.Set("ProxyHost", session.ProxyConfig.Host)
 		sessionInfo.Set("ProxyPort", fmt.Sprintf("%d", session.ProxyConfig.Port))
 		sessionInfo.Set("ProxyUsername", session.ProxyConfig.Username)
-		sessionInfo.Set("ProxyPassword", session.ProxyConfig.Password)
 	}
 
 	return sessionInfo
@@ -0,0 +1,67 @@
+package service
+
+import (
+	"go.mau.fi/whatsmeow"
+)
+
+// SessionHostListener é avisado quando a réplica passa a hospedar o client de uma sessão
+// (ConnectSession/restauração) e quando deixa de hospedá-lo (desconexão ou fim do supervisor).
+// Os avisos de uma sessão chegam em ordem, um por vez; o listener não deve bloquear.
+type SessionHostListener interface {
+	SessionHosted(sessionID string)
+	SessionReleased(sessionID string)
+}
+
+// AddHostListener registra o listener e o avisa das sessões já hospedadas
+func (m *SessionManager) AddHostListener(listener SessionHostListener) {
+	m.hostListenersMux.Lock()
+	defer m.hostListenersMux.Unlock()
+
+	m.hostListeners = append(m.hostListeners, listener)
+	for _, sessionID := range m.hostedSessionIDs() {
+		listener.SessionHosted(sessionID)
+	}
+}
+
+func (m *SessionManager) hostedSessionIDs() []string {
+	m.clientsMux.RLock()
+	defer m.clientsMux.RUnlock()
+
+	sessionIDs := make([]string, 0, len(m.clients))
+	for sessionID := range m.clients {
+		sessionIDs = append(sessionIDs, sessionID)
+	}
+	return sessionIDs
+}
+
+// hostClient adiciona o client ao map de clientes ativos e avisa os listeners
+func (m *SessionManager) hostClient(sessionID string, client *whatsmeow.Client) {
+	m.hostListenersMux.Lock()
+	defer m.hostListenersMux.Unlock()
+
+	m.clientsMux.Lock()
+	m.clients[sessionID] = client
+	m.clientsMux.Unlock()
+
+	for _, listener := range m.hostListeners {
+		listener.SessionHosted(sessionID)
+	}
+}
+
+// releaseClient remove o client do map de clientes ativos e avisa os listeners
+func (m *SessionManager) releaseClient(sessionID string) {
+	m.hostListenersMux.Lock()
+	defer m.hostListenersMux.Unlock()
+
+	m.clientsMux.Lock()
+	_, hosted := m.clients[sessionID]
+	delete(m.clients, sessionID)
+	m.clientsMux.Unlock()
+
+	if !hosted {
+		return
+	}
+	for _, listener := range m.hostListeners {
+		listener.SessionReleased(sessionID)
+	}
+}
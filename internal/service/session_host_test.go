@@ -0,0 +1,38 @@
+package service
+
+import (
+	"reflect"
+	"testing"
+
+	"go.mau.fi/whatsmeow"
+)
+
+type recordingHostListener struct {
+	events []string
+}
+
+func (l *recordingHostListener) SessionHosted(sessionID string) {
+	l.events = append(l.events, "hosted:"+sessionID)
+}
+
+func (l *recordingHostListener) SessionReleased(sessionID string) {
+	l.events = append(l.events, "released:"+sessionID)
+}
+
+func TestSessionHostListener(t *testing.T) {
+	m := &SessionManager{clients: map[string]*whatsmeow.Client{"s1": nil}}
+
+	// Sessões já hospedadas são avisadas no registro
+	listener := &recordingHostListener{}
+	m.AddHostListener(listener)
+
+	m.hostClient("s2", nil)
+	m.releaseClient("s1")
+	// Sessão que não está na réplica (ex: DisconnectSession repetido) não gera aviso
+	m.releaseClient("s1")
+
+	want := []string{"hosted:s1", "hosted:s2", "released:s1"}
+	if !reflect.DeepEqual(listener.events, want) {
+		t.Errorf("events = %v, want %v", listener.events, want)
+	}
+}
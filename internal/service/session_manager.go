@@ -15,6 +15,7 @@ import (
 	"github.com/mdp/qrterminal/v3"
 	"github.com/skip2/go-qrcode"
 	"go.mau.fi/whatsmeow"
+	"go.mau.fi/whatsmeow/types/events"
 	"golang.org/x/net/proxy"
 
 	"zpwoot/internal/model"
@@ -22,11 +23,26 @@ import (
 	"zpwoot/pkg/logger"
 )
 
-var (
-	// Map de canais kill para controlar sessões: sessionID -> chan bool
-	killChannels = make(map[string]chan bool)
-	killMux      sync.RWMutex
-)
+// ErrMaxSessionsReached é retornado quando o limite de sessões (MAX_SESSIONS) foi atingido
+var ErrMaxSessionsReached = repository.ErrSessionLimitReached
+
+// ErrConnectionTimeout é retornado quando client.Connect excede o timeout de conexão
+var ErrConnectionTimeout = errors.New("connection timed out")
+
+// ErrPairingTimeout é enviado no webhook pair_error quando o pareamento não termina a tempo
+var ErrPairingTimeout = errors.New("pairing timed out")
+
+// ErrResponderConflict é retornado ao ativar o fluxo e o assistente na mesma sessão: os dois
+// responderiam a mesma mensagem
+var ErrResponderConflict = errors.New("flow bot and assistant cannot both be enabled on a session")
+
+// SessionManagerConfig agrupa limites e timeouts aplicados pelo SessionManager
+type SessionManagerConfig struct {
+	MaxSessions       int           // Máximo de sessões (0 = sem limite)
+	ConnectionTimeout time.Duration // Tempo máximo de cada client.Connect
+	PairingTimeout    time.Duration // Tempo máximo para concluir o pareamento via QR
+	Reconnect         ReconnectConfig
+}
 
 type SessionManager struct {
 	whatsappSvc *WhatsAppService
@@ -42,6 +58,32 @@ type SessionManager struct {
 
 	// Event handler
 	eventHandler *EventHandler
+
+	// Supervisores de conexão: sessionID -> *sessionSupervisor
+	supervisors    map[string]*sessionSupervisor
+	supervisorsMux sync.RWMutex
+
+	// Stream de QR codes (SSE/WebSocket)
+	qrStream *QRBroadcaster
+
+	// Limites, timeouts e backoff de reconexão
+	config SessionManagerConfig
+
+	// Ingestão de histórico
+	historySync *HistorySyncService
+
+	// Cota e webhook padrão dos tenants (opcional)
+	tenants *TenantService
+
+	// Buffers de replay dos streams de eventos (opcional)
+	eventStream *EventStreamHub
+
+	// Fila de envio, limpa ao deletar a sessão (opcional)
+	outboundQueue *OutboundQueue
+
+	// Avisados quando a réplica passa a hospedar ou deixa de hospedar uma sessão
+	hostListeners    []SessionHostListener
+	hostListenersMux sync.Mutex
 }
 
 func NewSessionManager(
@@ -49,6 +91,8 @@ func NewSessionManager(
 	sessionRepo *repository.SessionRepository,
 	webhookProcessor *WebhookProcessor,
 	webhookFormatter *WebhookFormatter,
+	historySyncService *HistorySyncService,
+	config SessionManagerConfig,
 ) *SessionManager {
 	// Inicializar cache de sessões
 	InitSessionCache()
@@ -58,10 +102,14 @@ func NewSessionManager(
 		sessionRepo: sessionRepo,
 		clients:     make(map[string]*whatsmeow.Client),
 		httpClients: make(map[string]*resty.Client),
+		supervisors: make(map[string]*sessionSupervisor),
+		qrStream:    NewQRBroadcaster(),
+		config:      config,
+		historySync: historySyncService,
 	}
 
 	// Criar event handler com webhook support
-	manager.eventHandler = NewEventHandler(manager, sessionRepo, webhookProcessor, webhookFormatter)
+	manager.eventHandler = NewEventHandler(manager, sessionRepo, webhookProcessor, webhookFormatter, historySyncService)
 
 	return manager
 }
@@ -81,8 +129,12 @@ func (m *SessionManager) CreateSession(ctx context.Context, name, webhookURL str
 		}
 	}
 
-	if err := m.sessionRepo.Create(ctx, session); err != nil {
-		return nil, fmt.Errorf("failed to create session: %w", err)
+	if err := m.applyTenantDefaults(ctx, session); err != nil {
+		return nil, err
+	}
+
+	if err := m.createWithinLimit(ctx, session); err != nil {
+		return nil, err
 	}
 
 	logger.Log.Info().
@@ -94,8 +146,12 @@ func (m *SessionManager) CreateSession(ctx context.Context, name, webhookURL str
 }
 
 func (m *SessionManager) CreateSessionWithConfig(ctx context.Context, session *model.Session) error {
-	if err := m.sessionRepo.Create(ctx, session); err != nil {
-		return fmt.Errorf("failed to create session: %w", err)
+	if err := m.applyTenantDefaults(ctx, session); err != nil {
+		return err
+	}
+
+	if err := m.createWithinLimit(ctx, session); err != nil {
+		return err
 	}
 
 	logger.Log.Info().
@@ -106,6 +162,41 @@ func (m *SessionManager) CreateSessionWithConfig(ctx context.Context, session *m
 	return nil
 }
 
+// SubscribeQR registra um ouvinte para os eventos de QR code da sessão
+func (m *SessionManager) SubscribeQR(sessionID string) (<-chan QREvent, func()) {
+	return m.qrStream.Subscribe(sessionID)
+}
+
+// applyTenantDefaults aplica o webhook padrão do tenant do contexto
+func (m *SessionManager) applyTenantDefaults(ctx context.Context, session *model.Session) error {
+	if m.tenants == nil {
+		return nil
+	}
+	return m.tenants.applyDefaults(ctx, session)
+}
+
+// createWithinLimit grava a sessão respeitando a cota do tenant e o limite do servidor; a
+// verificação e o INSERT são atômicos no repositório
+func (m *SessionManager) createWithinLimit(ctx context.Context, session *model.Session) error {
+	tenantMaxSessions := 0
+	if m.tenants != nil {
+		quota, err := m.tenants.sessionQuota(ctx)
+		if err != nil {
+			return err
+		}
+		tenantMaxSessions = quota
+	}
+
+	if err := m.sessionRepo.CreateWithinLimit(ctx, session, m.config.MaxSessions, tenantMaxSessions); err != nil {
+		if errors.Is(err, ErrMaxSessionsReached) {
+			return err
+		}
+		return fmt.Errorf("failed to create session: %w", err)
+	}
+
+	return nil
+}
+
 func (m *SessionManager) GetQRCode(sessionID string) (string, bool) {
 	// Buscar QR code do banco
 	ctx := context.Background()
@@ -121,6 +212,11 @@ func (m *SessionManager) GetQRCode(sessionID string) (string, bool) {
 	return session.QRCode, true
 }
 
+// GetHistorySyncProgress retorna o progresso da sincronização de histórico da sessão
+func (m *SessionManager) GetHistorySyncProgress(ctx context.Context, sessionID string) (*model.HistorySyncProgress, error) {
+	return m.historySync.GetProgress(ctx, sessionID)
+}
+
 func (m *SessionManager) GetSession(ctx context.Context, sessionID string) (*model.Session, error) {
 	return m.sessionRepo.GetByID(ctx, sessionID)
 }
@@ -140,6 +236,20 @@ func (m *SessionManager) DeleteSession(ctx context.Context, sessionID string) er
 		return fmt.Errorf("failed to delete session: %w", err)
 	}
 
+	// Remover a fila de envio da sessão (consumer e mensagens pendentes)
+	if m.outboundQueue != nil {
+		if err := m.outboundQueue.DropSession(ctx, sessionID); err != nil {
+			logger.Log.Warn().Err(err).Str("session_id", sessionID).Msg("Failed to drop outbound queue")
+		}
+	}
+
+	// Liberar o buffer de replay dos streams em todas as réplicas
+	if m.eventStream != nil {
+		if err := m.eventStream.DropSession(sessionID); err != nil {
+			logger.Log.Warn().Err(err).Str("session_id", sessionID).Msg("Failed to drop event stream buffer")
+		}
+	}
+
 	logger.Log.Info().
 		Str("session_id", sessionID).
 		Msg("Session deleted")
@@ -193,27 +303,17 @@ func (m *SessionManager) ConnectSession(ctx context.Context, sessionID string) e
 		m.DisconnectSession(ctx, sessionID)
 	}
 
-	// Criar canal kill para esta sessão
-	killMux.Lock()
-	killChannels[sessionID] = make(chan bool)
-	killMux.Unlock()
-
-	// Iniciar cliente em goroutine (como no wuzapi)
-	go m.startClient(sessionID, session.DeviceJID)
-
-	return nil
+	// Iniciar supervisor da sessão (conecta e reconecta em background)
+	return m.startSupervisor(sessionID, session.DeviceJID)
 }
 
-// startClient é a função principal de conexão (baseada no wuzapi)
-func (m *SessionManager) startClient(sessionID string, textJID string) {
+// startClient é a função principal de conexão (baseada no wuzapi), executada pelo supervisor da sessão
+func (m *SessionManager) startClient(sup *sessionSupervisor, textJID string) {
+	sessionID := sup.sessionID
 	logger.Log.Info().Str("session_id", sessionID).Str("jid", textJID).Msg("Starting websocket connection to WhatsApp")
 
 	ctx := context.Background()
 
-	// Constantes de retry (do wuzapi)
-	const maxConnectionRetries = 3
-	const connectionRetryBaseWait = 5 * time.Second
-
 	// Obter ou criar device
 	logger.Log.Debug().
 		Str("session_id", sessionID).
@@ -238,14 +338,15 @@ func (m *SessionManager) startClient(sessionID string, textJID string) {
 	// Criar cliente WhatsApp
 	client := m.whatsappSvc.NewClient(deviceStore, false)
 
+	// A reconexão é feita pelo supervisor (backoff próprio)
+	client.EnableAutoReconnect = false
+
 	logger.Log.Debug().
 		Str("session_id", sessionID).
 		Msg("WhatsApp client created")
 
 	// Adicionar ao map de clientes
-	m.clientsMux.Lock()
-	m.clients[sessionID] = client
-	m.clientsMux.Unlock()
+	m.hostClient(sessionID, client)
 
 	// Configurar HTTP client com proxy support (do wuzapi)
 	httpClient := resty.New()
@@ -262,6 +363,11 @@ func (m *SessionManager) startClient(sessionID string, textJID string) {
 		}
 	}
 
+	// Aplicar preferências de histórico (só têm efeito no pareamento)
+	if err == nil && client.Store.ID == nil {
+		applyHistorySyncConfig(client, session.HistorySyncConfig)
+	}
+
 	// Salvar HTTP client
 	m.httpClientsMux.Lock()
 	m.httpClients[sessionID] = httpClient
@@ -277,7 +383,15 @@ func (m *SessionManager) startClient(sessionID string, textJID string) {
 			Msg("No device ID stored, starting QR code pairing process")
 
 		// No ID stored, new login - precisa QR code
-		qrChan, err := client.GetQRChannel(context.Background())
+		// O pareamento é cancelado após PAIRING_TIMEOUT
+		pairCtx := sup.ctx
+		if m.config.PairingTimeout > 0 {
+			var cancelPairing context.CancelFunc
+			pairCtx, cancelPairing = context.WithTimeout(sup.ctx, m.config.PairingTimeout)
+			defer cancelPairing()
+		}
+
+		qrChan, err := client.GetQRChannel(pairCtx)
 		if err != nil {
 			if !errors.Is(err, whatsmeow.ErrQRStoreContainsID) {
 				logger.Log.Error().
@@ -293,7 +407,7 @@ func (m *SessionManager) startClient(sessionID string, textJID string) {
 				Msg("QR channel obtained, connecting client")
 
 			// Conectar ANTES de processar QR codes (IMPORTANTE!)
-			err = client.Connect()
+			err = m.connectWithTimeout(client)
 			if err != nil {
 				logger.Log.Error().
 					Err(err).
@@ -309,6 +423,7 @@ func (m *SessionManager) startClient(sessionID string, textJID string) {
 
 			// Processar QR codes SÍNCRONAMENTE (como no wuzapi!)
 			// O loop só termina quando o canal fecha (após pareamento ou timeout)
+			paired := false
 			for evt := range qrChan {
 				if evt.Event == "code" {
 					// Novo QR code gerado
@@ -337,6 +452,15 @@ func (m *SessionManager) startClient(sessionID string, textJID string) {
 					// Atualizar status
 					m.sessionRepo.UpdateStatus(ctx, sessionID, "qr_code", false)
 
+					// Publicar no stream
+					expiresAt := time.Now().Add(evt.Timeout)
+					m.qrStream.Publish(sessionID, QREvent{
+						Event:     QREventCode,
+						Code:      evt.Code,
+						QRCode:    base64QRCode,
+						ExpiresAt: &expiresAt,
+					})
+
 					// Exibir QR code no terminal
 					fmt.Printf("\n========================================\n")
 					fmt.Printf("QR CODE for Session: %s\n", sessionID)
@@ -347,86 +471,107 @@ func (m *SessionManager) startClient(sessionID string, textJID string) {
 				} else if evt.Event == "timeout" {
 					logger.Log.Warn().Str("session_id", sessionID).Msg("QR code timeout - killing channel")
 
-					// Limpar QR code
-					m.sessionRepo.UpdateQRCode(ctx, sessionID, "")
-					m.sessionRepo.UpdateStatus(ctx, sessionID, "disconnected", false)
+					m.abortPairing(sup, ErrPairingTimeout)
+					return
 
-					// Cleanup
-					m.cleanupSession(sessionID)
+				} else if evt.Event == "error" {
+					logger.Log.Error().Err(evt.Error).Str("session_id", sessionID).Msg("QR pairing error")
 
-					// Enviar kill signal
-					killMux.Lock()
-					if ch, exists := killChannels[sessionID]; exists {
-						ch <- true
-					}
-					killMux.Unlock()
-					return
+					m.qrStream.Publish(sessionID, QREvent{Event: QREventError, Error: evt.Error.Error()})
 
 				} else if evt.Event == "success" {
 					logger.Log.Info().Str("session_id", sessionID).Msg("QR pairing ok!")
+					paired = true
 
 					// Limpar QR code e atualizar status
 					m.sessionRepo.UpdateQRCode(ctx, sessionID, "")
 					m.sessionRepo.UpdateStatus(ctx, sessionID, "connected", true)
 
 					// Salvar JID
+					successEvt := QREvent{Event: QREventSuccess}
 					if client.Store.ID != nil {
 						m.sessionRepo.UpdateDeviceJID(ctx, sessionID, client.Store.ID.String())
+						successEvt.JID = client.Store.ID.String()
 					}
 
+					m.qrStream.Publish(sessionID, successEvt)
+
 				} else {
 					logger.Log.Info().Str("session_id", sessionID).Str("event", evt.Event).Msg("Login event")
+
+					// Demais eventos (ex.: err-client-outdated) encerram o pareamento
+					m.qrStream.Publish(sessionID, QREvent{Event: QREventError, Error: evt.Event})
 				}
 			}
-			// Canal QR fechado - continuar para keep-alive loop
-		}
-	} else {
-		// Já está pareado, apenas conectar com retry
-		logger.Log.Info().Msg("Already logged in, connecting with retry logic")
+			// Canal QR fechado - continuar para o supervisor
+			if sup.ctx.Err() != nil {
+				return
+			}
 
-		var lastErr error
-		for attempt := 0; attempt < maxConnectionRetries; attempt++ {
-			if attempt > 0 {
-				waitTime := time.Duration(attempt) * connectionRetryBaseWait
+			if !paired && errors.Is(pairCtx.Err(), context.DeadlineExceeded) {
 				logger.Log.Warn().
-					Int("attempt", attempt+1).
-					Int("max_retries", maxConnectionRetries).
-					Dur("wait_time", waitTime).
-					Msg("Retrying connection after delay")
-				time.Sleep(waitTime)
-			}
+					Str("session_id", sessionID).
+					Dur("pairing_timeout", m.config.PairingTimeout).
+					Msg("Pairing timeout reached")
 
-			err = client.Connect()
-			if err == nil {
-				logger.Log.Info().
-					Int("attempt", attempt+1).
-					Msg("Successfully connected to WhatsApp")
-				break
+				m.abortPairing(sup, ErrPairingTimeout)
+				return
 			}
+		}
+	} else {
+		// Já está pareado, conectar com backoff até conseguir ou a sessão ser encerrada
+		logger.Log.Info().Str("session_id", sessionID).Msg("Already logged in, connecting")
 
-			lastErr = err
-			logger.Log.Warn().
-				Err(err).
-				Int("attempt", attempt+1).
-				Int("max_retries", maxConnectionRetries).
-				Msg("Failed to connect to WhatsApp")
+		if !m.connectWithBackoff(sup, client) {
+			return
 		}
+	}
 
-		if lastErr != nil {
-			logger.Log.Error().
-				Err(lastErr).
-				Str("session_id", sessionID).
-				Int("attempts", maxConnectionRetries).
-				Msg("Failed to connect after all retry attempts")
+	// Supervisionar a conexão (reconecta em quedas até a sessão ser encerrada)
+	m.superviseConnection(sup, client)
+}
 
-			m.cleanupSession(sessionID)
-			m.sessionRepo.UpdateStatus(ctx, sessionID, "disconnected", false)
-			return
+// abortPairing encerra uma tentativa de pareamento que não foi concluída
+func (m *SessionManager) abortPairing(sup *sessionSupervisor, reason error) {
+	ctx := context.Background()
+
+	// Limpar QR code
+	m.sessionRepo.UpdateQRCode(ctx, sup.sessionID, "")
+	GetSessionCache().UpdateSessionInfo(sup.sessionID, "QRCode", "")
+
+	// Notificar streams e webhook
+	m.qrStream.Publish(sup.sessionID, QREvent{Event: QREventTimeout, Error: reason.Error()})
+	m.eventHandler.notifyPairError(sup.sessionID, &events.PairError{Error: reason})
+
+	// Encerrar supervisor (cleanup é feito ao sair)
+	sup.stop(model.SessionStatusDisconnected)
+}
+
+// connectWithTimeout executa client.ConnectContext limitado por CONNECTION_TIMEOUT. O contexto
+// acompanha o socket durante toda a conexão, então só é cancelado quando o timeout vence antes de
+// Connect retornar; a chamada é síncrona para que nenhuma conexão atrasada sobreviva à tentativa.
+func (m *SessionManager) connectWithTimeout(client *whatsmeow.Client) error {
+	if m.config.ConnectionTimeout <= 0 {
+		return client.Connect()
+	}
+
+	ctx, cancel := context.WithCancel(client.BackgroundEventCtx)
+	timer := time.AfterFunc(m.config.ConnectionTimeout, cancel)
+
+	err := client.ConnectContext(ctx)
+	if timer.Stop() {
+		// Sem conexão nova (falha ou ErrAlreadyConnected) o contexto não é mais usado
+		if err != nil {
+			cancel()
 		}
+		return err
 	}
 
-	// Keep alive loop (do wuzapi) - mantém a conexão viva
-	m.keepAliveLoop(sessionID, client)
+	// O timeout venceu: uma conexão concluída no limite já teve o contexto cancelado e é descartada aqui
+	if err == nil {
+		client.Disconnect()
+	}
+	return fmt.Errorf("%w after %s", ErrConnectionTimeout, m.config.ConnectionTimeout)
 }
 
 // configureProxy configura proxy para o cliente (do wuzapi)
@@ -455,62 +600,27 @@ func (m *SessionManager) configureProxy(client *whatsmeow.Client, httpClient *re
 	}
 }
 
-// keepAliveLoop mantém a conexão ativa (do wuzapi)
-func (m *SessionManager) keepAliveLoop(sessionID string, client *whatsmeow.Client) {
-	killMux.RLock()
-	killChan, exists := killChannels[sessionID]
-	killMux.RUnlock()
-
-	if !exists {
-		logger.Log.Error().Str("session_id", sessionID).Msg("Kill channel not found")
-		return
-	}
-
-	for {
-		select {
-		case <-killChan:
-			logger.Log.Info().Str("session_id", sessionID).Msg("Received kill signal")
-			client.Disconnect()
-			m.cleanupSession(sessionID)
-			m.sessionRepo.UpdateStatus(context.Background(), sessionID, "disconnected", false)
-			return
-		default:
-			time.Sleep(1000 * time.Millisecond)
-		}
-	}
-}
-
 // cleanupSession limpa recursos da sessão
 func (m *SessionManager) cleanupSession(sessionID string) {
-	m.clientsMux.Lock()
-	delete(m.clients, sessionID)
-	m.clientsMux.Unlock()
+	m.releaseClient(sessionID)
 
 	m.httpClientsMux.Lock()
 	delete(m.httpClients, sessionID)
 	m.httpClientsMux.Unlock()
-
-	killMux.Lock()
-	delete(killChannels, sessionID)
-	killMux.Unlock()
 }
 
 // DisconnectSession desconecta uma sessão
 func (m *SessionManager) DisconnectSession(ctx context.Context, sessionID string) error {
 	logger.Log.Info().Str("session_id", sessionID).Msg("Disconnecting session")
 
-	// Enviar sinal kill
-	killMux.Lock()
-	if killChan, exists := killChannels[sessionID]; exists {
+	// Encerrar supervisor e aguardar o cleanup
+	if sup := m.stopSupervisor(sessionID, model.SessionStatusDisconnected); sup != nil {
 		select {
-		case killChan <- true:
-		default:
+		case <-sup.done:
+		case <-time.After(10 * time.Second):
+			logger.Log.Warn().Str("session_id", sessionID).Msg("Timeout waiting for session supervisor to stop")
 		}
 	}
-	killMux.Unlock()
-
-	// Aguardar um pouco para cleanup
-	time.Sleep(500 * time.Millisecond)
 
 	// Garantir cleanup
 	m.cleanupSession(sessionID)
@@ -539,13 +649,13 @@ func (m *SessionManager) RestoreAllSessions(ctx context.Context) error {
 			Str("jid", session.DeviceJID).
 			Msg("Attempting to restore session")
 
-		// Criar canal kill
-		killMux.Lock()
-		killChannels[session.ID] = make(chan bool)
-		killMux.Unlock()
-
-		// Iniciar cliente em goroutine
-		go m.startClient(session.ID, session.DeviceJID)
+		// Iniciar supervisor da sessão
+		if err := m.startSupervisor(session.ID, session.DeviceJID); err != nil {
+			logger.Log.Warn().
+				Str("session_id", session.ID).
+				Int("max_sessions", m.config.MaxSessions).
+				Msg("Session limit reached, skipping restore")
+		}
 	}
 
 	return nil
@@ -627,6 +737,156 @@ func (m *SessionManager) UpdateWebhookConfig(ctx context.Context, sessionID stri
 	return nil
 }
 
+func (m *SessionManager) UpdateCallConfig(ctx context.Context, sessionID string, callConfig *model.CallConfig) error {
+	session, err := m.sessionRepo.GetByID(ctx, sessionID)
+	if err != nil {
+		return fmt.Errorf("session not found: %w", err)
+	}
+
+	session.CallConfig = callConfig
+
+	if err := m.sessionRepo.Update(ctx, session); err != nil {
+		return fmt.Errorf("failed to update call config: %w", err)
+	}
+
+	logger.Log.Info().
+		Str("session_id", sessionID).
+		Str("policy", string(callConfig.Policy)).
+		Msg("Call config updated")
+
+	return nil
+}
+
+// UpdateTranscriptionConfig salva o endpoint de transcrição das mensagens de voz da sessão
+func (m *SessionManager) UpdateTranscriptionConfig(ctx context.Context, sessionID string, transcriptionConfig *model.TranscriptionConfig) error {
+	session, err := m.sessionRepo.GetByID(ctx, sessionID)
+	if err != nil {
+		return fmt.Errorf("session not found: %w", err)
+	}
+
+	session.TranscriptionConfig = transcriptionConfig
+
+	if err := m.sessionRepo.Update(ctx, session); err != nil {
+		return fmt.Errorf("failed to update transcription config: %w", err)
+	}
+
+	logger.Log.Info().
+		Str("session_id", sessionID).
+		Bool("enabled", transcriptionConfig.Enabled).
+		Str("base_url", transcriptionConfig.BaseURL).
+		Str("model", transcriptionConfig.Model).
+		Msg("Transcription config updated")
+
+	return nil
+}
+
+func (m *SessionManager) UpdateRateLimitConfig(ctx context.Context, sessionID string, rateLimitConfig *model.RateLimitConfig) error {
+	session, err := m.sessionRepo.GetByID(ctx, sessionID)
+	if err != nil {
+		return fmt.Errorf("session not found: %w", err)
+	}
+
+	session.RateLimitConfig = rateLimitConfig
+
+	if err := m.sessionRepo.Update(ctx, session); err != nil {
+		return fmt.Errorf("failed to update rate limit config: %w", err)
+	}
+
+	logger.Log.Info().
+		Str("session_id", sessionID).
+		Int("messages_per_minute", rateLimitConfig.MessagesPerMinute).
+		Int("burst", rateLimitConfig.Burst).
+		Int("recipient_spacing", rateLimitConfig.RecipientSpacing).
+		Msg("Rate limit config updated")
+
+	return nil
+}
+
+// UpdateChatwootConfig salva a inbox do Chatwoot da sessão. O token do webhook é mantido
+// quando não informado e gerado na primeira configuração.
+func (m *SessionManager) UpdateChatwootConfig(ctx context.Context, sessionID string, chatwootConfig *model.ChatwootConfig) error {
+	session, err := m.sessionRepo.GetByID(ctx, sessionID)
+	if err != nil {
+		return fmt.Errorf("session not found: %w", err)
+	}
+
+	if chatwootConfig.WebhookToken == "" && session.ChatwootConfig != nil {
+		chatwootConfig.WebhookToken = session.ChatwootConfig.WebhookToken
+	}
+	if chatwootConfig.WebhookToken == "" {
+		chatwootConfig.WebhookToken = newChatwootWebhookToken()
+	}
+
+	session.ChatwootConfig = chatwootConfig
+
+	if err := m.sessionRepo.Update(ctx, session); err != nil {
+		return fmt.Errorf("failed to update chatwoot config: %w", err)
+	}
+
+	logger.Log.Info().
+		Str("session_id", sessionID).
+		Bool("enabled", chatwootConfig.Enabled).
+		Str("url", chatwootConfig.URL).
+		Int64("inbox_id", chatwootConfig.InboxID).
+		Msg("Chatwoot config updated")
+
+	return nil
+}
+
+// UpdateFlowBotConfig salva o fluxo (Typebot) que atende os contatos da sessão
+func (m *SessionManager) UpdateFlowBotConfig(ctx context.Context, sessionID string, flowBotConfig *model.FlowBotConfig) error {
+	session, err := m.sessionRepo.GetByID(ctx, sessionID)
+	if err != nil {
+		return fmt.Errorf("session not found: %w", err)
+	}
+
+	if flowBotConfig.Enabled && session.AssistantEnabled() {
+		return ErrResponderConflict
+	}
+
+	session.FlowBotConfig = flowBotConfig
+
+	if err := m.sessionRepo.Update(ctx, session); err != nil {
+		return fmt.Errorf("failed to update flow bot config: %w", err)
+	}
+
+	logger.Log.Info().
+		Str("session_id", sessionID).
+		Bool("enabled", flowBotConfig.Enabled).
+		Str("url", flowBotConfig.URL).
+		Str("typebot_id", flowBotConfig.TypebotID).
+		Msg("Flow bot config updated")
+
+	return nil
+}
+
+// UpdateAssistantConfig salva o assistente (LLM) que atende os contatos da sessão
+func (m *SessionManager) UpdateAssistantConfig(ctx context.Context, sessionID string, assistantConfig *model.AssistantConfig) error {
+	session, err := m.sessionRepo.GetByID(ctx, sessionID)
+	if err != nil {
+		return fmt.Errorf("session not found: %w", err)
+	}
+
+	if assistantConfig.Enabled && session.FlowBotEnabled() {
+		return ErrResponderConflict
+	}
+
+	session.AssistantConfig = assistantConfig
+
+	if err := m.sessionRepo.Update(ctx, session); err != nil {
+		return fmt.Errorf("failed to update assistant config: %w", err)
+	}
+
+	logger.Log.Info().
+		Str("session_id", sessionID).
+		Bool("enabled", assistantConfig.Enabled).
+		Str("base_url", assistantConfig.BaseURL).
+		Str("model", assistantConfig.Model).
+		Msg("Assistant config updated")
+
+	return nil
+}
+
 func buildProxyURL(config *model.ProxyConfig) string {
 	if config == nil || !config.Enabled {
 		return ""
@@ -0,0 +1,285 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"sync"
+	"time"
+
+	"go.mau.fi/whatsmeow"
+
+	"zpwoot/internal/model"
+	"zpwoot/pkg/logger"
+)
+
+// ReconnectConfig define o backoff usado pelo supervisor para reconectar sessões
+type ReconnectConfig struct {
+	BaseDelay time.Duration // Espera inicial entre tentativas
+	MaxDelay  time.Duration // Teto da espera exponencial
+
+	// Keepalives seguidos sem resposta antes de derrubar a conexão e reconectar (0 = desativado).
+	// Com EnableAutoReconnect desligado, o whatsmeow não reconecta sozinho um socket meio morto.
+	KeepAliveMaxFailures int
+}
+
+// Backoff retorna a espera antes da tentativa informada (0 = primeira nova tentativa).
+// A espera dobra a cada tentativa até MaxDelay; metade dela é aleatória (jitter)
+// para que várias sessões não reconectem todas ao mesmo tempo.
+func (c ReconnectConfig) Backoff(attempt int) time.Duration {
+	base := c.BaseDelay
+	if base <= 0 {
+		base = time.Second
+	}
+	maxDelay := c.MaxDelay
+	if maxDelay < base {
+		maxDelay = base
+	}
+
+	delay := base
+	for i := 0; i < attempt && delay < maxDelay; i++ {
+		delay *= 2
+	}
+	if delay > maxDelay {
+		delay = maxDelay
+	}
+
+	half := delay / 2
+	return half + time.Duration(rand.Int63n(int64(delay-half)+1))
+}
+
+// sessionSupervisor controla o ciclo de vida da conexão de uma sessão
+type sessionSupervisor struct {
+	sessionID    string
+	ctx          context.Context
+	cancel       context.CancelFunc
+	disconnected chan struct{} // Sinaliza queda de conexão
+	done         chan struct{} // Fechado quando o supervisor termina
+
+	mu         sync.Mutex
+	stopStatus model.SessionStatus // Status gravado no banco ao encerrar
+}
+
+func newSessionSupervisor(sessionID string) *sessionSupervisor {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &sessionSupervisor{
+		sessionID:    sessionID,
+		ctx:          ctx,
+		cancel:       cancel,
+		disconnected: make(chan struct{}, 1),
+		done:         make(chan struct{}),
+	}
+}
+
+// notifyDisconnected sinaliza queda de conexão sem bloquear o event handler
+func (s *sessionSupervisor) notifyDisconnected() {
+	select {
+	case s.disconnected <- struct{}{}:
+	default:
+	}
+}
+
+// stop encerra o supervisor; o primeiro status informado prevalece
+func (s *sessionSupervisor) stop(status model.SessionStatus) {
+	s.mu.Lock()
+	if s.stopStatus == "" {
+		s.stopStatus = status
+	}
+	s.mu.Unlock()
+	s.cancel()
+}
+
+func (s *sessionSupervisor) finalStatus() model.SessionStatus {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.stopStatus
+}
+
+// startSupervisor cria o supervisor da sessão e inicia a conexão em background
+func (m *SessionManager) startSupervisor(sessionID string, deviceJID string) error {
+	sup, err := m.reserveSupervisor(sessionID)
+	if err != nil {
+		return err
+	}
+
+	go func() {
+		defer close(sup.done)
+		defer m.finishSupervisor(sup)
+		m.startClient(sup, deviceJID)
+	}()
+
+	return nil
+}
+
+// reserveSupervisor verifica o limite de sessões ativas (MAX_SESSIONS) e registra o supervisor
+// sob o mesmo lock, para que conexões simultâneas não ultrapassem o limite. O supervisor
+// anterior da própria sessão não conta, pois é substituído.
+func (m *SessionManager) reserveSupervisor(sessionID string) (*sessionSupervisor, error) {
+	m.supervisorsMux.Lock()
+	defer m.supervisorsMux.Unlock()
+
+	if m.config.MaxSessions > 0 {
+		active := len(m.supervisors)
+		if _, exists := m.supervisors[sessionID]; exists {
+			active--
+		}
+		if active >= m.config.MaxSessions {
+			return nil, fmt.Errorf("%w (%d active)", ErrMaxSessionsReached, m.config.MaxSessions)
+		}
+	}
+
+	sup := newSessionSupervisor(sessionID)
+	m.supervisors[sessionID] = sup
+	return sup, nil
+}
+
+func (m *SessionManager) getSupervisor(sessionID string) *sessionSupervisor {
+	m.supervisorsMux.RLock()
+	defer m.supervisorsMux.RUnlock()
+	return m.supervisors[sessionID]
+}
+
+// notifyDisconnected avisa o supervisor da sessão que a conexão caiu
+func (m *SessionManager) notifyDisconnected(sessionID string) {
+	if sup := m.getSupervisor(sessionID); sup != nil {
+		sup.notifyDisconnected()
+	}
+}
+
+// handleKeepAliveFailure derruba a conexão quando a sessão acumula KeepAliveMaxFailures keepalives
+// seguidos sem resposta e entrega a reconexão ao supervisor (mesmo caminho e backoff de uma queda).
+// Dispara uma vez por sequência de falhas; retorna true quando a reconexão foi solicitada.
+func (m *SessionManager) handleKeepAliveFailure(sessionID string, errorCount int) bool {
+	maxFailures := m.config.Reconnect.KeepAliveMaxFailures
+	if maxFailures <= 0 || errorCount != maxFailures {
+		return false
+	}
+
+	sup := m.getSupervisor(sessionID)
+	if sup == nil {
+		return false
+	}
+
+	logger.Log.Warn().
+		Str("session_id", sessionID).
+		Int("error_count", errorCount).
+		Msg("Keepalive failing, forcing reconnect")
+
+	// Disconnect é uma desconexão esperada (sem evento Disconnected): o supervisor é avisado direto
+	m.clientsMux.RLock()
+	client := m.clients[sessionID]
+	m.clientsMux.RUnlock()
+	if client != nil {
+		client.Disconnect()
+	}
+	sup.notifyDisconnected()
+
+	return true
+}
+
+// stopSupervisor encerra o supervisor sem aguardar (seguro para chamar de event handlers)
+func (m *SessionManager) stopSupervisor(sessionID string, status model.SessionStatus) *sessionSupervisor {
+	sup := m.getSupervisor(sessionID)
+	if sup != nil {
+		sup.stop(status)
+	}
+	return sup
+}
+
+// finishSupervisor libera os recursos da sessão quando o supervisor termina
+func (m *SessionManager) finishSupervisor(sup *sessionSupervisor) {
+	m.clientsMux.RLock()
+	client := m.clients[sup.sessionID]
+	m.clientsMux.RUnlock()
+
+	if client != nil {
+		client.Disconnect()
+	}
+
+	m.cleanupSession(sup.sessionID)
+
+	m.supervisorsMux.Lock()
+	if m.supervisors[sup.sessionID] == sup {
+		delete(m.supervisors, sup.sessionID)
+	}
+	m.supervisorsMux.Unlock()
+
+	if status := sup.finalStatus(); status != "" {
+		if err := m.sessionRepo.UpdateStatus(context.Background(), sup.sessionID, string(status), false); err != nil {
+			logger.Log.Warn().Err(err).Str("session_id", sup.sessionID).Msg("Failed to update session status")
+		}
+	}
+
+	logger.Log.Info().
+		Str("session_id", sup.sessionID).
+		Str("status", string(sup.finalStatus())).
+		Msg("Session supervisor stopped")
+}
+
+// superviseConnection aguarda quedas de conexão e reconecta até o supervisor ser encerrado
+func (m *SessionManager) superviseConnection(sup *sessionSupervisor, client *whatsmeow.Client) {
+	for {
+		select {
+		case <-sup.ctx.Done():
+			return
+		case <-sup.disconnected:
+			logger.Log.Warn().
+				Str("session_id", sup.sessionID).
+				Msg("Connection lost, reconnecting")
+
+			if !m.connectWithBackoff(sup, client) {
+				return
+			}
+		}
+	}
+}
+
+// connectWithBackoff tenta conectar até conseguir ou o supervisor ser encerrado
+func (m *SessionManager) connectWithBackoff(sup *sessionSupervisor, client *whatsmeow.Client) bool {
+	ctx := context.Background()
+
+	for attempt := 0; ; attempt++ {
+		if attempt > 0 {
+			wait := m.config.Reconnect.Backoff(attempt - 1)
+			logger.Log.Warn().
+				Str("session_id", sup.sessionID).
+				Int("attempt", attempt+1).
+				Dur("wait_time", wait).
+				Msg("Retrying connection after delay")
+
+			select {
+			case <-sup.ctx.Done():
+				return false
+			case <-time.After(wait):
+			}
+		}
+
+		if sup.ctx.Err() != nil {
+			return false
+		}
+
+		if client.IsConnected() {
+			return true
+		}
+
+		m.sessionRepo.UpdateStatus(ctx, sup.sessionID, string(model.SessionStatusConnecting), false)
+
+		err := m.connectWithTimeout(client)
+		if err == nil || errors.Is(err, whatsmeow.ErrAlreadyConnected) {
+			logger.Log.Info().
+				Str("session_id", sup.sessionID).
+				Int("attempt", attempt+1).
+				Msg("Successfully connected to WhatsApp")
+			return true
+		}
+
+		logger.Log.Warn().
+			Err(err).
+			Str("session_id", sup.sessionID).
+			Int("attempt", attempt+1).
+			Msg("Failed to connect to WhatsApp")
+
+		m.sessionRepo.UpdateStatus(ctx, sup.sessionID, string(model.SessionStatusFailed), false)
+	}
+}
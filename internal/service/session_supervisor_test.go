@@ -0,0 +1,124 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"go.mau.fi/whatsmeow"
+)
+
+func TestReconnectConfigBackoff(t *testing.T) {
+	cfg := ReconnectConfig{BaseDelay: 2 * time.Second, MaxDelay: 30 * time.Second}
+
+	tests := []struct {
+		name    string
+		attempt int
+		min     time.Duration
+		max     time.Duration
+	}{
+		{"First retry", 0, 1 * time.Second, 2 * time.Second},
+		{"Second retry doubles", 1, 2 * time.Second, 4 * time.Second},
+		{"Third retry doubles again", 2, 4 * time.Second, 8 * time.Second},
+		{"Capped at max delay", 10, 15 * time.Second, 30 * time.Second},
+		{"Large attempt stays capped", 1000, 15 * time.Second, 30 * time.Second},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for i := 0; i < 50; i++ {
+				got := cfg.Backoff(tt.attempt)
+				if got < tt.min || got > tt.max {
+					t.Fatalf("Backoff(%d) = %v, want between %v and %v", tt.attempt, got, tt.min, tt.max)
+				}
+			}
+		})
+	}
+}
+
+func TestReconnectConfigBackoffDefaults(t *testing.T) {
+	cfg := ReconnectConfig{}
+
+	if got := cfg.Backoff(5); got < 500*time.Millisecond || got > time.Second {
+		t.Errorf("Backoff with zero config = %v, want between 500ms and 1s", got)
+	}
+}
+
+func TestHandleKeepAliveFailure(t *testing.T) {
+	m := &SessionManager{
+		clients:     make(map[string]*whatsmeow.Client),
+		supervisors: make(map[string]*sessionSupervisor),
+		config:      SessionManagerConfig{Reconnect: ReconnectConfig{KeepAliveMaxFailures: 3}},
+	}
+	sup := newSessionSupervisor("s1")
+	m.supervisors["s1"] = sup
+
+	reconnectRequested := func() bool {
+		select {
+		case <-sup.disconnected:
+			return true
+		default:
+			return false
+		}
+	}
+
+	for errorCount := 1; errorCount <= 5; errorCount++ {
+		forced := m.handleKeepAliveFailure("s1", errorCount)
+		// Apenas a falha que atinge o limite aciona o supervisor (uma vez por sequência)
+		if want := errorCount == 3; forced != want || reconnectRequested() != want {
+			t.Errorf("errorCount %d: forced = %v, want %v", errorCount, forced, want)
+		}
+	}
+
+	// Sessão sem supervisor (encerrada) ou verificação desativada não reconecta
+	if m.handleKeepAliveFailure("unknown", 3) {
+		t.Error("reconnect requested for a session without supervisor")
+	}
+	m.config.Reconnect.KeepAliveMaxFailures = 0
+	if m.handleKeepAliveFailure("s1", 3) || reconnectRequested() {
+		t.Error("reconnect requested with KeepAliveMaxFailures = 0")
+	}
+}
+
+func TestReserveSupervisorLimit(t *testing.T) {
+	m := &SessionManager{
+		supervisors: make(map[string]*sessionSupervisor),
+		config:      SessionManagerConfig{MaxSessions: 5},
+	}
+
+	// Conexões simultâneas de sessões diferentes não passam do limite
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	reserved := 0
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			_, err := m.reserveSupervisor(fmt.Sprintf("s%d", i))
+			if err != nil && !errors.Is(err, ErrMaxSessionsReached) {
+				t.Error(err)
+				return
+			}
+			if err == nil {
+				mu.Lock()
+				reserved++
+				mu.Unlock()
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	if reserved != 5 || len(m.supervisors) != 5 {
+		t.Fatalf("reserved = %d, supervisors = %d, want 5", reserved, len(m.supervisors))
+	}
+
+	// Reconectar uma sessão já ativa substitui o próprio supervisor
+	for sessionID := range m.supervisors {
+		if _, err := m.reserveSupervisor(sessionID); err != nil {
+			t.Errorf("reconnect of active session %s: %v", sessionID, err)
+		}
+		break
+	}
+}
@@ -0,0 +1,75 @@
+package service
+
+import (
+	"context"
+	"sync"
+)
+
+// sessionWorkers executa tarefas em ordem por sessão: cada sessão tem uma fila e uma goroutine,
+// então eventos da mesma sessão não são reordenados e sessões lentas não atrasam as demais.
+type sessionWorkers struct {
+	queueSize int
+
+	mu      sync.Mutex
+	queues  map[string]chan func(context.Context)
+	ctx     context.Context
+	cancel  context.CancelFunc
+	wg      sync.WaitGroup
+	stopped bool
+}
+
+func newSessionWorkers(queueSize int) *sessionWorkers {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	return &sessionWorkers{
+		queueSize: queueSize,
+		queues:    make(map[string]chan func(context.Context)),
+		ctx:       ctx,
+		cancel:    cancel,
+	}
+}
+
+// enqueue agenda a tarefa na fila da sessão (bloqueia se a fila estiver cheia)
+func (w *sessionWorkers) enqueue(sessionID string, task func(context.Context)) {
+	w.mu.Lock()
+	if w.stopped {
+		w.mu.Unlock()
+		return
+	}
+	queue, ok := w.queues[sessionID]
+	if !ok {
+		queue = make(chan func(context.Context), w.queueSize)
+		w.queues[sessionID] = queue
+		w.wg.Add(1)
+		go w.run(queue)
+	}
+	w.mu.Unlock()
+
+	select {
+	case queue <- task:
+	case <-w.ctx.Done():
+	}
+}
+
+func (w *sessionWorkers) run(queue chan func(context.Context)) {
+	defer w.wg.Done()
+
+	for {
+		select {
+		case <-w.ctx.Done():
+			return
+		case task := <-queue:
+			task(w.ctx)
+		}
+	}
+}
+
+// stop encerra as goroutines; tarefas ainda na fila são descartadas
+func (w *sessionWorkers) stop() {
+	w.mu.Lock()
+	w.stopped = true
+	w.mu.Unlock()
+
+	w.cancel()
+	w.wg.Wait()
+}
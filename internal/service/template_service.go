@@ -0,0 +1,256 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/google/uuid"
+
+	"zpwoot/internal/model"
+	"zpwoot/internal/repository"
+	"zpwoot/pkg/logger"
+)
+
+var (
+	ErrTemplateNotFound = errors.New("template not found")
+	ErrInvalidTemplate  = errors.New("invalid template")
+	ErrMissingVariables = errors.New("missing template variables")
+)
+
+// TemplateService gerencia templates de mensagem versionados e os expande nos corpos dos
+// endpoints de envio ({"templateId", "variables"} no lugar do conteúdo da mensagem)
+type TemplateService struct {
+	templateRepo *repository.TemplateRepository
+}
+
+func NewTemplateService(templateRepo *repository.TemplateRepository) *TemplateService {
+	return &TemplateService{templateRepo: templateRepo}
+}
+
+func validateTemplateContent(content *model.TemplateContent) error {
+	if content == nil || (content.Text == "" && content.MediaURL == "") {
+		return fmt.Errorf("%w: text or mediaUrl is required", ErrInvalidTemplate)
+	}
+	for i, button := range content.Buttons {
+		if strings.TrimSpace(button.Text) == "" {
+			return fmt.Errorf("%w: button %d has no text", ErrInvalidTemplate, i+1)
+		}
+	}
+	return nil
+}
+
+func (s *TemplateService) Create(ctx context.Context, template *model.MessageTemplate) error {
+	if err := validateTemplateContent(template.Content); err != nil {
+		return err
+	}
+
+	template.ID = uuid.New().String()
+
+	if err := s.templateRepo.Create(ctx, template); err != nil {
+		return err
+	}
+
+	logger.Log.Info().
+		Str("template_id", template.ID).
+		Str("session_id", template.SessionID).
+		Str("name", template.Name).
+		Msg("Message template created")
+
+	return nil
+}
+
+// Update grava uma nova versão do template; as versões anteriores continuam disponíveis
+func (s *TemplateService) Update(ctx context.Context, template *model.MessageTemplate) error {
+	if err := validateTemplateContent(template.Content); err != nil {
+		return err
+	}
+
+	if err := s.templateRepo.Update(ctx, template); err != nil {
+		return err
+	}
+
+	logger.Log.Info().
+		Str("template_id", template.ID).
+		Int("version", template.Version).
+		Msg("Message template updated")
+
+	return nil
+}
+
+// Get retorna o template na versão informada (0 = atual)
+func (s *TemplateService) Get(ctx context.Context, id string, version int) (*model.MessageTemplate, error) {
+	return s.templateRepo.GetByID(ctx, id, version)
+}
+
+func (s *TemplateService) List(ctx context.Context, sessionID string) ([]*model.MessageTemplate, error) {
+	return s.templateRepo.List(ctx, sessionID)
+}
+
+func (s *TemplateService) Delete(ctx context.Context, id string) error {
+	return s.templateRepo.Delete(ctx, id)
+}
+
+func (s *TemplateService) Versions(ctx context.Context, id string) ([]*model.TemplateVersion, error) {
+	return s.templateRepo.ListVersions(ctx, id)
+}
+
+// RenderContent preenche os placeholders de todos os campos do conteúdo.
+// Retorna também as variáveis sem valor.
+func RenderContent(content *model.TemplateContent, variables map[string]string) (*model.TemplateContent, []string) {
+	var missing []string
+	seen := make(map[string]bool)
+
+	render := func(text string) string {
+		rendered, names := RenderPlaceholders(text, variables)
+		for _, name := range names {
+			if !seen[name] {
+				seen[name] = true
+				missing = append(missing, name)
+			}
+		}
+		return rendered
+	}
+
+	rendered := &model.TemplateContent{
+		Text:     render(content.Text),
+		MediaURL: render(content.MediaURL),
+		Caption:  render(content.Caption),
+		FileName: render(content.FileName),
+	}
+	for _, button := range content.Buttons {
+		rendered.Buttons = append(rendered.Buttons, model.TemplateButton{
+			ID:   button.ID,
+			Text: render(button.Text),
+		})
+	}
+
+	return rendered, missing
+}
+
+// templateReference são os campos que substituem o conteúdo no corpo do endpoint de envio
+type templateReference struct {
+	TemplateID      string            `json:"templateId"`
+	TemplateVersion int               `json:"templateVersion"`
+	Variables       map[string]string `json:"variables"`
+}
+
+// ExpandPayload substitui {"templateId", "templateVersion", "variables"} no corpo de um endpoint
+// de envio (kind = text, image, ...) pelos campos do template renderizado. Corpos sem templateId
+// são devolvidos sem alteração. Variáveis faltando são reportadas antes de qualquer envio.
+func (s *TemplateService) ExpandPayload(ctx context.Context, sessionID, kind string, payload []byte) ([]byte, error) {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(payload, &fields); err != nil {
+		// Corpo inválido: a validação do endpoint reporta o erro
+		return payload, nil
+	}
+	if _, ok := fields["templateId"]; !ok {
+		return payload, nil
+	}
+
+	var ref templateReference
+	if err := json.Unmarshal(payload, &ref); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidTemplate, err)
+	}
+
+	template, err := s.templateRepo.GetByID(ctx, ref.TemplateID, ref.TemplateVersion)
+	if err != nil || !template.IsAvailableTo(sessionID) {
+		return nil, fmt.Errorf("%w: %s", ErrTemplateNotFound, ref.TemplateID)
+	}
+
+	content, missing := RenderContent(template.Content, ref.Variables)
+	if len(missing) > 0 {
+		return nil, fmt.Errorf("%w: %s", ErrMissingVariables, strings.Join(missing, ", "))
+	}
+
+	values, err := templateFields(kind, content)
+	if err != nil {
+		return nil, err
+	}
+
+	delete(fields, "templateId")
+	delete(fields, "templateVersion")
+	delete(fields, "variables")
+
+	for name, value := range values {
+		data, err := json.Marshal(value)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode template field: %w", err)
+		}
+		fields[name] = data
+	}
+
+	return json.Marshal(fields)
+}
+
+// templateFields converte o conteúdo renderizado nos campos do DTO do endpoint.
+// Botões nativos não são entregues a contas comuns do WhatsApp, então viram opções numeradas
+// no texto ou as opções da enquete no endpoint poll.
+func templateFields(kind string, content *model.TemplateContent) (map[string]interface{}, error) {
+	caption := content.Caption
+	if caption == "" {
+		caption = content.Text
+	}
+
+	requireMedia := func() error {
+		if content.MediaURL == "" {
+			return fmt.Errorf("%w: template has no media for %s messages", ErrInvalidTemplate, kind)
+		}
+		return nil
+	}
+
+	switch kind {
+	case "text":
+		if content.Text == "" {
+			return nil, fmt.Errorf("%w: template has no text", ErrInvalidTemplate)
+		}
+		return map[string]interface{}{"message": textWithButtons(content.Text, content.Buttons)}, nil
+
+	case "image", "video":
+		if err := requireMedia(); err != nil {
+			return nil, err
+		}
+		return map[string]interface{}{kind: content.MediaURL, "caption": caption}, nil
+
+	case "audio":
+		if err := requireMedia(); err != nil {
+			return nil, err
+		}
+		return map[string]interface{}{"audio": content.MediaURL}, nil
+
+	case "document", "media":
+		if err := requireMedia(); err != nil {
+			return nil, err
+		}
+		return map[string]interface{}{kind: content.MediaURL, "caption": caption, "fileName": content.FileName}, nil
+
+	case "poll":
+		if content.Text == "" || len(content.Buttons) < 2 {
+			return nil, fmt.Errorf("%w: poll requires text and at least 2 buttons", ErrInvalidTemplate)
+		}
+		options := make([]string, 0, len(content.Buttons))
+		for _, button := range content.Buttons {
+			options = append(options, button.Text)
+		}
+		return map[string]interface{}{"question": content.Text, "options": options}, nil
+
+	default:
+		return nil, fmt.Errorf("%w: templates are not supported for %s messages", ErrInvalidTemplate, kind)
+	}
+}
+
+func textWithButtons(text string, buttons []model.TemplateButton) string {
+	if len(buttons) == 0 {
+		return text
+	}
+
+	var b strings.Builder
+	b.WriteString(text)
+	b.WriteString("\n")
+	for i, button := range buttons {
+		fmt.Fprintf(&b, "\n%d. %s", i+1, button.Text)
+	}
+	return b.String()
+}
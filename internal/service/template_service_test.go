@@ -0,0 +1,68 @@
+package service
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"zpwoot/internal/model"
+)
+
+func TestRenderContent(t *testing.T) {
+	content := &model.TemplateContent{
+		Text:    "Olá {{name}}, confirma {{date}}?",
+		Caption: "{{name}}",
+		Buttons: []model.TemplateButton{{ID: "yes", Text: "Sim, {{name}}"}, {Text: "Não"}},
+	}
+
+	rendered, missing := RenderContent(content, map[string]string{"name": "Ana"})
+
+	if rendered.Text != "Olá Ana, confirma {{date}}?" || rendered.Caption != "Ana" {
+		t.Errorf("rendered = %+v", rendered)
+	}
+	if rendered.Buttons[0].Text != "Sim, Ana" || rendered.Buttons[0].ID != "yes" {
+		t.Errorf("buttons = %+v", rendered.Buttons)
+	}
+	if !reflect.DeepEqual(missing, []string{"date"}) {
+		t.Errorf("missing = %v, want [date]", missing)
+	}
+	if content.Text != "Olá {{name}}, confirma {{date}}?" {
+		t.Error("RenderContent must not modify the template")
+	}
+}
+
+func TestTemplateFields(t *testing.T) {
+	content := &model.TemplateContent{
+		Text:     "Escolha uma opção",
+		MediaURL: "https://example.com/menu.jpg",
+		Buttons:  []model.TemplateButton{{Text: "Vendas"}, {Text: "Suporte"}},
+	}
+
+	tests := []struct {
+		kind string
+		want map[string]interface{}
+	}{
+		{"text", map[string]interface{}{"message": "Escolha uma opção\n\n1. Vendas\n2. Suporte"}},
+		{"image", map[string]interface{}{"image": "https://example.com/menu.jpg", "caption": "Escolha uma opção"}},
+		{"poll", map[string]interface{}{"question": "Escolha uma opção", "options": []string{"Vendas", "Suporte"}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.kind, func(t *testing.T) {
+			got, err := templateFields(tt.kind, content)
+			if err != nil {
+				t.Fatalf("templateFields() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("templateFields() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	if _, err := templateFields("location", content); !errors.Is(err, ErrInvalidTemplate) {
+		t.Errorf("expected ErrInvalidTemplate for location, got %v", err)
+	}
+	if _, err := templateFields("video", &model.TemplateContent{Text: "sem mídia"}); !errors.Is(err, ErrInvalidTemplate) {
+		t.Errorf("expected ErrInvalidTemplate for video without media, got %v", err)
+	}
+}
@@ -0,0 +1,166 @@
+package service
+
+import (
+	"context"
+	"errors"
+
+	"github.com/google/uuid"
+
+	"zpwoot/internal/model"
+	"zpwoot/internal/repository"
+	"zpwoot/pkg/logger"
+)
+
+var ErrTenantNotFound = errors.New("tenant not found")
+
+// TenantService gerencia as organizações (tenants). Cada tenant autentica com a própria API key,
+// enxerga apenas as próprias sessões e templates, tem uma cota de sessões e um webhook padrão
+// para as sessões novas. Apenas o hash SHA-256 das chaves é gravado.
+type TenantService struct {
+	tenantRepo *repository.TenantRepository
+}
+
+func NewTenantService(tenantRepo *repository.TenantRepository) *TenantService {
+	return &TenantService{
+		tenantRepo: tenantRepo,
+	}
+}
+
+// SetTenants liga a cota e o webhook padrão dos tenants à criação de sessões
+func (m *SessionManager) SetTenants(tenants *TenantService) {
+	m.tenants = tenants
+}
+
+// Create grava o tenant e retorna a API key gerada (exibida apenas nesta resposta)
+func (s *TenantService) Create(ctx context.Context, tenant *model.Tenant) (string, error) {
+	key, hash, prefix, err := generateAPIKey()
+	if err != nil {
+		return "", err
+	}
+
+	tenant.ID = uuid.New().String()
+	tenant.APIKeyHash = hash
+	tenant.APIKeyPrefix = prefix
+
+	if err := s.tenantRepo.Create(ctx, tenant); err != nil {
+		return "", err
+	}
+
+	logger.Log.Info().
+		Str("tenant_id", tenant.ID).
+		Str("name", tenant.Name).
+		Msg("Tenant created")
+
+	return key, nil
+}
+
+func (s *TenantService) Get(ctx context.Context, id string) (*model.Tenant, error) {
+	tenant, err := s.tenantRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if tenant == nil {
+		return nil, ErrTenantNotFound
+	}
+	return tenant, nil
+}
+
+// find retorna o tenant, ou nil se ele não existe
+func (s *TenantService) find(ctx context.Context, id string) (*model.Tenant, error) {
+	return s.tenantRepo.GetByID(ctx, id)
+}
+
+func (s *TenantService) List(ctx context.Context) ([]*model.Tenant, error) {
+	return s.tenantRepo.List(ctx)
+}
+
+func (s *TenantService) Update(ctx context.Context, tenant *model.Tenant) error {
+	if _, err := s.Get(ctx, tenant.ID); err != nil {
+		return err
+	}
+
+	if err := s.tenantRepo.Update(ctx, tenant); err != nil {
+		return err
+	}
+
+	logger.Log.Info().Str("tenant_id", tenant.ID).Msg("Tenant updated")
+	return nil
+}
+
+// Delete remove o tenant; falha com repository.ErrTenantHasSessions se ele ainda tiver sessões
+func (s *TenantService) Delete(ctx context.Context, id string) error {
+	deleted, err := s.tenantRepo.Delete(ctx, id)
+	if err != nil {
+		return err
+	}
+	if !deleted {
+		return ErrTenantNotFound
+	}
+
+	logger.Log.Info().Str("tenant_id", id).Msg("Tenant deleted")
+	return nil
+}
+
+// RotateKey gera uma nova API key para o tenant; a anterior deixa de valer imediatamente
+func (s *TenantService) RotateKey(ctx context.Context, id string) (string, error) {
+	if _, err := s.Get(ctx, id); err != nil {
+		return "", err
+	}
+
+	key, hash, prefix, err := generateAPIKey()
+	if err != nil {
+		return "", err
+	}
+
+	if err := s.tenantRepo.UpdateAPIKey(ctx, id, hash, prefix); err != nil {
+		return "", err
+	}
+
+	logger.Log.Info().Str("tenant_id", id).Str("key_prefix", prefix).Msg("Tenant API key rotated")
+	return key, nil
+}
+
+// findByAPIKey retorna o tenant ativo cuja API key principal é a informada (nil quando nenhum)
+func (s *TenantService) findByAPIKey(ctx context.Context, apiKey string) (*model.Tenant, error) {
+	tenant, err := s.tenantRepo.GetByAPIKeyHash(ctx, HashAPIKey(apiKey))
+	if err != nil || tenant == nil || !tenant.Enabled {
+		return nil, err
+	}
+	return tenant, nil
+}
+
+// sessionQuota retorna a cota de sessões do tenant do contexto (0 = sem cota ou sem tenant)
+func (s *TenantService) sessionQuota(ctx context.Context) (int, error) {
+	tenantID := repository.TenantFromContext(ctx)
+	if tenantID == "" {
+		return 0, nil
+	}
+
+	tenant, err := s.Get(ctx, tenantID)
+	if err != nil {
+		return 0, err
+	}
+
+	return tenant.MaxSessions, nil
+}
+
+// applyDefaults aplica o webhook padrão do tenant do contexto à sessão criada sem webhook
+func (s *TenantService) applyDefaults(ctx context.Context, session *model.Session) error {
+	tenantID := repository.TenantFromContext(ctx)
+	if tenantID == "" || session.WebhookConfig != nil {
+		return nil
+	}
+
+	tenant, err := s.Get(ctx, tenantID)
+	if err != nil {
+		return err
+	}
+
+	if tenant.WebhookDefaults != nil {
+		webhook := *tenant.WebhookDefaults
+		webhook.Events = append([]string(nil), tenant.WebhookDefaults.Events...)
+		session.WebhookConfig = &webhook
+	}
+
+	return nil
+}
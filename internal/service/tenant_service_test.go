@@ -0,0 +1,167 @@
+package service
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/lib/pq"
+
+	"zpwoot/internal/model"
+	"zpwoot/internal/repository"
+)
+
+var tenantTestColumns = []string{
+	"id", "name", "api_key_hash", "api_key_prefix", "max_sessions", "webhook_defaults",
+	"enabled", "created_at", "updated_at",
+}
+
+func newMockTenantService(t *testing.T) (*TenantService, sqlmock.Sqlmock) {
+	t.Helper()
+
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		if err := mock.ExpectationsWereMet(); err != nil {
+			t.Error(err)
+		}
+		db.Close()
+	})
+
+	return NewTenantService(repository.NewTenantRepository(db)), mock
+}
+
+// capturedArg guarda o valor do argumento recebido pelo banco
+type capturedArg struct {
+	value driver.Value
+}
+
+func (a *capturedArg) Match(v driver.Value) bool {
+	a.value = v
+	return true
+}
+
+func TestTenantServiceCreate(t *testing.T) {
+	tenants, mock := newMockTenantService(t)
+
+	hash := &capturedArg{}
+	mock.ExpectQuery("INSERT INTO tenants").
+		WithArgs(sqlmock.AnyArg(), "Agência", hash, sqlmock.AnyArg(), 5, nil, true).
+		WillReturnRows(sqlmock.NewRows([]string{"created_at", "updated_at"}).AddRow(time.Now(), time.Now()))
+
+	tenant := &model.Tenant{Name: "Agência", MaxSessions: 5, Enabled: true}
+	key, err := tenants.Create(context.Background(), tenant)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Apenas o hash da chave vai para o banco
+	if tenant.ID == "" || key == "" || hash.value != HashAPIKey(key) || tenant.APIKeyHash != hash.value {
+		t.Errorf("tenant = %+v, key = %q, stored hash = %v", tenant, key, hash.value)
+	}
+}
+
+func TestTenantServiceNotFound(t *testing.T) {
+	tenants, mock := newMockTenantService(t)
+	ctx := context.Background()
+
+	mock.ExpectQuery("SELECT .* FROM tenants").WithArgs("t1").WillReturnRows(sqlmock.NewRows(tenantTestColumns))
+	if _, err := tenants.Get(ctx, "t1"); !errors.Is(err, ErrTenantNotFound) {
+		t.Errorf("Get error = %v, want ErrTenantNotFound", err)
+	}
+
+	// A chave de um tenant inexistente não é trocada
+	mock.ExpectQuery("SELECT .* FROM tenants").WithArgs("t1").WillReturnRows(sqlmock.NewRows(tenantTestColumns))
+	if _, err := tenants.RotateKey(ctx, "t1"); !errors.Is(err, ErrTenantNotFound) {
+		t.Errorf("RotateKey error = %v, want ErrTenantNotFound", err)
+	}
+
+	mock.ExpectExec("DELETE FROM tenants").WithArgs("t1").WillReturnResult(sqlmock.NewResult(0, 0))
+	if err := tenants.Delete(ctx, "t1"); !errors.Is(err, ErrTenantNotFound) {
+		t.Errorf("Delete error = %v, want ErrTenantNotFound", err)
+	}
+
+	// A FK das sessões impede remover um tenant em uso
+	mock.ExpectExec("DELETE FROM tenants").WithArgs("t2").WillReturnError(&pq.Error{Code: "23503"})
+	if err := tenants.Delete(ctx, "t2"); !errors.Is(err, repository.ErrTenantHasSessions) {
+		t.Errorf("Delete error = %v, want ErrTenantHasSessions", err)
+	}
+}
+
+func TestTenantServiceScope(t *testing.T) {
+	tenants, mock := newMockTenantService(t)
+	ctx := repository.WithTenant(context.Background(), "t1")
+
+	defaults := `{"enabled":true,"url":"https://hooks.example.com","events":["message"]}`
+	tenantRow := func() *sqlmock.Rows {
+		return sqlmock.NewRows(tenantTestColumns).
+			AddRow("t1", "Agência", "hash", "zpw_abc", 3, defaults, true, time.Now(), time.Now())
+	}
+
+	mock.ExpectQuery("SELECT .* FROM tenants").WithArgs("t1").WillReturnRows(tenantRow())
+	if quota, err := tenants.sessionQuota(ctx); err != nil || quota != 3 {
+		t.Errorf("sessionQuota = %d, %v, want 3", quota, err)
+	}
+
+	// Sem tenant no contexto (API key global) não há cota nem consulta ao banco
+	if quota, err := tenants.sessionQuota(context.Background()); err != nil || quota != 0 {
+		t.Errorf("sessionQuota without tenant = %d, %v, want 0", quota, err)
+	}
+
+	mock.ExpectQuery("SELECT .* FROM tenants").WithArgs("t1").WillReturnRows(tenantRow())
+	session := &model.Session{}
+	if err := tenants.applyDefaults(ctx, session); err != nil {
+		t.Fatal(err)
+	}
+	if session.WebhookConfig == nil || session.WebhookConfig.URL != "https://hooks.example.com" || len(session.WebhookConfig.Events) != 1 {
+		t.Errorf("webhook = %+v, want tenant defaults", session.WebhookConfig)
+	}
+
+	// O webhook informado na criação prevalece sobre o padrão do tenant
+	own := &model.WebhookConfig{URL: "https://own.example.com"}
+	session = &model.Session{WebhookConfig: own}
+	if err := tenants.applyDefaults(ctx, session); err != nil || session.WebhookConfig != own {
+		t.Errorf("webhook = %+v, %v, want session webhook", session.WebhookConfig, err)
+	}
+
+	// A API key principal de um tenant desativado não autentica
+	mock.ExpectQuery("SELECT .* FROM tenants").WithArgs(HashAPIKey("zpw_key")).
+		WillReturnRows(sqlmock.NewRows(tenantTestColumns).
+			AddRow("t1", "Agência", HashAPIKey("zpw_key"), "zpw_key", 3, nil, false, time.Now(), time.Now()))
+	if tenant, err := tenants.findByAPIKey(context.Background(), "zpw_key"); err != nil || tenant != nil {
+		t.Errorf("findByAPIKey of disabled tenant = %+v, %v, want nil", tenant, err)
+	}
+}
+
+func TestSessionTenantScope(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	m := &SessionManager{sessionRepo: repository.NewSessionRepository(db)}
+
+	// A sessão de outro tenant não é encontrada: o filtro vai na própria consulta
+	mock.ExpectQuery("SELECT .* FROM sessions").
+		WithArgs("s1", "t1", sqlmock.AnyArg()).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}))
+	if _, err := m.GetSession(repository.WithTenant(context.Background(), "t1"), "s1"); err == nil {
+		t.Error("GetSession of another tenant's session succeeded")
+	}
+
+	mock.ExpectExec("DELETE FROM sessions").
+		WithArgs("s1", "t1").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	if err := m.sessionRepo.Delete(repository.WithTenant(context.Background(), "t1"), "s1"); err == nil {
+		t.Error("Delete of another tenant's session succeeded")
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
@@ -0,0 +1,114 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/textproto"
+	"strings"
+	"time"
+
+	"zpwoot/internal/model"
+)
+
+// TranscriptionAPIError é uma resposta de erro do endpoint /audio/transcriptions
+type TranscriptionAPIError struct {
+	StatusCode int
+	Body       string
+}
+
+func (e *TranscriptionAPIError) Error() string {
+	return fmt.Sprintf("transcription api returned status %d: %s", e.StatusCode, e.Body)
+}
+
+// TranscriptionClient acessa um endpoint compatível com /audio/transcriptions da OpenAI
+// (OpenAI Whisper, Groq, faster-whisper-server, LocalAI...)
+type TranscriptionClient struct {
+	baseURL  string
+	model    string
+	apiKey   string
+	language string
+	client   *http.Client
+}
+
+func NewTranscriptionClient(config *model.TranscriptionConfig, timeout time.Duration) *TranscriptionClient {
+	return &TranscriptionClient{
+		baseURL:  strings.TrimRight(config.BaseURL, "/"),
+		model:    config.Model,
+		apiKey:   config.APIKey,
+		language: config.Language,
+		client:   &http.Client{Timeout: timeout},
+	}
+}
+
+// Transcribe envia o áudio e retorna o texto transcrito
+func (c *TranscriptionClient) Transcribe(ctx context.Context, audio []byte, fileName, mimeType string) (string, error) {
+	var body bytes.Buffer
+	writer := multipart.NewWriter(&body)
+
+	fields := map[string]string{
+		"model":           c.model,
+		"response_format": "json",
+	}
+	if c.language != "" {
+		fields["language"] = c.language
+	}
+	for name, value := range fields {
+		if err := writer.WriteField(name, value); err != nil {
+			return "", fmt.Errorf("failed to write transcription field: %w", err)
+		}
+	}
+
+	header := make(textproto.MIMEHeader)
+	header.Set("Content-Disposition", fmt.Sprintf(`form-data; name="file"; filename="%s"`, strings.ReplaceAll(fileName, `"`, "")))
+	header.Set("Content-Type", mimeType)
+	part, err := writer.CreatePart(header)
+	if err != nil {
+		return "", fmt.Errorf("failed to create transcription file: %w", err)
+	}
+	if _, err := part.Write(audio); err != nil {
+		return "", fmt.Errorf("failed to write transcription file: %w", err)
+	}
+	if err := writer.Close(); err != nil {
+		return "", fmt.Errorf("failed to close transcription request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.baseURL+"/audio/transcriptions", &body)
+	if err != nil {
+		return "", fmt.Errorf("failed to create transcription request: %w", err)
+	}
+
+	req.Header.Set("Content-Type", writer.FormDataContentType())
+	req.Header.Set("User-Agent", "zpwoot-transcription/1.0")
+	if c.apiKey != "" {
+		req.Header.Set("Authorization", "Bearer "+c.apiKey)
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("failed to send transcription request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return "", fmt.Errorf("failed to read transcription response: %w", err)
+	}
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return "", &TranscriptionAPIError{StatusCode: resp.StatusCode, Body: string(data)}
+	}
+
+	var result struct {
+		Text string `json:"text"`
+	}
+	if err := json.Unmarshal(data, &result); err != nil {
+		return "", fmt.Errorf("failed to decode transcription response: %w", err)
+	}
+
+	return strings.TrimSpace(result.Text), nil
+}
@@ -0,0 +1,77 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	waProto "go.mau.fi/whatsmeow/binary/proto"
+	"go.mau.fi/whatsmeow/types/events"
+	"google.golang.org/protobuf/proto"
+
+	"zpwoot/internal/model"
+)
+
+func TestTranscriptionClientTranscribe(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/audio/transcriptions" || r.Header.Get("Authorization") != "Bearer sk-test" {
+			http.Error(w, "unexpected request", http.StatusUnauthorized)
+			return
+		}
+		if err := r.ParseMultipartForm(1 << 20); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		file, header, err := r.FormFile("file")
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		data, _ := io.ReadAll(file)
+
+		if r.FormValue("model") != "whisper-1" || r.FormValue("language") != "pt" || header.Filename != "ABC.ogg" || string(data) != "opus" {
+			http.Error(w, "unexpected form", http.StatusBadRequest)
+			return
+		}
+
+		json.NewEncoder(w).Encode(map[string]string{"text": " Olá, tudo bem? "})
+	}))
+	t.Cleanup(server.Close)
+
+	config := &model.TranscriptionConfig{BaseURL: server.URL + "/v1", Model: "whisper-1", APIKey: "sk-test", Language: "pt"}
+
+	text, err := NewTranscriptionClient(config, 5*time.Second).Transcribe(context.Background(), []byte("opus"), "ABC.ogg", "audio/ogg")
+	if err != nil {
+		t.Fatalf("Transcribe() error = %v", err)
+	}
+	if text != "Olá, tudo bem?" {
+		t.Errorf("Transcribe() = %q", text)
+	}
+
+	config.APIKey = ""
+	if _, err := NewTranscriptionClient(config, 5*time.Second).Transcribe(context.Background(), []byte("opus"), "ABC.ogg", "audio/ogg"); err == nil {
+		t.Error("Transcribe(without key) error = nil, want unauthorized")
+	}
+}
+
+func TestFormatMessageTranscription(t *testing.T) {
+	evt := &events.Message{Message: &waProto.Message{
+		AudioMessage: &waProto.AudioMessage{Mimetype: proto.String("audio/ogg; codecs=opus"), PTT: proto.Bool(true)},
+	}}
+
+	formatter := NewWebhookFormatter()
+
+	payload := formatter.FormatMessage("session", evt, "Olá")
+	if payload.Data["type"] != "audio" || payload.Data["ptt"] != true || payload.Data["transcription"] != "Olá" {
+		t.Errorf("FormatMessage() data = %v", payload.Data)
+	}
+
+	if _, ok := formatter.FormatMessage("session", evt, "").Data["transcription"]; ok {
+		t.Error("FormatMessage() without transcription should omit data.transcription")
+	}
+}
@@ -0,0 +1,145 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"mime"
+	"strings"
+	"time"
+
+	"go.mau.fi/whatsmeow/types/events"
+
+	"zpwoot/internal/model"
+	"zpwoot/internal/repository"
+	"zpwoot/pkg/logger"
+)
+
+// TranscriptionServiceConfig define os limites da transcrição
+type TranscriptionServiceConfig struct {
+	Timeout      time.Duration // Tempo máximo para baixar e transcrever uma mensagem de voz
+	MaxAudioSize int64         // Mensagens de voz maiores (bytes) não são transcritas
+	Concurrency  int           // Transcrições simultâneas
+}
+
+// TranscriptionService transcreve as mensagens de voz (PTT) das sessões que ativaram a transcrição,
+// usando um endpoint compatível com a API do Whisper. O texto vai no webhook da mensagem
+// (data.transcription) e é gravado junto com a mensagem.
+type TranscriptionService struct {
+	sessionRepo    *repository.SessionRepository
+	messageRepo    *repository.MessageRepository
+	sessionManager *SessionManager
+	config         TranscriptionServiceConfig
+	slots          chan struct{}
+}
+
+func NewTranscriptionService(sessionRepo *repository.SessionRepository, messageRepo *repository.MessageRepository, sessionManager *SessionManager, config TranscriptionServiceConfig) *TranscriptionService {
+	if config.Timeout <= 0 {
+		config.Timeout = 60 * time.Second
+	}
+	if config.MaxAudioSize <= 0 {
+		config.MaxAudioSize = 25 << 20
+	}
+	if config.Concurrency <= 0 {
+		config.Concurrency = 4
+	}
+
+	return &TranscriptionService{
+		sessionRepo:    sessionRepo,
+		messageRepo:    messageRepo,
+		sessionManager: sessionManager,
+		config:         config,
+		slots:          make(chan struct{}, config.Concurrency),
+	}
+}
+
+// SetTranscription liga a transcrição de mensagens de voz ao processamento de eventos das sessões
+func (m *SessionManager) SetTranscription(transcription *TranscriptionService) {
+	m.eventHandler.transcription = transcription
+}
+
+// sessionConfig retorna a configuração de transcrição se a mensagem for uma mensagem de voz a
+// transcrever (nil caso contrário)
+func (s *TranscriptionService) sessionConfig(sessionID string, evt *events.Message) *model.TranscriptionConfig {
+	audio := evt.Message.GetAudioMessage()
+	if audio == nil || !audio.GetPTT() {
+		return nil
+	}
+
+	session, err := s.sessionRepo.GetByID(context.Background(), sessionID)
+	if err != nil {
+		logger.Log.Warn().Err(err).Str("session_id", sessionID).Msg("Failed to load session for transcription")
+		return nil
+	}
+	if !session.TranscriptionEnabled() {
+		return nil
+	}
+	if evt.Info.IsFromMe && !session.TranscriptionConfig.FromMe {
+		return nil
+	}
+
+	if size := int64(audio.GetFileLength()); size > s.config.MaxAudioSize {
+		logger.Log.Debug().
+			Str("session_id", sessionID).
+			Str("message_id", evt.Info.ID).
+			Int64("size", size).
+			Msg("Voice note too large to transcribe")
+		return nil
+	}
+
+	return session.TranscriptionConfig
+}
+
+// Transcribe baixa a mensagem de voz, transcreve e grava a mensagem com o texto.
+// Retorna vazio se a transcrição falhar.
+func (s *TranscriptionService) Transcribe(sessionID string, evt *events.Message, config *model.TranscriptionConfig) string {
+	s.slots <- struct{}{}
+	defer func() { <-s.slots }()
+
+	log := logger.Log.With().Str("session_id", sessionID).Str("message_id", evt.Info.ID).Logger()
+
+	ctx, cancel := context.WithTimeout(context.Background(), s.config.Timeout)
+	defer cancel()
+
+	text, err := s.transcribe(ctx, sessionID, evt, config)
+	if err != nil {
+		log.Warn().Err(err).Msg("Failed to transcribe voice note")
+		return ""
+	}
+	if text == "" {
+		return ""
+	}
+
+	msg := buildStoredMessage(sessionID, evt, model.MessageSourceLive)
+	msg.Transcription = text
+	if err := s.messageRepo.UpsertMessages(ctx, []*model.Message{msg}); err != nil {
+		log.Error().Err(err).Msg("Failed to store transcription")
+	}
+
+	log.Debug().Int("length", len(text)).Msg("Voice note transcribed")
+	return text
+}
+
+func (s *TranscriptionService) transcribe(ctx context.Context, sessionID string, evt *events.Message, config *model.TranscriptionConfig) (string, error) {
+	client, err := s.sessionManager.GetClient(sessionID)
+	if err != nil {
+		return "", fmt.Errorf("client not found: %w", err)
+	}
+
+	audio := evt.Message.GetAudioMessage()
+	data, err := client.Download(ctx, audio)
+	if err != nil {
+		return "", fmt.Errorf("failed to download voice note: %w", err)
+	}
+
+	// O Whisper identifica o formato pela extensão do arquivo (mensagens de voz são ogg/opus)
+	mimeType := strings.Split(audio.GetMimetype(), ";")[0]
+	if mimeType == "" {
+		mimeType = "audio/ogg"
+	}
+	fileName := evt.Info.ID + ".ogg"
+	if exts, _ := mime.ExtensionsByType(mimeType); len(exts) > 0 && mimeType != "audio/ogg" {
+		fileName = evt.Info.ID + exts[0]
+	}
+
+	return NewTranscriptionClient(config, s.config.Timeout).Transcribe(ctx, data, fileName, mimeType)
+}
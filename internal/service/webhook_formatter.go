@@ -3,8 +3,11 @@ package service
 import (
 	"time"
 
+	waBinary "go.mau.fi/whatsmeow/binary"
+	"go.mau.fi/whatsmeow/types"
 	"go.mau.fi/whatsmeow/types/events"
 	"zpwoot/internal/constants"
+	"zpwoot/internal/model"
 )
 
 type WebhookFormatter struct{}
@@ -20,7 +23,9 @@ type WebhookPayload struct {
 	Data      map[string]interface{} `json:"data"`
 }
 
-func (f *WebhookFormatter) FormatMessage(sessionID string, evt *events.Message) *WebhookPayload {
+// FormatMessage monta o webhook de mensagem; transcription é o texto das mensagens de voz
+// transcritas (omitido quando vazio)
+func (f *WebhookFormatter) FormatMessage(sessionID string, evt *events.Message, transcription string) *WebhookPayload {
 	data := map[string]interface{}{
 		"message_id": evt.Info.ID,
 		"from":       evt.Info.Sender.String(),
@@ -47,6 +52,7 @@ func (f *WebhookFormatter) FormatMessage(sessionID string, evt *events.Message)
 	} else if evt.Message.AudioMessage != nil {
 		data["type"] = "audio"
 		data["mime_type"] = evt.Message.AudioMessage.Mimetype
+		data["ptt"] = evt.Message.AudioMessage.GetPTT()
 	} else if evt.Message.DocumentMessage != nil {
 		data["type"] = "document"
 		data["file_name"] = evt.Message.DocumentMessage.FileName
@@ -55,6 +61,10 @@ func (f *WebhookFormatter) FormatMessage(sessionID string, evt *events.Message)
 		data["type"] = "unknown"
 	}
 
+	if transcription != "" {
+		data["transcription"] = transcription
+	}
+
 	return &WebhookPayload{
 		Event:     string(constants.EventMessage),
 		SessionID: sessionID,
@@ -106,6 +116,72 @@ func (f *WebhookFormatter) FormatDisconnected(sessionID string, evt *events.Disc
 	}
 }
 
+func (f *WebhookFormatter) FormatPairError(sessionID string, evt *events.PairError) *WebhookPayload {
+	data := map[string]interface{}{}
+
+	if evt.Error != nil {
+		data["error"] = evt.Error.Error()
+	}
+	if !evt.ID.IsEmpty() {
+		data["jid"] = evt.ID.String()
+	}
+	if evt.BusinessName != "" {
+		data["business_name"] = evt.BusinessName
+	}
+	if evt.Platform != "" {
+		data["platform"] = evt.Platform
+	}
+
+	return &WebhookPayload{
+		Event:     string(constants.EventPairError),
+		SessionID: sessionID,
+		Timestamp: time.Now(),
+		Data:      data,
+	}
+}
+
+func (f *WebhookFormatter) FormatKeepAliveTimeout(sessionID string, evt *events.KeepAliveTimeout) *WebhookPayload {
+	data := map[string]interface{}{
+		"error_count":  evt.ErrorCount,
+		"last_success": evt.LastSuccess,
+	}
+
+	return &WebhookPayload{
+		Event:     string(constants.EventKeepAliveTimeout),
+		SessionID: sessionID,
+		Timestamp: time.Now(),
+		Data:      data,
+	}
+}
+
+func (f *WebhookFormatter) FormatKeepAliveRestored(sessionID string, evt *events.KeepAliveRestored) *WebhookPayload {
+	data := map[string]interface{}{
+		"status": "restored",
+	}
+
+	return &WebhookPayload{
+		Event:     string(constants.EventKeepAliveRestored),
+		SessionID: sessionID,
+		Timestamp: time.Now(),
+		Data:      data,
+	}
+}
+
+func (f *WebhookFormatter) FormatTemporaryBan(sessionID string, evt *events.TemporaryBan) *WebhookPayload {
+	data := map[string]interface{}{
+		"code":           int(evt.Code),
+		"reason":         evt.Code.String(),
+		"expire_seconds": int(evt.Expire.Seconds()),
+	}
+
+	return &WebhookPayload{
+		Event:     string(constants.EventTemporaryBan),
+		SessionID: sessionID,
+		Timestamp: time.Now(),
+		Data:      data,
+	}
+}
+
 func (f *WebhookFormatter) FormatGroupInfo(sessionID string, evt *events.GroupInfo) *WebhookPayload {
 	data := map[string]interface{}{
 		"jid":       evt.JID.String(),
@@ -140,3 +216,201 @@ func (f *WebhookFormatter) FormatPicture(sessionID string, evt *events.Picture)
 		Data:      data,
 	}
 }
+
+func (f *WebhookFormatter) FormatHistorySync(sessionID string, summary *HistorySyncSummary) *WebhookPayload {
+	data := map[string]interface{}{
+		"sync_type":     summary.SyncType,
+		"chunk_order":   summary.ChunkOrder,
+		"progress":      summary.Progress,
+		"conversations": summary.Conversations,
+		"messages":      summary.Messages,
+		"skipped":       summary.Skipped,
+	}
+
+	if summary.Totals != nil {
+		data["total_chunks"] = summary.Totals.ChunksProcessed
+		data["total_conversations"] = summary.Totals.Conversations
+		data["total_messages"] = summary.Totals.Messages
+	}
+
+	return &WebhookPayload{
+		Event:     string(constants.EventHistorySync),
+		SessionID: sessionID,
+		Timestamp: time.Now(),
+		Data:      data,
+	}
+}
+
+// callMetaData monta os campos comuns aos eventos de chamada
+func callMetaData(meta types.BasicCallMeta) map[string]interface{} {
+	data := map[string]interface{}{
+		"call_id":   meta.CallID,
+		"from":      meta.From.String(),
+		"caller":    meta.CallCreator.String(),
+		"timestamp": meta.Timestamp,
+		"is_group":  !meta.GroupJID.IsEmpty(),
+	}
+
+	if !meta.GroupJID.IsEmpty() {
+		data["group_jid"] = meta.GroupJID.String()
+	}
+
+	return data
+}
+
+// isVideoCall verifica se o nó da oferta de chamada contém mídia de vídeo
+func isVideoCall(node *waBinary.Node) bool {
+	if node == nil {
+		return false
+	}
+	_, ok := node.GetOptionalChildByTag("video")
+	return ok
+}
+
+func (f *WebhookFormatter) FormatCallOffer(sessionID string, evt *events.CallOffer, action string) *WebhookPayload {
+	data := callMetaData(evt.BasicCallMeta)
+	data["is_video"] = isVideoCall(evt.Data)
+	data["platform"] = evt.RemotePlatform
+	data["version"] = evt.RemoteVersion
+	data["action"] = action
+
+	return &WebhookPayload{
+		Event:     string(constants.EventCallOffer),
+		SessionID: sessionID,
+		Timestamp: time.Now(),
+		Data:      data,
+	}
+}
+
+func (f *WebhookFormatter) FormatCallAccept(sessionID string, evt *events.CallAccept) *WebhookPayload {
+	data := callMetaData(evt.BasicCallMeta)
+	data["is_video"] = isVideoCall(evt.Data)
+	data["platform"] = evt.RemotePlatform
+	data["version"] = evt.RemoteVersion
+
+	return &WebhookPayload{
+		Event:     string(constants.EventCallAccept),
+		SessionID: sessionID,
+		Timestamp: time.Now(),
+		Data:      data,
+	}
+}
+
+func (f *WebhookFormatter) FormatCallTerminate(sessionID string, evt *events.CallTerminate) *WebhookPayload {
+	data := callMetaData(evt.BasicCallMeta)
+	data["reason"] = evt.Reason
+
+	return &WebhookPayload{
+		Event:     string(constants.EventCallTerminate),
+		SessionID: sessionID,
+		Timestamp: time.Now(),
+		Data:      data,
+	}
+}
+
+func (f *WebhookFormatter) FormatNewsletterJoin(sessionID string, evt *events.NewsletterJoin) *WebhookPayload {
+	data := map[string]interface{}{
+		"jid":              evt.ID.String(),
+		"name":             evt.ThreadMeta.Name.Text,
+		"description":      evt.ThreadMeta.Description.Text,
+		"invite_code":      evt.ThreadMeta.InviteCode,
+		"subscriber_count": evt.ThreadMeta.SubscriberCount,
+	}
+
+	if evt.ViewerMeta != nil {
+		data["role"] = string(evt.ViewerMeta.Role)
+		data["mute"] = string(evt.ViewerMeta.Mute)
+	}
+
+	return &WebhookPayload{
+		Event:     string(constants.EventNewsletterJoin),
+		SessionID: sessionID,
+		Timestamp: time.Now(),
+		Data:      data,
+	}
+}
+
+func (f *WebhookFormatter) FormatNewsletterLeave(sessionID string, evt *events.NewsletterLeave) *WebhookPayload {
+	data := map[string]interface{}{
+		"jid":  evt.ID.String(),
+		"role": string(evt.Role),
+	}
+
+	return &WebhookPayload{
+		Event:     string(constants.EventNewsletterLeave),
+		SessionID: sessionID,
+		Timestamp: time.Now(),
+		Data:      data,
+	}
+}
+
+func (f *WebhookFormatter) FormatNewsletterMuteChange(sessionID string, evt *events.NewsletterMuteChange) *WebhookPayload {
+	data := map[string]interface{}{
+		"jid":  evt.ID.String(),
+		"mute": string(evt.Mute),
+	}
+
+	return &WebhookPayload{
+		Event:     string(constants.EventNewsletterMuteChange),
+		SessionID: sessionID,
+		Timestamp: time.Now(),
+		Data:      data,
+	}
+}
+
+func (f *WebhookFormatter) FormatNewsletterLiveUpdate(sessionID string, evt *events.NewsletterLiveUpdate) *WebhookPayload {
+	messages := make([]map[string]interface{}, 0, len(evt.Messages))
+	for _, msg := range evt.Messages {
+		if msg == nil {
+			continue
+		}
+		messages = append(messages, map[string]interface{}{
+			"server_id":       msg.MessageServerID,
+			"message_id":      msg.MessageID,
+			"type":            msg.Type,
+			"timestamp":       msg.Timestamp,
+			"views_count":     msg.ViewsCount,
+			"reaction_counts": msg.ReactionCounts,
+		})
+	}
+
+	data := map[string]interface{}{
+		"jid":      evt.JID.String(),
+		"time":     evt.Time,
+		"messages": messages,
+	}
+
+	return &WebhookPayload{
+		Event:     string(constants.EventNewsletterLiveUpdate),
+		SessionID: sessionID,
+		Timestamp: time.Now(),
+		Data:      data,
+	}
+}
+
+func (f *WebhookFormatter) FormatMessageJob(sessionID string, job *model.MessageJob) *WebhookPayload {
+	data := map[string]interface{}{
+		"job_id":   job.ID,
+		"status":   string(job.Status),
+		"type":     job.Type,
+		"phone":    job.Phone,
+		"attempts": job.Attempts,
+	}
+
+	if job.MessageID != "" {
+		data["message_id"] = job.MessageID
+	}
+	if job.Error != "" {
+		data["error"] = job.Error
+	}
+	if job.SentAt != nil {
+		data["sent_at"] = job.SentAt
+	}
+
+	return &WebhookPayload{
+		Event:     string(constants.EventMessageJob),
+		SessionID: sessionID,
+		Timestamp: time.Now(),
+		Data:      data,
+	}
+}
@@ -0,0 +1,63 @@
+package service
+
+import (
+	"testing"
+
+	waBinary "go.mau.fi/whatsmeow/binary"
+	"go.mau.fi/whatsmeow/types"
+	"go.mau.fi/whatsmeow/types/events"
+
+	"zpwoot/internal/constants"
+	"zpwoot/internal/model"
+)
+
+func TestFormatCallEvents(t *testing.T) {
+	f := NewWebhookFormatter()
+	caller := types.NewJID("5511999999999", types.DefaultUserServer)
+	meta := types.BasicCallMeta{From: caller, CallCreator: caller, CallID: "call-1"}
+
+	offer := &events.CallOffer{
+		BasicCallMeta:  meta,
+		CallRemoteMeta: types.CallRemoteMeta{RemotePlatform: "android"},
+		Data:           &waBinary.Node{Tag: "offer", Content: []waBinary.Node{{Tag: "audio"}, {Tag: "video"}}},
+	}
+	payload := f.FormatCallOffer("s1", offer, string(model.CallPolicyReject))
+	if payload.Event != string(constants.EventCallOffer) || payload.Data["call_id"] != "call-1" ||
+		payload.Data["action"] != "reject" || payload.Data["is_video"] != true || payload.Data["is_group"] != false {
+		t.Errorf("offer data = %v", payload.Data)
+	}
+	if _, ok := payload.Data["group_jid"]; ok {
+		t.Errorf("offer data = %v, want no group_jid for 1:1 call", payload.Data)
+	}
+
+	// Chamada de voz em grupo
+	meta.GroupJID = types.NewJID("120363025246125486", types.GroupServer)
+	offer = &events.CallOffer{BasicCallMeta: meta, Data: &waBinary.Node{Tag: "offer", Content: []waBinary.Node{{Tag: "audio"}}}}
+	payload = f.FormatCallOffer("s1", offer, string(model.CallPolicyAccept))
+	if payload.Data["is_video"] != false || payload.Data["is_group"] != true || payload.Data["group_jid"] != meta.GroupJID.String() {
+		t.Errorf("group offer data = %v", payload.Data)
+	}
+
+	payload = f.FormatCallTerminate("s1", &events.CallTerminate{BasicCallMeta: meta, Reason: "timeout"})
+	if payload.Event != string(constants.EventCallTerminate) || payload.Data["reason"] != "timeout" {
+		t.Errorf("terminate data = %v", payload.Data)
+	}
+}
+
+func TestSessionCallPolicy(t *testing.T) {
+	tests := []struct {
+		config *model.CallConfig
+		want   model.CallPolicy
+	}{
+		{nil, model.CallPolicyAccept},
+		{&model.CallConfig{}, model.CallPolicyAccept},
+		{&model.CallConfig{Policy: model.CallPolicyRejectMessage, Message: "Não atendemos ligações"}, model.CallPolicyRejectMessage},
+	}
+
+	for _, tt := range tests {
+		session := &model.Session{CallConfig: tt.config}
+		if got := session.CallPolicy(); got != tt.want {
+			t.Errorf("CallPolicy(%+v) = %s, want %s", tt.config, got, tt.want)
+		}
+	}
+}
@@ -10,35 +10,50 @@ import (
 	natsclient "zpwoot/internal/nats"
 	"zpwoot/internal/repository"
 	"zpwoot/pkg/logger"
+	"zpwoot/pkg/secrets"
 )
 
 type WebhookProcessor struct {
 	natsClient  *natsclient.Client
 	formatter   *WebhookFormatter
 	sessionRepo *repository.SessionRepository
+	eventStream *EventStreamHub
 }
 
 func NewWebhookProcessor(
 	natsClient *natsclient.Client,
 	formatter *WebhookFormatter,
 	sessionRepo *repository.SessionRepository,
+	eventStream *EventStreamHub,
 ) *WebhookProcessor {
 	return &WebhookProcessor{
 		natsClient:  natsClient,
 		formatter:   formatter,
 		sessionRepo: sessionRepo,
+		eventStream: eventStream,
 	}
 }
 
 type WebhookMessage struct {
 	SessionID    string          `json:"session_id"`
 	WebhookURL   string          `json:"webhook_url"`
-	WebhookToken string          `json:"webhook_token,omitempty"`
+	WebhookToken string          `json:"webhook_token,omitempty"` // Cifrado com a chave mestra (a mensagem fica gravada no JetStream)
 	Attempt      int             `json:"attempt"`
 	Payload      *WebhookPayload `json:"payload"`
 }
 
 func (p *WebhookProcessor) ProcessEvent(sessionID string, eventType constants.WebhookEventType, payload *WebhookPayload) error {
+	// 0. Publish to event stream (SSE/WebSocket), independent of webhook config
+	if p.eventStream != nil {
+		if err := p.eventStream.Publish(sessionID, payload); err != nil {
+			logger.Log.Warn().
+				Err(err).
+				Str("session_id", sessionID).
+				Str("event", string(eventType)).
+				Msg("Failed to publish event to stream")
+		}
+	}
+
 	// 1. Get session from database
 	ctx := context.Background()
 	session, err := p.sessionRepo.GetByID(ctx, sessionID)
@@ -77,10 +92,15 @@ func (p *WebhookProcessor) ProcessEvent(sessionID string, eventType constants.We
 	}
 
 	// 5. Create webhook message
+	webhookToken, err := secrets.Encrypt(session.WebhookConfig.Token)
+	if err != nil {
+		return fmt.Errorf("failed to encrypt webhook token: %w", err)
+	}
+
 	webhookMsg := &WebhookMessage{
 		SessionID:    sessionID,
 		WebhookURL:   session.WebhookConfig.URL,
-		WebhookToken: session.WebhookConfig.Token,
+		WebhookToken: webhookToken,
 		Attempt:      1,
 		Payload:      payload,
 	}
@@ -10,6 +10,7 @@ import (
 	"github.com/rs/zerolog"
 	natsclient "zpwoot/internal/nats"
 	"zpwoot/pkg/logger"
+	"zpwoot/pkg/secrets"
 )
 
 type WebhookWorker struct {
@@ -92,8 +93,18 @@ func (w *WebhookWorker) handleMessage(msg *nats.Msg) {
 		return
 	}
 
+	// Token cifrado na fila; retentativas republicam a mensagem ainda cifrada
+	webhookToken, err := secrets.Decrypt(webhookMsg.WebhookToken)
+	if err != nil {
+		sessionLog.Error().
+			Err(err).
+			Msg("Failed to decrypt webhook token")
+		msg.Ack() // ACK to remove from queue
+		return
+	}
+
 	// Deliver webhook
-	result := w.delivery.Send(webhookMsg.WebhookURL, payloadBytes, webhookMsg.WebhookToken)
+	result := w.delivery.Send(webhookMsg.WebhookURL, payloadBytes, webhookToken)
 
 	// Handle result
 	if result.Success {
@@ -0,0 +1,246 @@
+// Package secrets cifra segredos gravados no banco (senhas de proxy, tokens e API keys) com
+// envelope encryption: cada valor é cifrado com uma chave de dados aleatória (AES-256-GCM), e a
+// chave de dados é cifrada com a chave mestra configurada. Chaves mestras anteriores continuam
+// decifrando os valores antigos até que sejam recifrados com a atual.
+package secrets
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// prefix identifica valores cifrados: enc:v1:<id da chave mestra>:<chave de dados>:<valor>
+const prefix = "enc:v1:"
+
+// plainPrefix marca valores gravados em texto puro (sem chave mestra) que começam com prefix, para
+// que não sejam lidos como cifrados. Fica dentro de prefix, que já era reservado: nenhum valor
+// legado em texto puro começa com ele.
+const plainPrefix = prefix + "plain:"
+
+var (
+	// ErrNoKey indica um valor cifrado sem chave mestra configurada (ou com uma chave desconhecida)
+	ErrNoKey = errors.New("secret encrypted with an unknown master key")
+	// ErrMalformed indica um valor com o prefixo de cifrado, mas em formato inválido
+	ErrMalformed = errors.New("malformed encrypted secret")
+)
+
+type masterKey struct {
+	id   string
+	aead cipher.AEAD
+}
+
+// Keyring guarda a chave mestra atual (usada para cifrar) e as anteriores (apenas para decifrar)
+type Keyring struct {
+	current *masterKey
+	keys    map[string]*masterKey
+}
+
+// NewKeyring recebe as chaves mestras em base64 (32 bytes); a primeira é a atual
+func NewKeyring(current string, previous ...string) (*Keyring, error) {
+	keyring := &Keyring{keys: make(map[string]*masterKey)}
+
+	for i, encoded := range append([]string{current}, previous...) {
+		key, err := parseMasterKey(encoded)
+		if err != nil {
+			return nil, fmt.Errorf("invalid master key %d: %w", i+1, err)
+		}
+		if i == 0 {
+			keyring.current = key
+		}
+		keyring.keys[key.id] = key
+	}
+
+	return keyring, nil
+}
+
+func parseMasterKey(encoded string) (*masterKey, error) {
+	raw, err := base64.StdEncoding.DecodeString(strings.TrimSpace(encoded))
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode base64: %w", err)
+	}
+	if len(raw) != 32 {
+		return nil, fmt.Errorf("key must have 32 bytes, got %d", len(raw))
+	}
+
+	aead, err := newAEAD(raw)
+	if err != nil {
+		return nil, err
+	}
+
+	// O ID é derivado da própria chave: nenhum nome precisa ser configurado
+	sum := sha256.Sum256(raw)
+	return &masterKey{id: hex.EncodeToString(sum[:4]), aead: aead}, nil
+}
+
+func newAEAD(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create cipher: %w", err)
+	}
+	return cipher.NewGCM(block)
+}
+
+// seal cifra plaintext e retorna nonce || ciphertext
+func seal(aead cipher.AEAD, plaintext, additionalData []byte) ([]byte, error) {
+	nonce := make([]byte, aead.NonceSize(), aead.NonceSize()+len(plaintext)+aead.Overhead())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, fmt.Errorf("failed to generate nonce: %w", err)
+	}
+	return aead.Seal(nonce, nonce, plaintext, additionalData), nil
+}
+
+func open(aead cipher.AEAD, sealed, additionalData []byte) ([]byte, error) {
+	if len(sealed) < aead.NonceSize() {
+		return nil, ErrMalformed
+	}
+	return aead.Open(nil, sealed[:aead.NonceSize()], sealed[aead.NonceSize():], additionalData)
+}
+
+// Encrypt cifra o valor com uma chave de dados nova, cifrada pela chave mestra atual. Valores
+// vazios são retornados como estão; os demais são sempre cifrados, inclusive os que já parecem
+// cifrados (os valores lidos do banco chegam decifrados por Scan, então só a entrada do cliente
+// pode ter o prefixo).
+func (k *Keyring) Encrypt(plaintext string) (string, error) {
+	if plaintext == "" {
+		return plaintext, nil
+	}
+
+	dataKey := make([]byte, 32)
+	if _, err := rand.Read(dataKey); err != nil {
+		return "", fmt.Errorf("failed to generate data key: %w", err)
+	}
+
+	aead, err := newAEAD(dataKey)
+	if err != nil {
+		return "", err
+	}
+	value, err := seal(aead, []byte(plaintext), nil)
+	if err != nil {
+		return "", err
+	}
+
+	wrappedKey, err := seal(k.current.aead, dataKey, []byte(k.current.id))
+	if err != nil {
+		return "", err
+	}
+
+	return prefix + k.current.id + ":" +
+		base64.RawStdEncoding.EncodeToString(wrappedKey) + ":" +
+		base64.RawStdEncoding.EncodeToString(value), nil
+}
+
+// Decrypt decifra valores cifrados por qualquer chave do keyring; valores em texto puro
+// (gravados antes da configuração da chave mestra) são retornados como estão
+func (k *Keyring) Decrypt(value string) (string, error) {
+	if !IsEncrypted(value) {
+		return unescapePlaintext(value), nil
+	}
+
+	parts := strings.Split(strings.TrimPrefix(value, prefix), ":")
+	if len(parts) != 3 {
+		return "", ErrMalformed
+	}
+
+	master, ok := k.keys[parts[0]]
+	if !ok {
+		return "", fmt.Errorf("%w: %s", ErrNoKey, parts[0])
+	}
+
+	wrappedKey, err := base64.RawStdEncoding.DecodeString(parts[1])
+	if err != nil {
+		return "", ErrMalformed
+	}
+	sealed, err := base64.RawStdEncoding.DecodeString(parts[2])
+	if err != nil {
+		return "", ErrMalformed
+	}
+
+	dataKey, err := open(master.aead, wrappedKey, []byte(master.id))
+	if err != nil {
+		return "", fmt.Errorf("failed to decrypt data key: %w", err)
+	}
+	aead, err := newAEAD(dataKey)
+	if err != nil {
+		return "", err
+	}
+	plaintext, err := open(aead, sealed, nil)
+	if err != nil {
+		return "", fmt.Errorf("failed to decrypt secret: %w", err)
+	}
+
+	return string(plaintext), nil
+}
+
+// NeedsRotation informa se o valor está em texto puro ou cifrado por uma chave mestra anterior
+func (k *Keyring) NeedsRotation(value string) bool {
+	if value == "" {
+		return false
+	}
+	return !strings.HasPrefix(value, prefix+k.current.id+":")
+}
+
+// IsEncrypted informa se o valor está no formato cifrado
+func IsEncrypted(value string) bool {
+	return strings.HasPrefix(value, prefix) && !strings.HasPrefix(value, plainPrefix)
+}
+
+// escapePlaintext marca o texto puro que seria confundido com um valor cifrado (ou marcado)
+func escapePlaintext(value string) string {
+	if strings.HasPrefix(value, prefix) {
+		return plainPrefix + value
+	}
+	return value
+}
+
+func unescapePlaintext(value string) string {
+	return strings.TrimPrefix(value, plainPrefix)
+}
+
+// Keyring padrão usado pelos métodos Value/Scan dos modelos
+var (
+	mu             sync.RWMutex
+	defaultKeyring *Keyring
+)
+
+// SetDefault define o keyring usado por Encrypt/Decrypt (nil desliga a cifragem)
+func SetDefault(keyring *Keyring) {
+	mu.Lock()
+	defer mu.Unlock()
+	defaultKeyring = keyring
+}
+
+// Default retorna o keyring padrão (nil sem chave mestra configurada)
+func Default() *Keyring {
+	mu.RLock()
+	defer mu.RUnlock()
+	return defaultKeyring
+}
+
+// Encrypt cifra com o keyring padrão; sem chave mestra o valor é gravado em texto puro
+func Encrypt(plaintext string) (string, error) {
+	keyring := Default()
+	if keyring == nil {
+		return escapePlaintext(plaintext), nil
+	}
+	return keyring.Encrypt(plaintext)
+}
+
+// Decrypt decifra com o keyring padrão; valores cifrados sem chave mestra configurada retornam ErrNoKey
+func Decrypt(value string) (string, error) {
+	keyring := Default()
+	if keyring == nil {
+		if IsEncrypted(value) {
+			return "", ErrNoKey
+		}
+		return unescapePlaintext(value), nil
+	}
+	return keyring.Decrypt(value)
+}
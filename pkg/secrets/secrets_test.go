@@ -0,0 +1,136 @@
+package secrets
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func newKey(t *testing.T) string {
+	t.Helper()
+	key := make([]byte, 32)
+	if _, err := rand.Read(key); err != nil {
+		t.Fatal(err)
+	}
+	return base64.StdEncoding.EncodeToString(key)
+}
+
+func TestKeyringRoundTrip(t *testing.T) {
+	keyring, err := NewKeyring(newKey(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	encrypted, err := keyring.Encrypt("proxypass")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !IsEncrypted(encrypted) || strings.Contains(encrypted, "proxypass") {
+		t.Fatalf("Encrypt = %q", encrypted)
+	}
+	if again, _ := keyring.Encrypt("proxypass"); again == encrypted {
+		t.Error("Encrypt reused the data key/nonce")
+	}
+
+	if plaintext, err := keyring.Decrypt(encrypted); err != nil || plaintext != "proxypass" {
+		t.Errorf("Decrypt = %q, %v", plaintext, err)
+	}
+	if plaintext, err := keyring.Decrypt("legacy-plaintext"); err != nil || plaintext != "legacy-plaintext" {
+		t.Errorf("Decrypt(plaintext) = %q, %v", plaintext, err)
+	}
+	if value, _ := keyring.Encrypt(""); value != "" {
+		t.Errorf("Encrypt(\"\") = %q", value)
+	}
+
+	// Alterar qualquer byte invalida o valor (GCM autentica a chave de dados e o conteúdo)
+	tampered := encrypted[:len(encrypted)-2] + "AA"
+	if _, err := keyring.Decrypt(tampered); err == nil {
+		t.Error("Decrypt accepted a tampered value")
+	}
+}
+
+func TestKeyringRotation(t *testing.T) {
+	oldKey, newKeyValue := newKey(t), newKey(t)
+
+	oldKeyring, _ := NewKeyring(oldKey)
+	encrypted, _ := oldKeyring.Encrypt("token")
+
+	keyring, err := NewKeyring(newKeyValue, oldKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if plaintext, err := keyring.Decrypt(encrypted); err != nil || plaintext != "token" {
+		t.Fatalf("Decrypt with previous key = %q, %v", plaintext, err)
+	}
+	if !keyring.NeedsRotation(encrypted) || !keyring.NeedsRotation("token") || keyring.NeedsRotation("") {
+		t.Error("NeedsRotation should flag plaintext and previous keys only")
+	}
+
+	reencrypted, _ := keyring.Encrypt("token")
+	if keyring.NeedsRotation(reencrypted) {
+		t.Error("value encrypted with the current key needs rotation")
+	}
+
+	// Sem a chave anterior o valor antigo não pode mais ser lido
+	withoutOld, _ := NewKeyring(newKeyValue)
+	if _, err := withoutOld.Decrypt(encrypted); !errors.Is(err, ErrNoKey) {
+		t.Errorf("Decrypt without previous key error = %v, want ErrNoKey", err)
+	}
+
+	if _, err := NewKeyring("c2hvcnQ="); err == nil {
+		t.Error("NewKeyring accepted a short key")
+	}
+}
+
+func TestEncryptLookalikeInput(t *testing.T) {
+	lookalike := "enc:v1:x:y:z"
+
+	// Com chave mestra, o valor informado pelo cliente é cifrado como qualquer outro
+	keyring, _ := NewKeyring(newKey(t))
+	encrypted, err := keyring.Encrypt(lookalike)
+	if err != nil || encrypted == lookalike {
+		t.Fatalf("Encrypt(%q) = %q, %v; want a new ciphertext", lookalike, encrypted, err)
+	}
+	if plaintext, err := keyring.Decrypt(encrypted); err != nil || plaintext != lookalike {
+		t.Errorf("Decrypt = %q, %v; want %q", plaintext, err, lookalike)
+	}
+
+	// Sem chave mestra, o texto puro é marcado para não ser lido como cifrado
+	SetDefault(nil)
+	for _, value := range []string{lookalike, plainPrefix + "token", "plain:token", "token"} {
+		stored, err := Encrypt(value)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if plaintext, err := Decrypt(stored); err != nil || plaintext != value {
+			t.Errorf("Decrypt(Encrypt(%q)) = %q, %v", value, plaintext, err)
+		}
+		// A marcação também é removida depois que a chave mestra é configurada
+		if plaintext, err := keyring.Decrypt(stored); err != nil || plaintext != value {
+			t.Errorf("keyring.Decrypt(%q) = %q, %v", stored, plaintext, err)
+		}
+		if !keyring.NeedsRotation(stored) {
+			t.Errorf("NeedsRotation(%q) = false, want true", stored)
+		}
+	}
+}
+
+func TestDecryptLegacyPlainPrefix(t *testing.T) {
+	// Valores gravados em texto puro antes da marcação são lidos como estão, mesmo com "plain:"
+	legacy := "plain:senha"
+
+	SetDefault(nil)
+	if plaintext, err := Decrypt(legacy); err != nil || plaintext != legacy {
+		t.Errorf("Decrypt(%q) = %q, %v", legacy, plaintext, err)
+	}
+
+	keyring, _ := NewKeyring(newKey(t))
+	if plaintext, err := keyring.Decrypt(legacy); err != nil || plaintext != legacy {
+		t.Errorf("keyring.Decrypt(%q) = %q, %v", legacy, plaintext, err)
+	}
+	if stored, err := Encrypt(legacy); err != nil || stored != legacy {
+		t.Errorf("Encrypt(%q) = %q, %v; want it unchanged", legacy, stored, err)
+	}
+}